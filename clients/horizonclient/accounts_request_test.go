@@ -0,0 +1,175 @@
+package horizonclient
+
+import (
+	"testing"
+
+	"github.com/stellar/go/support/http/httptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountsRequestBuildUrl(t *testing.T) {
+	ar := AccountsRequest{}
+	_, err := ar.BuildURL()
+
+	// error case: no parameters
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid request: no parameters")
+	}
+
+	ar = AccountsRequest{
+		Signer: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU",
+		Asset:  "USD:GDZST3XVCDTUJ76ZAV2HA72KYQODXXZ5PTMAPZGDHZ6CS7RO7MGG3DBM",
+	}
+	_, err = ar.BuildURL()
+
+	// error case: signer and asset
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid request: too many parameters")
+	}
+
+	ar = AccountsRequest{Signer: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU"}
+	endpoint, err := ar.BuildURL()
+
+	// It should return valid accounts endpoint and no errors
+	require.NoError(t, err)
+	assert.Equal(t, "accounts?signer=GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU", endpoint)
+
+	ar = AccountsRequest{
+		Asset:  "USD:GDZST3XVCDTUJ76ZAV2HA72KYQODXXZ5PTMAPZGDHZ6CS7RO7MGG3DBM",
+		Cursor: "GAAAA",
+		Limit:  10,
+		Order:  OrderDesc,
+	}
+	endpoint, err = ar.BuildURL()
+
+	// It should return valid accounts endpoint and no errors
+	require.NoError(t, err)
+	assert.Equal(t, "accounts?asset=USD%3AGDZST3XVCDTUJ76ZAV2HA72KYQODXXZ5PTMAPZGDHZ6CS7RO7MGG3DBM&cursor=GAAAA&limit=10&order=desc", endpoint)
+}
+
+func TestAccountsRequest(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		HorizonURL: "https://localhost/",
+		HTTP:       hmock,
+	}
+
+	accountsRequest := AccountsRequest{Signer: "GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4", Limit: 1}
+
+	hmock.On(
+		"GET",
+		"https://localhost/accounts?limit=1&signer=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4",
+	).ReturnString(200, firstAccountsPage)
+
+	accounts, err := client.Accounts(accountsRequest)
+	if assert.NoError(t, err) {
+		if assert.Len(t, accounts.Embedded.Records, 1) {
+			account := accounts.Embedded.Records[0]
+			assert.Equal(t, "GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4", account.AccountID)
+			assert.Equal(t, "GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4", account.PT)
+		}
+	}
+
+	hmock.On(
+		"GET",
+		"https://horizon-testnet.stellar.org/accounts?cursor=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4&limit=1&order=asc&signer=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4",
+	).ReturnString(200, emptyAccountsPage)
+
+	nextPage, err := client.NextAccountsPage(accounts)
+	if assert.NoError(t, err) {
+		assert.Len(t, nextPage.Embedded.Records, 0)
+	}
+
+	hmock.On(
+		"GET",
+		"https://horizon-testnet.stellar.org/accounts?cursor=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4&limit=1&order=desc&signer=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4",
+	).ReturnString(200, emptyAccountsPage)
+
+	prevPage, err := client.PrevAccountsPage(accounts)
+	if assert.NoError(t, err) {
+		assert.Len(t, prevPage.Embedded.Records, 0)
+	}
+
+	// failure response
+	hmock.On(
+		"GET",
+		"https://localhost/accounts?signer=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4",
+	).ReturnString(400, badRequestResponse)
+
+	_, err = client.Accounts(AccountsRequest{Signer: "GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4"})
+	if assert.Error(t, err) {
+		horizonError, ok := err.(*Error)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, horizonError.Problem.Title, "Bad Request")
+	}
+}
+
+var firstAccountsPage = `{
+  "_links": {
+    "self": {
+      "href": "https://horizon-testnet.stellar.org/accounts?cursor=&limit=1&order=asc&signer=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4"
+    },
+    "next": {
+      "href": "https://horizon-testnet.stellar.org/accounts?cursor=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4&limit=1&order=asc&signer=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4"
+    },
+    "prev": {
+      "href": "https://horizon-testnet.stellar.org/accounts?cursor=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4&limit=1&order=desc&signer=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4"
+    }
+  },
+  "_embedded": {
+    "records": [
+      {
+        "id": "GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4",
+        "account_id": "GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4",
+        "sequence": "3902600647884800",
+        "subentry_count": 0,
+        "last_modified_ledger": 908631,
+        "thresholds": {
+          "low_threshold": 0,
+          "med_threshold": 0,
+          "high_threshold": 0
+        },
+        "flags": {
+          "auth_required": false,
+          "auth_revocable": false,
+          "auth_immutable": false
+        },
+        "balances": [
+          {
+            "balance": "10000.0000000",
+            "buying_liabilities": "0.0000000",
+            "selling_liabilities": "0.0000000",
+            "asset_type": "native"
+          }
+        ],
+        "signers": [
+          {
+            "weight": 1,
+            "key": "GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4",
+            "type": "ed25519_public_key"
+          }
+        ],
+        "data": {},
+        "paging_token": "GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4"
+      }
+    ]
+  }
+}`
+
+var emptyAccountsPage = `{
+  "_links": {
+    "self": {
+      "href": "https://horizon-testnet.stellar.org/accounts?cursor=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4&limit=1&order=asc&signer=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4"
+    },
+    "next": {
+      "href": "https://horizon-testnet.stellar.org/accounts?cursor=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4&limit=1&order=asc&signer=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4"
+    },
+    "prev": {
+      "href": "https://horizon-testnet.stellar.org/accounts?cursor=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4&limit=1&order=desc&signer=GAP2KHWUMOHY7IO37UJY7SEBIITJIDZS5DRIIQRPEUT4VUKHZQGIRWS4"
+    }
+  },
+  "_embedded": {
+    "records": []
+  }
+}`
@@ -0,0 +1,482 @@
+package horizonclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/support/clock"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/txnbuild"
+)
+
+const (
+	// maxBatchOperations is the maximum number of operations of a transaction.
+	maxBatchOperations = 100
+	// defaultBatchBaseFee is the base fee of the transactions of a BatchPayer whose BaseFee is not
+	// set, the minimum base fee of the network.
+	defaultBatchBaseFee = 100
+	// defaultBatchTimeout is the validity of the transactions of a BatchPayer whose Timeout is not
+	// set.
+	defaultBatchTimeout = 5 * time.Minute
+	// batchExpiryMargin is the time waited after the expiry of a transaction before it is
+	// considered as never applied, since ledger close times lag behind local clocks.
+	batchExpiryMargin = time.Minute
+)
+
+// errNotApplied is the outcome of a transaction of a batch which failed or expired.
+var errNotApplied = errors.New("transaction was not applied")
+
+// BatchStatus is the status of a BatchInstruction.
+type BatchStatus string
+
+const (
+	// BatchPending instructions have not been paid yet.
+	BatchPending BatchStatus = "pending"
+	// BatchSubmitted instructions are part of a transaction which was submitted, but whose outcome
+	// is unknown. They are settled when the batch is resumed.
+	BatchSubmitted BatchStatus = "submitted"
+	// BatchSucceeded instructions have been paid.
+	BatchSucceeded BatchStatus = "succeeded"
+	// BatchFailed instructions cannot be paid, see BatchInstructionState.Error.
+	BatchFailed BatchStatus = "failed"
+)
+
+// BatchInstruction is a payment of a batch.
+type BatchInstruction struct {
+	// ID identifies the instruction. It must be unique within a batch.
+	ID          string
+	Destination string
+	Amount      string
+	// Asset is the asset paid, lumens when nil. Destination accounts which do not exist are
+	// created with a create_account operation when paid in lumens.
+	Asset txnbuild.Asset
+	// DestinationExists tells whether the destination account exists. It is looked up with
+	// AccountDetail when nil.
+	DestinationExists *bool
+}
+
+// BatchState tracks the progress of a batch. It can be marshalled to JSON, so that it is saved
+// by BatchPayer.Checkpoint and a batch is resumed after a crash with the saved state.
+type BatchState struct {
+	Instructions map[string]*BatchInstructionState `json:"instructions"`
+	// Transactions are the transactions whose outcome is unknown, by hash.
+	Transactions map[string]*BatchTransaction `json:"transactions"`
+}
+
+// BatchInstructionState is the state of a BatchInstruction.
+type BatchInstructionState struct {
+	Status BatchStatus `json:"status"`
+	// TxHash is the hash of the transaction which paid the instruction, or which was submitted
+	// last.
+	TxHash string `json:"tx_hash,omitempty"`
+	// Error is the reason why the instruction failed.
+	Error string `json:"error,omitempty"`
+	// DestinationExists is set when a transaction failed because the existence of the destination
+	// account was wrong, and overrides BatchInstruction.DestinationExists.
+	DestinationExists *bool `json:"destination_exists,omitempty"`
+}
+
+// BatchTransaction is a transaction of a batch whose outcome is unknown.
+type BatchTransaction struct {
+	Envelope string `json:"envelope"`
+	// Instructions are the IDs of the instructions paid by the operations of the transaction, in
+	// the same order.
+	Instructions []string `json:"instructions"`
+	// MaxTime is the upper time bound of the transaction, as a UNIX timestamp.
+	MaxTime int64 `json:"max_time"`
+}
+
+// BatchPayer pays batches of instructions, packing them into as few transactions as possible.
+// Instructions are paid with create_account operations when their destination account does not
+// exist, with payment operations otherwise.
+//
+// The state of every instruction is tracked in a BatchState, saved by Checkpoint before each
+// submission and after each result. An instruction is only paid again once the transaction it
+// was submitted in is known to have failed, or to have expired without being applied, so a batch
+// which stopped for any reason can be resumed without paying anyone twice.
+//
+// The transactions have no memo, so the instructions paying an account which requires a memo, as
+// defined by SEP-29, fail unless SkipMemoRequiredCheck is set.
+type BatchPayer struct {
+	Client ClientInterface
+	// Network is the passphrase of the network transactions are submitted to.
+	Network string
+	// SourceAccount is the account paying the instructions, and the source account of the
+	// transactions.
+	SourceAccount string
+	// Signers sign every transaction.
+	Signers []keypair.Signer
+	// BaseFee is the base fee of the transactions, 100 stroops when 0.
+	BaseFee uint32
+	// MaxFee is the maximum fee of a transaction, which limits its number of operations. There is
+	// no limit other than the 100 operations of a transaction when 0.
+	MaxFee uint32
+	// Timeout is the validity of the transactions, 5 minutes when 0. A batch is only resumed once
+	// the transactions whose outcome is unknown have expired.
+	Timeout time.Duration
+	// MaxAttempts is the maximum number of consecutive submissions failing with tx_bad_seq before
+	// Pay gives up, 3 when 0.
+	MaxAttempts int
+	// Checkpoint, when set, is called every time state changes. Pay stops when it fails.
+	Checkpoint func(state *BatchState) error
+	// SkipMemoRequiredCheck disables the check of the destination accounts requiring a memo.
+	SkipMemoRequiredCheck bool
+
+	// clock is a Clock returning the current time.
+	clock *clock.Clock
+}
+
+// Pay pays the instructions which are not paid yet according to state, and updates state. state
+// is empty for a new batch, and the state of the previous call to resume a batch.
+//
+// Pay returns nil once every instruction has succeeded or failed, and an error when it stopped
+// before. It can then be called again with the same instructions and state.
+func (p *BatchPayer) Pay(ctx context.Context, instructions []BatchInstruction, state *BatchState) error {
+	if state.Instructions == nil {
+		state.Instructions = map[string]*BatchInstructionState{}
+	}
+	if state.Transactions == nil {
+		state.Transactions = map[string]*BatchTransaction{}
+	}
+	for _, instruction := range instructions {
+		if _, ok := state.Instructions[instruction.ID]; !ok {
+			state.Instructions[instruction.ID] = &BatchInstructionState{Status: BatchPending}
+		}
+	}
+
+	// transactions left with an unknown outcome must be settled before paying anything again
+	for txHash, batchTx := range state.Transactions {
+		if err := p.settle(ctx, state, txHash, batchTx); err != nil {
+			return err
+		}
+	}
+
+	exists := map[string]bool{}
+	badSequences := 0
+	for {
+		var pending []BatchInstruction
+		for _, instruction := range instructions {
+			if state.Instructions[instruction.ID].Status == BatchPending {
+				pending = append(pending, instruction)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		ops, ids, created, err := p.operations(ctx, pending, state, exists)
+		if err != nil {
+			return err
+		}
+		if len(ops) == 0 {
+			// none of the pending instructions can be paid
+			if err = p.checkpoint(state); err != nil {
+				return err
+			}
+			continue
+		}
+
+		badSeq, err := p.submit(ctx, state, ops, ids)
+		if err != nil {
+			return err
+		}
+		for id, destination := range created {
+			if state.Instructions[id].Status == BatchSucceeded {
+				exists[destination] = true
+			}
+		}
+		if badSeq {
+			badSequences++
+			if badSequences >= p.maxAttempts() {
+				return errors.Errorf("transaction not submitted after %d attempts: tx_bad_seq", badSequences)
+			}
+		} else {
+			badSequences = 0
+		}
+	}
+}
+
+// operations returns the operations of the next transaction, paying the first instructions which
+// can be paid, and the IDs of their instructions. created maps the IDs of the instructions paid
+// with create_account operations to their destination. Instructions which cannot be paid are
+// marked as failed.
+func (p *BatchPayer) operations(ctx context.Context, instructions []BatchInstruction, state *BatchState, exists map[string]bool) (ops []txnbuild.Operation, ids []string, created map[string]string, err error) {
+	created = map[string]string{}
+	// creating holds the destinations created by the transaction, paid with payment operations
+	// by the instructions which follow
+	creating := map[string]bool{}
+	for _, instruction := range instructions {
+		if len(ops) == p.maxOperations() {
+			break
+		}
+		instructionState := state.Instructions[instruction.ID]
+		if _, err = amount.Parse(instruction.Amount); err != nil {
+			instructionState.Status = BatchFailed
+			instructionState.Error = "invalid amount: " + err.Error()
+			continue
+		}
+
+		var destinationExists bool
+		destinationExists, err = p.destinationExists(ctx, instruction, instructionState, exists)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		native := instruction.Asset == nil || instruction.Asset.IsNative()
+		switch {
+		case destinationExists || creating[instruction.Destination]:
+			asset := instruction.Asset
+			if asset == nil {
+				asset = txnbuild.NativeAsset{}
+			}
+			ops = append(ops, &txnbuild.Payment{
+				Destination: instruction.Destination,
+				Amount:      instruction.Amount,
+				Asset:       asset,
+			})
+		case native:
+			ops = append(ops, &txnbuild.CreateAccount{
+				Destination: instruction.Destination,
+				Amount:      instruction.Amount,
+			})
+			creating[instruction.Destination] = true
+			created[instruction.ID] = instruction.Destination
+		default:
+			instructionState.Status = BatchFailed
+			instructionState.Error = "destination account does not exist"
+			continue
+		}
+		ids = append(ids, instruction.ID)
+	}
+	return ops, ids, created, nil
+}
+
+// destinationExists reports whether the destination account of instruction exists, looking it up
+// when it is not known yet.
+func (p *BatchPayer) destinationExists(ctx context.Context, instruction BatchInstruction, instructionState *BatchInstructionState, exists map[string]bool) (bool, error) {
+	if instructionState.DestinationExists != nil {
+		return *instructionState.DestinationExists, nil
+	}
+	if destinationExists, ok := exists[instruction.Destination]; ok {
+		return destinationExists, nil
+	}
+	if instruction.DestinationExists != nil {
+		exists[instruction.Destination] = *instruction.DestinationExists
+		return *instruction.DestinationExists, nil
+	}
+
+	_, err := p.Client.AccountDetailWithContext(ctx, AccountRequest{AccountID: instruction.Destination})
+	switch {
+	case isNotFound(err):
+		exists[instruction.Destination] = false
+	case err != nil:
+		return false, errors.Wrapf(err, "looking up destination account %s", instruction.Destination)
+	default:
+		exists[instruction.Destination] = true
+	}
+	return exists[instruction.Destination], nil
+}
+
+// submit builds a transaction made of ops, paying the instructions with the given IDs, and
+// submits it. badSeq is true when the transaction was rejected with tx_bad_seq. When an
+// instruction pays an account which requires a memo, it is marked as failed and nothing is
+// submitted.
+func (p *BatchPayer) submit(ctx context.Context, state *BatchState, ops []txnbuild.Operation, ids []string) (badSeq bool, err error) {
+	account, err := p.Client.AccountDetailWithContext(ctx, AccountRequest{AccountID: p.SourceAccount})
+	if err != nil {
+		return false, errors.Wrap(err, "loading source account")
+	}
+	maxTime := p.clock.Now().Add(p.timeout()).Unix()
+	tx := txnbuild.Transaction{
+		SourceAccount: &account,
+		Operations:    ops,
+		Timebounds:    txnbuild.NewTimebounds(0, maxTime),
+		Network:       p.Network,
+		BaseFee:       p.baseFee(),
+	}
+	if !p.SkipMemoRequiredCheck {
+		err = p.Client.CheckMemoRequiredWithContext(ctx, tx)
+		if memoErr, ok := errors.Cause(err).(*AccountRequiresMemoError); ok {
+			instructionState := state.Instructions[ids[memoErr.OperationIndex]]
+			instructionState.Status = BatchFailed
+			instructionState.Error = "destination account requires a memo"
+			return false, p.checkpoint(state)
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	txeBase64, err := tx.BuildSignEncode(p.Signers...)
+	if err != nil {
+		return false, errors.Wrap(err, "building transaction")
+	}
+	txHash, err := tx.HashHex()
+	if err != nil {
+		return false, errors.Wrap(err, "hashing transaction")
+	}
+
+	// the transaction is saved before it is submitted, so that it is settled if Pay stops before
+	// knowing its outcome
+	batchTx := &BatchTransaction{Envelope: txeBase64, Instructions: ids, MaxTime: maxTime}
+	state.Transactions[txHash] = batchTx
+	for _, id := range ids {
+		state.Instructions[id].Status = BatchSubmitted
+		state.Instructions[id].TxHash = txHash
+	}
+	if err = p.checkpoint(state); err != nil {
+		return false, err
+	}
+
+	_, submitErr := p.Client.SubmitTransactionXDRWithContext(ctx, txeBase64)
+	return isBadSequence(submitErr), p.result(state, txHash, submitErr)
+}
+
+// settle resolves the outcome of a transaction whose outcome was unknown when the batch stopped.
+// It is looked up, and submitted again when not found and not expired yet.
+func (p *BatchPayer) settle(ctx context.Context, state *BatchState, txHash string, batchTx *BatchTransaction) error {
+	tx, err := p.Client.TransactionDetailWithContext(ctx, txHash)
+	switch {
+	case err == nil && tx.Successful:
+		return p.result(state, txHash, nil)
+	case err == nil:
+		// the transaction failed, and none of its operations were applied
+		return p.result(state, txHash, errNotApplied)
+	case !isNotFound(err):
+		return errors.Wrapf(err, "looking up transaction %s", txHash)
+	}
+
+	if p.clock.Now().After(time.Unix(batchTx.MaxTime, 0).Add(batchExpiryMargin)) {
+		// the transaction expired without being applied
+		return p.result(state, txHash, errNotApplied)
+	}
+
+	// the transaction may still be applied: the same envelope is submitted again, which can only
+	// be applied once
+	_, submitErr := p.Client.SubmitTransactionXDRWithContext(ctx, batchTx.Envelope)
+	if isBadSequence(submitErr) {
+		// the sequence number was consumed, maybe by the transaction itself
+		tx, err = p.Client.TransactionDetailWithContext(ctx, txHash)
+		if err == nil && tx.Successful {
+			return p.result(state, txHash, nil)
+		}
+		return errors.Errorf("outcome of transaction %s is unknown until it expires", txHash)
+	}
+	return p.result(state, txHash, submitErr)
+}
+
+// result updates the instructions of the transaction with hash txHash after its submission failed
+// with submitErr, or succeeded when submitErr is nil. Only a rejection with transaction result
+// codes, or errNotApplied, means that nothing was applied: after any other error, e.g. a 5xx
+// response from horizon or a proxy, the instructions are left as submitted and an error is
+// returned.
+func (p *BatchPayer) result(state *BatchState, txHash string, submitErr error) error {
+	if submitErr != nil && submitErr != errNotApplied && !isRejected(submitErr) {
+		// the transaction may have reached stellar-core: it is settled when the batch is resumed
+		return errors.Wrapf(submitErr, "outcome of transaction %s is unknown", txHash)
+	}
+	batchTx, ok := state.Transactions[txHash]
+	if !ok {
+		return nil
+	}
+	delete(state.Transactions, txHash)
+
+	for i, id := range batchTx.Instructions {
+		instructionState := state.Instructions[id]
+		if instructionState == nil || instructionState.Status != BatchSubmitted {
+			continue
+		}
+		if submitErr == nil {
+			instructionState.Status = BatchSucceeded
+			continue
+		}
+
+		// nothing was applied, so the instruction is paid again unless its operation failed
+		instructionState.Status = BatchPending
+		switch code := operationCode(submitErr, i); code {
+		case "", "op_success":
+		case "op_no_destination":
+			destinationExists := false
+			instructionState.DestinationExists = &destinationExists
+		case "op_already_exists":
+			destinationExists := true
+			instructionState.DestinationExists = &destinationExists
+		default:
+			instructionState.Status = BatchFailed
+			instructionState.Error = code
+		}
+	}
+	if err := p.checkpoint(state); err != nil {
+		return err
+	}
+
+	if submitErr == nil || submitErr == errNotApplied || isTransactionCode(submitErr, "tx_failed") ||
+		isBadSequence(submitErr) || isTransactionCode(submitErr, "tx_too_late") {
+		return nil
+	}
+	return errors.Wrapf(submitErr, "submitting transaction %s", txHash)
+}
+
+func (p *BatchPayer) checkpoint(state *BatchState) error {
+	if p.Checkpoint == nil {
+		return nil
+	}
+	return errors.Wrap(p.Checkpoint(state), "checkpoint failed")
+}
+
+func (p *BatchPayer) maxOperations() int {
+	maxOperations := maxBatchOperations
+	if p.MaxFee > 0 && int(p.MaxFee/p.baseFee()) < maxOperations {
+		maxOperations = int(p.MaxFee / p.baseFee())
+	}
+	if maxOperations < 1 {
+		return 1
+	}
+	return maxOperations
+}
+
+func (p *BatchPayer) baseFee() uint32 {
+	if p.BaseFee == 0 {
+		return defaultBatchBaseFee
+	}
+	return p.BaseFee
+}
+
+func (p *BatchPayer) timeout() time.Duration {
+	if p.Timeout <= 0 {
+		return defaultBatchTimeout
+	}
+	return p.Timeout
+}
+
+func (p *BatchPayer) maxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return defaultSubmitAttempts
+	}
+	return p.MaxAttempts
+}
+
+// isRejected reports whether err is a definitive rejection of a transaction by horizon, a 400
+// response with transaction result codes, in which case nothing was applied.
+func isRejected(err error) bool {
+	herr, ok := errors.Cause(err).(*Error)
+	if !ok || herr.Response == nil || herr.Response.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	codes, err := herr.ResultCodes()
+	return err == nil && codes.TransactionCode != ""
+}
+
+// operationCode returns the result code of the operation at index i of a transaction rejected
+// with tx_failed, or an empty string when err is not such a rejection.
+func operationCode(err error, i int) string {
+	if !isTransactionCode(err, "tx_failed") {
+		return ""
+	}
+	codes, _ := errors.Cause(err).(*Error).ResultCodes()
+	if i >= len(codes.OperationCodes) {
+		return ""
+	}
+	return codes.OperationCodes[i]
+}
@@ -0,0 +1,247 @@
+package horizonclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/support/clock"
+	"github.com/stellar/go/support/clock/clocktest"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newBatchPayer returns a BatchPayer submitting to client, and a function recording the operations
+// of the transactions submitted, to be run by the submission mocks. None of the destinations
+// require a memo, unless memo checks are mocked before.
+func newBatchPayer(t *testing.T, client *MockClient) (*BatchPayer, *[][]xdr.OperationType, func(mock.Arguments)) {
+	source := keypair.MustParse("SBPQUZ6G4FZNWFHKUWC5BEYWF6R52E3SEP7R3GWYSM2XTKGF5LNTWW4R").(*keypair.Full)
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: source.Address()}).
+		Return(hProtocol.Account{AccountID: source.Address(), Sequence: "100"}, nil)
+	client.On("CheckMemoRequiredWithContext", mock.Anything, mock.Anything).Return(nil)
+
+	var transactions [][]xdr.OperationType
+	record := func(args mock.Arguments) {
+		var envelope xdr.TransactionEnvelope
+		require.NoError(t, xdr.SafeUnmarshalBase64(args.String(1), &envelope))
+		var ops []xdr.OperationType
+		for _, op := range envelope.Tx.Operations {
+			ops = append(ops, op.Body.Type)
+		}
+		transactions = append(transactions, ops)
+	}
+
+	return &BatchPayer{
+		Client:        client,
+		Network:       network.TestNetworkPassphrase,
+		SourceAccount: source.Address(),
+		Signers:       []keypair.Signer{source},
+	}, &transactions, record
+}
+
+func TestBatchPayerPay(t *testing.T) {
+	existing := keypair.MustRandom().Address()
+	missing := keypair.MustRandom().Address()
+	missingForUSD := keypair.MustRandom().Address()
+	notLookedUp := keypair.MustRandom().Address()
+	usd := txnbuild.CreditAsset{Code: "USD", Issuer: existing}
+	exists := true
+
+	client := &MockClient{}
+	payer, transactions, record := newBatchPayer(t, client)
+	payer.MaxFee = 200
+	var checkpoints int
+	payer.Checkpoint = func(state *BatchState) error {
+		checkpoints++
+		return nil
+	}
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: existing}).
+		Return(hProtocol.Account{AccountID: existing}, nil).Once()
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: missing}).
+		Return(hProtocol.Account{}, submitterError(404, "")).Once()
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: missingForUSD}).
+		Return(hProtocol.Account{}, submitterError(404, "")).Once()
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{}, nil).Run(record).Twice()
+
+	instructions := []BatchInstruction{
+		{ID: "1", Destination: existing, Amount: "10"},
+		{ID: "2", Destination: missing, Amount: "5"},
+		{ID: "3", Destination: missing, Amount: "1"},
+		{ID: "4", Destination: missingForUSD, Amount: "1", Asset: usd},
+		{ID: "5", Destination: notLookedUp, Amount: "1", Asset: usd, DestinationExists: &exists},
+		{ID: "6", Destination: existing, Amount: "ten"},
+	}
+	state := &BatchState{}
+	require.NoError(t, payer.Pay(context.Background(), instructions, state))
+	client.AssertExpectations(t)
+
+	// the instructions are packed into transactions of at most 2 operations because of MaxFee,
+	// and the missing account is created before being paid
+	assert.Equal(t, [][]xdr.OperationType{
+		{xdr.OperationTypePayment, xdr.OperationTypeCreateAccount},
+		{xdr.OperationTypePayment, xdr.OperationTypePayment},
+	}, *transactions)
+	for _, id := range []string{"1", "2", "3", "5"} {
+		assert.Equal(t, BatchSucceeded, state.Instructions[id].Status, id)
+	}
+	assert.Equal(t, BatchFailed, state.Instructions["4"].Status)
+	assert.Equal(t, "destination account does not exist", state.Instructions["4"].Error)
+	assert.Equal(t, BatchFailed, state.Instructions["6"].Status)
+	assert.Empty(t, state.Transactions)
+	assert.Equal(t, 5, checkpoints)
+
+	// paying a batch again does nothing
+	require.NoError(t, payer.Pay(context.Background(), instructions, state))
+	assert.Len(t, *transactions, 2)
+}
+
+func TestBatchPayerFailedOperations(t *testing.T) {
+	destination := keypair.MustRandom().Address()
+	other := keypair.MustRandom().Address()
+	exists := true
+
+	client := &MockClient{}
+	payer, transactions, record := newBatchPayer(t, client)
+	txFailed := submitterError(400, "tx_failed")
+	txFailed.Problem.Extras["result_codes"].(map[string]interface{})["operations"] =
+		[]interface{}{"op_no_destination", "op_underfunded"}
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{}, txFailed).Run(record).Once()
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{}, nil).Run(record).Once()
+
+	instructions := []BatchInstruction{
+		{ID: "1", Destination: destination, Amount: "10", DestinationExists: &exists},
+		{ID: "2", Destination: other, Amount: "10000", DestinationExists: &exists},
+	}
+	state := &BatchState{}
+	require.NoError(t, payer.Pay(context.Background(), instructions, state))
+	client.AssertExpectations(t)
+
+	// the destination which did not exist is created instead
+	assert.Equal(t, [][]xdr.OperationType{
+		{xdr.OperationTypePayment, xdr.OperationTypePayment},
+		{xdr.OperationTypeCreateAccount},
+	}, *transactions)
+	assert.Equal(t, BatchSucceeded, state.Instructions["1"].Status)
+	assert.Equal(t, BatchFailed, state.Instructions["2"].Status)
+	assert.Equal(t, "op_underfunded", state.Instructions["2"].Error)
+}
+
+func TestBatchPayerResume(t *testing.T) {
+	destination := keypair.MustRandom().Address()
+	exists := true
+	instructions := []BatchInstruction{{ID: "1", Destination: destination, Amount: "10", DestinationExists: &exists}}
+
+	client := &MockClient{}
+	payer, transactions, record := newBatchPayer(t, client)
+	now := time.Now()
+	payer.clock = &clock.Clock{Source: clocktest.FixedSource(now)}
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{}, submitterError(504, "")).Run(record).Once()
+
+	// the outcome of the transaction is unknown
+	state := &BatchState{}
+	assert.Error(t, payer.Pay(context.Background(), instructions, state))
+	client.AssertExpectations(t)
+	assert.Equal(t, BatchSubmitted, state.Instructions["1"].Status)
+	txHash := state.Instructions["1"].TxHash
+	require.Contains(t, state.Transactions, txHash)
+	envelope := state.Transactions[txHash].Envelope
+
+	// the transaction is not found and has not expired: the same envelope is submitted again
+	client.On("TransactionDetailWithContext", mock.Anything, txHash).
+		Return(hProtocol.Transaction{}, submitterError(404, "")).Once()
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, envelope).
+		Return(hProtocol.TransactionSuccess{}, submitterError(504, "")).Run(record).Once()
+	assert.Error(t, payer.Pay(context.Background(), instructions, state))
+	client.AssertExpectations(t)
+	assert.Equal(t, BatchSubmitted, state.Instructions["1"].Status)
+
+	// the transaction expired: the instruction is paid with a new transaction
+	payer.clock.Source = clocktest.FixedSource(now.Add(defaultBatchTimeout + 2*batchExpiryMargin))
+	client.On("TransactionDetailWithContext", mock.Anything, txHash).
+		Return(hProtocol.Transaction{}, submitterError(404, "")).Once()
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{}, nil).Run(record).Once()
+	require.NoError(t, payer.Pay(context.Background(), instructions, state))
+	client.AssertExpectations(t)
+	assert.Equal(t, BatchSucceeded, state.Instructions["1"].Status)
+	assert.NotEqual(t, txHash, state.Instructions["1"].TxHash)
+	assert.NotContains(t, state.Transactions, txHash)
+	assert.Len(t, *transactions, 3)
+
+	// a transaction found on the network is not submitted again
+	state = &BatchState{
+		Instructions: map[string]*BatchInstructionState{"1": {Status: BatchSubmitted, TxHash: "abc"}},
+		Transactions: map[string]*BatchTransaction{"abc": {Envelope: envelope, Instructions: []string{"1"}}},
+	}
+	client.On("TransactionDetailWithContext", mock.Anything, "abc").
+		Return(hProtocol.Transaction{Hash: "abc", Successful: true}, nil).Once()
+	require.NoError(t, payer.Pay(context.Background(), instructions, state))
+	client.AssertExpectations(t)
+	assert.Equal(t, BatchSucceeded, state.Instructions["1"].Status)
+	assert.Len(t, *transactions, 3)
+}
+
+func TestBatchPayerServerError(t *testing.T) {
+	destination := keypair.MustRandom().Address()
+	exists := true
+	instructions := []BatchInstruction{{ID: "1", Destination: destination, Amount: "10", DestinationExists: &exists}}
+
+	client := &MockClient{}
+	payer, transactions, record := newBatchPayer(t, client)
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{}, submitterError(502, "")).Run(record).Once()
+
+	// the envelope may have reached stellar-core before the gateway failed: the instruction is
+	// not paid again
+	state := &BatchState{}
+	assert.Error(t, payer.Pay(context.Background(), instructions, state))
+	client.AssertExpectations(t)
+	assert.Equal(t, BatchSubmitted, state.Instructions["1"].Status)
+	txHash := state.Instructions["1"].TxHash
+	require.Contains(t, state.Transactions, txHash)
+
+	// the transaction is then found in a ledger
+	client.On("TransactionDetailWithContext", mock.Anything, txHash).
+		Return(hProtocol.Transaction{Hash: txHash, Successful: true}, nil).Once()
+	require.NoError(t, payer.Pay(context.Background(), instructions, state))
+	client.AssertExpectations(t)
+	assert.Equal(t, BatchSucceeded, state.Instructions["1"].Status)
+	assert.Empty(t, state.Transactions)
+	assert.Len(t, *transactions, 1)
+}
+
+func TestBatchPayerMemoRequired(t *testing.T) {
+	destination := keypair.MustRandom().Address()
+	requiresMemo := keypair.MustRandom().Address()
+	exists := true
+	instructions := []BatchInstruction{
+		{ID: "1", Destination: destination, Amount: "10", DestinationExists: &exists},
+		{ID: "2", Destination: requiresMemo, Amount: "10", DestinationExists: &exists},
+	}
+
+	client := &MockClient{}
+	client.On("CheckMemoRequiredWithContext", mock.Anything, mock.Anything).
+		Return(&AccountRequiresMemoError{AccountID: requiresMemo, OperationIndex: 1}).Once()
+	payer, transactions, record := newBatchPayer(t, client)
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{}, nil).Run(record).Once()
+
+	// the instruction paying the account requiring a memo fails, the other one is paid
+	state := &BatchState{}
+	require.NoError(t, payer.Pay(context.Background(), instructions, state))
+	client.AssertExpectations(t)
+	assert.Equal(t, [][]xdr.OperationType{{xdr.OperationTypePayment}}, *transactions)
+	assert.Equal(t, BatchSucceeded, state.Instructions["1"].Status)
+	assert.Equal(t, BatchFailed, state.Instructions["2"].Status)
+	assert.Equal(t, "destination account requires a memo", state.Instructions["2"].Error)
+}
@@ -0,0 +1,291 @@
+package horizonclient
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/stellar/go/keypair"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	// defaultMaxChannels is the number of channel accounts of a ChannelPool whose MaxChannels and
+	// Channels are not set.
+	defaultMaxChannels = 10
+	// defaultChannelBalance is the starting balance of the channel accounts created by a
+	// ChannelPool whose ChannelBalance is not set.
+	defaultChannelBalance = "5"
+)
+
+// ChannelPool submits transactions in parallel through channel accounts. The sequence number of an
+// account is consumed by every transaction it is the source of, so the transactions of a single
+// account have to be submitted one after the other. Using a channel account as the source of each
+// transaction, while the operations keep their own source accounts, lifts that limit: the
+// transactions of different channels can be submitted at the same time.
+//
+// Channel accounts are leased to one goroutine at a time. The pool tracks their sequence numbers,
+// reloading them after failed submissions, and creates the channel accounts missing on the network
+// from the base account, which also funds the channels running out of lumens.
+type ChannelPool struct {
+	Client ClientInterface
+	// Network is the passphrase of the network transactions are submitted to.
+	Network string
+	// BaseKeypair is the keypair of the base account, which creates and funds channel accounts.
+	BaseKeypair *keypair.Full
+	// Channels are the keypairs of the channel accounts. The accounts which do not exist are
+	// created when they are first leased.
+	Channels []*keypair.Full
+	// MaxChannels is the maximum number of channel accounts. When it is higher than the number of
+	// Channels, new channel accounts are created with random keypairs. It defaults to the number of
+	// Channels, or 10 when Channels is empty.
+	MaxChannels int
+	// ChannelBalance is the starting balance of the channel accounts created by the pool, and the
+	// amount sent to the channel accounts running out of lumens. It defaults to 5 lumens.
+	ChannelBalance string
+	// BaseFee is the base fee of the transactions, txnbuild's default when 0.
+	BaseFee uint32
+	// SkipMemoRequiredCheck disables the check of the destination accounts requiring a memo, see
+	// TransactionSubmitter.
+	SkipMemoRequiredCheck bool
+
+	mutex   sync.Mutex
+	idle    chan *Channel
+	created int
+	// baseMutex serializes the transactions of the base account
+	baseMutex sync.Mutex
+}
+
+// Channel is a channel account leased from a ChannelPool. It must be used by a single goroutine
+// and released once done.
+type Channel struct {
+	Keypair *keypair.Full
+
+	pool    *ChannelPool
+	account txnbuild.SimpleAccount
+	// synced is false until the sequence number of the account is loaded, and after failures
+	synced bool
+	// underfunded is set when a transaction was rejected because the account ran out of lumens
+	underfunded bool
+	// released is set to 1 by Release, until the channel is leased again
+	released int32
+}
+
+// Lease returns a channel which is not used by any other goroutine, waiting for one to be released
+// when they are all leased.
+func (p *ChannelPool) Lease(ctx context.Context) (*Channel, error) {
+	p.mutex.Lock()
+	if p.idle == nil {
+		p.idle = make(chan *Channel, p.maxChannels())
+	}
+	select {
+	case channel := <-p.idle:
+		p.mutex.Unlock()
+		atomic.StoreInt32(&channel.released, 0)
+		return channel, nil
+	default:
+	}
+
+	if p.created < p.maxChannels() {
+		var kp *keypair.Full
+		if p.created < len(p.Channels) {
+			kp = p.Channels[p.created]
+		} else {
+			var err error
+			kp, err = keypair.Random()
+			if err != nil {
+				p.mutex.Unlock()
+				return nil, errors.Wrap(err, "generating channel keypair")
+			}
+		}
+		p.created++
+		p.mutex.Unlock()
+		return &Channel{Keypair: kp, pool: p}, nil
+	}
+
+	idle := p.idle
+	p.mutex.Unlock()
+	select {
+	case channel := <-idle:
+		atomic.StoreInt32(&channel.released, 0)
+		return channel, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Submit leases a channel, submits a transaction made of ops through it and releases it. See
+// Channel.Submit.
+func (p *ChannelPool) Submit(ops []txnbuild.Operation, signers ...keypair.Signer) (hProtocol.TransactionSuccess, error) {
+	return p.SubmitWithContext(context.Background(), ops, signers...)
+}
+
+// SubmitWithContext is the same as Submit, but waiting for a channel and the requests are cancelled
+// when ctx is done.
+func (p *ChannelPool) SubmitWithContext(ctx context.Context, ops []txnbuild.Operation, signers ...keypair.Signer) (hProtocol.TransactionSuccess, error) {
+	channel, err := p.Lease(ctx)
+	if err != nil {
+		return hProtocol.TransactionSuccess{}, errors.Wrap(err, "leasing channel")
+	}
+	defer channel.Release()
+	return channel.SubmitWithContext(ctx, ops, signers...)
+}
+
+func (p *ChannelPool) maxChannels() int {
+	if p.MaxChannels > 0 {
+		return p.MaxChannels
+	}
+	if len(p.Channels) > 0 {
+		return len(p.Channels)
+	}
+	return defaultMaxChannels
+}
+
+func (p *ChannelPool) channelBalance() string {
+	if p.ChannelBalance == "" {
+		return defaultChannelBalance
+	}
+	return p.ChannelBalance
+}
+
+// submitFromBase submits a transaction made of op from the base account.
+func (p *ChannelPool) submitFromBase(ctx context.Context, op txnbuild.Operation) error {
+	if p.BaseKeypair == nil {
+		return errors.New("channel pool has no base account")
+	}
+	p.baseMutex.Lock()
+	defer p.baseMutex.Unlock()
+
+	base, err := p.Client.AccountDetailWithContext(ctx, AccountRequest{AccountID: p.BaseKeypair.Address()})
+	if err != nil {
+		return errors.Wrap(err, "loading base account")
+	}
+	// the destinations of the base account are the pool's own channel accounts
+	submitter := TransactionSubmitter{
+		Client:                p.Client,
+		Signers:               []keypair.Signer{p.BaseKeypair},
+		SkipMemoRequiredCheck: true,
+	}
+	_, err = submitter.SubmitWithContext(ctx, txnbuild.Transaction{
+		SourceAccount: &base,
+		Operations:    []txnbuild.Operation{op},
+		Timebounds:    txnbuild.NewInfiniteTimeout(),
+		Network:       p.Network,
+		BaseFee:       p.BaseFee,
+	})
+	return err
+}
+
+// Address returns the address of the channel account.
+func (c *Channel) Address() string {
+	return c.Keypair.Address()
+}
+
+// Release returns the channel to its pool. The channel must not be used afterwards. Releasing a
+// channel again before it is leased again does nothing.
+func (c *Channel) Release() {
+	if atomic.CompareAndSwapInt32(&c.released, 0, 1) {
+		c.pool.idle <- c
+	}
+}
+
+// Submit submits a transaction made of ops, using the channel account as the source account of
+// the transaction. Every operation must have a source account, otherwise it would be applied to
+// the channel account. The transaction is signed by the channel account and by signers, which
+// must include the signers of the source accounts of the operations.
+//
+// Submissions are made with a TransactionSubmitter. After a failed submission the sequence number
+// of the channel account is reloaded, and the account is funded from the base account when the
+// transaction was rejected with tx_insufficient_balance.
+func (c *Channel) Submit(ops []txnbuild.Operation, signers ...keypair.Signer) (hProtocol.TransactionSuccess, error) {
+	return c.SubmitWithContext(context.Background(), ops, signers...)
+}
+
+// SubmitWithContext is the same as Submit, but the requests are cancelled when ctx is done.
+func (c *Channel) SubmitWithContext(ctx context.Context, ops []txnbuild.Operation, signers ...keypair.Signer) (hProtocol.TransactionSuccess, error) {
+	for i, op := range ops {
+		if op.GetSourceAccount() == nil {
+			return hProtocol.TransactionSuccess{}, errors.Errorf("operation %d has no source account", i)
+		}
+	}
+	if err := c.sync(ctx); err != nil {
+		return hProtocol.TransactionSuccess{}, err
+	}
+
+	submitter := TransactionSubmitter{
+		Client:                c.pool.Client,
+		Signers:               append([]keypair.Signer{c.Keypair}, signers...),
+		SkipMemoRequiredCheck: c.pool.SkipMemoRequiredCheck,
+	}
+	txSuccess, err := submitter.SubmitWithContext(ctx, txnbuild.Transaction{
+		SourceAccount: &c.account,
+		Operations:    ops,
+		Timebounds:    txnbuild.NewInfiniteTimeout(),
+		Network:       c.pool.Network,
+		BaseFee:       c.pool.BaseFee,
+	})
+	if err != nil {
+		// the sequence number may or may not have been consumed
+		c.synced = false
+		c.underfunded = isTransactionCode(err, "tx_insufficient_balance")
+		return txSuccess, err
+	}
+
+	// the submitter rebuilds the transaction with a reloaded sequence number after tx_bad_seq, so
+	// the sequence number is taken from the envelope which was applied
+	if txSuccess.Env != "" {
+		var envelope xdr.TransactionEnvelope
+		if xdr.SafeUnmarshalBase64(txSuccess.Env, &envelope) == nil {
+			c.account.Sequence = int64(envelope.Tx.SeqNum)
+		} else {
+			c.synced = false
+		}
+	}
+	return txSuccess, nil
+}
+
+// sync funds the channel account when it ran out of lumens, and loads its sequence number when
+// needed. The account is created when it does not exist.
+func (c *Channel) sync(ctx context.Context) error {
+	if c.underfunded {
+		err := c.pool.submitFromBase(ctx, &txnbuild.Payment{
+			Destination: c.Address(),
+			Amount:      c.pool.channelBalance(),
+			Asset:       txnbuild.NativeAsset{},
+		})
+		if err != nil {
+			return errors.Wrap(err, "funding channel account")
+		}
+		c.underfunded = false
+	}
+	if c.synced {
+		return nil
+	}
+
+	request := AccountRequest{AccountID: c.Address()}
+	account, err := c.pool.Client.AccountDetailWithContext(ctx, request)
+	if isNotFound(err) {
+		err = c.pool.submitFromBase(ctx, &txnbuild.CreateAccount{
+			Destination: c.Address(),
+			Amount:      c.pool.channelBalance(),
+		})
+		if err != nil {
+			return errors.Wrap(err, "creating channel account")
+		}
+		account, err = c.pool.Client.AccountDetailWithContext(ctx, request)
+	}
+	if err != nil {
+		return errors.Wrap(err, "loading channel account")
+	}
+
+	sequence, err := account.GetSequenceNumber()
+	if err != nil {
+		return errors.Wrap(err, "invalid channel account sequence number")
+	}
+	c.account = txnbuild.SimpleAccount{AccountID: c.Address(), Sequence: int64(sequence)}
+	c.synced = true
+	return nil
+}
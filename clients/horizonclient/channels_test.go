@@ -0,0 +1,157 @@
+package horizonclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelPoolSubmit(t *testing.T) {
+	sender := keypair.MustParse("SBPQUZ6G4FZNWFHKUWC5BEYWF6R52E3SEP7R3GWYSM2XTKGF5LNTWW4R").(*keypair.Full)
+	channelKP := keypair.MustParse("SBMSVD4KKELKGZXHBUQTIROWUAPQASDX7KEJITARP4VMZ6KLUHOGPTYW").(*keypair.Full)
+	client := &MockClient{}
+	pool := &ChannelPool{Client: client, Network: network.TestNetworkPassphrase, Channels: []*keypair.Full{channelKP}}
+	client.On("CheckMemoRequiredWithContext", mock.Anything, mock.Anything).Return(nil)
+
+	var envelopes []xdr.TransactionEnvelope
+	recordEnvelope := func(args mock.Arguments) {
+		var envelope xdr.TransactionEnvelope
+		require.NoError(t, xdr.SafeUnmarshalBase64(args.String(1), &envelope))
+		envelopes = append(envelopes, envelope)
+	}
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: channelKP.Address()}).
+		Return(hProtocol.Account{AccountID: channelKP.Address(), Sequence: "100"}, nil).Once()
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{Hash: "abc"}, nil).Run(recordEnvelope).Twice()
+
+	ops := []txnbuild.Operation{&txnbuild.Payment{
+		Destination:   channelKP.Address(),
+		Amount:        "10",
+		Asset:         txnbuild.NativeAsset{},
+		SourceAccount: &txnbuild.SimpleAccount{AccountID: sender.Address()},
+	}}
+	for i := 0; i < 2; i++ {
+		resp, err := pool.Submit(ops, sender)
+		require.NoError(t, err)
+		assert.Equal(t, "abc", resp.Hash)
+	}
+	client.AssertExpectations(t)
+
+	// the channel is the source of the transactions, and its sequence number is tracked
+	require.Len(t, envelopes, 2)
+	for i, envelope := range envelopes {
+		assert.Equal(t, channelKP.Address(), envelope.Tx.SourceAccount.Address())
+		assert.Equal(t, xdr.SequenceNumber(101+i), envelope.Tx.SeqNum)
+		assert.Equal(t, sender.Address(), envelope.Tx.Operations[0].SourceAccount.Address())
+		assert.Len(t, envelope.Signatures, 2)
+	}
+
+	_, err := pool.Submit([]txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 1}})
+	assert.EqualError(t, err, "operation 0 has no source account")
+}
+
+func TestChannelPoolCreatesAndFundsChannels(t *testing.T) {
+	base := keypair.MustParse("SBPQUZ6G4FZNWFHKUWC5BEYWF6R52E3SEP7R3GWYSM2XTKGF5LNTWW4R").(*keypair.Full)
+	channelKP := keypair.MustParse("SBMSVD4KKELKGZXHBUQTIROWUAPQASDX7KEJITARP4VMZ6KLUHOGPTYW").(*keypair.Full)
+	client := &MockClient{}
+	pool := &ChannelPool{
+		Client:         client,
+		Network:        network.TestNetworkPassphrase,
+		BaseKeypair:    base,
+		Channels:       []*keypair.Full{channelKP},
+		ChannelBalance: "2",
+	}
+	client.On("CheckMemoRequiredWithContext", mock.Anything, mock.Anything).Return(nil)
+
+	var operations []xdr.OperationType
+	recordOperation := func(args mock.Arguments) {
+		var envelope xdr.TransactionEnvelope
+		require.NoError(t, xdr.SafeUnmarshalBase64(args.String(1), &envelope))
+		operations = append(operations, envelope.Tx.Operations[0].Body.Type)
+	}
+	baseAccount := hProtocol.Account{AccountID: base.Address(), Sequence: "10"}
+	channelAccount := hProtocol.Account{AccountID: channelKP.Address(), Sequence: "200"}
+
+	// the channel account does not exist yet: it is created from the base account, then the
+	// transaction is rejected because the channel ran out of lumens
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: channelKP.Address()}).
+		Return(hProtocol.Account{}, submitterError(404, "")).Once()
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: base.Address()}).
+		Return(baseAccount, nil).Once()
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{}, nil).Run(recordOperation).Once()
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: channelKP.Address()}).
+		Return(channelAccount, nil).Once()
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{}, submitterError(400, "tx_insufficient_balance")).Run(recordOperation).Once()
+
+	ops := []txnbuild.Operation{&txnbuild.BumpSequence{
+		BumpTo:        1,
+		SourceAccount: &txnbuild.SimpleAccount{AccountID: base.Address()},
+	}}
+	_, err := pool.Submit(ops, base)
+	assert.Error(t, err)
+	client.AssertExpectations(t)
+
+	// the channel is funded from the base account before the next transaction, and its sequence
+	// number is reloaded
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: base.Address()}).
+		Return(baseAccount, nil).Once()
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{}, nil).Run(recordOperation).Once()
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: channelKP.Address()}).
+		Return(channelAccount, nil).Once()
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{Hash: "abc"}, nil).Run(recordOperation).Once()
+
+	resp, err := pool.Submit(ops, base)
+	require.NoError(t, err)
+	assert.Equal(t, "abc", resp.Hash)
+	client.AssertExpectations(t)
+
+	assert.Equal(t, []xdr.OperationType{
+		xdr.OperationTypeCreateAccount,
+		xdr.OperationTypeBumpSequence,
+		xdr.OperationTypePayment,
+		xdr.OperationTypeBumpSequence,
+	}, operations)
+	// the transactions of the base account funding the channel are not checked for memos
+	client.AssertNumberOfCalls(t, "CheckMemoRequiredWithContext", 2)
+}
+
+func TestChannelPoolLease(t *testing.T) {
+	pool := &ChannelPool{Client: &MockClient{}, MaxChannels: 2}
+
+	first, err := pool.Lease(context.Background())
+	require.NoError(t, err)
+	second, err := pool.Lease(context.Background())
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Address(), second.Address())
+
+	// all the channels are leased
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = pool.Lease(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// releasing a channel twice returns it to the pool once
+	second.Release()
+	second.Release()
+	channel, err := pool.Lease(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, second, channel)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = pool.Lease(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
@@ -22,7 +22,7 @@ import (
 )
 
 // sendRequest builds the URL for the given horizon request and sends the url to a horizon server
-func (c *Client) sendRequest(hr HorizonRequest, resp interface{}) (err error) {
+func (c *Client) sendRequest(ctx context.Context, hr HorizonRequest, resp interface{}) (err error) {
 	endpoint, err := hr.BuildURL()
 	if err != nil {
 		return
@@ -31,42 +31,74 @@ func (c *Client) sendRequest(hr HorizonRequest, resp interface{}) (err error) {
 	c.HorizonURL = c.fixHorizonURL()
 	_, ok := hr.(submitRequest)
 	if ok {
-		return c.sendRequestURL(c.HorizonURL+endpoint, "post", resp)
+		return c.sendRequestURL(ctx, c.HorizonURL+endpoint, "post", resp)
 	}
 
-	return c.sendRequestURL(c.HorizonURL+endpoint, "get", resp)
+	return c.sendRequestURL(ctx, c.HorizonURL+endpoint, "get", resp)
 }
 
 // sendRequestURL sends a url to a horizon server.
 // It can be used for requests that do not implement the HorizonRequest interface.
-func (c *Client) sendRequestURL(requestURL string, method string, a interface{}) (err error) {
+// The request is cancelled when ctx is done or when the client's horizon timeout elapses,
+// whichever happens first. Failed requests are retried according to the client's RetryPolicy.
+func (c *Client) sendRequestURL(ctx context.Context, requestURL string, method string, a interface{}) (err error) {
+	c.setDefaultClient()
+	if c.horizonTimeOut == 0 {
+		c.horizonTimeOut = HorizonTimeOut
+	}
+	isSubmission := method == "post" || method == "POST"
+
+	for attempt := 0; ; attempt++ {
+		err = c.RetryPolicy.waitForRateLimit(ctx, requestURL, c.clock.Now())
+		if err != nil {
+			return errors.Wrap(err, "waiting for rate limit reset")
+		}
+
+		var resp *http.Response
+		info := c.requestStarted(requestURL, method, attempt, false)
+		resp, err = c.attemptRequest(ctx, requestURL, isSubmission, a)
+		c.requestFinished(info, resp, err)
+		c.RetryPolicy.observe(requestURL, resp, c.clock.Now())
+
+		delay, retry := c.RetryPolicy.shouldRetry(ctx, attempt, isSubmission, resp, err)
+		if !retry {
+			return err
+		}
+		if sleepErr := sleep(ctx, delay); sleepErr != nil {
+			return err
+		}
+	}
+}
+
+// attemptRequest sends a single request to a horizon server and decodes the response into a.
+// It returns the response, whose body has already been closed, if one was received.
+func (c *Client) attemptRequest(ctx context.Context, requestURL string, isSubmission bool,
+	a interface{}) (*http.Response, error) {
 	var req *http.Request
+	var err error
 
-	if method == "post" || method == "POST" {
+	if isSubmission {
 		req, err = http.NewRequest("POST", requestURL, nil)
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+		}
 	} else {
 		req, err = http.NewRequest("GET", requestURL, nil)
 	}
 
 	if err != nil {
-		return errors.Wrap(err, "error creating HTTP request")
+		return nil, errors.Wrap(err, "error creating HTTP request")
 	}
 	c.setClientAppHeaders(req)
-	c.setDefaultClient()
-	if c.horizonTimeOut == 0 {
-		c.horizonTimeOut = HorizonTimeOut
-	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*c.horizonTimeOut)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*c.horizonTimeOut)
+	defer cancel()
 	resp, err := c.HTTP.Do(req.WithContext(ctx))
 	if err != nil {
-		cancel()
-		return
+		return nil, err
 	}
 
-	err = decodeResponse(resp, &a, c)
-	cancel()
-	return
+	return resp, decodeResponse(resp, &a, c)
 }
 
 // stream handles connections to endpoints that support streaming on a horizon server
@@ -81,11 +113,21 @@ func (c *Client) stream(
 	}
 
 	query := su.Query()
+	key := streamKey(*su)
+	if c.CursorStore != nil {
+		saved, loadErr := c.CursorStore.Load(key)
+		if loadErr != nil {
+			return errors.Wrap(loadErr, "error loading stream cursor")
+		}
+		if saved != "" {
+			query.Set("cursor", saved)
+		}
+	}
 	if query.Get("cursor") == "" {
 		query.Set("cursor", "now")
 	}
 
-	for {
+	for connection := 0; ; connection++ {
 		// updates the url with new cursor
 		su.RawQuery = query.Encode()
 		req, err := http.NewRequest("GET", su.String(), nil)
@@ -96,16 +138,26 @@ func (c *Client) stream(
 		c.setDefaultClient()
 		c.setClientAppHeaders(req)
 
+		info := c.requestStarted(su.String(), "GET", 0, true)
+		if connection > 0 && c.Observer != nil {
+			c.Observer.StreamReconnected(info)
+		}
+
 		// We can use c.HTTP here because we set Timeout per request not on the client. See sendRequest()
 		resp, err := c.HTTP.Do(req)
 		if err != nil {
+			c.requestFinished(info, nil, err)
 			return errors.Wrap(err, "error sending HTTP request")
 		}
 
 		// Expected statusCode are 200-299
 		if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
-			return fmt.Errorf("got bad HTTP status code %d", resp.StatusCode)
+			resp.Body.Close()
+			err = badStatusError{statusCode: resp.StatusCode}
+			c.requestFinished(info, resp, err)
+			return err
 		}
+		c.requestFinished(info, resp, nil)
 		defer resp.Body.Close()
 
 		reader := bufio.NewReader(resp.Body)
@@ -191,11 +243,28 @@ func (c *Client) stream(
 				if err != nil {
 					return err
 				}
+
+				// The event has been processed, so a restarted stream can resume after it
+				if event.Id != "" && c.CursorStore != nil {
+					err = c.CursorStore.Save(key, event.Id)
+					if err != nil {
+						return errors.Wrap(err, "error saving stream cursor")
+					}
+				}
 			}
 		}
 	}
 }
 
+// badStatusError is returned by streams when horizon responds with a non-2xx status code.
+type badStatusError struct {
+	statusCode int
+}
+
+func (e badStatusError) Error() string {
+	return fmt.Sprintf("got bad HTTP status code %d", e.statusCode)
+}
+
 func (c *Client) setClientAppHeaders(req *http.Request) {
 	req.Header.Set("X-Client-Name", "go-stellar-sdk")
 	req.Header.Set("X-Client-Version", c.Version())
@@ -228,7 +297,12 @@ func (c *Client) HorizonTimeOut() time.Duration {
 
 // AccountDetail returns information for a single account.
 // See https://www.stellar.org/developers/horizon/reference/endpoints/accounts-single.html
-func (c *Client) AccountDetail(request AccountRequest) (account hProtocol.Account, err error) {
+func (c *Client) AccountDetail(request AccountRequest) (hProtocol.Account, error) {
+	return c.AccountDetailWithContext(context.Background(), request)
+}
+
+// AccountDetailWithContext is the same as AccountDetail, but the request is cancelled when ctx is done.
+func (c *Client) AccountDetailWithContext(ctx context.Context, request AccountRequest) (account hProtocol.Account, err error) {
 	if request.AccountID == "" {
 		err = errors.New("no account ID provided")
 	}
@@ -237,13 +311,18 @@ func (c *Client) AccountDetail(request AccountRequest) (account hProtocol.Accoun
 		return
 	}
 
-	err = c.sendRequest(request, &account)
+	err = c.sendRequest(ctx, request, &account)
 	return
 }
 
 // AccountData returns a single data associated with a given account
 // See https://www.stellar.org/developers/horizon/reference/endpoints/data-for-account.html
-func (c *Client) AccountData(request AccountRequest) (accountData hProtocol.AccountData, err error) {
+func (c *Client) AccountData(request AccountRequest) (hProtocol.AccountData, error) {
+	return c.AccountDataWithContext(context.Background(), request)
+}
+
+// AccountDataWithContext is the same as AccountData, but the request is cancelled when ctx is done.
+func (c *Client) AccountDataWithContext(ctx context.Context, request AccountRequest) (accountData hProtocol.AccountData, err error) {
 	if request.AccountID == "" || request.DataKey == "" {
 		err = errors.New("too few parameters")
 	}
@@ -252,34 +331,66 @@ func (c *Client) AccountData(request AccountRequest) (accountData hProtocol.Acco
 		return
 	}
 
-	err = c.sendRequest(request, &accountData)
+	err = c.sendRequest(ctx, request, &accountData)
+	return
+}
+
+// Accounts returns accounts who have a given signer or have a trustline to an asset.
+// See https://www.stellar.org/developers/horizon/reference/endpoints/accounts.html
+func (c *Client) Accounts(request AccountsRequest) (hProtocol.AccountsPage, error) {
+	return c.AccountsWithContext(context.Background(), request)
+}
+
+// AccountsWithContext is the same as Accounts, but the request is cancelled when ctx is done.
+func (c *Client) AccountsWithContext(ctx context.Context, request AccountsRequest) (accounts hProtocol.AccountsPage, err error) {
+	err = c.sendRequest(ctx, request, &accounts)
 	return
 }
 
 // Effects returns effects(https://www.stellar.org/developers/horizon/reference/resources/effect.html)
 // It can be used to return effects for an account, a ledger, an operation, a transaction and all effects on the network.
-func (c *Client) Effects(request EffectRequest) (effects effects.EffectsPage, err error) {
-	err = c.sendRequest(request, &effects)
+func (c *Client) Effects(request EffectRequest) (effects.EffectsPage, error) {
+	return c.EffectsWithContext(context.Background(), request)
+}
+
+// EffectsWithContext is the same as Effects, but the request is cancelled when ctx is done.
+func (c *Client) EffectsWithContext(ctx context.Context, request EffectRequest) (effects effects.EffectsPage, err error) {
+	err = c.sendRequest(ctx, request, &effects)
 	return
 }
 
 // Assets returns asset information.
 // See https://www.stellar.org/developers/horizon/reference/endpoints/assets-all.html
-func (c *Client) Assets(request AssetRequest) (assets hProtocol.AssetsPage, err error) {
-	err = c.sendRequest(request, &assets)
+func (c *Client) Assets(request AssetRequest) (hProtocol.AssetsPage, error) {
+	return c.AssetsWithContext(context.Background(), request)
+}
+
+// AssetsWithContext is the same as Assets, but the request is cancelled when ctx is done.
+func (c *Client) AssetsWithContext(ctx context.Context, request AssetRequest) (assets hProtocol.AssetsPage, err error) {
+	err = c.sendRequest(ctx, request, &assets)
 	return
 }
 
 // Ledgers returns information about all ledgers.
 // See https://www.stellar.org/developers/horizon/reference/endpoints/ledgers-all.html
-func (c *Client) Ledgers(request LedgerRequest) (ledgers hProtocol.LedgersPage, err error) {
-	err = c.sendRequest(request, &ledgers)
+func (c *Client) Ledgers(request LedgerRequest) (hProtocol.LedgersPage, error) {
+	return c.LedgersWithContext(context.Background(), request)
+}
+
+// LedgersWithContext is the same as Ledgers, but the request is cancelled when ctx is done.
+func (c *Client) LedgersWithContext(ctx context.Context, request LedgerRequest) (ledgers hProtocol.LedgersPage, err error) {
+	err = c.sendRequest(ctx, request, &ledgers)
 	return
 }
 
 // LedgerDetail returns information about a particular ledger for a given sequence number
 // See https://www.stellar.org/developers/horizon/reference/endpoints/ledgers-single.html
-func (c *Client) LedgerDetail(sequence uint32) (ledger hProtocol.Ledger, err error) {
+func (c *Client) LedgerDetail(sequence uint32) (hProtocol.Ledger, error) {
+	return c.LedgerDetailWithContext(context.Background(), sequence)
+}
+
+// LedgerDetailWithContext is the same as LedgerDetail, but the request is cancelled when ctx is done.
+func (c *Client) LedgerDetailWithContext(ctx context.Context, sequence uint32) (ledger hProtocol.Ledger, err error) {
 	if sequence == 0 {
 		err = errors.New("invalid sequence number provided")
 	}
@@ -289,43 +400,68 @@ func (c *Client) LedgerDetail(sequence uint32) (ledger hProtocol.Ledger, err err
 	}
 
 	request := LedgerRequest{forSequence: sequence}
-	err = c.sendRequest(request, &ledger)
+	err = c.sendRequest(ctx, request, &ledger)
 	return
 }
 
 // Metrics returns monitoring information about a horizon server
 // See https://www.stellar.org/developers/horizon/reference/endpoints/metrics.html
-func (c *Client) Metrics() (metrics hProtocol.Metrics, err error) {
+func (c *Client) Metrics() (hProtocol.Metrics, error) {
+	return c.MetricsWithContext(context.Background())
+}
+
+// MetricsWithContext is the same as Metrics, but the request is cancelled when ctx is done.
+func (c *Client) MetricsWithContext(ctx context.Context) (metrics hProtocol.Metrics, err error) {
 	request := metricsRequest{endpoint: "metrics"}
-	err = c.sendRequest(request, &metrics)
+	err = c.sendRequest(ctx, request, &metrics)
 	return
 }
 
 // FeeStats returns information about fees in the last 5 ledgers.
 // See https://www.stellar.org/developers/horizon/reference/endpoints/fee-stats.html
-func (c *Client) FeeStats() (feestats hProtocol.FeeStats, err error) {
+func (c *Client) FeeStats() (hProtocol.FeeStats, error) {
+	return c.FeeStatsWithContext(context.Background())
+}
+
+// FeeStatsWithContext is the same as FeeStats, but the request is cancelled when ctx is done.
+func (c *Client) FeeStatsWithContext(ctx context.Context) (feestats hProtocol.FeeStats, err error) {
 	request := feeStatsRequest{endpoint: "fee_stats"}
-	err = c.sendRequest(request, &feestats)
+	err = c.sendRequest(ctx, request, &feestats)
 	return
 }
 
 // Offers returns information about offers made on the SDEX.
 // See https://www.stellar.org/developers/horizon/reference/endpoints/offers-for-account.html
-func (c *Client) Offers(request OfferRequest) (offers hProtocol.OffersPage, err error) {
-	err = c.sendRequest(request, &offers)
+func (c *Client) Offers(request OfferRequest) (hProtocol.OffersPage, error) {
+	return c.OffersWithContext(context.Background(), request)
+}
+
+// OffersWithContext is the same as Offers, but the request is cancelled when ctx is done.
+func (c *Client) OffersWithContext(ctx context.Context, request OfferRequest) (offers hProtocol.OffersPage, err error) {
+	err = c.sendRequest(ctx, request, &offers)
 	return
 }
 
 // Operations returns stellar operations (https://www.stellar.org/developers/horizon/reference/resources/operation.html)
 // It can be used to return operations for an account, a ledger, a transaction and all operations on the network.
-func (c *Client) Operations(request OperationRequest) (ops operations.OperationsPage, err error) {
-	err = c.sendRequest(request.SetOperationsEndpoint(), &ops)
+func (c *Client) Operations(request OperationRequest) (operations.OperationsPage, error) {
+	return c.OperationsWithContext(context.Background(), request)
+}
+
+// OperationsWithContext is the same as Operations, but the request is cancelled when ctx is done.
+func (c *Client) OperationsWithContext(ctx context.Context, request OperationRequest) (ops operations.OperationsPage, err error) {
+	err = c.sendRequest(ctx, request.SetOperationsEndpoint(), &ops)
 	return
 }
 
 // OperationDetail returns a single stellar operations (https://www.stellar.org/developers/horizon/reference/resources/operation.html)
 // for a given operation id
-func (c *Client) OperationDetail(id string) (ops operations.Operation, err error) {
+func (c *Client) OperationDetail(id string) (operations.Operation, error) {
+	return c.OperationDetailWithContext(context.Background(), id)
+}
+
+// OperationDetailWithContext is the same as OperationDetail, but the request is cancelled when ctx is done.
+func (c *Client) OperationDetailWithContext(ctx context.Context, id string) (ops operations.Operation, err error) {
 	if id == "" {
 		return ops, errors.New("invalid operation id provided")
 	}
@@ -334,7 +470,7 @@ func (c *Client) OperationDetail(id string) (ops operations.Operation, err error
 
 	var record interface{}
 
-	err = c.sendRequest(request, &record)
+	err = c.sendRequest(ctx, request, &record)
 	if err != nil {
 		return ops, errors.Wrap(err, "sending request to horizon")
 	}
@@ -357,79 +493,154 @@ func (c *Client) OperationDetail(id string) (ops operations.Operation, err error
 
 // SubmitTransactionXDR submits a transaction represented as a base64 XDR string to the network. err can be either error object or horizon.Error object.
 // See https://www.stellar.org/developers/horizon/reference/endpoints/transactions-create.html
-func (c *Client) SubmitTransactionXDR(transactionXdr string) (txSuccess hProtocol.TransactionSuccess,
-	err error) {
+func (c *Client) SubmitTransactionXDR(transactionXdr string) (hProtocol.TransactionSuccess, error) {
+	return c.SubmitTransactionXDRWithContext(context.Background(), transactionXdr)
+}
+
+// SubmitTransactionXDRWithContext is the same as SubmitTransactionXDR, but the request is cancelled when ctx is done.
+func (c *Client) SubmitTransactionXDRWithContext(ctx context.Context, transactionXdr string) (txSuccess hProtocol.TransactionSuccess, err error) {
 	request := submitRequest{endpoint: "transactions", transactionXdr: transactionXdr}
-	err = c.sendRequest(request, &txSuccess)
+	err = c.sendRequest(ctx, request, &txSuccess)
 	return
 }
 
 // SubmitTransaction submits a transaction to the network. err can be either error object or horizon.Error object.
+// The transaction is not submitted, and an *AccountRequiresMemoError is returned, when it has no
+// memo and pays an account which requires one (see CheckMemoRequired).
 // See https://www.stellar.org/developers/horizon/reference/endpoints/transactions-create.html
-func (c *Client) SubmitTransaction(transaction txnbuild.Transaction) (txSuccess hProtocol.TransactionSuccess,
-	err error) {
+func (c *Client) SubmitTransaction(transaction txnbuild.Transaction) (hProtocol.TransactionSuccess, error) {
+	return c.SubmitTransactionWithContext(context.Background(), transaction)
+}
+
+// SubmitTransactionWithContext is the same as SubmitTransaction, but the requests are cancelled when ctx is done.
+func (c *Client) SubmitTransactionWithContext(ctx context.Context, transaction txnbuild.Transaction) (hProtocol.TransactionSuccess, error) {
+	return c.SubmitTransactionWithOptionsWithContext(ctx, transaction, SubmitTxOpts{})
+}
+
+// SubmitTransactionWithOptions is the same as SubmitTransaction, with options controlling the
+// checks made before submitting the transaction.
+func (c *Client) SubmitTransactionWithOptions(transaction txnbuild.Transaction, opts SubmitTxOpts) (hProtocol.TransactionSuccess, error) {
+	return c.SubmitTransactionWithOptionsWithContext(context.Background(), transaction, opts)
+}
+
+// SubmitTransactionWithOptionsWithContext is the same as SubmitTransactionWithOptions, but the requests are cancelled when ctx is done.
+func (c *Client) SubmitTransactionWithOptionsWithContext(ctx context.Context, transaction txnbuild.Transaction, opts SubmitTxOpts) (txSuccess hProtocol.TransactionSuccess, err error) {
+	if !opts.SkipMemoRequiredCheck {
+		err = c.CheckMemoRequiredWithContext(ctx, transaction)
+		if err != nil {
+			return
+		}
+	}
+
 	txeBase64, err := transaction.Base64()
 	if err != nil {
 		err = errors.Wrap(err, "Unable to convert transaction object to base64 string")
 		return
 	}
 
-	return c.SubmitTransactionXDR(txeBase64)
+	return c.SubmitTransactionXDRWithContext(ctx, txeBase64)
 }
 
 // Transactions returns stellar transactions (https://www.stellar.org/developers/horizon/reference/resources/transaction.html)
 // It can be used to return transactions for an account, a ledger,and all transactions on the network.
-func (c *Client) Transactions(request TransactionRequest) (txs hProtocol.TransactionsPage, err error) {
-	err = c.sendRequest(request, &txs)
+func (c *Client) Transactions(request TransactionRequest) (hProtocol.TransactionsPage, error) {
+	return c.TransactionsWithContext(context.Background(), request)
+}
+
+// TransactionsWithContext is the same as Transactions, but the request is cancelled when ctx is done.
+func (c *Client) TransactionsWithContext(ctx context.Context, request TransactionRequest) (txs hProtocol.TransactionsPage, err error) {
+	err = c.sendRequest(ctx, request, &txs)
 	return
 }
 
 // TransactionDetail returns information about a particular transaction for a given transaction hash
 // See https://www.stellar.org/developers/horizon/reference/endpoints/transactions-single.html
-func (c *Client) TransactionDetail(txHash string) (tx hProtocol.Transaction, err error) {
+func (c *Client) TransactionDetail(txHash string) (hProtocol.Transaction, error) {
+	return c.TransactionDetailWithContext(context.Background(), txHash)
+}
+
+// TransactionDetailWithContext is the same as TransactionDetail, but the request is cancelled when ctx is done.
+func (c *Client) TransactionDetailWithContext(ctx context.Context, txHash string) (tx hProtocol.Transaction, err error) {
 	if txHash == "" {
 		return tx, errors.New("no transaction hash provided")
 	}
 
 	request := TransactionRequest{forTransactionHash: txHash}
-	err = c.sendRequest(request, &tx)
+	err = c.sendRequest(ctx, request, &tx)
 	return
 }
 
 // OrderBook returns the orderbook for an asset pair (https://www.stellar.org/developers/horizon/reference/resources/orderbook.html)
-func (c *Client) OrderBook(request OrderBookRequest) (obs hProtocol.OrderBookSummary, err error) {
-	err = c.sendRequest(request, &obs)
+func (c *Client) OrderBook(request OrderBookRequest) (hProtocol.OrderBookSummary, error) {
+	return c.OrderBookWithContext(context.Background(), request)
+}
+
+// OrderBookWithContext is the same as OrderBook, but the request is cancelled when ctx is done.
+func (c *Client) OrderBookWithContext(ctx context.Context, request OrderBookRequest) (obs hProtocol.OrderBookSummary, err error) {
+	err = c.sendRequest(ctx, request, &obs)
 	return
 }
 
 // Paths returns the available paths to make a payment. See https://www.stellar.org/developers/horizon/reference/endpoints/path-finding.html
-func (c *Client) Paths(request PathsRequest) (paths hProtocol.PathsPage, err error) {
-	err = c.sendRequest(request, &paths)
+func (c *Client) Paths(request PathsRequest) (hProtocol.PathsPage, error) {
+	return c.PathsWithContext(context.Background(), request)
+}
+
+// PathsWithContext is the same as Paths, but the request is cancelled when ctx is done.
+func (c *Client) PathsWithContext(ctx context.Context, request PathsRequest) (paths hProtocol.PathsPage, err error) {
+	err = c.sendRequest(ctx, request, &paths)
+	return
+}
+
+// StrictSendPaths returns the available paths to make a strict send path payment.
+// See https://www.stellar.org/developers/horizon/reference/endpoints/path-finding-strict-send.html
+func (c *Client) StrictSendPaths(request StrictSendPathsRequest) (hProtocol.PathsPage, error) {
+	return c.StrictSendPathsWithContext(context.Background(), request)
+}
+
+// StrictSendPathsWithContext is the same as StrictSendPaths, but the request is cancelled when ctx is done.
+func (c *Client) StrictSendPathsWithContext(ctx context.Context, request StrictSendPathsRequest) (paths hProtocol.PathsPage, err error) {
+	err = c.sendRequest(ctx, request, &paths)
 	return
 }
 
 // Payments returns stellar account_merge, create_account, path payment and payment operations.
 // It can be used to return payments for an account, a ledger, a transaction and all payments on the network.
-func (c *Client) Payments(request OperationRequest) (ops operations.OperationsPage, err error) {
-	err = c.sendRequest(request.SetPaymentsEndpoint(), &ops)
+func (c *Client) Payments(request OperationRequest) (operations.OperationsPage, error) {
+	return c.PaymentsWithContext(context.Background(), request)
+}
+
+// PaymentsWithContext is the same as Payments, but the request is cancelled when ctx is done.
+func (c *Client) PaymentsWithContext(ctx context.Context, request OperationRequest) (ops operations.OperationsPage, err error) {
+	err = c.sendRequest(ctx, request.SetPaymentsEndpoint(), &ops)
 	return
 }
 
 // Trades returns stellar trades (https://www.stellar.org/developers/horizon/reference/resources/trade.html)
 // It can be used to return trades for an account, an offer and all trades on the network.
-func (c *Client) Trades(request TradeRequest) (tds hProtocol.TradesPage, err error) {
-	err = c.sendRequest(request, &tds)
+func (c *Client) Trades(request TradeRequest) (hProtocol.TradesPage, error) {
+	return c.TradesWithContext(context.Background(), request)
+}
+
+// TradesWithContext is the same as Trades, but the request is cancelled when ctx is done.
+func (c *Client) TradesWithContext(ctx context.Context, request TradeRequest) (tds hProtocol.TradesPage, err error) {
+	err = c.sendRequest(ctx, request, &tds)
 	return
 }
 
 // Fund creates a new account funded from friendbot. It only works on test networks. See
 // https://www.stellar.org/developers/guides/get-started/create-account.html for more information.
-func (c *Client) Fund(addr string) (txSuccess hProtocol.TransactionSuccess, err error) {
+func (c *Client) Fund(addr string) (hProtocol.TransactionSuccess, error) {
+	return c.FundWithContext(context.Background(), addr)
+}
+
+// FundWithContext is the same as Fund, but the request is cancelled when ctx is done.
+func (c *Client) FundWithContext(ctx context.Context, addr string) (txSuccess hProtocol.TransactionSuccess, err error) {
 	if !c.isTestNet {
 		return txSuccess, errors.New("can't fund account from friendbot on production network")
 	}
 	friendbotURL := fmt.Sprintf("%sfriendbot?addr=%s", c.fixHorizonURL(), addr)
-	err = c.sendRequestURL(friendbotURL, "get", &txSuccess)
+	err = c.sendRequestURL(ctx, friendbotURL, "get", &txSuccess)
 	return
 }
 
@@ -442,8 +653,13 @@ func (c *Client) StreamTrades(ctx context.Context, request TradeRequest, handler
 }
 
 // TradeAggregations returns stellar trade aggregations (https://www.stellar.org/developers/horizon/reference/resources/trade_aggregation.html)
-func (c *Client) TradeAggregations(request TradeAggregationRequest) (tds hProtocol.TradeAggregationsPage, err error) {
-	err = c.sendRequest(request, &tds)
+func (c *Client) TradeAggregations(request TradeAggregationRequest) (hProtocol.TradeAggregationsPage, error) {
+	return c.TradeAggregationsWithContext(context.Background(), request)
+}
+
+// TradeAggregationsWithContext is the same as TradeAggregations, but the request is cancelled when ctx is done.
+func (c *Client) TradeAggregationsWithContext(ctx context.Context, request TradeAggregationRequest) (tds hProtocol.TradeAggregationsPage, err error) {
+	err = c.sendRequest(ctx, request, &tds)
 	return
 }
 
@@ -519,8 +735,13 @@ func (c *Client) FetchTimebounds(seconds int64) (txnbuild.Timebounds, error) {
 }
 
 // Root loads the root endpoint of horizon
-func (c *Client) Root() (root hProtocol.Root, err error) {
-	err = c.sendRequestURL(c.fixHorizonURL(), "get", &root)
+func (c *Client) Root() (hProtocol.Root, error) {
+	return c.RootWithContext(context.Background())
+}
+
+// RootWithContext is the same as Root, but the request is cancelled when ctx is done.
+func (c *Client) RootWithContext(ctx context.Context) (root hProtocol.Root, err error) {
+	err = c.sendRequestURL(ctx, c.fixHorizonURL(), "get", &root)
 	return
 }
 
@@ -530,106 +751,191 @@ func (c *Client) Version() string {
 }
 
 // NextAssetsPage returns the next page of assets.
-func (c *Client) NextAssetsPage(page hProtocol.AssetsPage) (assets hProtocol.AssetsPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &assets)
+func (c *Client) NextAssetsPage(page hProtocol.AssetsPage) (hProtocol.AssetsPage, error) {
+	return c.NextAssetsPageWithContext(context.Background(), page)
+}
+
+// NextAssetsPageWithContext is the same as NextAssetsPage, but the request is cancelled when ctx is done.
+func (c *Client) NextAssetsPageWithContext(ctx context.Context, page hProtocol.AssetsPage) (assets hProtocol.AssetsPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Next.Href, "get", &assets)
 	return
 }
 
 // PrevAssetsPage returns the previous page of assets.
-func (c *Client) PrevAssetsPage(page hProtocol.AssetsPage) (assets hProtocol.AssetsPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &assets)
+func (c *Client) PrevAssetsPage(page hProtocol.AssetsPage) (hProtocol.AssetsPage, error) {
+	return c.PrevAssetsPageWithContext(context.Background(), page)
+}
+
+// PrevAssetsPageWithContext is the same as PrevAssetsPage, but the request is cancelled when ctx is done.
+func (c *Client) PrevAssetsPageWithContext(ctx context.Context, page hProtocol.AssetsPage) (assets hProtocol.AssetsPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Prev.Href, "get", &assets)
 	return
 }
 
 // NextLedgersPage returns the next page of ledgers.
-func (c *Client) NextLedgersPage(page hProtocol.LedgersPage) (ledgers hProtocol.LedgersPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &ledgers)
+func (c *Client) NextLedgersPage(page hProtocol.LedgersPage) (hProtocol.LedgersPage, error) {
+	return c.NextLedgersPageWithContext(context.Background(), page)
+}
+
+// NextLedgersPageWithContext is the same as NextLedgersPage, but the request is cancelled when ctx is done.
+func (c *Client) NextLedgersPageWithContext(ctx context.Context, page hProtocol.LedgersPage) (ledgers hProtocol.LedgersPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Next.Href, "get", &ledgers)
 	return
 }
 
 // PrevLedgersPage returns the previous page of ledgers.
-func (c *Client) PrevLedgersPage(page hProtocol.LedgersPage) (ledgers hProtocol.LedgersPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &ledgers)
+func (c *Client) PrevLedgersPage(page hProtocol.LedgersPage) (hProtocol.LedgersPage, error) {
+	return c.PrevLedgersPageWithContext(context.Background(), page)
+}
+
+// PrevLedgersPageWithContext is the same as PrevLedgersPage, but the request is cancelled when ctx is done.
+func (c *Client) PrevLedgersPageWithContext(ctx context.Context, page hProtocol.LedgersPage) (ledgers hProtocol.LedgersPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Prev.Href, "get", &ledgers)
 	return
 }
 
 // NextEffectsPage returns the next page of effects.
-func (c *Client) NextEffectsPage(page effects.EffectsPage) (efp effects.EffectsPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &efp)
+func (c *Client) NextEffectsPage(page effects.EffectsPage) (effects.EffectsPage, error) {
+	return c.NextEffectsPageWithContext(context.Background(), page)
+}
+
+// NextEffectsPageWithContext is the same as NextEffectsPage, but the request is cancelled when ctx is done.
+func (c *Client) NextEffectsPageWithContext(ctx context.Context, page effects.EffectsPage) (efp effects.EffectsPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Next.Href, "get", &efp)
 	return
 }
 
 // PrevEffectsPage returns the previous page of effects.
-func (c *Client) PrevEffectsPage(page effects.EffectsPage) (efp effects.EffectsPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &efp)
+func (c *Client) PrevEffectsPage(page effects.EffectsPage) (effects.EffectsPage, error) {
+	return c.PrevEffectsPageWithContext(context.Background(), page)
+}
+
+// PrevEffectsPageWithContext is the same as PrevEffectsPage, but the request is cancelled when ctx is done.
+func (c *Client) PrevEffectsPageWithContext(ctx context.Context, page effects.EffectsPage) (efp effects.EffectsPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Prev.Href, "get", &efp)
 	return
 }
 
 // NextTransactionsPage returns the next page of transactions.
-func (c *Client) NextTransactionsPage(page hProtocol.TransactionsPage) (transactions hProtocol.TransactionsPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &transactions)
+func (c *Client) NextTransactionsPage(page hProtocol.TransactionsPage) (hProtocol.TransactionsPage, error) {
+	return c.NextTransactionsPageWithContext(context.Background(), page)
+}
+
+// NextTransactionsPageWithContext is the same as NextTransactionsPage, but the request is cancelled when ctx is done.
+func (c *Client) NextTransactionsPageWithContext(ctx context.Context, page hProtocol.TransactionsPage) (transactions hProtocol.TransactionsPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Next.Href, "get", &transactions)
 	return
 }
 
 // PrevTransactionsPage returns the previous page of transactions.
-func (c *Client) PrevTransactionsPage(page hProtocol.TransactionsPage) (transactions hProtocol.TransactionsPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &transactions)
+func (c *Client) PrevTransactionsPage(page hProtocol.TransactionsPage) (hProtocol.TransactionsPage, error) {
+	return c.PrevTransactionsPageWithContext(context.Background(), page)
+}
+
+// PrevTransactionsPageWithContext is the same as PrevTransactionsPage, but the request is cancelled when ctx is done.
+func (c *Client) PrevTransactionsPageWithContext(ctx context.Context, page hProtocol.TransactionsPage) (transactions hProtocol.TransactionsPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Prev.Href, "get", &transactions)
 	return
 }
 
 // NextOperationsPage returns the next page of operations.
-func (c *Client) NextOperationsPage(page operations.OperationsPage) (operations operations.OperationsPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &operations)
+func (c *Client) NextOperationsPage(page operations.OperationsPage) (operations.OperationsPage, error) {
+	return c.NextOperationsPageWithContext(context.Background(), page)
+}
+
+// NextOperationsPageWithContext is the same as NextOperationsPage, but the request is cancelled when ctx is done.
+func (c *Client) NextOperationsPageWithContext(ctx context.Context, page operations.OperationsPage) (operations operations.OperationsPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Next.Href, "get", &operations)
 	return
 }
 
 // PrevOperationsPage returns the previous page of operations.
-func (c *Client) PrevOperationsPage(page operations.OperationsPage) (operations operations.OperationsPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &operations)
+func (c *Client) PrevOperationsPage(page operations.OperationsPage) (operations.OperationsPage, error) {
+	return c.PrevOperationsPageWithContext(context.Background(), page)
+}
+
+// PrevOperationsPageWithContext is the same as PrevOperationsPage, but the request is cancelled when ctx is done.
+func (c *Client) PrevOperationsPageWithContext(ctx context.Context, page operations.OperationsPage) (operations operations.OperationsPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Prev.Href, "get", &operations)
 	return
 }
 
 // NextPaymentsPage returns the next page of payments.
 func (c *Client) NextPaymentsPage(page operations.OperationsPage) (operations.OperationsPage, error) {
-	return c.NextOperationsPage(page)
+	return c.NextPaymentsPageWithContext(context.Background(), page)
+}
+
+// NextPaymentsPageWithContext is the same as NextPaymentsPage, but the request is cancelled when ctx is done.
+func (c *Client) NextPaymentsPageWithContext(ctx context.Context, page operations.OperationsPage) (operations.OperationsPage, error) {
+	return c.NextOperationsPageWithContext(ctx, page)
 }
 
 // PrevPaymentsPage returns the previous page of payments.
 func (c *Client) PrevPaymentsPage(page operations.OperationsPage) (operations.OperationsPage, error) {
-	return c.PrevOperationsPage(page)
+	return c.PrevPaymentsPageWithContext(context.Background(), page)
+}
+
+// PrevPaymentsPageWithContext is the same as PrevPaymentsPage, but the request is cancelled when ctx is done.
+func (c *Client) PrevPaymentsPageWithContext(ctx context.Context, page operations.OperationsPage) (operations.OperationsPage, error) {
+	return c.PrevOperationsPageWithContext(ctx, page)
 }
 
 // NextOffersPage returns the next page of offers.
-func (c *Client) NextOffersPage(page hProtocol.OffersPage) (offers hProtocol.OffersPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &offers)
+func (c *Client) NextOffersPage(page hProtocol.OffersPage) (hProtocol.OffersPage, error) {
+	return c.NextOffersPageWithContext(context.Background(), page)
+}
+
+// NextOffersPageWithContext is the same as NextOffersPage, but the request is cancelled when ctx is done.
+func (c *Client) NextOffersPageWithContext(ctx context.Context, page hProtocol.OffersPage) (offers hProtocol.OffersPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Next.Href, "get", &offers)
 	return
 }
 
 // PrevOffersPage returns the previous page of offers.
-func (c *Client) PrevOffersPage(page hProtocol.OffersPage) (offers hProtocol.OffersPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &offers)
+func (c *Client) PrevOffersPage(page hProtocol.OffersPage) (hProtocol.OffersPage, error) {
+	return c.PrevOffersPageWithContext(context.Background(), page)
+}
+
+// PrevOffersPageWithContext is the same as PrevOffersPage, but the request is cancelled when ctx is done.
+func (c *Client) PrevOffersPageWithContext(ctx context.Context, page hProtocol.OffersPage) (offers hProtocol.OffersPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Prev.Href, "get", &offers)
 	return
 }
 
 // NextTradesPage returns the next page of trades.
-func (c *Client) NextTradesPage(page hProtocol.TradesPage) (trades hProtocol.TradesPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &trades)
+func (c *Client) NextTradesPage(page hProtocol.TradesPage) (hProtocol.TradesPage, error) {
+	return c.NextTradesPageWithContext(context.Background(), page)
+}
+
+// NextTradesPageWithContext is the same as NextTradesPage, but the request is cancelled when ctx is done.
+func (c *Client) NextTradesPageWithContext(ctx context.Context, page hProtocol.TradesPage) (trades hProtocol.TradesPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Next.Href, "get", &trades)
 	return
 }
 
 // PrevTradesPage returns the previous page of trades.
-func (c *Client) PrevTradesPage(page hProtocol.TradesPage) (trades hProtocol.TradesPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &trades)
+func (c *Client) PrevTradesPage(page hProtocol.TradesPage) (hProtocol.TradesPage, error) {
+	return c.PrevTradesPageWithContext(context.Background(), page)
+}
+
+// PrevTradesPageWithContext is the same as PrevTradesPage, but the request is cancelled when ctx is done.
+func (c *Client) PrevTradesPageWithContext(ctx context.Context, page hProtocol.TradesPage) (trades hProtocol.TradesPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Prev.Href, "get", &trades)
 	return
 }
 
 // HomeDomainForAccount returns the home domain for a single account.
 func (c *Client) HomeDomainForAccount(aid string) (string, error) {
+	return c.HomeDomainForAccountWithContext(context.Background(), aid)
+}
+
+// HomeDomainForAccountWithContext is the same as HomeDomainForAccount, but the request is cancelled when ctx is done.
+func (c *Client) HomeDomainForAccountWithContext(ctx context.Context, aid string) (string, error) {
 	if aid == "" {
 		return "", errors.New("no account ID provided")
 	}
 
-	accountDetail, err := c.AccountDetail(AccountRequest{AccountID: aid})
+	accountDetail, err := c.AccountDetailWithContext(ctx, AccountRequest{AccountID: aid})
 	if err != nil {
 		return "", errors.Wrap(err, "get account detail failed")
 	}
@@ -639,15 +945,47 @@ func (c *Client) HomeDomainForAccount(aid string) (string, error) {
 
 // NextTradeAggregationsPage returns the next page of trade aggregations from the current
 // trade aggregations response.
-func (c *Client) NextTradeAggregationsPage(page hProtocol.TradeAggregationsPage) (ta hProtocol.TradeAggregationsPage, err error) {
-	err = c.sendRequestURL(page.Links.Next.Href, "get", &ta)
+func (c *Client) NextTradeAggregationsPage(page hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error) {
+	return c.NextTradeAggregationsPageWithContext(context.Background(), page)
+}
+
+// NextTradeAggregationsPageWithContext is the same as NextTradeAggregationsPage, but the request is cancelled when ctx is done.
+func (c *Client) NextTradeAggregationsPageWithContext(ctx context.Context, page hProtocol.TradeAggregationsPage) (ta hProtocol.TradeAggregationsPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Next.Href, "get", &ta)
 	return
 }
 
 // PrevTradeAggregationsPage returns the previous page of trade aggregations from the current
 // trade aggregations response.
-func (c *Client) PrevTradeAggregationsPage(page hProtocol.TradeAggregationsPage) (ta hProtocol.TradeAggregationsPage, err error) {
-	err = c.sendRequestURL(page.Links.Prev.Href, "get", &ta)
+func (c *Client) PrevTradeAggregationsPage(page hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error) {
+	return c.PrevTradeAggregationsPageWithContext(context.Background(), page)
+}
+
+// PrevTradeAggregationsPageWithContext is the same as PrevTradeAggregationsPage, but the request is cancelled when ctx is done.
+func (c *Client) PrevTradeAggregationsPageWithContext(ctx context.Context, page hProtocol.TradeAggregationsPage) (ta hProtocol.TradeAggregationsPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Prev.Href, "get", &ta)
+	return
+}
+
+// NextAccountsPage returns the next page of accounts.
+func (c *Client) NextAccountsPage(page hProtocol.AccountsPage) (hProtocol.AccountsPage, error) {
+	return c.NextAccountsPageWithContext(context.Background(), page)
+}
+
+// NextAccountsPageWithContext is the same as NextAccountsPage, but the request is cancelled when ctx is done.
+func (c *Client) NextAccountsPageWithContext(ctx context.Context, page hProtocol.AccountsPage) (accounts hProtocol.AccountsPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Next.Href, "get", &accounts)
+	return
+}
+
+// PrevAccountsPage returns the previous page of accounts.
+func (c *Client) PrevAccountsPage(page hProtocol.AccountsPage) (hProtocol.AccountsPage, error) {
+	return c.PrevAccountsPageWithContext(context.Background(), page)
+}
+
+// PrevAccountsPageWithContext is the same as PrevAccountsPage, but the request is cancelled when ctx is done.
+func (c *Client) PrevAccountsPageWithContext(ctx context.Context, page hProtocol.AccountsPage) (accounts hProtocol.AccountsPage, err error) {
+	err = c.sendRequestURL(ctx, page.Links.Prev.Href, "get", &accounts)
 	return
 }
 
@@ -0,0 +1,153 @@
+package horizonclient
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/stellar/go/support/errors"
+)
+
+// CursorStore persists the paging token of the last event processed by a stream, so that the
+// stream can resume after the last processed event when it is restarted, even by another process.
+//
+// Streams identify themselves with a key built from the streamed endpoint and its query parameters,
+// excluding the cursor and the horizon host, e.g. "accounts/GABC.../payments".
+type CursorStore interface {
+	// Load returns the cursor saved for key, or an empty string if there is none.
+	Load(key string) (string, error)
+	// Save stores cursor as the latest cursor for key.
+	Save(key, cursor string) error
+}
+
+// MemoryCursorStore is a CursorStore keeping cursors in memory. It is safe for concurrent use. It
+// survives stream reconnections but not process restarts.
+type MemoryCursorStore struct {
+	mutex   sync.Mutex
+	cursors map[string]string
+}
+
+// NewMemoryCursorStore returns an empty MemoryCursorStore.
+func NewMemoryCursorStore() *MemoryCursorStore {
+	return &MemoryCursorStore{cursors: make(map[string]string)}
+}
+
+// Load implements CursorStore.
+func (s *MemoryCursorStore) Load(key string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.cursors[key], nil
+}
+
+// Save implements CursorStore.
+func (s *MemoryCursorStore) Save(key, cursor string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.cursors == nil {
+		s.cursors = make(map[string]string)
+	}
+	s.cursors[key] = cursor
+	return nil
+}
+
+// FileCursorStore is a CursorStore keeping the cursors of all streams in a single JSON file. The
+// file is replaced atomically on every save, so it is never left half-written if the process dies.
+// It is safe for concurrent use within a process, but the file must not be shared between
+// processes.
+type FileCursorStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileCursorStore returns a FileCursorStore saving cursors to the file at path. The file is
+// created on the first save if it does not exist.
+func NewFileCursorStore(path string) *FileCursorStore {
+	return &FileCursorStore{path: path}
+}
+
+// Load implements CursorStore.
+func (s *FileCursorStore) Load(key string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cursors, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	return cursors[key], nil
+}
+
+// Save implements CursorStore.
+func (s *FileCursorStore) Save(key, cursor string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cursors, err := s.read()
+	if err != nil {
+		return err
+	}
+	cursors[key] = cursor
+
+	data, err := json.MarshalIndent(cursors, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshaling cursors")
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "creating temporary cursor file")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "writing temporary cursor file")
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "syncing temporary cursor file")
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrap(err, "closing temporary cursor file")
+	}
+
+	return errors.Wrap(os.Rename(tmp.Name(), s.path), "replacing cursor file")
+}
+
+// read loads all the cursors from the file. A missing file holds no cursors.
+func (s *FileCursorStore) read() (map[string]string, error) {
+	cursors := make(map[string]string)
+
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return cursors, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "reading cursor file")
+	}
+
+	if err = json.Unmarshal(data, &cursors); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling cursor file")
+	}
+	return cursors, nil
+}
+
+// streamKey returns the key identifying the stream at u in a CursorStore: its path and query
+// parameters, without the horizon host and the cursor.
+func streamKey(u url.URL) string {
+	query := u.Query()
+	query.Del("cursor")
+
+	key := strings.TrimLeft(u.Path, "/")
+	if encoded := query.Encode(); encoded != "" {
+		key += "?" + encoded
+	}
+	return key
+}
+
+var _ CursorStore = &MemoryCursorStore{}
+var _ CursorStore = &FileCursorStore{}
@@ -0,0 +1,130 @@
+package horizonclient
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/support/http/httptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCursorStore(t *testing.T) {
+	store := NewMemoryCursorStore()
+
+	cursor, err := store.Load("ledgers")
+	require.NoError(t, err)
+	assert.Equal(t, "", cursor)
+
+	require.NoError(t, store.Save("ledgers", "123"))
+	require.NoError(t, store.Save("ledgers", "456"))
+	cursor, err = store.Load("ledgers")
+	require.NoError(t, err)
+	assert.Equal(t, "456", cursor)
+
+	// the zero value is usable
+	var zero MemoryCursorStore
+	require.NoError(t, zero.Save("ledgers", "1"))
+}
+
+func TestFileCursorStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cursors")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cursors.json")
+
+	store := NewFileCursorStore(path)
+	cursor, err := store.Load("ledgers")
+	require.NoError(t, err)
+	assert.Equal(t, "", cursor)
+
+	require.NoError(t, store.Save("ledgers", "123"))
+	require.NoError(t, store.Save("accounts/GABC/payments", "456"))
+
+	// cursors survive a new store being created for the same file
+	store = NewFileCursorStore(path)
+	cursor, err = store.Load("ledgers")
+	require.NoError(t, err)
+	assert.Equal(t, "123", cursor)
+	cursor, err = store.Load("accounts/GABC/payments")
+	require.NoError(t, err)
+	assert.Equal(t, "456", cursor)
+
+	// no temporary files are left behind
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	// corrupted file
+	require.NoError(t, ioutil.WriteFile(path, []byte("{"), 0600))
+	_, err = store.Load("ledgers")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unmarshaling cursor file")
+	}
+}
+
+func TestStreamKey(t *testing.T) {
+	u, err := url.Parse("https://localhost/accounts/GABC/payments?cursor=now&include_failed=true")
+	require.NoError(t, err)
+	assert.Equal(t, "accounts/GABC/payments?include_failed=true", streamKey(*u))
+
+	u, err = url.Parse("https://localhost/ledgers?cursor=123")
+	require.NoError(t, err)
+	assert.Equal(t, "ledgers", streamKey(*u))
+}
+
+func TestStreamWithCursorStore(t *testing.T) {
+	hmock := httptest.NewClient()
+	store := NewMemoryCursorStore()
+	client := &Client{
+		HorizonURL:  "https://localhost/",
+		HTTP:        hmock,
+		CursorStore: store,
+	}
+
+	// no saved cursor: the stream starts from now and saves the id of each processed event
+	hmock.On(
+		"GET",
+		"https://localhost/ledgers?cursor=now",
+	).ReturnString(200, ledgerStreamWithIDs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var sequences []int32
+	err := client.StreamLedgers(ctx, LedgerRequest{}, func(ledger hProtocol.Ledger) {
+		sequences = append(sequences, ledger.Sequence)
+		if len(sequences) == 2 {
+			cancel()
+		}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int32{69859, 69860}, sequences)
+
+	cursor, err := store.Load("ledgers")
+	require.NoError(t, err)
+	assert.Equal(t, "300046415298560", cursor)
+
+	// the saved cursor is used when the stream is started again, even if the request has a cursor
+	hmock.On(
+		"GET",
+		"https://localhost/ledgers?cursor=300046415298560",
+	).ReturnString(200, ledgerStreamWithIDs)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	err = client.StreamLedgers(ctx, LedgerRequest{Cursor: "1"}, func(ledger hProtocol.Ledger) {
+		cancel()
+	})
+	require.NoError(t, err)
+}
+
+var ledgerStreamWithIDs = `id: 300042120331264
+data: {"id":"71a40c0581d8d7c1158e1d9368024c5f9fd70de17a8d277cdd96781590cc10fb","paging_token":"300042120331264","hash":"71a40c0581d8d7c1158e1d9368024c5f9fd70de17a8d277cdd96781590cc10fb","sequence":69859,"closed_at":"2019-03-03T13:38:16Z"}
+
+id: 300046415298560
+data: {"id":"c5e5cc0fbd8b2e6e9ff0d8a8d4b3f9bf07a3cfa18b33b8c1e2bd53d10ea6b9d9","paging_token":"300046415298560","hash":"c5e5cc0fbd8b2e6e9ff0d8a8d4b3f9bf07a3cfa18b33b8c1e2bd53d10ea6b9d9","sequence":69860,"closed_at":"2019-03-03T13:38:21Z"}
+
+`
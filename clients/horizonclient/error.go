@@ -78,3 +78,31 @@ func (herr *Error) ResultCodes() (*hProtocol.TransactionResultCodes, error) {
 
 	return &result, nil
 }
+
+// ResultError decodes the transaction result XDR of a failed transaction submission into a
+// TransactionResultError.
+func (herr *Error) ResultError() (*TransactionResultError, error) {
+	b64, err := herr.ResultString()
+	if err != nil {
+		return nil, err
+	}
+
+	var result xdr.TransactionResult
+	err = xdr.SafeUnmarshalBase64(b64, &result)
+	if err != nil {
+		return nil, errors.Wrap(err, "xdr decode failed")
+	}
+
+	return newTransactionResultError(result), nil
+}
+
+// Unwrap returns the TransactionResultError of a failed transaction submission, so that errors.Is
+// and errors.As can be used with the ErrTx* and ErrOp* result codes. It returns nil for other
+// errors.
+func (herr *Error) Unwrap() error {
+	resultErr, err := herr.ResultError()
+	if err != nil {
+		return nil
+	}
+	return resultErr
+}
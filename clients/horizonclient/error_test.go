@@ -3,7 +3,9 @@ package horizonclient
 import (
 	"testing"
 
+	"github.com/stellar/go/xdr"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestError_ResultCodes(t *testing.T) {
@@ -92,3 +94,94 @@ func TestError_Envelope(t *testing.T) {
 		assert.Contains(t, err.Error(), "xdr decode")
 	}
 }
+
+func TestError_ResultError(t *testing.T) {
+	var herr Error
+
+	// happy path: the first of two create_account operations is malformed
+	herr.Problem.Type = "transaction_failed"
+	herr.Problem.Extras = make(map[string]interface{})
+	herr.Problem.Extras["result_xdr"] = "AAAAAAAAAMj/////AAAAAgAAAAAAAAAA/////wAAAAAAAAAAAAAAAAAAAAA="
+
+	resultErr, err := herr.ResultError()
+	require.NoError(t, err)
+	assert.Equal(t, ErrTxFailed, resultErr.Code)
+	if assert.Len(t, resultErr.Operations, 1) {
+		assert.Equal(t, 0, resultErr.Operations[0].Index)
+		assert.Equal(t, ErrOpMalformed, resultErr.Operations[0].Code)
+	}
+	assert.Equal(t, "transaction failed: tx_failed (operation 0 failed: op_malformed)", resultErr.Error())
+	assert.Equal(t, resultErr, herr.Unwrap())
+
+	assert.True(t, resultErr.Is(ErrTxFailed))
+	assert.True(t, resultErr.Is(ErrOpMalformed))
+	assert.False(t, resultErr.Is(ErrOpUnderfunded))
+	var opErr *OperationResultError
+	if assert.True(t, resultErr.As(&opErr)) {
+		assert.Equal(t, 0, opErr.Index)
+		assert.Equal(t, ErrOpMalformed, opErr.Unwrap())
+	}
+
+	// operation level codes and inner codes of other operation types
+	result := xdr.TransactionResult{
+		FeeCharged: 300,
+		Result: xdr.TransactionResultResult{
+			Code: xdr.TransactionResultCodeTxFailed,
+			Results: &[]xdr.OperationResult{
+				{Code: xdr.OperationResultCodeOpBadAuth},
+				{
+					Code: xdr.OperationResultCodeOpInner,
+					Tr: &xdr.OperationResultTr{
+						Type:          xdr.OperationTypePayment,
+						PaymentResult: &xdr.PaymentResult{Code: xdr.PaymentResultCodePaymentSuccess},
+					},
+				},
+				{
+					Code: xdr.OperationResultCodeOpInner,
+					Tr: &xdr.OperationResultTr{
+						Type:          xdr.OperationTypePayment,
+						PaymentResult: &xdr.PaymentResult{Code: xdr.PaymentResultCodePaymentUnderfunded},
+					},
+				},
+			},
+		},
+	}
+	herr.Problem.Extras["result_xdr"], err = xdr.MarshalBase64(result)
+	require.NoError(t, err)
+
+	resultErr, err = herr.ResultError()
+	require.NoError(t, err)
+	if assert.Len(t, resultErr.Operations, 2) {
+		assert.Equal(t, OperationResultError{Index: 0, Code: ErrOpBadAuth}, *resultErr.Operations[0])
+		assert.Equal(t, OperationResultError{Index: 2, Code: ErrOpUnderfunded}, *resultErr.Operations[1])
+	}
+	assert.True(t, resultErr.Is(ErrOpUnderfunded))
+
+	// transaction level failure
+	result = xdr.TransactionResult{
+		FeeCharged: 100,
+		Result:     xdr.TransactionResultResult{Code: xdr.TransactionResultCodeTxBadSeq},
+	}
+	herr.Problem.Extras["result_xdr"], err = xdr.MarshalBase64(result)
+	require.NoError(t, err)
+
+	resultErr, err = herr.ResultError()
+	require.NoError(t, err)
+	assert.Equal(t, ErrTxBadSeq, resultErr.Code)
+	assert.Empty(t, resultErr.Operations)
+	assert.Equal(t, "transaction failed: tx_bad_seq", resultErr.Error())
+	assert.False(t, resultErr.As(&opErr))
+
+	// sad path: missing result_xdr extra
+	herr.Problem.Extras = make(map[string]interface{})
+	_, err = herr.ResultError()
+	assert.Equal(t, ErrResultNotPopulated, err)
+	assert.Nil(t, herr.Unwrap())
+
+	// sad path: unparseable result_xdr extra
+	herr.Problem.Extras["result_xdr"] = "AAAAAAAAAMj"
+	_, err = herr.ResultError()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "xdr decode")
+	}
+}
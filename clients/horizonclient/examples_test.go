@@ -24,6 +24,27 @@ func ExampleClient_AccountDetail() {
 	fmt.Print(account)
 }
 
+func ExampleClient_Accounts() {
+	client := horizonclient.DefaultPublicNetClient
+	// accounts with a given signer
+	accountsRequest := horizonclient.AccountsRequest{Signer: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU"}
+	accounts, err := client.Accounts(accountsRequest)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(accounts)
+
+	// accounts holding a trustline to an asset
+	accountsRequest = horizonclient.AccountsRequest{Asset: "NGN:GDZST3XVCDTUJ76ZAV2HA72KYQODXXZ5PTMAPZGDHZ6CS7RO7MGG3DBM"}
+	accounts, err = client.Accounts(accountsRequest)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(accounts)
+}
+
 func ExampleClient_Assets() {
 	client := horizonclient.DefaultPublicNetClient
 	// assets for asset issuer
@@ -120,6 +141,27 @@ func ExampleClient_Metrics() {
 	fmt.Print(metrics)
 }
 
+func ExampleClient_NextAccountsPage() {
+	client := horizonclient.DefaultPublicNetClient
+	// accounts with a given signer
+	accountsRequest := horizonclient.AccountsRequest{Signer: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU",
+		Limit: 20}
+	accounts, err := client.Accounts(accountsRequest)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(accounts)
+
+	// next page
+	nextPage, err := client.NextAccountsPage(accounts)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(nextPage)
+}
+
 func ExampleClient_NextAssetsPage() {
 	client := horizonclient.DefaultPublicNetClient
 	// assets for asset issuer
@@ -1021,6 +1063,24 @@ func ExampleClient_SubmitTransactionXDR() {
 	// Output: {{{https://horizon.stellar.org/transactions/f8a09e8a17fc828a1b99814818ddc931876eec0fe9c203f5980d26d92641e1c2 false}} f8a09e8a17fc828a1b99814818ddc931876eec0fe9c203f5980d26d92641e1c2 23350654 AAAAAOoS/5V+BiCPXRiVcz8YsnkDdODufq+g7xdqTdIXN8vyAAAE4gFiW0YAAALxAAAAAQAAAAAAAAAAAAAAAFyuBUcAAAABAAAABzIyMjgyNDUAAAAAAQAAAAEAAAAALhsY/FdAHXllTmb025DtCVBw06WDSQjq6I9NrCQHOV8AAAABAAAAAHT8zKV7bRQzuGTpk9AO3gjWJ9jVxBXTgguFORkxHVIKAAAAAAAAAAAAOnDwAAAAAAAAAAIkBzlfAAAAQPefqlsOvni6xX1g3AqddvOp1GOM88JYzayGZodbzTfV5toyhxZvL1ZggY3prFsvrereugEpj1kyPJ67z6gcRg0XN8vyAAAAQGwmoTssW49gaze8iQkz/UA2E2N+BOo+6v7YdOSsvIcZnMc37KmXH920nLosKpDLqkNChVztSZFcbVUlHhjbQgA= AAAAAAAABOIAAAAAAAAAAQAAAAAAAAABAAAAAAAAAAA= AAAAAQAAAAIAAAADAWRNfgAAAAAAAAAA6hL/lX4GII9dGJVzPxiyeQN04O5+r6DvF2pN0hc3y/IAAAAAAuyTvgFiW0YAAALwAAAAAAAAAAAAAAAAAAAAAAEAAAAAAAAAAAAAAAAAAAAAAAABAWRNfgAAAAAAAAAA6hL/lX4GII9dGJVzPxiyeQN04O5+r6DvF2pN0hc3y/IAAAAAAuyTvgFiW0YAAALxAAAAAAAAAAAAAAAAAAAAAAEAAAAAAAAAAAAAAAAAAAAAAAABAAAABAAAAAMBZE0IAAAAAAAAAAB0/Myle20UM7hk6ZPQDt4I1ifY1cQV04ILhTkZMR1SCgAAAbZToYkOAToKfwAAAAEAAAAAAAAAAAAAAAAAAAAAAQAAAAAAAAAAAAAAAAAAAAAAAAEBZE1+AAAAAAAAAAB0/Myle20UM7hk6ZPQDt4I1ifY1cQV04ILhTkZMR1SCgAAAbZT2/n+AToKfwAAAAEAAAAAAAAAAAAAAAAAAAAAAQAAAAAAAAAAAAAAAAAAAAAAAAMBZE19AAAAAAAAAAAuGxj8V0AdeWVOZvTbkO0JUHDTpYNJCOroj02sJAc5XwAAAACrUfjvARGUKgAApRsAAAAAAAAAAAAAAAAAAAAObnlhbmRldi1pZC5vcmcAAAEAAAAAAAAAAAAAAAAAAAAAAAABAWRNfgAAAAAAAAAALhsY/FdAHXllTmb025DtCVBw06WDSQjq6I9NrCQHOV8AAAAAqxeH/wERlCoAAKUbAAAAAAAAAAAAAAAAAAAADm55YW5kZXYtaWQub3JnAAABAAAAAAAAAAAAAAAAAAAA}
 }
 
+func ExampleClient_StrictSendPaths() {
+	client := horizonclient.DefaultPublicNetClient
+	// Find paths for USD->XLM or NGN
+	pr := horizonclient.StrictSendPathsRequest{
+		DestinationAssets: "native,NGN:GDZST3XVCDTUJ76ZAV2HA72KYQODXXZ5PTMAPZGDHZ6CS7RO7MGG3DBM",
+		SourceAmount:      "20",
+		SourceAssetCode:   "USD",
+		SourceAssetIssuer: "GDUKMGUGDZQK6YHYA5Z6AY2G4XDSZPSZ3SW5UN3ARVMO6QSRDWP5YLEX",
+		SourceAssetType:   horizonclient.AssetType4,
+	}
+	paths, err := client.StrictSendPaths(pr)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(paths)
+}
+
 func ExampleClient_TradeAggregations() {
 	client := horizonclient.DefaultPublicNetClient
 	testTime := time.Unix(int64(1517521726), int64(0))
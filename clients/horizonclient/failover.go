@@ -0,0 +1,1151 @@
+package horizonclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/effects"
+	"github.com/stellar/go/protocols/horizon/operations"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/txnbuild"
+)
+
+// ErrNoHealthyNode is returned by a FailoverClient when none of its horizon servers is healthy.
+var ErrNoHealthyNode = errors.New("no healthy horizon server available")
+
+// FailoverClient is a ClientInterface spreading requests over several horizon servers ("nodes").
+// It checks the health of every node through its root endpoint and routes each request to the
+// first healthy node, in the order the nodes were given. A node is healthy when its root endpoint
+// responds, its ingested ledger is not too far behind its stellar-core ledger and not too far
+// behind the ingested ledger of the most up-to-date node.
+//
+// When a node fails to serve a request (connection error, 429 or 5xx) it is marked unhealthy until
+// the next health check and the request is retried on the next healthy node. Requests rejected by
+// horizon for any other reason are not retried. Transaction submissions are only retried when the
+// node did not respond at all: once a submission reached a node it may be applied, so its errors,
+// including timeouts, are returned to the caller. Stream* calls fail over the same way as other
+// requests, and also when the connection drops while streaming, resuming on the new node from the
+// paging token of the last event passed to the handler.
+type FailoverClient struct {
+	// MaxIngestionLag is the maximum number of ledgers the ingested ledger of a node may be behind
+	// its stellar-core ledger.
+	MaxIngestionLag int32
+	// MaxLedgerLag is the maximum number of ledgers the ingested ledger of a node may be behind the
+	// ingested ledger of the most up-to-date node.
+	MaxLedgerLag int32
+	// HealthCheckInterval is how often the health of the nodes is checked. Checks are done lazily,
+	// before a request is routed, when the last check is older than this.
+	HealthCheckInterval time.Duration
+	// CursorStore, when set, is used by failing over streams to save and resume their cursor. When
+	// nil, every Stream* call keeps its cursor in memory.
+	CursorStore CursorStore
+
+	nodes []*failoverNode
+
+	// checkMutex ensures a single health check runs at a time
+	checkMutex sync.Mutex
+	mutex      sync.RWMutex
+	lastCheck  time.Time
+}
+
+// failoverNode is a single horizon server of a FailoverClient.
+type failoverNode struct {
+	client  *Client
+	healthy bool
+	root    hProtocol.Root
+	err     error
+}
+
+// NodeStatus describes the health of a node of a FailoverClient, as of its last health check.
+type NodeStatus struct {
+	HorizonURL string
+	Healthy    bool
+	// HorizonSequence is the latest ledger ingested by the node.
+	HorizonSequence int32
+	// CoreSequence is the latest ledger of the stellar-core instance of the node.
+	CoreSequence int32
+	// Err is the reason the node is unhealthy, if any.
+	Err error
+}
+
+// NewFailoverClient returns a FailoverClient for the horizon servers at horizonURLs. Each node is
+// queried with a copy of template (which may be nil) whose HorizonURL is set to the node's URL, so
+// template can be used to configure the HTTP client, application name or retry policy of all nodes.
+func NewFailoverClient(template *Client, horizonURLs ...string) *FailoverClient {
+	if template == nil {
+		template = &Client{}
+	}
+
+	fc := &FailoverClient{
+		MaxIngestionLag:     10,
+		MaxLedgerLag:        5,
+		HealthCheckInterval: 30 * time.Second,
+	}
+	for _, horizonURL := range horizonURLs {
+		client := *template
+		client.HorizonURL = strings.TrimRight(horizonURL, "/") + "/"
+		// nodes are assumed healthy until the first check says otherwise
+		fc.nodes = append(fc.nodes, &failoverNode{client: &client, healthy: true})
+	}
+	return fc
+}
+
+// CheckHealth queries the root endpoint of every node and updates their health.
+func (fc *FailoverClient) CheckHealth(ctx context.Context) {
+	fc.checkMutex.Lock()
+	defer fc.checkMutex.Unlock()
+
+	roots := make([]hProtocol.Root, len(fc.nodes))
+	errs := make([]error, len(fc.nodes))
+	var wg sync.WaitGroup
+	for i, node := range fc.nodes {
+		wg.Add(1)
+		go func(i int, client *Client) {
+			defer wg.Done()
+			roots[i], errs[i] = client.RootWithContext(ctx)
+		}(i, node.client)
+	}
+	wg.Wait()
+
+	var latest int32
+	for i := range fc.nodes {
+		if errs[i] == nil && roots[i].HorizonSequence > latest {
+			latest = roots[i].HorizonSequence
+		}
+	}
+
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+	for i, node := range fc.nodes {
+		node.root = roots[i]
+		node.err = errs[i]
+		if node.err == nil {
+			node.err = fc.checkRoot(roots[i], latest)
+		}
+		node.healthy = node.err == nil
+	}
+	fc.lastCheck = time.Now()
+}
+
+// checkRoot returns an error if a node whose root endpoint returned root is not healthy. latest is
+// the highest ingested ledger among all nodes.
+func (fc *FailoverClient) checkRoot(root hProtocol.Root, latest int32) error {
+	if lag := root.CoreSequence - root.HorizonSequence; lag > fc.MaxIngestionLag {
+		return errors.Errorf("history is stale: ingested ledger %d is %d ledgers behind core",
+			root.HorizonSequence, lag)
+	}
+	if lag := latest - root.HorizonSequence; lag > fc.MaxLedgerLag {
+		return errors.Errorf("ingested ledger %d is %d ledgers behind the most up-to-date node",
+			root.HorizonSequence, lag)
+	}
+	return nil
+}
+
+// Nodes returns the status of every node, in the order they were given to NewFailoverClient.
+func (fc *FailoverClient) Nodes() []NodeStatus {
+	fc.mutex.RLock()
+	defer fc.mutex.RUnlock()
+
+	statuses := make([]NodeStatus, len(fc.nodes))
+	for i, node := range fc.nodes {
+		statuses[i] = NodeStatus{
+			HorizonURL:      node.client.HorizonURL,
+			Healthy:         node.healthy,
+			HorizonSequence: node.root.HorizonSequence,
+			CoreSequence:    node.root.CoreSequence,
+			Err:             node.err,
+		}
+	}
+	return statuses
+}
+
+// healthyNodes returns the currently healthy nodes, checking the health of all nodes first if the
+// last check is too old.
+func (fc *FailoverClient) healthyNodes(ctx context.Context) []*failoverNode {
+	fc.mutex.RLock()
+	stale := fc.lastCheck.IsZero() || time.Since(fc.lastCheck) > fc.HealthCheckInterval
+	fc.mutex.RUnlock()
+	if stale {
+		fc.CheckHealth(ctx)
+	}
+
+	nodes := fc.filterHealthy()
+	if len(nodes) == 0 && !stale {
+		// nodes marked unhealthy after a failed request may have recovered since
+		fc.CheckHealth(ctx)
+		nodes = fc.filterHealthy()
+	}
+	return nodes
+}
+
+func (fc *FailoverClient) filterHealthy() []*failoverNode {
+	fc.mutex.RLock()
+	defer fc.mutex.RUnlock()
+	var nodes []*failoverNode
+	for _, node := range fc.nodes {
+		if node.healthy {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// markUnhealthy takes node out of rotation until the next health check.
+func (fc *FailoverClient) markUnhealthy(node *failoverNode, err error) {
+	fc.mutex.Lock()
+	defer fc.mutex.Unlock()
+	node.healthy = false
+	node.err = err
+}
+
+// do calls fn with the client of each healthy node in turn, until a call succeeds or fails for a
+// reason that is not the node's fault.
+func (fc *FailoverClient) do(ctx context.Context, fn func(c *Client) error) error {
+	return fc.doUntil(ctx, isNodeFailure, fn)
+}
+
+// submit is like do for transaction submissions, which are only sent to the next node when they
+// could not reach the node at all. Submissions which reached a node, e.g. timing out with a 504,
+// are not retried, as with Client.
+func (fc *FailoverClient) submit(ctx context.Context, fn func(c *Client) error) error {
+	return fc.doUntil(ctx, func(status int) bool { return status == statusNoResponse }, fn)
+}
+
+// doUntil calls fn with the client of each healthy node in turn, until a call succeeds or fails
+// with a status, as returned by attempt, for which failed returns false.
+func (fc *FailoverClient) doUntil(ctx context.Context, failed func(status int) bool, fn func(c *Client) error) error {
+	nodes := fc.healthyNodes(ctx)
+	if len(nodes) == 0 {
+		return ErrNoHealthyNode
+	}
+
+	var err error
+	for _, node := range nodes {
+		var status int
+		status, err = fc.attempt(node, nil, fn)
+		if err == nil || ctx.Err() != nil || !failed(status) {
+			return err
+		}
+		fc.markUnhealthy(node, err)
+	}
+	return err
+}
+
+// The statuses returned by attempt when no HTTP status was received.
+const (
+	// statusNoRequest means that no request was sent.
+	statusNoRequest = -1
+	// statusNoResponse means that the last request sent got no response.
+	statusNoResponse = 0
+	// statusResponseCut means that the body of the last response could not be read to the end,
+	// e.g. because the connection dropped in the middle of a stream.
+	statusResponseCut = -2
+)
+
+// attempt calls fn with a copy of the client of node, after passing the copy to configure if not
+// nil. It returns the HTTP status of the last response received from the node, or one of
+// statusNoRequest, statusNoResponse and statusResponseCut.
+func (fc *FailoverClient) attempt(node *failoverNode, configure func(c *Client), fn func(c *Client) error) (int, error) {
+	client := *node.client
+	client.setDefaultClient()
+	recorder := &statusRecorder{HTTP: client.HTTP, status: statusNoRequest}
+	client.HTTP = recorder
+	if configure != nil {
+		configure(&client)
+	}
+
+	err := fn(&client)
+	return recorder.lastStatus(), err
+}
+
+// doPage is like do, for requests following a link of a previously returned page. The link is
+// rewritten to point to the node the request is sent to.
+func (fc *FailoverClient) doPage(ctx context.Context, href string, fn func(c *Client, href string) error) error {
+	return fc.do(ctx, func(c *Client) error {
+		return fn(c, fc.rebase(href, c))
+	})
+}
+
+// rebase rewrites href, a link returned by one of the nodes, so that it points to the node of c.
+func (fc *FailoverClient) rebase(href string, c *Client) string {
+	for _, node := range fc.nodes {
+		if strings.HasPrefix(href, node.client.HorizonURL) {
+			return c.HorizonURL + strings.TrimPrefix(href, node.client.HorizonURL)
+		}
+	}
+	return href
+}
+
+// stream runs fn, a Stream* call, against the healthy nodes until ctx is done. Whenever a node
+// fails, the stream is resumed on the next healthy node from the cursor saved after the last
+// event handled. It gives up when every node failed in a row without making any progress.
+func (fc *FailoverClient) stream(ctx context.Context, fn func(c *Client) error) error {
+	store := fc.CursorStore
+	if store == nil {
+		store = NewMemoryCursorStore()
+	}
+	progress := &progressCursorStore{CursorStore: store}
+
+	failures := 0
+	for {
+		nodes := fc.healthyNodes(ctx)
+		if len(nodes) == 0 {
+			return ErrNoHealthyNode
+		}
+
+		for _, node := range nodes {
+			progress.reset()
+
+			status, err := fc.attempt(node, func(c *Client) {
+				c.CursorStore = progress
+			}, fn)
+			if err == nil || ctx.Err() != nil {
+				return err
+			}
+			if !isNodeFailure(status) {
+				return err
+			}
+			fc.markUnhealthy(node, err)
+
+			if progress.saved() {
+				failures = 0
+			}
+			failures++
+			if failures >= len(fc.nodes) {
+				return errors.Wrap(err, "stream failed on every horizon server")
+			}
+		}
+
+		// all healthy nodes failed: check them again before the next round
+		fc.CheckHealth(ctx)
+	}
+}
+
+// progressCursorStore wraps a CursorStore, recording whether any cursor was saved since the last
+// reset.
+type progressCursorStore struct {
+	CursorStore
+	mutex sync.Mutex
+	dirty bool
+}
+
+func (s *progressCursorStore) Save(key, cursor string) error {
+	s.mutex.Lock()
+	s.dirty = true
+	s.mutex.Unlock()
+	return s.CursorStore.Save(key, cursor)
+}
+
+func (s *progressCursorStore) reset() {
+	s.mutex.Lock()
+	s.dirty = false
+	s.mutex.Unlock()
+}
+
+func (s *progressCursorStore) saved() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.dirty
+}
+
+// statusRecorder wraps the HTTP client of a node, recording the status of the last response
+// received.
+type statusRecorder struct {
+	HTTP
+	mutex  sync.Mutex
+	status int
+}
+
+func (r *statusRecorder) Do(req *http.Request) (*http.Response, error) {
+	resp, err := r.HTTP.Do(req)
+	if err != nil {
+		r.setStatus(statusNoResponse)
+		return resp, err
+	}
+
+	r.setStatus(resp.StatusCode)
+	resp.Body = &recordedBody{ReadCloser: resp.Body, recorder: r}
+	return resp, nil
+}
+
+func (r *statusRecorder) setStatus(status int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.status = status
+}
+
+func (r *statusRecorder) lastStatus() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.status
+}
+
+// recordedBody is the body of a response received by a statusRecorder, recording the errors
+// reading it.
+type recordedBody struct {
+	io.ReadCloser
+	recorder *statusRecorder
+}
+
+func (b *recordedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		b.recorder.setStatus(statusResponseCut)
+	}
+	return n, err
+}
+
+// isNodeFailure reports whether status, the HTTP status of the last response received from a
+// horizon server as returned by attempt, means that the server could not serve a request, as
+// opposed to the request being rejected. The server failed when it didn't respond at all, or when
+// its response was cut.
+func isNodeFailure(status int) bool {
+	return status == statusNoResponse || status == statusResponseCut || isNodeFailureStatus(status)
+}
+
+func isNodeFailureStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// AccountDetail is the same as Client.AccountDetail.
+func (fc *FailoverClient) AccountDetail(request AccountRequest) (hProtocol.Account, error) {
+	return fc.AccountDetailWithContext(context.Background(), request)
+}
+
+// AccountDetailWithContext is the same as Client.AccountDetailWithContext.
+func (fc *FailoverClient) AccountDetailWithContext(ctx context.Context, request AccountRequest) (result hProtocol.Account, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.AccountDetailWithContext(ctx, request)
+		return
+	})
+	return
+}
+
+// AccountData is the same as Client.AccountData.
+func (fc *FailoverClient) AccountData(request AccountRequest) (hProtocol.AccountData, error) {
+	return fc.AccountDataWithContext(context.Background(), request)
+}
+
+// AccountDataWithContext is the same as Client.AccountDataWithContext.
+func (fc *FailoverClient) AccountDataWithContext(ctx context.Context, request AccountRequest) (result hProtocol.AccountData, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.AccountDataWithContext(ctx, request)
+		return
+	})
+	return
+}
+
+// Accounts is the same as Client.Accounts.
+func (fc *FailoverClient) Accounts(request AccountsRequest) (hProtocol.AccountsPage, error) {
+	return fc.AccountsWithContext(context.Background(), request)
+}
+
+// AccountsWithContext is the same as Client.AccountsWithContext.
+func (fc *FailoverClient) AccountsWithContext(ctx context.Context, request AccountsRequest) (result hProtocol.AccountsPage, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.AccountsWithContext(ctx, request)
+		return
+	})
+	return
+}
+
+// Effects is the same as Client.Effects.
+func (fc *FailoverClient) Effects(request EffectRequest) (effects.EffectsPage, error) {
+	return fc.EffectsWithContext(context.Background(), request)
+}
+
+// EffectsWithContext is the same as Client.EffectsWithContext.
+func (fc *FailoverClient) EffectsWithContext(ctx context.Context, request EffectRequest) (result effects.EffectsPage, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.EffectsWithContext(ctx, request)
+		return
+	})
+	return
+}
+
+// Assets is the same as Client.Assets.
+func (fc *FailoverClient) Assets(request AssetRequest) (hProtocol.AssetsPage, error) {
+	return fc.AssetsWithContext(context.Background(), request)
+}
+
+// AssetsWithContext is the same as Client.AssetsWithContext.
+func (fc *FailoverClient) AssetsWithContext(ctx context.Context, request AssetRequest) (result hProtocol.AssetsPage, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.AssetsWithContext(ctx, request)
+		return
+	})
+	return
+}
+
+// Ledgers is the same as Client.Ledgers.
+func (fc *FailoverClient) Ledgers(request LedgerRequest) (hProtocol.LedgersPage, error) {
+	return fc.LedgersWithContext(context.Background(), request)
+}
+
+// LedgersWithContext is the same as Client.LedgersWithContext.
+func (fc *FailoverClient) LedgersWithContext(ctx context.Context, request LedgerRequest) (result hProtocol.LedgersPage, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.LedgersWithContext(ctx, request)
+		return
+	})
+	return
+}
+
+// LedgerDetail is the same as Client.LedgerDetail.
+func (fc *FailoverClient) LedgerDetail(sequence uint32) (hProtocol.Ledger, error) {
+	return fc.LedgerDetailWithContext(context.Background(), sequence)
+}
+
+// LedgerDetailWithContext is the same as Client.LedgerDetailWithContext.
+func (fc *FailoverClient) LedgerDetailWithContext(ctx context.Context, sequence uint32) (result hProtocol.Ledger, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.LedgerDetailWithContext(ctx, sequence)
+		return
+	})
+	return
+}
+
+// Metrics is the same as Client.Metrics.
+func (fc *FailoverClient) Metrics() (hProtocol.Metrics, error) {
+	return fc.MetricsWithContext(context.Background())
+}
+
+// MetricsWithContext is the same as Client.MetricsWithContext.
+func (fc *FailoverClient) MetricsWithContext(ctx context.Context) (result hProtocol.Metrics, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.MetricsWithContext(ctx)
+		return
+	})
+	return
+}
+
+// FeeStats is the same as Client.FeeStats.
+func (fc *FailoverClient) FeeStats() (hProtocol.FeeStats, error) {
+	return fc.FeeStatsWithContext(context.Background())
+}
+
+// FeeStatsWithContext is the same as Client.FeeStatsWithContext.
+func (fc *FailoverClient) FeeStatsWithContext(ctx context.Context) (result hProtocol.FeeStats, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.FeeStatsWithContext(ctx)
+		return
+	})
+	return
+}
+
+// Offers is the same as Client.Offers.
+func (fc *FailoverClient) Offers(request OfferRequest) (hProtocol.OffersPage, error) {
+	return fc.OffersWithContext(context.Background(), request)
+}
+
+// OffersWithContext is the same as Client.OffersWithContext.
+func (fc *FailoverClient) OffersWithContext(ctx context.Context, request OfferRequest) (result hProtocol.OffersPage, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.OffersWithContext(ctx, request)
+		return
+	})
+	return
+}
+
+// Operations is the same as Client.Operations.
+func (fc *FailoverClient) Operations(request OperationRequest) (operations.OperationsPage, error) {
+	return fc.OperationsWithContext(context.Background(), request)
+}
+
+// OperationsWithContext is the same as Client.OperationsWithContext.
+func (fc *FailoverClient) OperationsWithContext(ctx context.Context, request OperationRequest) (result operations.OperationsPage, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.OperationsWithContext(ctx, request)
+		return
+	})
+	return
+}
+
+// OperationDetail is the same as Client.OperationDetail.
+func (fc *FailoverClient) OperationDetail(id string) (operations.Operation, error) {
+	return fc.OperationDetailWithContext(context.Background(), id)
+}
+
+// OperationDetailWithContext is the same as Client.OperationDetailWithContext.
+func (fc *FailoverClient) OperationDetailWithContext(ctx context.Context, id string) (result operations.Operation, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.OperationDetailWithContext(ctx, id)
+		return
+	})
+	return
+}
+
+// SubmitTransactionXDR is the same as Client.SubmitTransactionXDR.
+func (fc *FailoverClient) SubmitTransactionXDR(transactionXdr string) (hProtocol.TransactionSuccess, error) {
+	return fc.SubmitTransactionXDRWithContext(context.Background(), transactionXdr)
+}
+
+// SubmitTransactionXDRWithContext is the same as Client.SubmitTransactionXDRWithContext.
+func (fc *FailoverClient) SubmitTransactionXDRWithContext(ctx context.Context, transactionXdr string) (result hProtocol.TransactionSuccess, err error) {
+	err = fc.submit(ctx, func(c *Client) (err error) {
+		result, err = c.SubmitTransactionXDRWithContext(ctx, transactionXdr)
+		return
+	})
+	return
+}
+
+// SubmitTransaction is the same as Client.SubmitTransaction.
+func (fc *FailoverClient) SubmitTransaction(transactionXdr txnbuild.Transaction) (hProtocol.TransactionSuccess, error) {
+	return fc.SubmitTransactionWithContext(context.Background(), transactionXdr)
+}
+
+// SubmitTransactionWithContext is the same as Client.SubmitTransactionWithContext.
+func (fc *FailoverClient) SubmitTransactionWithContext(ctx context.Context, transactionXdr txnbuild.Transaction) (result hProtocol.TransactionSuccess, err error) {
+	return fc.SubmitTransactionWithOptionsWithContext(ctx, transactionXdr, SubmitTxOpts{})
+}
+
+// SubmitTransactionWithOptions is the same as Client.SubmitTransactionWithOptions.
+func (fc *FailoverClient) SubmitTransactionWithOptions(transaction txnbuild.Transaction, opts SubmitTxOpts) (hProtocol.TransactionSuccess, error) {
+	return fc.SubmitTransactionWithOptionsWithContext(context.Background(), transaction, opts)
+}
+
+// SubmitTransactionWithOptionsWithContext is the same as Client.SubmitTransactionWithOptionsWithContext.
+// The memo check fails over like other requests, the submission like SubmitTransactionXDR.
+func (fc *FailoverClient) SubmitTransactionWithOptionsWithContext(ctx context.Context, transaction txnbuild.Transaction, opts SubmitTxOpts) (result hProtocol.TransactionSuccess, err error) {
+	if !opts.SkipMemoRequiredCheck {
+		err = fc.CheckMemoRequiredWithContext(ctx, transaction)
+		if err != nil {
+			return
+		}
+	}
+
+	err = fc.submit(ctx, func(c *Client) (err error) {
+		result, err = c.SubmitTransactionWithOptionsWithContext(ctx, transaction, SubmitTxOpts{SkipMemoRequiredCheck: true})
+		return
+	})
+	return
+}
+
+// CheckMemoRequired is the same as Client.CheckMemoRequired.
+func (fc *FailoverClient) CheckMemoRequired(transaction txnbuild.Transaction) error {
+	return fc.CheckMemoRequiredWithContext(context.Background(), transaction)
+}
+
+// CheckMemoRequiredWithContext is the same as Client.CheckMemoRequiredWithContext.
+func (fc *FailoverClient) CheckMemoRequiredWithContext(ctx context.Context, transaction txnbuild.Transaction) error {
+	return fc.do(ctx, func(c *Client) error {
+		return c.CheckMemoRequiredWithContext(ctx, transaction)
+	})
+}
+
+// Transactions is the same as Client.Transactions.
+func (fc *FailoverClient) Transactions(request TransactionRequest) (hProtocol.TransactionsPage, error) {
+	return fc.TransactionsWithContext(context.Background(), request)
+}
+
+// TransactionsWithContext is the same as Client.TransactionsWithContext.
+func (fc *FailoverClient) TransactionsWithContext(ctx context.Context, request TransactionRequest) (result hProtocol.TransactionsPage, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.TransactionsWithContext(ctx, request)
+		return
+	})
+	return
+}
+
+// TransactionDetail is the same as Client.TransactionDetail.
+func (fc *FailoverClient) TransactionDetail(txHash string) (hProtocol.Transaction, error) {
+	return fc.TransactionDetailWithContext(context.Background(), txHash)
+}
+
+// TransactionDetailWithContext is the same as Client.TransactionDetailWithContext.
+func (fc *FailoverClient) TransactionDetailWithContext(ctx context.Context, txHash string) (result hProtocol.Transaction, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.TransactionDetailWithContext(ctx, txHash)
+		return
+	})
+	return
+}
+
+// OrderBook is the same as Client.OrderBook.
+func (fc *FailoverClient) OrderBook(request OrderBookRequest) (hProtocol.OrderBookSummary, error) {
+	return fc.OrderBookWithContext(context.Background(), request)
+}
+
+// OrderBookWithContext is the same as Client.OrderBookWithContext.
+func (fc *FailoverClient) OrderBookWithContext(ctx context.Context, request OrderBookRequest) (result hProtocol.OrderBookSummary, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.OrderBookWithContext(ctx, request)
+		return
+	})
+	return
+}
+
+// Paths is the same as Client.Paths.
+func (fc *FailoverClient) Paths(request PathsRequest) (hProtocol.PathsPage, error) {
+	return fc.PathsWithContext(context.Background(), request)
+}
+
+// PathsWithContext is the same as Client.PathsWithContext.
+func (fc *FailoverClient) PathsWithContext(ctx context.Context, request PathsRequest) (result hProtocol.PathsPage, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.PathsWithContext(ctx, request)
+		return
+	})
+	return
+}
+
+// StrictSendPaths is the same as Client.StrictSendPaths.
+func (fc *FailoverClient) StrictSendPaths(request StrictSendPathsRequest) (hProtocol.PathsPage, error) {
+	return fc.StrictSendPathsWithContext(context.Background(), request)
+}
+
+// StrictSendPathsWithContext is the same as Client.StrictSendPathsWithContext.
+func (fc *FailoverClient) StrictSendPathsWithContext(ctx context.Context, request StrictSendPathsRequest) (result hProtocol.PathsPage, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.StrictSendPathsWithContext(ctx, request)
+		return
+	})
+	return
+}
+
+// Payments is the same as Client.Payments.
+func (fc *FailoverClient) Payments(request OperationRequest) (operations.OperationsPage, error) {
+	return fc.PaymentsWithContext(context.Background(), request)
+}
+
+// PaymentsWithContext is the same as Client.PaymentsWithContext.
+func (fc *FailoverClient) PaymentsWithContext(ctx context.Context, request OperationRequest) (result operations.OperationsPage, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.PaymentsWithContext(ctx, request)
+		return
+	})
+	return
+}
+
+// TradeAggregations is the same as Client.TradeAggregations.
+func (fc *FailoverClient) TradeAggregations(request TradeAggregationRequest) (hProtocol.TradeAggregationsPage, error) {
+	return fc.TradeAggregationsWithContext(context.Background(), request)
+}
+
+// TradeAggregationsWithContext is the same as Client.TradeAggregationsWithContext.
+func (fc *FailoverClient) TradeAggregationsWithContext(ctx context.Context, request TradeAggregationRequest) (result hProtocol.TradeAggregationsPage, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.TradeAggregationsWithContext(ctx, request)
+		return
+	})
+	return
+}
+
+// Trades is the same as Client.Trades.
+func (fc *FailoverClient) Trades(request TradeRequest) (hProtocol.TradesPage, error) {
+	return fc.TradesWithContext(context.Background(), request)
+}
+
+// TradesWithContext is the same as Client.TradesWithContext.
+func (fc *FailoverClient) TradesWithContext(ctx context.Context, request TradeRequest) (result hProtocol.TradesPage, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.TradesWithContext(ctx, request)
+		return
+	})
+	return
+}
+
+// Fund is the same as Client.Fund.
+func (fc *FailoverClient) Fund(addr string) (hProtocol.TransactionSuccess, error) {
+	return fc.FundWithContext(context.Background(), addr)
+}
+
+// FundWithContext is the same as Client.FundWithContext.
+func (fc *FailoverClient) FundWithContext(ctx context.Context, addr string) (result hProtocol.TransactionSuccess, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.FundWithContext(ctx, addr)
+		return
+	})
+	return
+}
+
+// StreamTransactions is the same as Client.StreamTransactions, resuming the stream on another node when it fails.
+func (fc *FailoverClient) StreamTransactions(ctx context.Context, request TransactionRequest, handler TransactionHandler) error {
+	return fc.stream(ctx, func(c *Client) error {
+		return c.StreamTransactions(ctx, request, handler)
+	})
+}
+
+// StreamTrades is the same as Client.StreamTrades, resuming the stream on another node when it fails.
+func (fc *FailoverClient) StreamTrades(ctx context.Context, request TradeRequest, handler TradeHandler) error {
+	return fc.stream(ctx, func(c *Client) error {
+		return c.StreamTrades(ctx, request, handler)
+	})
+}
+
+// StreamEffects is the same as Client.StreamEffects, resuming the stream on another node when it fails.
+func (fc *FailoverClient) StreamEffects(ctx context.Context, request EffectRequest, handler EffectHandler) error {
+	return fc.stream(ctx, func(c *Client) error {
+		return c.StreamEffects(ctx, request, handler)
+	})
+}
+
+// StreamOperations is the same as Client.StreamOperations, resuming the stream on another node when it fails.
+func (fc *FailoverClient) StreamOperations(ctx context.Context, request OperationRequest, handler OperationHandler) error {
+	return fc.stream(ctx, func(c *Client) error {
+		return c.StreamOperations(ctx, request, handler)
+	})
+}
+
+// StreamPayments is the same as Client.StreamPayments, resuming the stream on another node when it fails.
+func (fc *FailoverClient) StreamPayments(ctx context.Context, request OperationRequest, handler OperationHandler) error {
+	return fc.stream(ctx, func(c *Client) error {
+		return c.StreamPayments(ctx, request, handler)
+	})
+}
+
+// StreamOffers is the same as Client.StreamOffers, resuming the stream on another node when it fails.
+func (fc *FailoverClient) StreamOffers(ctx context.Context, request OfferRequest, handler OfferHandler) error {
+	return fc.stream(ctx, func(c *Client) error {
+		return c.StreamOffers(ctx, request, handler)
+	})
+}
+
+// StreamLedgers is the same as Client.StreamLedgers, resuming the stream on another node when it fails.
+func (fc *FailoverClient) StreamLedgers(ctx context.Context, request LedgerRequest, handler LedgerHandler) error {
+	return fc.stream(ctx, func(c *Client) error {
+		return c.StreamLedgers(ctx, request, handler)
+	})
+}
+
+// StreamOrderBooks is the same as Client.StreamOrderBooks, resuming the stream on another node when it fails.
+func (fc *FailoverClient) StreamOrderBooks(ctx context.Context, request OrderBookRequest, handler OrderBookHandler) error {
+	return fc.stream(ctx, func(c *Client) error {
+		return c.StreamOrderBooks(ctx, request, handler)
+	})
+}
+
+// Root is the same as Client.Root.
+func (fc *FailoverClient) Root() (hProtocol.Root, error) {
+	return fc.RootWithContext(context.Background())
+}
+
+// RootWithContext is the same as Client.RootWithContext.
+func (fc *FailoverClient) RootWithContext(ctx context.Context) (result hProtocol.Root, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.RootWithContext(ctx)
+		return
+	})
+	return
+}
+
+// NextAssetsPage is the same as Client.NextAssetsPage.
+func (fc *FailoverClient) NextAssetsPage(page hProtocol.AssetsPage) (hProtocol.AssetsPage, error) {
+	return fc.NextAssetsPageWithContext(context.Background(), page)
+}
+
+// NextAssetsPageWithContext is the same as Client.NextAssetsPageWithContext.
+func (fc *FailoverClient) NextAssetsPageWithContext(ctx context.Context, page hProtocol.AssetsPage) (result hProtocol.AssetsPage, err error) {
+	err = fc.doPage(ctx, page.Links.Next.Href, func(c *Client, href string) (err error) {
+		page.Links.Next.Href = href
+		result, err = c.NextAssetsPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// PrevAssetsPage is the same as Client.PrevAssetsPage.
+func (fc *FailoverClient) PrevAssetsPage(page hProtocol.AssetsPage) (hProtocol.AssetsPage, error) {
+	return fc.PrevAssetsPageWithContext(context.Background(), page)
+}
+
+// PrevAssetsPageWithContext is the same as Client.PrevAssetsPageWithContext.
+func (fc *FailoverClient) PrevAssetsPageWithContext(ctx context.Context, page hProtocol.AssetsPage) (result hProtocol.AssetsPage, err error) {
+	err = fc.doPage(ctx, page.Links.Prev.Href, func(c *Client, href string) (err error) {
+		page.Links.Prev.Href = href
+		result, err = c.PrevAssetsPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// NextLedgersPage is the same as Client.NextLedgersPage.
+func (fc *FailoverClient) NextLedgersPage(page hProtocol.LedgersPage) (hProtocol.LedgersPage, error) {
+	return fc.NextLedgersPageWithContext(context.Background(), page)
+}
+
+// NextLedgersPageWithContext is the same as Client.NextLedgersPageWithContext.
+func (fc *FailoverClient) NextLedgersPageWithContext(ctx context.Context, page hProtocol.LedgersPage) (result hProtocol.LedgersPage, err error) {
+	err = fc.doPage(ctx, page.Links.Next.Href, func(c *Client, href string) (err error) {
+		page.Links.Next.Href = href
+		result, err = c.NextLedgersPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// PrevLedgersPage is the same as Client.PrevLedgersPage.
+func (fc *FailoverClient) PrevLedgersPage(page hProtocol.LedgersPage) (hProtocol.LedgersPage, error) {
+	return fc.PrevLedgersPageWithContext(context.Background(), page)
+}
+
+// PrevLedgersPageWithContext is the same as Client.PrevLedgersPageWithContext.
+func (fc *FailoverClient) PrevLedgersPageWithContext(ctx context.Context, page hProtocol.LedgersPage) (result hProtocol.LedgersPage, err error) {
+	err = fc.doPage(ctx, page.Links.Prev.Href, func(c *Client, href string) (err error) {
+		page.Links.Prev.Href = href
+		result, err = c.PrevLedgersPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// NextEffectsPage is the same as Client.NextEffectsPage.
+func (fc *FailoverClient) NextEffectsPage(page effects.EffectsPage) (effects.EffectsPage, error) {
+	return fc.NextEffectsPageWithContext(context.Background(), page)
+}
+
+// NextEffectsPageWithContext is the same as Client.NextEffectsPageWithContext.
+func (fc *FailoverClient) NextEffectsPageWithContext(ctx context.Context, page effects.EffectsPage) (result effects.EffectsPage, err error) {
+	err = fc.doPage(ctx, page.Links.Next.Href, func(c *Client, href string) (err error) {
+		page.Links.Next.Href = href
+		result, err = c.NextEffectsPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// PrevEffectsPage is the same as Client.PrevEffectsPage.
+func (fc *FailoverClient) PrevEffectsPage(page effects.EffectsPage) (effects.EffectsPage, error) {
+	return fc.PrevEffectsPageWithContext(context.Background(), page)
+}
+
+// PrevEffectsPageWithContext is the same as Client.PrevEffectsPageWithContext.
+func (fc *FailoverClient) PrevEffectsPageWithContext(ctx context.Context, page effects.EffectsPage) (result effects.EffectsPage, err error) {
+	err = fc.doPage(ctx, page.Links.Prev.Href, func(c *Client, href string) (err error) {
+		page.Links.Prev.Href = href
+		result, err = c.PrevEffectsPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// NextTransactionsPage is the same as Client.NextTransactionsPage.
+func (fc *FailoverClient) NextTransactionsPage(page hProtocol.TransactionsPage) (hProtocol.TransactionsPage, error) {
+	return fc.NextTransactionsPageWithContext(context.Background(), page)
+}
+
+// NextTransactionsPageWithContext is the same as Client.NextTransactionsPageWithContext.
+func (fc *FailoverClient) NextTransactionsPageWithContext(ctx context.Context, page hProtocol.TransactionsPage) (result hProtocol.TransactionsPage, err error) {
+	err = fc.doPage(ctx, page.Links.Next.Href, func(c *Client, href string) (err error) {
+		page.Links.Next.Href = href
+		result, err = c.NextTransactionsPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// PrevTransactionsPage is the same as Client.PrevTransactionsPage.
+func (fc *FailoverClient) PrevTransactionsPage(page hProtocol.TransactionsPage) (hProtocol.TransactionsPage, error) {
+	return fc.PrevTransactionsPageWithContext(context.Background(), page)
+}
+
+// PrevTransactionsPageWithContext is the same as Client.PrevTransactionsPageWithContext.
+func (fc *FailoverClient) PrevTransactionsPageWithContext(ctx context.Context, page hProtocol.TransactionsPage) (result hProtocol.TransactionsPage, err error) {
+	err = fc.doPage(ctx, page.Links.Prev.Href, func(c *Client, href string) (err error) {
+		page.Links.Prev.Href = href
+		result, err = c.PrevTransactionsPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// NextOperationsPage is the same as Client.NextOperationsPage.
+func (fc *FailoverClient) NextOperationsPage(page operations.OperationsPage) (operations.OperationsPage, error) {
+	return fc.NextOperationsPageWithContext(context.Background(), page)
+}
+
+// NextOperationsPageWithContext is the same as Client.NextOperationsPageWithContext.
+func (fc *FailoverClient) NextOperationsPageWithContext(ctx context.Context, page operations.OperationsPage) (result operations.OperationsPage, err error) {
+	err = fc.doPage(ctx, page.Links.Next.Href, func(c *Client, href string) (err error) {
+		page.Links.Next.Href = href
+		result, err = c.NextOperationsPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// PrevOperationsPage is the same as Client.PrevOperationsPage.
+func (fc *FailoverClient) PrevOperationsPage(page operations.OperationsPage) (operations.OperationsPage, error) {
+	return fc.PrevOperationsPageWithContext(context.Background(), page)
+}
+
+// PrevOperationsPageWithContext is the same as Client.PrevOperationsPageWithContext.
+func (fc *FailoverClient) PrevOperationsPageWithContext(ctx context.Context, page operations.OperationsPage) (result operations.OperationsPage, err error) {
+	err = fc.doPage(ctx, page.Links.Prev.Href, func(c *Client, href string) (err error) {
+		page.Links.Prev.Href = href
+		result, err = c.PrevOperationsPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// NextPaymentsPage is the same as Client.NextPaymentsPage.
+func (fc *FailoverClient) NextPaymentsPage(page operations.OperationsPage) (operations.OperationsPage, error) {
+	return fc.NextPaymentsPageWithContext(context.Background(), page)
+}
+
+// NextPaymentsPageWithContext is the same as Client.NextPaymentsPageWithContext.
+func (fc *FailoverClient) NextPaymentsPageWithContext(ctx context.Context, page operations.OperationsPage) (result operations.OperationsPage, err error) {
+	err = fc.doPage(ctx, page.Links.Next.Href, func(c *Client, href string) (err error) {
+		page.Links.Next.Href = href
+		result, err = c.NextPaymentsPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// PrevPaymentsPage is the same as Client.PrevPaymentsPage.
+func (fc *FailoverClient) PrevPaymentsPage(page operations.OperationsPage) (operations.OperationsPage, error) {
+	return fc.PrevPaymentsPageWithContext(context.Background(), page)
+}
+
+// PrevPaymentsPageWithContext is the same as Client.PrevPaymentsPageWithContext.
+func (fc *FailoverClient) PrevPaymentsPageWithContext(ctx context.Context, page operations.OperationsPage) (result operations.OperationsPage, err error) {
+	err = fc.doPage(ctx, page.Links.Prev.Href, func(c *Client, href string) (err error) {
+		page.Links.Prev.Href = href
+		result, err = c.PrevPaymentsPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// NextOffersPage is the same as Client.NextOffersPage.
+func (fc *FailoverClient) NextOffersPage(page hProtocol.OffersPage) (hProtocol.OffersPage, error) {
+	return fc.NextOffersPageWithContext(context.Background(), page)
+}
+
+// NextOffersPageWithContext is the same as Client.NextOffersPageWithContext.
+func (fc *FailoverClient) NextOffersPageWithContext(ctx context.Context, page hProtocol.OffersPage) (result hProtocol.OffersPage, err error) {
+	err = fc.doPage(ctx, page.Links.Next.Href, func(c *Client, href string) (err error) {
+		page.Links.Next.Href = href
+		result, err = c.NextOffersPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// PrevOffersPage is the same as Client.PrevOffersPage.
+func (fc *FailoverClient) PrevOffersPage(page hProtocol.OffersPage) (hProtocol.OffersPage, error) {
+	return fc.PrevOffersPageWithContext(context.Background(), page)
+}
+
+// PrevOffersPageWithContext is the same as Client.PrevOffersPageWithContext.
+func (fc *FailoverClient) PrevOffersPageWithContext(ctx context.Context, page hProtocol.OffersPage) (result hProtocol.OffersPage, err error) {
+	err = fc.doPage(ctx, page.Links.Prev.Href, func(c *Client, href string) (err error) {
+		page.Links.Prev.Href = href
+		result, err = c.PrevOffersPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// NextTradesPage is the same as Client.NextTradesPage.
+func (fc *FailoverClient) NextTradesPage(page hProtocol.TradesPage) (hProtocol.TradesPage, error) {
+	return fc.NextTradesPageWithContext(context.Background(), page)
+}
+
+// NextTradesPageWithContext is the same as Client.NextTradesPageWithContext.
+func (fc *FailoverClient) NextTradesPageWithContext(ctx context.Context, page hProtocol.TradesPage) (result hProtocol.TradesPage, err error) {
+	err = fc.doPage(ctx, page.Links.Next.Href, func(c *Client, href string) (err error) {
+		page.Links.Next.Href = href
+		result, err = c.NextTradesPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// PrevTradesPage is the same as Client.PrevTradesPage.
+func (fc *FailoverClient) PrevTradesPage(page hProtocol.TradesPage) (hProtocol.TradesPage, error) {
+	return fc.PrevTradesPageWithContext(context.Background(), page)
+}
+
+// PrevTradesPageWithContext is the same as Client.PrevTradesPageWithContext.
+func (fc *FailoverClient) PrevTradesPageWithContext(ctx context.Context, page hProtocol.TradesPage) (result hProtocol.TradesPage, err error) {
+	err = fc.doPage(ctx, page.Links.Prev.Href, func(c *Client, href string) (err error) {
+		page.Links.Prev.Href = href
+		result, err = c.PrevTradesPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// HomeDomainForAccount is the same as Client.HomeDomainForAccount.
+func (fc *FailoverClient) HomeDomainForAccount(aid string) (string, error) {
+	return fc.HomeDomainForAccountWithContext(context.Background(), aid)
+}
+
+// HomeDomainForAccountWithContext is the same as Client.HomeDomainForAccountWithContext.
+func (fc *FailoverClient) HomeDomainForAccountWithContext(ctx context.Context, aid string) (result string, err error) {
+	err = fc.do(ctx, func(c *Client) (err error) {
+		result, err = c.HomeDomainForAccountWithContext(ctx, aid)
+		return
+	})
+	return
+}
+
+// NextTradeAggregationsPage is the same as Client.NextTradeAggregationsPage.
+func (fc *FailoverClient) NextTradeAggregationsPage(page hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error) {
+	return fc.NextTradeAggregationsPageWithContext(context.Background(), page)
+}
+
+// NextTradeAggregationsPageWithContext is the same as Client.NextTradeAggregationsPageWithContext.
+func (fc *FailoverClient) NextTradeAggregationsPageWithContext(ctx context.Context, page hProtocol.TradeAggregationsPage) (result hProtocol.TradeAggregationsPage, err error) {
+	err = fc.doPage(ctx, page.Links.Next.Href, func(c *Client, href string) (err error) {
+		page.Links.Next.Href = href
+		result, err = c.NextTradeAggregationsPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// PrevTradeAggregationsPage is the same as Client.PrevTradeAggregationsPage.
+func (fc *FailoverClient) PrevTradeAggregationsPage(page hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error) {
+	return fc.PrevTradeAggregationsPageWithContext(context.Background(), page)
+}
+
+// PrevTradeAggregationsPageWithContext is the same as Client.PrevTradeAggregationsPageWithContext.
+func (fc *FailoverClient) PrevTradeAggregationsPageWithContext(ctx context.Context, page hProtocol.TradeAggregationsPage) (result hProtocol.TradeAggregationsPage, err error) {
+	err = fc.doPage(ctx, page.Links.Prev.Href, func(c *Client, href string) (err error) {
+		page.Links.Prev.Href = href
+		result, err = c.PrevTradeAggregationsPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// NextAccountsPage is the same as Client.NextAccountsPage.
+func (fc *FailoverClient) NextAccountsPage(page hProtocol.AccountsPage) (hProtocol.AccountsPage, error) {
+	return fc.NextAccountsPageWithContext(context.Background(), page)
+}
+
+// NextAccountsPageWithContext is the same as Client.NextAccountsPageWithContext.
+func (fc *FailoverClient) NextAccountsPageWithContext(ctx context.Context, page hProtocol.AccountsPage) (result hProtocol.AccountsPage, err error) {
+	err = fc.doPage(ctx, page.Links.Next.Href, func(c *Client, href string) (err error) {
+		page.Links.Next.Href = href
+		result, err = c.NextAccountsPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+// PrevAccountsPage is the same as Client.PrevAccountsPage.
+func (fc *FailoverClient) PrevAccountsPage(page hProtocol.AccountsPage) (hProtocol.AccountsPage, error) {
+	return fc.PrevAccountsPageWithContext(context.Background(), page)
+}
+
+// PrevAccountsPageWithContext is the same as Client.PrevAccountsPageWithContext.
+func (fc *FailoverClient) PrevAccountsPageWithContext(ctx context.Context, page hProtocol.AccountsPage) (result hProtocol.AccountsPage, err error) {
+	err = fc.doPage(ctx, page.Links.Prev.Href, func(c *Client, href string) (err error) {
+		page.Links.Prev.Href = href
+		result, err = c.PrevAccountsPageWithContext(ctx, page)
+		return
+	})
+	return
+}
+
+var _ ClientInterface = &FailoverClient{}
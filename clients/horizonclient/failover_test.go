@@ -0,0 +1,238 @@
+package horizonclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/http/httptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailoverClientHealth(t *testing.T) {
+	hmock := httptest.NewClient()
+	fc := NewFailoverClient(&Client{HTTP: hmock}, "https://node1", "https://node2/", "https://node3")
+
+	// node1 is far behind its stellar-core, node3 is far behind node2
+	hmock.On("GET", "https://node1/").ReturnString(200, failoverRoot(80, 100))
+	hmock.On("GET", "https://node2/").ReturnString(200, failoverRoot(100, 100))
+	hmock.On("GET", "https://node3/").ReturnString(200, failoverRoot(90, 90))
+	hmock.On("GET", "https://node2/ledgers/69859").ReturnString(200, ledgerResponse)
+
+	ledger, err := fc.LedgerDetail(69859)
+	require.NoError(t, err)
+	assert.Equal(t, int32(69859), ledger.Sequence)
+
+	nodes := fc.Nodes()
+	require.Len(t, nodes, 3)
+	assert.Equal(t, "https://node1/", nodes[0].HorizonURL)
+	assert.False(t, nodes[0].Healthy)
+	assert.Contains(t, nodes[0].Err.Error(), "history is stale")
+	assert.True(t, nodes[1].Healthy)
+	assert.Equal(t, int32(100), nodes[1].HorizonSequence)
+	assert.Equal(t, int32(100), nodes[1].CoreSequence)
+	assert.False(t, nodes[2].Healthy)
+	assert.Contains(t, nodes[2].Err.Error(), "behind the most up-to-date node")
+
+	// no node is healthy
+	hmock.On("GET", "https://node1/").ReturnString(500, "")
+	hmock.On("GET", "https://node2/").ReturnError("connection refused")
+	hmock.On("GET", "https://node3/").ReturnString(200, failoverRoot(50, 100))
+	fc.CheckHealth(context.Background())
+	_, err = fc.LedgerDetail(69859)
+	assert.Equal(t, ErrNoHealthyNode, err)
+}
+
+func TestFailoverClientFailover(t *testing.T) {
+	hmock := httptest.NewClient()
+	fc := NewFailoverClient(&Client{HTTP: hmock}, "https://node1", "https://node2")
+
+	hmock.On("GET", "https://node1/").ReturnString(200, failoverRoot(100, 100))
+	hmock.On("GET", "https://node2/").ReturnString(200, failoverRoot(100, 100))
+	fc.CheckHealth(context.Background())
+
+	// node1 fails, the request is sent to node2 and node1 is taken out of rotation
+	hmock.On("GET", "https://node1/ledgers/69859").ReturnString(503, "")
+	hmock.On("GET", "https://node2/ledgers/69859").ReturnString(200, ledgerResponse)
+
+	ledger, err := fc.LedgerDetail(69859)
+	require.NoError(t, err)
+	assert.Equal(t, int32(69859), ledger.Sequence)
+	assert.False(t, fc.Nodes()[0].Healthy)
+	assert.True(t, fc.Nodes()[1].Healthy)
+
+	// requests rejected by horizon are not retried on another node
+	hmock.On("GET", "https://node1/").ReturnString(200, failoverRoot(100, 100))
+	hmock.On("GET", "https://node2/").ReturnString(200, failoverRoot(100, 100))
+	fc.CheckHealth(context.Background())
+	hmock.On("GET", "https://node1/ledgers/1").ReturnString(404, notFoundResponse)
+
+	_, err = fc.LedgerDetail(1)
+	if assert.Error(t, err) {
+		herr, ok := errors.Cause(err).(*Error)
+		require.True(t, ok)
+		assert.Equal(t, 404, herr.Response.StatusCode)
+	}
+	assert.True(t, fc.Nodes()[0].Healthy)
+
+	// gateway errors without a horizon problem body are node failures too
+	hmock.On("GET", "https://node1/ledgers/69859").ReturnString(502, "<html>Bad Gateway</html>")
+	hmock.On("GET", "https://node2/ledgers/69859").ReturnString(200, ledgerResponse)
+
+	ledger, err = fc.LedgerDetail(69859)
+	require.NoError(t, err)
+	assert.Equal(t, int32(69859), ledger.Sequence)
+	assert.False(t, fc.Nodes()[0].Healthy)
+
+	// so are connection errors
+	hmock.On("GET", "https://node1/").ReturnString(200, failoverRoot(100, 100))
+	hmock.On("GET", "https://node2/").ReturnString(200, failoverRoot(100, 100))
+	fc.CheckHealth(context.Background())
+	hmock.On("GET", "https://node1/ledgers/69859").ReturnError("connection refused")
+	hmock.On("GET", "https://node2/ledgers/69859").ReturnString(200, ledgerResponse)
+
+	ledger, err = fc.LedgerDetail(69859)
+	require.NoError(t, err)
+	assert.Equal(t, int32(69859), ledger.Sequence)
+	assert.False(t, fc.Nodes()[0].Healthy)
+}
+
+func TestFailoverClientPageLinks(t *testing.T) {
+	hmock := httptest.NewClient()
+	fc := NewFailoverClient(&Client{HTTP: hmock}, "https://node1", "https://node2")
+
+	hmock.On("GET", "https://node1/").ReturnString(503, "")
+	hmock.On("GET", "https://node2/").ReturnString(200, failoverRoot(100, 100))
+
+	// the link returned by node1 is followed on node2
+	var page hProtocol.LedgersPage
+	page.Links.Next.Href = "https://node1/ledgers?cursor=300042120331264&limit=10&order=asc"
+	hmock.On(
+		"GET",
+		"https://node2/ledgers?cursor=300042120331264&limit=10&order=asc",
+	).ReturnString(200, `{"_embedded": {"records": []}}`)
+
+	_, err := fc.NextLedgersPage(page)
+	require.NoError(t, err)
+}
+
+func TestFailoverClientStream(t *testing.T) {
+	hmock := httptest.NewClient()
+	fc := NewFailoverClient(&Client{HTTP: hmock}, "https://node1", "https://node2")
+
+	hmock.On("GET", "https://node1/").ReturnString(200, failoverRoot(100, 100))
+	hmock.On("GET", "https://node2/").ReturnString(200, failoverRoot(100, 100))
+
+	// node1 fails when the stream reconnects, so it is resumed on node2 from the last event handled
+	hmock.On("GET", "https://node1/ledgers?cursor=now").ReturnString(200, ledgerStreamWithIDs)
+	hmock.On("GET", "https://node1/ledgers?cursor=300046415298560").ReturnString(503, "")
+	hmock.On("GET", "https://node2/ledgers?cursor=300046415298560").ReturnString(200, ledgerStreamWithIDs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var sequences []int32
+	err := fc.StreamLedgers(ctx, LedgerRequest{}, func(ledger hProtocol.Ledger) {
+		sequences = append(sequences, ledger.Sequence)
+		if len(sequences) == 3 {
+			cancel()
+		}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int32{69859, 69860, 69859}, sequences)
+	assert.False(t, fc.Nodes()[0].Healthy)
+
+	// streams give up when every node failed without progress
+	hmock.On("GET", "https://node1/").ReturnString(200, failoverRoot(100, 100))
+	hmock.On("GET", "https://node2/").ReturnString(200, failoverRoot(100, 100))
+	hmock.On("GET", "https://node1/ledgers?cursor=now").ReturnString(503, "")
+	hmock.On("GET", "https://node2/ledgers?cursor=now").ReturnString(502, "")
+	fc.CheckHealth(context.Background())
+
+	err = fc.StreamLedgers(context.Background(), LedgerRequest{}, func(ledger hProtocol.Ledger) {})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "stream failed on every horizon server")
+		assert.Contains(t, err.Error(), "got bad HTTP status code 502")
+	}
+}
+
+func TestFailoverClientStreamCut(t *testing.T) {
+	hmock := httptest.NewClient()
+	fc := NewFailoverClient(&Client{HTTP: hmock}, "https://node1", "https://node2")
+
+	hmock.On("GET", "https://node1/").ReturnString(200, failoverRoot(100, 100))
+	hmock.On("GET", "https://node2/").ReturnString(200, failoverRoot(100, 100))
+
+	// the connection to node1 drops after the first event, so the stream is resumed on node2 from it
+	firstEvent := ledgerStreamWithIDs[:strings.Index(ledgerStreamWithIDs, "\n\n")+2]
+	hmock.On("GET", "https://node1/ledgers?cursor=now").Return(
+		func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(io.MultiReader(strings.NewReader(firstEvent), cutReader{})),
+				Request:    req,
+			}, nil
+		},
+	)
+	hmock.On("GET", "https://node2/ledgers?cursor=300042120331264").ReturnString(200, ledgerStreamWithIDs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var sequences []int32
+	err := fc.StreamLedgers(ctx, LedgerRequest{}, func(ledger hProtocol.Ledger) {
+		sequences = append(sequences, ledger.Sequence)
+		if len(sequences) == 3 {
+			cancel()
+		}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int32{69859, 69859, 69860}, sequences)
+	assert.False(t, fc.Nodes()[0].Healthy)
+	assert.True(t, fc.Nodes()[1].Healthy)
+}
+
+func TestFailoverClientSubmit(t *testing.T) {
+	hmock := httptest.NewClient()
+	fc := NewFailoverClient(&Client{HTTP: hmock}, "https://node1", "https://node2")
+
+	hmock.On("GET", "https://node1/").ReturnString(200, failoverRoot(100, 100))
+	hmock.On("GET", "https://node2/").ReturnString(200, failoverRoot(100, 100))
+
+	txXdr := `AAAAABB90WssODNIgi6BHveqzxTRmIpvAFRyVNM+Hm2GVuCcAAAAZAAABD0AAuV/AAAAAAAAAAAAAAABAAAAAAAAAAAAAAAAyTBGxOgfSApppsTnb/YRr6gOR8WT0LZNrhLh4y3FCgoAAAAXSHboAAAAAAAAAAABhlbgnAAAAEAivKe977CQCxMOKTuj+cWTFqc2OOJU8qGr9afrgu2zDmQaX5Q0cNshc3PiBwe0qw/+D/qJk5QqM5dYeSUGeDQP`
+
+	// a submission which reached node1 may be applied, so it is not sent to node2
+	hmock.On("POST", "https://node1/transactions").ReturnString(504, `{"status": 504, "title": "Timeout"}`)
+	_, err := fc.SubmitTransactionXDR(txXdr)
+	if assert.Error(t, err) {
+		herr, ok := errors.Cause(err).(*Error)
+		if assert.True(t, ok) {
+			assert.Equal(t, 504, herr.Response.StatusCode)
+		}
+	}
+	assert.True(t, fc.Nodes()[0].Healthy)
+
+	// a submission which got no response from node1 is sent to node2
+	hmock.On("POST", "https://node1/transactions").ReturnError("connection refused")
+	hmock.On("POST", "https://node2/transactions").ReturnString(200, txSuccess)
+	resp, err := fc.SubmitTransactionXDR(txXdr)
+	require.NoError(t, err)
+	assert.Equal(t, "bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca", resp.Hash)
+	assert.False(t, fc.Nodes()[0].Healthy)
+}
+
+// cutReader fails like a connection dropped by the server.
+type cutReader struct{}
+
+func (cutReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
+
+func failoverRoot(horizonSequence, coreSequence int32) string {
+	return fmt.Sprintf(`{"history_latest_ledger": %d, "core_latest_ledger": %d}`,
+		horizonSequence, coreSequence)
+}
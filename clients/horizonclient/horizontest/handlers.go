@@ -0,0 +1,517 @@
+package horizontest
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stellar/go/network"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/operations"
+	"github.com/stellar/go/support/render/hal"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 200
+	// baseFee is the fee charged per operation of a submitted transaction
+	baseFee = 100
+	// streamIdleTimeout is how long a stream stays open without new records. Like horizon, the
+	// server then closes it and the client reconnects from its last cursor.
+	streamIdleTimeout = 100 * time.Millisecond
+)
+
+var notFound = problem.P{
+	Type:   "https://stellar.org/horizon-errors/not_found",
+	Title:  "Resource Missing",
+	Status: http.StatusNotFound,
+	Detail: "The resource at the url requested was not found.",
+}
+
+var memoTypes = map[xdr.MemoType]string{
+	xdr.MemoTypeMemoNone:   "none",
+	xdr.MemoTypeMemoText:   "text",
+	xdr.MemoTypeMemoId:     "id",
+	xdr.MemoTypeMemoHash:   "hash",
+	xdr.MemoTypeMemoReturn: "return",
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if response, ok := s.scripted(r.Method, r.URL.Path); ok {
+		writeResponse(w, response.Status, response.Body)
+		return
+	}
+
+	if r.Method == http.MethodPost && r.URL.Path == "/transactions" {
+		s.submit(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeResponse(w, http.StatusMethodNotAllowed, problem.P{
+			Type:   "https://stellar.org/horizon-errors/method_not_allowed",
+			Title:  "Method Not Allowed",
+			Status: http.StatusMethodNotAllowed,
+		})
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "":
+		s.root(w)
+	case len(parts) == 2 && parts[0] == "accounts":
+		s.account(w, parts[1])
+	case len(parts) == 3 && parts[0] == "accounts" && parts[2] == "transactions":
+		s.collection(w, r, func() []hal.Pageable {
+			return s.transactionRecords(func(tx hProtocol.Transaction) bool { return tx.Account == parts[1] })
+		})
+	case len(parts) == 3 && parts[0] == "accounts" && parts[2] == "operations":
+		s.collection(w, r, func() []hal.Pageable {
+			return s.operationRecords(func(op operations.Operation) bool {
+				return s.transactionSource(op.GetTransactionHash()) == parts[1]
+			})
+		})
+	case len(parts) == 1 && parts[0] == "transactions":
+		s.collection(w, r, func() []hal.Pageable {
+			return s.transactionRecords(func(hProtocol.Transaction) bool { return true })
+		})
+	case len(parts) == 2 && parts[0] == "transactions":
+		s.transaction(w, parts[1])
+	case len(parts) == 3 && parts[0] == "transactions" && parts[2] == "operations":
+		s.collection(w, r, func() []hal.Pageable {
+			return s.operationRecords(func(op operations.Operation) bool { return op.GetTransactionHash() == parts[1] })
+		})
+	case len(parts) == 1 && parts[0] == "operations":
+		s.collection(w, r, func() []hal.Pageable {
+			return s.operationRecords(func(operations.Operation) bool { return true })
+		})
+	case len(parts) == 2 && parts[0] == "operations":
+		s.operation(w, parts[1])
+	case len(parts) == 1 && parts[0] == "order_book":
+		s.orderBook(w, r)
+	default:
+		writeResponse(w, http.StatusNotFound, notFound)
+	}
+}
+
+// scripted pops the next scripted response for method and path, if any.
+func (s *Server) scripted(method, path string) (Response, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := method + " " + path
+	responses := s.scripts[key]
+	if len(responses) == 0 {
+		return Response{}, false
+	}
+	s.scripts[key] = responses[1:]
+	return responses[0], true
+}
+
+func (s *Server) root(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeResponse(w, http.StatusOK, hProtocol.Root{
+		HorizonVersion:       "horizontest",
+		HorizonSequence:      s.ledger,
+		HistoryElderSequence: 1,
+		CoreSequence:         s.ledger,
+		NetworkPassphrase:    s.NetworkPassphrase,
+	})
+}
+
+func (s *Server) account(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account, ok := s.accounts[id]
+	if !ok {
+		writeResponse(w, http.StatusNotFound, notFound)
+		return
+	}
+	writeResponse(w, http.StatusOK, account)
+}
+
+func (s *Server) transaction(w http.ResponseWriter, hash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tx := range s.transactions {
+		if tx.Hash == hash {
+			writeResponse(w, http.StatusOK, tx)
+			return
+		}
+	}
+	writeResponse(w, http.StatusNotFound, notFound)
+}
+
+func (s *Server) operation(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, op := range s.operations {
+		if op.GetID() == id {
+			writeResponse(w, http.StatusOK, op)
+			return
+		}
+	}
+	writeResponse(w, http.StatusNotFound, notFound)
+}
+
+func (s *Server) orderBook(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	selling := hProtocol.Asset{
+		Type:   query.Get("selling_asset_type"),
+		Code:   query.Get("selling_asset_code"),
+		Issuer: query.Get("selling_asset_issuer"),
+	}
+	buying := hProtocol.Asset{
+		Type:   query.Get("buying_asset_type"),
+		Code:   query.Get("buying_asset_code"),
+		Issuer: query.Get("buying_asset_issuer"),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summary, ok := s.orderBooks[assetKey(selling.Type, selling.Code, selling.Issuer)+"/"+
+		assetKey(buying.Type, buying.Code, buying.Issuer)]
+	if !ok {
+		summary = hProtocol.OrderBookSummary{
+			Bids:    []hProtocol.PriceLevel{},
+			Asks:    []hProtocol.PriceLevel{},
+			Selling: selling,
+			Buying:  buying,
+		}
+	}
+	writeResponse(w, http.StatusOK, summary)
+}
+
+// submit applies a transaction when the sequence number of its source account allows it. The
+// transaction is recorded in a new ledger with its operations.
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	txeBase64 := r.FormValue("tx")
+	var envelope xdr.TransactionEnvelope
+	if err := xdr.SafeUnmarshalBase64(txeBase64, &envelope); err != nil {
+		writeResponse(w, http.StatusBadRequest, problem.P{
+			Type:   "https://stellar.org/horizon-errors/transaction_malformed",
+			Title:  "Transaction Malformed",
+			Status: http.StatusBadRequest,
+			Detail: err.Error(),
+		})
+		return
+	}
+	hash, err := network.HashTransaction(&envelope.Tx, s.NetworkPassphrase)
+	if err != nil {
+		writeResponse(w, http.StatusInternalServerError, problem.ServerError)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	source := envelope.Tx.SourceAccount.Address()
+	account, ok := s.accounts[source]
+	if !ok {
+		response := TransactionFailed("tx_no_source_account")
+		writeResponse(w, response.Status, response.Body)
+		return
+	}
+	sequence, err := account.GetSequenceNumber()
+	if err != nil {
+		writeResponse(w, http.StatusInternalServerError, problem.ServerError)
+		return
+	}
+	if envelope.Tx.SeqNum != sequence+1 {
+		response := TransactionFailed("tx_bad_seq")
+		writeResponse(w, response.Status, response.Body)
+		return
+	}
+
+	fee := int32(baseFee * len(envelope.Tx.Operations))
+	resultXdr, err := xdr.MarshalBase64(xdr.TransactionResult{
+		FeeCharged: xdr.Int64(fee),
+		Result: xdr.TransactionResultResult{
+			Code:    xdr.TransactionResultCodeTxSuccess,
+			Results: &[]xdr.OperationResult{},
+		},
+	})
+	if err != nil {
+		writeResponse(w, http.StatusInternalServerError, problem.ServerError)
+		return
+	}
+
+	account.Sequence = strconv.FormatInt(int64(envelope.Tx.SeqNum), 10)
+	account.LastModifiedLedger = uint32(s.ledger + 1)
+	s.accounts[source] = account
+
+	tx := hProtocol.Transaction{
+		Successful:      true,
+		Hash:            hex.EncodeToString(hash[:]),
+		Account:         source,
+		AccountSequence: account.Sequence,
+		FeeCharged:      fee,
+		MaxFee:          int32(envelope.Tx.Fee),
+		OperationCount:  int32(len(envelope.Tx.Operations)),
+		EnvelopeXdr:     txeBase64,
+		ResultXdr:       resultXdr,
+		MemoType:        memoTypes[envelope.Tx.Memo.Type],
+		Memo:            memoValue(envelope.Tx.Memo),
+	}
+	for _, signature := range envelope.Signatures {
+		tx.Signatures = append(tx.Signatures, base64.StdEncoding.EncodeToString(signature.Signature))
+	}
+	tx.Links.Self = hal.NewLink(s.URL + "/transactions/" + tx.Hash)
+	tx = s.addTransaction(tx)
+
+	for i, op := range envelope.Tx.Operations {
+		opSource := source
+		if op.SourceAccount != nil {
+			opSource = op.SourceAccount.Address()
+		}
+		token := strconv.FormatInt(pagingToken(tx.Ledger, i+1), 10)
+		s.operations = append(s.operations, operations.Base{
+			ID:                    token,
+			PT:                    token,
+			TransactionSuccessful: true,
+			SourceAccount:         opSource,
+			Type:                  operations.TypeNames[op.Body.Type],
+			TypeI:                 int32(op.Body.Type),
+			LedgerCloseTime:       tx.LedgerCloseTime,
+			TransactionHash:       tx.Hash,
+		})
+	}
+	s.notify()
+
+	var success hProtocol.TransactionSuccess
+	success.Links.Transaction = tx.Links.Self
+	success.Hash = tx.Hash
+	success.Ledger = tx.Ledger
+	success.Env = tx.EnvelopeXdr
+	success.Result = tx.ResultXdr
+	writeResponse(w, http.StatusOK, success)
+}
+
+// collection serves a page of the records returned by list, or streams them when the client asks
+// for an SSE stream. list is called with s.mu held and must return the records in paging token
+// order.
+func (s *Server) collection(w http.ResponseWriter, r *http.Request, list func() []hal.Pageable) {
+	query := r.URL.Query()
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		s.stream(w, r, query.Get("cursor"), list)
+		return
+	}
+
+	fullURL := *r.URL
+	fullURL.Scheme = "http"
+	fullURL.Host = r.Host
+	page := hal.Page{
+		BasePage: hal.BasePage{FullURL: &fullURL},
+		Order:    query.Get("order"),
+		Cursor:   query.Get("cursor"),
+		Limit:    defaultLimit,
+	}
+	if page.Order == "" {
+		page.Order = "asc"
+	}
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		page.Limit, err = strconv.ParseUint(limit, 10, 64)
+		if err != nil || page.Limit == 0 || page.Limit > maxLimit {
+			writeResponse(w, http.StatusBadRequest, invalidField("limit"))
+			return
+		}
+	}
+	if page.Order != "asc" && page.Order != "desc" {
+		writeResponse(w, http.StatusBadRequest, invalidField("order"))
+		return
+	}
+
+	s.mu.Lock()
+	records := list()
+	s.mu.Unlock()
+
+	selected, ok := selectRecords(records, page.Cursor, page.Order == "asc")
+	if !ok {
+		writeResponse(w, http.StatusBadRequest, invalidField("cursor"))
+		return
+	}
+	for i, record := range selected {
+		if uint64(i) == page.Limit {
+			break
+		}
+		page.Add(record)
+	}
+	page.PopulateLinks()
+	writeResponse(w, http.StatusOK, page)
+}
+
+// stream sends the records following cursor as SSE events, then the records added later, until
+// the client goes away or no record is added for streamIdleTimeout.
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, cursor string, list func() []hal.Pageable) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeResponse(w, http.StatusInternalServerError, problem.ServerError)
+		return
+	}
+
+	s.mu.Lock()
+	records := list()
+	s.mu.Unlock()
+	if cursor == "now" {
+		cursor = ""
+		if len(records) > 0 {
+			cursor = records[len(records)-1].PagingToken()
+		}
+	}
+	if _, ok := selectRecords(records, cursor, true); !ok {
+		writeResponse(w, http.StatusBadRequest, invalidField("cursor"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 1000\nevent: open\ndata: \"hello\"\n\n")
+	flusher.Flush()
+
+	for {
+		s.mu.Lock()
+		records := list()
+		changed := s.changed
+		s.mu.Unlock()
+
+		selected, _ := selectRecords(records, cursor, true)
+		for _, record := range selected {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return
+			}
+			cursor = record.PagingToken()
+			fmt.Fprintf(w, "id: %s\ndata: %s\n\n", cursor, data)
+		}
+		flusher.Flush()
+
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		case <-time.After(streamIdleTimeout):
+			fmt.Fprint(w, "retry: 10\nevent: close\ndata: \"byebye\"\n\n")
+			flusher.Flush()
+			return
+		}
+	}
+}
+
+// transactionRecords returns the transactions matching filter. s.mu must be held.
+func (s *Server) transactionRecords(filter func(hProtocol.Transaction) bool) []hal.Pageable {
+	var records []hal.Pageable
+	for _, tx := range s.transactions {
+		if filter(tx) {
+			records = append(records, tx)
+		}
+	}
+	return records
+}
+
+// operationRecords returns the operations matching filter. s.mu must be held.
+func (s *Server) operationRecords(filter func(operations.Operation) bool) []hal.Pageable {
+	var records []hal.Pageable
+	for _, op := range s.operations {
+		if filter(op) {
+			records = append(records, op)
+		}
+	}
+	return records
+}
+
+// transactionSource returns the source account of a transaction. s.mu must be held.
+func (s *Server) transactionSource(hash string) string {
+	for _, tx := range s.transactions {
+		if tx.Hash == hash {
+			return tx.Account
+		}
+	}
+	return ""
+}
+
+// selectRecords returns the records after cursor in the given order. ok is false when cursor is
+// not a valid paging token.
+func selectRecords(records []hal.Pageable, cursor string, ascending bool) (selected []hal.Pageable, ok bool) {
+	var position int64
+	if cursor != "" {
+		var err error
+		position, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil || position < 0 {
+			return nil, false
+		}
+	}
+
+	for i := range records {
+		record := records[i]
+		if !ascending {
+			record = records[len(records)-1-i]
+		}
+		token, err := strconv.ParseInt(record.PagingToken(), 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		if cursor == "" || (ascending && token > position) || (!ascending && token < position) {
+			selected = append(selected, record)
+		}
+	}
+	return selected, true
+}
+
+func memoValue(memo xdr.Memo) string {
+	switch memo.Type {
+	case xdr.MemoTypeMemoText:
+		return memo.MustText()
+	case xdr.MemoTypeMemoId:
+		return strconv.FormatUint(uint64(memo.MustId()), 10)
+	case xdr.MemoTypeMemoHash:
+		hash := memo.MustHash()
+		return base64.StdEncoding.EncodeToString(hash[:])
+	case xdr.MemoTypeMemoReturn:
+		hash := memo.MustRetHash()
+		return base64.StdEncoding.EncodeToString(hash[:])
+	}
+	return ""
+}
+
+func invalidField(field string) problem.P {
+	return problem.P{
+		Type:   "https://stellar.org/horizon-errors/bad_request",
+		Title:  "Bad Request",
+		Status: http.StatusBadRequest,
+		Detail: "The request you sent was invalid in some way.",
+		Extras: map[string]interface{}{"invalid_field": field},
+	}
+}
+
+func writeResponse(w http.ResponseWriter, status int, body interface{}) {
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if text, ok := body.(string); ok {
+		w.WriteHeader(status)
+		fmt.Fprint(w, text)
+		return
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if _, isProblem := body.(problem.P); isProblem {
+		w.Header().Set("Content-Type", "application/problem+json; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/hal+json; charset=utf-8")
+	}
+	w.WriteHeader(status)
+	w.Write(data)
+}
@@ -0,0 +1,206 @@
+// Package horizontest provides an in-process horizon server for testing code that uses
+// horizonclient and txnbuild end to end, without a network.
+//
+// A Server serves the main horizon routes from in-memory fixtures: the root endpoint, accounts,
+// transactions, operations and order books. Collection endpoints support paging and SSE streaming,
+// and transactions posted to the server are checked against the sequence number of their source
+// account, recorded and streamed to clients like horizon would. Responses can also be scripted
+// with Server.Script, for instance to simulate a timeout or a failed submission.
+//
+//	server := horizontest.NewServer()
+//	defer server.Close()
+//
+//	server.AddAccount(hProtocol.Account{AccountID: address, Sequence: "100"})
+//	client := server.Client()
+package horizontest
+
+import (
+	"net/http"
+	stdtest "net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/network"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/operations"
+	"github.com/stellar/go/support/render/problem"
+)
+
+// Server is a fake horizon server. Its fixtures can be changed at any time, including while
+// clients are streaming from it.
+type Server struct {
+	*stdtest.Server
+
+	// NetworkPassphrase is used to hash submitted transactions. It defaults to the test network
+	// passphrase.
+	NetworkPassphrase string
+
+	mu           sync.Mutex
+	ledger       int32
+	accounts     map[string]hProtocol.Account
+	transactions []hProtocol.Transaction
+	operations   []operations.Operation
+	orderBooks   map[string]hProtocol.OrderBookSummary
+	scripts      map[string][]Response
+	// changed is closed and replaced whenever a record is added, waking up the streams
+	changed chan struct{}
+}
+
+// Response is a scripted response of the server.
+type Response struct {
+	Status int
+	// Body is sent as-is when it is a string and encoded to JSON otherwise.
+	Body interface{}
+}
+
+// NewServer starts a server with no fixtures. The server must be closed once the test is done.
+func NewServer() *Server {
+	s := &Server{
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		ledger:            1,
+		accounts:          map[string]hProtocol.Account{},
+		orderBooks:        map[string]hProtocol.OrderBookSummary{},
+		scripts:           map[string][]Response{},
+		changed:           make(chan struct{}),
+	}
+	s.Server = stdtest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a horizonclient.Client sending its requests to the server.
+func (s *Server) Client() *horizonclient.Client {
+	return &horizonclient.Client{
+		HorizonURL: s.URL + "/",
+		HTTP:       s.Server.Client(),
+	}
+}
+
+// Script makes the server answer the next requests with the given method and path (without query)
+// with responses, in order, before going back to its fixtures.
+func (s *Server) Script(method, path string, responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := method + " " + path
+	s.scripts[key] = append(s.scripts[key], responses...)
+}
+
+// AddAccount adds an account, replacing the account with the same ID if any.
+func (s *Server) AddAccount(account hProtocol.Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if account.ID == "" {
+		account.ID = account.AccountID
+	}
+	if account.PT == "" {
+		account.PT = account.AccountID
+	}
+	s.accounts[account.AccountID] = account
+}
+
+// Account returns the account with the given ID, reflecting the transactions submitted so far.
+func (s *Server) Account(id string) (hProtocol.Account, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	account, ok := s.accounts[id]
+	return account, ok
+}
+
+// AddTransaction adds a transaction in a new ledger. Its ID and paging token are set when they are
+// empty; paging tokens must be numbers.
+func (s *Server) AddTransaction(tx hProtocol.Transaction) hProtocol.Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addTransaction(tx)
+}
+
+// AddOperation adds an operation. It is listed for the source account of its transaction, so the
+// transaction should be added first.
+func (s *Server) AddOperation(op operations.Operation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.operations = append(s.operations, op)
+	s.notify()
+}
+
+// Transactions returns the transactions added or submitted so far.
+func (s *Server) Transactions() []hProtocol.Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]hProtocol.Transaction(nil), s.transactions...)
+}
+
+// Operations returns the operations added or submitted so far.
+func (s *Server) Operations() []operations.Operation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]operations.Operation(nil), s.operations...)
+}
+
+// SetOrderBook sets the order book between the Selling and Buying assets of summary.
+func (s *Server) SetOrderBook(summary hProtocol.OrderBookSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := assetKey(summary.Selling.Type, summary.Selling.Code, summary.Selling.Issuer) + "/" +
+		assetKey(summary.Buying.Type, summary.Buying.Code, summary.Buying.Issuer)
+	s.orderBooks[key] = summary
+}
+
+// TransactionFailed returns the response of horizon to a transaction that failed with the given
+// result codes, to be used with Script.
+func TransactionFailed(transactionCode string, operationCodes ...string) Response {
+	resultCodes := map[string]interface{}{"transaction": transactionCode}
+	if len(operationCodes) > 0 {
+		resultCodes["operations"] = operationCodes
+	}
+	return Response{
+		Status: http.StatusBadRequest,
+		Body: problem.P{
+			Type:   "https://stellar.org/horizon-errors/transaction_failed",
+			Title:  "Transaction Failed",
+			Status: http.StatusBadRequest,
+			Detail: "The transaction failed when submitted to the stellar network.",
+			Extras: map[string]interface{}{"result_codes": resultCodes},
+		},
+	}
+}
+
+// addTransaction closes a ledger containing tx. s.mu must be held.
+func (s *Server) addTransaction(tx hProtocol.Transaction) hProtocol.Transaction {
+	s.ledger++
+	if tx.Ledger == 0 {
+		tx.Ledger = s.ledger
+	}
+	if tx.ID == "" {
+		tx.ID = tx.Hash
+	}
+	if tx.PT == "" {
+		tx.PT = strconv.FormatInt(pagingToken(tx.Ledger, 0), 10)
+	}
+	if tx.LedgerCloseTime.IsZero() {
+		tx.LedgerCloseTime = time.Now().UTC().Truncate(time.Second)
+	}
+	s.transactions = append(s.transactions, tx)
+	s.notify()
+	return tx
+}
+
+// notify wakes up the streams. s.mu must be held.
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// pagingToken returns a paging token ordering records like horizon's: by ledger, then by
+// operation within the ledger. The transaction itself is operation 0.
+func pagingToken(ledger int32, operation int) int64 {
+	return int64(ledger)<<32 | int64(1)<<12 | int64(operation)
+}
+
+func assetKey(assetType, code, issuer string) string {
+	if assetType == "native" {
+		return assetType
+	}
+	return code + ":" + issuer
+}
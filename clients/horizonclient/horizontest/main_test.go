@@ -0,0 +1,184 @@
+package horizontest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/clients/horizonclient/horizontest"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/operations"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var kp = keypair.MustParse("SBPQUZ6G4FZNWFHKUWC5BEYWF6R52E3SEP7R3GWYSM2XTKGF5LNTWW4R").(*keypair.Full)
+
+const destination = "GAS4V4O2B7DW5T7IQRPEEVCRXMDZESKISR7DVIGKZQYYV3OSQ5SH5LVP"
+
+func TestServerSubmission(t *testing.T) {
+	server := horizontest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	server.AddAccount(hProtocol.Account{AccountID: kp.Address(), Sequence: "100"})
+	account, err := client.AccountDetail(horizonclient.AccountRequest{AccountID: kp.Address()})
+	require.NoError(t, err)
+
+	tx := txnbuild.Transaction{
+		SourceAccount: &account,
+		Operations: []txnbuild.Operation{
+			&txnbuild.Payment{Destination: destination, Amount: "10", Asset: txnbuild.NativeAsset{}},
+		},
+		Memo:       txnbuild.MemoText("horizontest"),
+		Timebounds: txnbuild.NewInfiniteTimeout(),
+		Network:    network.TestNetworkPassphrase,
+	}
+	txeBase64, err := tx.BuildSignEncode(kp)
+	require.NoError(t, err)
+	txHash, err := tx.HashHex()
+	require.NoError(t, err)
+
+	resp, err := client.SubmitTransactionXDR(txeBase64)
+	require.NoError(t, err)
+	assert.Equal(t, txHash, resp.Hash)
+
+	updated, ok := server.Account(kp.Address())
+	require.True(t, ok)
+	assert.Equal(t, "101", updated.Sequence)
+
+	detail, err := client.TransactionDetail(txHash)
+	require.NoError(t, err)
+	assert.Equal(t, kp.Address(), detail.Account)
+	assert.Equal(t, "horizontest", detail.Memo)
+	assert.Equal(t, int32(1), detail.OperationCount)
+
+	ops, err := client.Operations(horizonclient.OperationRequest{ForAccount: kp.Address()})
+	require.NoError(t, err)
+	require.Len(t, ops.Embedded.Records, 1)
+	payment, ok := ops.Embedded.Records[0].(operations.Payment)
+	require.True(t, ok)
+	assert.Equal(t, txHash, payment.TransactionHash)
+
+	// the sequence number has been consumed
+	_, err = client.SubmitTransactionXDR(txeBase64)
+	if assert.Error(t, err) {
+		herr, ok := err.(*horizonclient.Error)
+		require.True(t, ok)
+		codes, err := herr.ResultCodes()
+		require.NoError(t, err)
+		assert.Equal(t, "tx_bad_seq", codes.TransactionCode)
+	}
+}
+
+func TestServerPaging(t *testing.T) {
+	server := horizontest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	for _, hash := range []string{"a", "b", "c"} {
+		server.AddTransaction(hProtocol.Transaction{Hash: hash, Account: kp.Address(), Successful: true})
+	}
+
+	page, err := client.Transactions(horizonclient.TransactionRequest{ForAccount: kp.Address(), Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Embedded.Records, 2)
+	assert.Equal(t, "a", page.Embedded.Records[0].Hash)
+	assert.Equal(t, "b", page.Embedded.Records[1].Hash)
+
+	page, err = client.NextTransactionsPage(page)
+	require.NoError(t, err)
+	require.Len(t, page.Embedded.Records, 1)
+	assert.Equal(t, "c", page.Embedded.Records[0].Hash)
+
+	page, err = client.Transactions(horizonclient.TransactionRequest{Order: horizonclient.OrderDesc})
+	require.NoError(t, err)
+	require.Len(t, page.Embedded.Records, 3)
+	assert.Equal(t, "c", page.Embedded.Records[0].Hash)
+}
+
+func TestServerStream(t *testing.T) {
+	server := horizontest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	first := server.AddTransaction(hProtocol.Transaction{Hash: "a", Successful: true})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var hashes []string
+	done := make(chan error)
+	go func() {
+		done <- client.StreamTransactions(ctx, horizonclient.TransactionRequest{Cursor: first.PT},
+			func(tx hProtocol.Transaction) {
+				hashes = append(hashes, tx.Hash)
+				if tx.Hash == "b" {
+					server.AddTransaction(hProtocol.Transaction{Hash: "c", Successful: true})
+				}
+				if tx.Hash == "c" {
+					cancel()
+				}
+			})
+	}()
+	server.AddTransaction(hProtocol.Transaction{Hash: "b", Successful: true})
+
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"b", "c"}, hashes)
+}
+
+func TestServerScript(t *testing.T) {
+	server := horizontest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	server.AddAccount(hProtocol.Account{AccountID: kp.Address(), Sequence: "100"})
+	server.Script("GET", "/accounts/"+kp.Address(), horizontest.Response{Status: 503})
+	server.Script("POST", "/transactions", horizontest.TransactionFailed("tx_failed", "op_underfunded"))
+
+	_, err := client.AccountDetail(horizonclient.AccountRequest{AccountID: kp.Address()})
+	if assert.Error(t, err) {
+		herr, ok := err.(*horizonclient.Error)
+		require.True(t, ok)
+		assert.Equal(t, 503, herr.Response.StatusCode)
+	}
+
+	account, err := client.AccountDetail(horizonclient.AccountRequest{AccountID: kp.Address()})
+	require.NoError(t, err)
+	assert.Equal(t, "100", account.Sequence)
+
+	_, err = client.SubmitTransactionXDR("AAAA")
+	if assert.Error(t, err) {
+		herr, ok := err.(*horizonclient.Error)
+		require.True(t, ok)
+		codes, err := herr.ResultCodes()
+		require.NoError(t, err)
+		assert.Equal(t, "tx_failed", codes.TransactionCode)
+		assert.Equal(t, []string{"op_underfunded"}, codes.OperationCodes)
+	}
+}
+
+func TestServerOrderBook(t *testing.T) {
+	server := horizontest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	issuer := "GDZST3XVCDTUJ76ZAV2HA72KYQODXXZ5PTMAPZGDHZ6CS7RO7MGG3DBM"
+	server.SetOrderBook(hProtocol.OrderBookSummary{
+		Selling: hProtocol.Asset{Type: "native"},
+		Buying:  hProtocol.Asset{Type: "credit_alphanum4", Code: "USD", Issuer: issuer},
+		Bids:    []hProtocol.PriceLevel{{Price: "0.1", Amount: "100"}},
+		Asks:    []hProtocol.PriceLevel{},
+	})
+
+	summary, err := client.OrderBook(horizonclient.OrderBookRequest{
+		SellingAssetType:  horizonclient.AssetTypeNative,
+		BuyingAssetType:   horizonclient.AssetType4,
+		BuyingAssetCode:   "USD",
+		BuyingAssetIssuer: issuer,
+	})
+	require.NoError(t, err)
+	require.Len(t, summary.Bids, 1)
+	assert.Equal(t, "0.1", summary.Bids[0].Price)
+}
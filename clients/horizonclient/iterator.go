@@ -0,0 +1,474 @@
+package horizonclient
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/effects"
+	"github.com/stellar/go/protocols/horizon/operations"
+)
+
+// IterationBound makes an iterator stop before the first record that lies beyond a ledger or a
+// point in time. "Beyond" follows the order of the request: for ascending requests the iteration
+// stops at the first record from a later ledger or time, for descending requests at the first
+// record from an earlier one. Zero values disable the corresponding bound.
+//
+// Bounds are only checked against records that carry the relevant information: offers and assets
+// are not tied to a ledger or a time, so they are never stopped by a bound.
+type IterationBound struct {
+	Ledger uint32
+	Time   time.Time
+}
+
+// reached reports whether r lies beyond the bound when iterating in the given order.
+func (b IterationBound) reached(r pageRecord, order Order) bool {
+	desc := order == OrderDesc
+	if b.Ledger != 0 && r.ledger != 0 {
+		if (!desc && r.ledger > b.Ledger) || (desc && r.ledger < b.Ledger) {
+			return true
+		}
+	}
+	if !b.Time.IsZero() && !r.closedAt.IsZero() {
+		if (!desc && r.closedAt.After(b.Time)) || (desc && r.closedAt.Before(b.Time)) {
+			return true
+		}
+	}
+	return false
+}
+
+// pageRecord is a single record of a page together with the data needed to iterate over it.
+type pageRecord struct {
+	value    interface{}
+	token    string
+	ledger   uint32
+	closedAt time.Time
+}
+
+// pageIterator walks the records of a collection endpoint, following the `next` link of each page
+// until an empty page is returned or the bound is reached. The typed iterators embed it.
+type pageIterator struct {
+	ctx      context.Context
+	order    Order
+	bound    IterationBound
+	nextPage func(ctx context.Context) ([]pageRecord, error)
+
+	records []pageRecord
+	pos     int
+	current interface{}
+	token   string
+	done    bool
+	err     error
+}
+
+func newPageIterator(ctx context.Context, cursor string, order Order, bound IterationBound,
+	nextPage func(ctx context.Context) ([]pageRecord, error)) pageIterator {
+	return pageIterator{
+		ctx:      ctx,
+		order:    order,
+		bound:    bound,
+		nextPage: nextPage,
+		token:    cursor,
+	}
+}
+
+// Next advances the iterator to the next record, loading the next page from horizon when the
+// current one is exhausted. It returns false when there are no more records, when the iteration
+// bound is reached or when an error occurred, in which case Err returns it.
+func (it *pageIterator) Next() bool {
+	if it.done || it.err != nil {
+		return false
+	}
+
+	for it.pos >= len(it.records) {
+		records, err := it.nextPage(it.ctx)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if len(records) == 0 {
+			it.done = true
+			return false
+		}
+		it.records, it.pos = records, 0
+	}
+
+	record := it.records[it.pos]
+	if it.bound.reached(record, it.order) {
+		it.done = true
+		return false
+	}
+
+	it.pos++
+	it.current = record.value
+	it.token = record.token
+	return true
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *pageIterator) Err() error {
+	return it.err
+}
+
+// PagingToken returns the paging token of the record the iterator currently points at, or the
+// cursor of the request if Next has not returned any record yet. Using it as the `Cursor` of a new
+// request resumes the iteration after the current record.
+func (it *pageIterator) PagingToken() string {
+	return it.token
+}
+
+// ledgerFromPagingToken extracts the ledger sequence from paging tokens based on an operation ID
+// (transactions, operations, effects and trades), which hold the ledger sequence in their upper
+// 32 bits. It returns 0 if the token can't be parsed.
+func ledgerFromPagingToken(token string) uint32 {
+	id, err := strconv.ParseInt(strings.SplitN(token, "-", 2)[0], 10, 64)
+	if err != nil || id < 0 {
+		return 0
+	}
+	return uint32(id >> 32)
+}
+
+// ledgerCloseTimer is implemented by the operation and effect types.
+type ledgerCloseTimer interface {
+	GetLedgerCloseTime() time.Time
+}
+
+func closeTimeOf(record interface{}) time.Time {
+	if r, ok := record.(ledgerCloseTimer); ok {
+		return r.GetLedgerCloseTime()
+	}
+	return time.Time{}
+}
+
+// TransactionIterator iterates over the transactions matching a TransactionRequest.
+// Call Next to advance it and Transaction to read the current record. Err reports the error that
+// stopped the iteration, if any.
+type TransactionIterator struct {
+	pageIterator
+}
+
+// NewTransactionIterator returns an iterator over all the transactions matching request, starting
+// at request.Cursor. ctx is used for every page request made by the iterator.
+func NewTransactionIterator(ctx context.Context, client ClientInterface, request TransactionRequest,
+	bound IterationBound) *TransactionIterator {
+	var page hProtocol.TransactionsPage
+	loaded := false
+	nextPage := func(ctx context.Context) ([]pageRecord, error) {
+		var err error
+		if loaded {
+			page, err = client.NextTransactionsPageWithContext(ctx, page)
+		} else {
+			page, err = client.TransactionsWithContext(ctx, request)
+		}
+		if err != nil {
+			return nil, err
+		}
+		loaded = true
+
+		records := make([]pageRecord, len(page.Embedded.Records))
+		for i, tx := range page.Embedded.Records {
+			records[i] = pageRecord{
+				value:    tx,
+				token:    tx.PagingToken(),
+				ledger:   uint32(tx.Ledger),
+				closedAt: tx.LedgerCloseTime,
+			}
+		}
+		return records, nil
+	}
+
+	return &TransactionIterator{newPageIterator(ctx, request.Cursor, request.Order, bound, nextPage)}
+}
+
+// Transaction returns the transaction the iterator currently points at.
+func (it *TransactionIterator) Transaction() hProtocol.Transaction {
+	tx, _ := it.current.(hProtocol.Transaction)
+	return tx
+}
+
+// OperationIterator iterates over the operations or payments matching an OperationRequest.
+// Call Next to advance it and Operation to read the current record. Err reports the error that
+// stopped the iteration, if any.
+type OperationIterator struct {
+	pageIterator
+}
+
+// NewOperationIterator returns an iterator over all the operations matching request, starting at
+// request.Cursor. ctx is used for every page request made by the iterator.
+func NewOperationIterator(ctx context.Context, client ClientInterface, request OperationRequest,
+	bound IterationBound) *OperationIterator {
+	return newOperationIterator(ctx, client.OperationsWithContext, client.NextOperationsPageWithContext,
+		request, bound)
+}
+
+// NewPaymentIterator returns an iterator over all the payments matching request, starting at
+// request.Cursor. ctx is used for every page request made by the iterator.
+func NewPaymentIterator(ctx context.Context, client ClientInterface, request OperationRequest,
+	bound IterationBound) *OperationIterator {
+	return newOperationIterator(ctx, client.PaymentsWithContext, client.NextPaymentsPageWithContext,
+		request, bound)
+}
+
+func newOperationIterator(
+	ctx context.Context,
+	first func(context.Context, OperationRequest) (operations.OperationsPage, error),
+	next func(context.Context, operations.OperationsPage) (operations.OperationsPage, error),
+	request OperationRequest,
+	bound IterationBound,
+) *OperationIterator {
+	var page operations.OperationsPage
+	loaded := false
+	nextPage := func(ctx context.Context) ([]pageRecord, error) {
+		var err error
+		if loaded {
+			page, err = next(ctx, page)
+		} else {
+			page, err = first(ctx, request)
+		}
+		if err != nil {
+			return nil, err
+		}
+		loaded = true
+
+		records := make([]pageRecord, len(page.Embedded.Records))
+		for i, op := range page.Embedded.Records {
+			records[i] = pageRecord{
+				value:    op,
+				token:    op.PagingToken(),
+				ledger:   ledgerFromPagingToken(op.PagingToken()),
+				closedAt: closeTimeOf(op),
+			}
+		}
+		return records, nil
+	}
+
+	return &OperationIterator{newPageIterator(ctx, request.Cursor, request.Order, bound, nextPage)}
+}
+
+// Operation returns the operation the iterator currently points at.
+func (it *OperationIterator) Operation() operations.Operation {
+	op, _ := it.current.(operations.Operation)
+	return op
+}
+
+// EffectIterator iterates over the effects matching an EffectRequest.
+// Call Next to advance it and Effect to read the current record. Err reports the error that
+// stopped the iteration, if any.
+type EffectIterator struct {
+	pageIterator
+}
+
+// NewEffectIterator returns an iterator over all the effects matching request, starting at
+// request.Cursor. ctx is used for every page request made by the iterator.
+func NewEffectIterator(ctx context.Context, client ClientInterface, request EffectRequest,
+	bound IterationBound) *EffectIterator {
+	var page effects.EffectsPage
+	loaded := false
+	nextPage := func(ctx context.Context) ([]pageRecord, error) {
+		var err error
+		if loaded {
+			page, err = client.NextEffectsPageWithContext(ctx, page)
+		} else {
+			page, err = client.EffectsWithContext(ctx, request)
+		}
+		if err != nil {
+			return nil, err
+		}
+		loaded = true
+
+		records := make([]pageRecord, len(page.Embedded.Records))
+		for i, effect := range page.Embedded.Records {
+			records[i] = pageRecord{
+				value:    effect,
+				token:    effect.PagingToken(),
+				ledger:   ledgerFromPagingToken(effect.PagingToken()),
+				closedAt: closeTimeOf(effect),
+			}
+		}
+		return records, nil
+	}
+
+	return &EffectIterator{newPageIterator(ctx, request.Cursor, request.Order, bound, nextPage)}
+}
+
+// Effect returns the effect the iterator currently points at.
+func (it *EffectIterator) Effect() effects.Effect {
+	effect, _ := it.current.(effects.Effect)
+	return effect
+}
+
+// TradeIterator iterates over the trades matching a TradeRequest.
+// Call Next to advance it and Trade to read the current record. Err reports the error that
+// stopped the iteration, if any.
+type TradeIterator struct {
+	pageIterator
+}
+
+// NewTradeIterator returns an iterator over all the trades matching request, starting at
+// request.Cursor. ctx is used for every page request made by the iterator.
+func NewTradeIterator(ctx context.Context, client ClientInterface, request TradeRequest,
+	bound IterationBound) *TradeIterator {
+	var page hProtocol.TradesPage
+	loaded := false
+	nextPage := func(ctx context.Context) ([]pageRecord, error) {
+		var err error
+		if loaded {
+			page, err = client.NextTradesPageWithContext(ctx, page)
+		} else {
+			page, err = client.TradesWithContext(ctx, request)
+		}
+		if err != nil {
+			return nil, err
+		}
+		loaded = true
+
+		records := make([]pageRecord, len(page.Embedded.Records))
+		for i, trade := range page.Embedded.Records {
+			records[i] = pageRecord{
+				value:    trade,
+				token:    trade.PagingToken(),
+				ledger:   ledgerFromPagingToken(trade.PagingToken()),
+				closedAt: trade.LedgerCloseTime,
+			}
+		}
+		return records, nil
+	}
+
+	return &TradeIterator{newPageIterator(ctx, request.Cursor, request.Order, bound, nextPage)}
+}
+
+// Trade returns the trade the iterator currently points at.
+func (it *TradeIterator) Trade() hProtocol.Trade {
+	trade, _ := it.current.(hProtocol.Trade)
+	return trade
+}
+
+// OfferIterator iterates over the offers matching an OfferRequest.
+// Call Next to advance it and Offer to read the current record. Err reports the error that
+// stopped the iteration, if any.
+type OfferIterator struct {
+	pageIterator
+}
+
+// NewOfferIterator returns an iterator over all the offers matching request, starting at
+// request.Cursor. ctx is used for every page request made by the iterator. Offers are not
+// bound to a ledger, so no IterationBound applies to them.
+func NewOfferIterator(ctx context.Context, client ClientInterface, request OfferRequest) *OfferIterator {
+	var page hProtocol.OffersPage
+	loaded := false
+	nextPage := func(ctx context.Context) ([]pageRecord, error) {
+		var err error
+		if loaded {
+			page, err = client.NextOffersPageWithContext(ctx, page)
+		} else {
+			page, err = client.OffersWithContext(ctx, request)
+		}
+		if err != nil {
+			return nil, err
+		}
+		loaded = true
+
+		records := make([]pageRecord, len(page.Embedded.Records))
+		for i, offer := range page.Embedded.Records {
+			records[i] = pageRecord{value: offer, token: offer.PagingToken()}
+		}
+		return records, nil
+	}
+
+	return &OfferIterator{newPageIterator(ctx, request.Cursor, request.Order, IterationBound{}, nextPage)}
+}
+
+// Offer returns the offer the iterator currently points at.
+func (it *OfferIterator) Offer() hProtocol.Offer {
+	offer, _ := it.current.(hProtocol.Offer)
+	return offer
+}
+
+// LedgerIterator iterates over the ledgers matching a LedgerRequest.
+// Call Next to advance it and Ledger to read the current record. Err reports the error that
+// stopped the iteration, if any.
+type LedgerIterator struct {
+	pageIterator
+}
+
+// NewLedgerIterator returns an iterator over all the ledgers matching request, starting at
+// request.Cursor. ctx is used for every page request made by the iterator.
+func NewLedgerIterator(ctx context.Context, client ClientInterface, request LedgerRequest,
+	bound IterationBound) *LedgerIterator {
+	var page hProtocol.LedgersPage
+	loaded := false
+	nextPage := func(ctx context.Context) ([]pageRecord, error) {
+		var err error
+		if loaded {
+			page, err = client.NextLedgersPageWithContext(ctx, page)
+		} else {
+			page, err = client.LedgersWithContext(ctx, request)
+		}
+		if err != nil {
+			return nil, err
+		}
+		loaded = true
+
+		records := make([]pageRecord, len(page.Embedded.Records))
+		for i, ledger := range page.Embedded.Records {
+			records[i] = pageRecord{
+				value:    ledger,
+				token:    ledger.PagingToken(),
+				ledger:   uint32(ledger.Sequence),
+				closedAt: ledger.ClosedAt,
+			}
+		}
+		return records, nil
+	}
+
+	return &LedgerIterator{newPageIterator(ctx, request.Cursor, request.Order, bound, nextPage)}
+}
+
+// Ledger returns the ledger the iterator currently points at.
+func (it *LedgerIterator) Ledger() hProtocol.Ledger {
+	ledger, _ := it.current.(hProtocol.Ledger)
+	return ledger
+}
+
+// AssetIterator iterates over the assets matching an AssetRequest.
+// Call Next to advance it and Asset to read the current record. Err reports the error that
+// stopped the iteration, if any.
+type AssetIterator struct {
+	pageIterator
+}
+
+// NewAssetIterator returns an iterator over all the assets matching request, starting at
+// request.Cursor. ctx is used for every page request made by the iterator. Assets are not
+// bound to a ledger, so no IterationBound applies to them.
+func NewAssetIterator(ctx context.Context, client ClientInterface, request AssetRequest) *AssetIterator {
+	var page hProtocol.AssetsPage
+	loaded := false
+	nextPage := func(ctx context.Context) ([]pageRecord, error) {
+		var err error
+		if loaded {
+			page, err = client.NextAssetsPageWithContext(ctx, page)
+		} else {
+			page, err = client.AssetsWithContext(ctx, request)
+		}
+		if err != nil {
+			return nil, err
+		}
+		loaded = true
+
+		records := make([]pageRecord, len(page.Embedded.Records))
+		for i, asset := range page.Embedded.Records {
+			records[i] = pageRecord{value: asset, token: asset.PagingToken()}
+		}
+		return records, nil
+	}
+
+	return &AssetIterator{newPageIterator(ctx, request.Cursor, request.Order, IterationBound{}, nextPage)}
+}
+
+// Asset returns the asset the iterator currently points at.
+func (it *AssetIterator) Asset() hProtocol.AssetStat {
+	asset, _ := it.current.(hProtocol.AssetStat)
+	return asset
+}
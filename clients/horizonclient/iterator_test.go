@@ -0,0 +1,161 @@
+package horizonclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/support/http/httptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionIterator(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		HorizonURL: "https://localhost/",
+		HTTP:       hmock,
+	}
+
+	hmock.On(
+		"GET",
+		"https://localhost/transactions?limit=2",
+	).ReturnString(200, iteratorTxPage1)
+	hmock.On(
+		"GET",
+		"https://localhost/transactions?cursor=12884905984&limit=2&order=asc",
+	).ReturnString(200, iteratorTxPage2)
+	hmock.On(
+		"GET",
+		"https://localhost/transactions?cursor=17179873280&limit=2&order=asc",
+	).ReturnString(200, emptyTransactionsPage)
+
+	it := NewTransactionIterator(context.Background(), client, TransactionRequest{Limit: 2}, IterationBound{})
+	var tokens []string
+	for it.Next() {
+		tokens = append(tokens, it.Transaction().PagingToken())
+		assert.Equal(t, it.Transaction().PagingToken(), it.PagingToken())
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, []string{"8589938688", "12884905984", "17179873280"}, tokens)
+
+	// Next keeps returning false once the iteration is over
+	assert.False(t, it.Next())
+	assert.Equal(t, "17179873280", it.PagingToken())
+}
+
+func TestTransactionIteratorBounds(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		HorizonURL: "https://localhost/",
+		HTTP:       hmock,
+	}
+
+	hmock.On(
+		"GET",
+		"https://localhost/transactions?limit=2",
+	).ReturnString(200, iteratorTxPage1)
+	hmock.On(
+		"GET",
+		"https://localhost/transactions?cursor=12884905984&limit=2&order=asc",
+	).ReturnString(200, iteratorTxPage2)
+
+	// ledger bound
+	it := NewTransactionIterator(context.Background(), client, TransactionRequest{Limit: 2}, IterationBound{Ledger: 3})
+	count := 0
+	for it.Next() {
+		count++
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, 2, count)
+	assert.Equal(t, "12884905984", it.PagingToken())
+
+	// time bound
+	hmock.On(
+		"GET",
+		"https://localhost/transactions?limit=2",
+	).ReturnString(200, iteratorTxPage1)
+	bound := IterationBound{Time: time.Date(2019, 10, 1, 0, 0, 30, 0, time.UTC)}
+	it = NewTransactionIterator(context.Background(), client, TransactionRequest{Limit: 2}, bound)
+	count = 0
+	for it.Next() {
+		count++
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "8589938688", it.PagingToken())
+}
+
+func TestTransactionIteratorError(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		HorizonURL: "https://localhost/",
+		HTTP:       hmock,
+	}
+
+	hmock.On(
+		"GET",
+		"https://localhost/transactions?cursor=now",
+	).ReturnString(404, notFoundResponse)
+
+	it := NewTransactionIterator(context.Background(), client, TransactionRequest{Cursor: "now"}, IterationBound{})
+	assert.False(t, it.Next())
+	if assert.Error(t, it.Err()) {
+		_, ok := it.Err().(*Error)
+		assert.True(t, ok)
+	}
+	// the paging token is left at the cursor of the request
+	assert.Equal(t, "now", it.PagingToken())
+}
+
+func TestLedgerFromPagingToken(t *testing.T) {
+	assert.Equal(t, uint32(2), ledgerFromPagingToken("8589938688"))
+	assert.Equal(t, uint32(25016), ledgerFromPagingToken("107442901876737-1"))
+	assert.Equal(t, uint32(0), ledgerFromPagingToken("now"))
+	assert.Equal(t, uint32(0), ledgerFromPagingToken(""))
+}
+
+var iteratorTxPage1 = `{
+  "_links": {
+    "self": {"href": "https://localhost/transactions?cursor=&limit=2&order=asc"},
+    "next": {"href": "https://localhost/transactions?cursor=12884905984&limit=2&order=asc"},
+    "prev": {"href": "https://localhost/transactions?cursor=8589938688&limit=2&order=desc"}
+  },
+  "_embedded": {
+    "records": [
+      {
+        "id": "3389e9f0f1a65f19736cacf544c2e825313e8447f569233bb8db39aa607c8889",
+        "paging_token": "8589938688",
+        "hash": "3389e9f0f1a65f19736cacf544c2e825313e8447f569233bb8db39aa607c8889",
+        "ledger": 2,
+        "created_at": "2019-10-01T00:00:00Z"
+      },
+      {
+        "id": "2db4b22ca018119c5027a80578813ffcf582cda4aa9e31cd92b43cf1bda4fc5a",
+        "paging_token": "12884905984",
+        "hash": "2db4b22ca018119c5027a80578813ffcf582cda4aa9e31cd92b43cf1bda4fc5a",
+        "ledger": 3,
+        "created_at": "2019-10-01T00:01:00Z"
+      }
+    ]
+  }
+}`
+
+var iteratorTxPage2 = `{
+  "_links": {
+    "self": {"href": "https://localhost/transactions?cursor=12884905984&limit=2&order=asc"},
+    "next": {"href": "https://localhost/transactions?cursor=17179873280&limit=2&order=asc"},
+    "prev": {"href": "https://localhost/transactions?cursor=17179873280&limit=2&order=desc"}
+  },
+  "_embedded": {
+    "records": [
+      {
+        "id": "5c3a2e7d9a7e3e8e2b1f2b5cde1f8a3c5e7d9a7e3e8e2b1f2b5cde1f8a3c5e7d",
+        "paging_token": "17179873280",
+        "hash": "5c3a2e7d9a7e3e8e2b1f2b5cde1f8a3c5e7d9a7e3e8e2b1f2b5cde1f8a3c5e7d",
+        "ledger": 4,
+        "created_at": "2019-10-01T00:02:00Z"
+      }
+    ]
+  }
+}`
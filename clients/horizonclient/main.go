@@ -131,7 +131,22 @@ type Client struct {
 	AppName string
 
 	// AppVersion is the version of the application using the horizonclient package
-	AppVersion     string
+	AppVersion string
+
+	// RetryPolicy controls whether and how failed requests are retried. Requests are not retried
+	// when it is nil.
+	RetryPolicy *RetryPolicy
+
+	// CursorStore, when set, is used by the Stream* methods to save the paging token of every
+	// event once its handler has returned, and to resume from the saved token when a stream is
+	// started again. This gives at-least-once delivery of events across restarts. A saved cursor
+	// takes precedence over the cursor of the request.
+	CursorStore CursorStore
+
+	// Observer, when set, is notified of every request sent to horizon, e.g. to collect metrics
+	// with a MetricsObserver.
+	Observer Observer
+
 	horizonTimeOut time.Duration
 	isTestNet      bool
 
@@ -142,26 +157,55 @@ type Client struct {
 // ClientInterface contains methods implemented by the horizon client
 type ClientInterface interface {
 	AccountDetail(request AccountRequest) (hProtocol.Account, error)
+	AccountDetailWithContext(ctx context.Context, request AccountRequest) (hProtocol.Account, error)
 	AccountData(request AccountRequest) (hProtocol.AccountData, error)
+	AccountDataWithContext(ctx context.Context, request AccountRequest) (hProtocol.AccountData, error)
+	Accounts(request AccountsRequest) (hProtocol.AccountsPage, error)
+	AccountsWithContext(ctx context.Context, request AccountsRequest) (hProtocol.AccountsPage, error)
 	Effects(request EffectRequest) (effects.EffectsPage, error)
+	EffectsWithContext(ctx context.Context, request EffectRequest) (effects.EffectsPage, error)
 	Assets(request AssetRequest) (hProtocol.AssetsPage, error)
+	AssetsWithContext(ctx context.Context, request AssetRequest) (hProtocol.AssetsPage, error)
 	Ledgers(request LedgerRequest) (hProtocol.LedgersPage, error)
+	LedgersWithContext(ctx context.Context, request LedgerRequest) (hProtocol.LedgersPage, error)
 	LedgerDetail(sequence uint32) (hProtocol.Ledger, error)
+	LedgerDetailWithContext(ctx context.Context, sequence uint32) (hProtocol.Ledger, error)
 	Metrics() (hProtocol.Metrics, error)
+	MetricsWithContext(ctx context.Context) (hProtocol.Metrics, error)
 	FeeStats() (hProtocol.FeeStats, error)
+	FeeStatsWithContext(ctx context.Context) (hProtocol.FeeStats, error)
 	Offers(request OfferRequest) (hProtocol.OffersPage, error)
+	OffersWithContext(ctx context.Context, request OfferRequest) (hProtocol.OffersPage, error)
 	Operations(request OperationRequest) (operations.OperationsPage, error)
+	OperationsWithContext(ctx context.Context, request OperationRequest) (operations.OperationsPage, error)
 	OperationDetail(id string) (operations.Operation, error)
+	OperationDetailWithContext(ctx context.Context, id string) (operations.Operation, error)
 	SubmitTransactionXDR(transactionXdr string) (hProtocol.TransactionSuccess, error)
+	SubmitTransactionXDRWithContext(ctx context.Context, transactionXdr string) (hProtocol.TransactionSuccess, error)
 	SubmitTransaction(transactionXdr txnbuild.Transaction) (hProtocol.TransactionSuccess, error)
+	SubmitTransactionWithContext(ctx context.Context, transactionXdr txnbuild.Transaction) (hProtocol.TransactionSuccess, error)
+	SubmitTransactionWithOptions(transaction txnbuild.Transaction, opts SubmitTxOpts) (hProtocol.TransactionSuccess, error)
+	SubmitTransactionWithOptionsWithContext(ctx context.Context, transaction txnbuild.Transaction, opts SubmitTxOpts) (hProtocol.TransactionSuccess, error)
+	CheckMemoRequired(transaction txnbuild.Transaction) error
+	CheckMemoRequiredWithContext(ctx context.Context, transaction txnbuild.Transaction) error
 	Transactions(request TransactionRequest) (hProtocol.TransactionsPage, error)
+	TransactionsWithContext(ctx context.Context, request TransactionRequest) (hProtocol.TransactionsPage, error)
 	TransactionDetail(txHash string) (hProtocol.Transaction, error)
+	TransactionDetailWithContext(ctx context.Context, txHash string) (hProtocol.Transaction, error)
 	OrderBook(request OrderBookRequest) (hProtocol.OrderBookSummary, error)
+	OrderBookWithContext(ctx context.Context, request OrderBookRequest) (hProtocol.OrderBookSummary, error)
 	Paths(request PathsRequest) (hProtocol.PathsPage, error)
+	PathsWithContext(ctx context.Context, request PathsRequest) (hProtocol.PathsPage, error)
+	StrictSendPaths(request StrictSendPathsRequest) (hProtocol.PathsPage, error)
+	StrictSendPathsWithContext(ctx context.Context, request StrictSendPathsRequest) (hProtocol.PathsPage, error)
 	Payments(request OperationRequest) (operations.OperationsPage, error)
+	PaymentsWithContext(ctx context.Context, request OperationRequest) (operations.OperationsPage, error)
 	TradeAggregations(request TradeAggregationRequest) (hProtocol.TradeAggregationsPage, error)
+	TradeAggregationsWithContext(ctx context.Context, request TradeAggregationRequest) (hProtocol.TradeAggregationsPage, error)
 	Trades(request TradeRequest) (hProtocol.TradesPage, error)
+	TradesWithContext(ctx context.Context, request TradeRequest) (hProtocol.TradesPage, error)
 	Fund(addr string) (hProtocol.TransactionSuccess, error)
+	FundWithContext(ctx context.Context, addr string) (hProtocol.TransactionSuccess, error)
 	StreamTransactions(ctx context.Context, request TransactionRequest, handler TransactionHandler) error
 	StreamTrades(ctx context.Context, request TradeRequest, handler TradeHandler) error
 	StreamEffects(ctx context.Context, request EffectRequest, handler EffectHandler) error
@@ -171,25 +215,49 @@ type ClientInterface interface {
 	StreamLedgers(ctx context.Context, request LedgerRequest, handler LedgerHandler) error
 	StreamOrderBooks(ctx context.Context, request OrderBookRequest, handler OrderBookHandler) error
 	Root() (hProtocol.Root, error)
+	RootWithContext(ctx context.Context) (hProtocol.Root, error)
 	NextAssetsPage(hProtocol.AssetsPage) (hProtocol.AssetsPage, error)
+	NextAssetsPageWithContext(context.Context, hProtocol.AssetsPage) (hProtocol.AssetsPage, error)
 	PrevAssetsPage(hProtocol.AssetsPage) (hProtocol.AssetsPage, error)
+	PrevAssetsPageWithContext(context.Context, hProtocol.AssetsPage) (hProtocol.AssetsPage, error)
 	NextLedgersPage(hProtocol.LedgersPage) (hProtocol.LedgersPage, error)
+	NextLedgersPageWithContext(context.Context, hProtocol.LedgersPage) (hProtocol.LedgersPage, error)
 	PrevLedgersPage(hProtocol.LedgersPage) (hProtocol.LedgersPage, error)
+	PrevLedgersPageWithContext(context.Context, hProtocol.LedgersPage) (hProtocol.LedgersPage, error)
 	NextEffectsPage(effects.EffectsPage) (effects.EffectsPage, error)
+	NextEffectsPageWithContext(context.Context, effects.EffectsPage) (effects.EffectsPage, error)
 	PrevEffectsPage(effects.EffectsPage) (effects.EffectsPage, error)
+	PrevEffectsPageWithContext(context.Context, effects.EffectsPage) (effects.EffectsPage, error)
 	NextTransactionsPage(hProtocol.TransactionsPage) (hProtocol.TransactionsPage, error)
+	NextTransactionsPageWithContext(context.Context, hProtocol.TransactionsPage) (hProtocol.TransactionsPage, error)
 	PrevTransactionsPage(hProtocol.TransactionsPage) (hProtocol.TransactionsPage, error)
+	PrevTransactionsPageWithContext(context.Context, hProtocol.TransactionsPage) (hProtocol.TransactionsPage, error)
 	NextOperationsPage(operations.OperationsPage) (operations.OperationsPage, error)
+	NextOperationsPageWithContext(context.Context, operations.OperationsPage) (operations.OperationsPage, error)
 	PrevOperationsPage(operations.OperationsPage) (operations.OperationsPage, error)
+	PrevOperationsPageWithContext(context.Context, operations.OperationsPage) (operations.OperationsPage, error)
 	NextPaymentsPage(operations.OperationsPage) (operations.OperationsPage, error)
+	NextPaymentsPageWithContext(context.Context, operations.OperationsPage) (operations.OperationsPage, error)
 	PrevPaymentsPage(operations.OperationsPage) (operations.OperationsPage, error)
+	PrevPaymentsPageWithContext(context.Context, operations.OperationsPage) (operations.OperationsPage, error)
 	NextOffersPage(hProtocol.OffersPage) (hProtocol.OffersPage, error)
+	NextOffersPageWithContext(context.Context, hProtocol.OffersPage) (hProtocol.OffersPage, error)
 	PrevOffersPage(hProtocol.OffersPage) (hProtocol.OffersPage, error)
+	PrevOffersPageWithContext(context.Context, hProtocol.OffersPage) (hProtocol.OffersPage, error)
 	NextTradesPage(hProtocol.TradesPage) (hProtocol.TradesPage, error)
+	NextTradesPageWithContext(context.Context, hProtocol.TradesPage) (hProtocol.TradesPage, error)
 	PrevTradesPage(hProtocol.TradesPage) (hProtocol.TradesPage, error)
+	PrevTradesPageWithContext(context.Context, hProtocol.TradesPage) (hProtocol.TradesPage, error)
 	HomeDomainForAccount(aid string) (string, error)
+	HomeDomainForAccountWithContext(ctx context.Context, aid string) (string, error)
 	NextTradeAggregationsPage(hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error)
+	NextTradeAggregationsPageWithContext(context.Context, hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error)
 	PrevTradeAggregationsPage(hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error)
+	PrevTradeAggregationsPageWithContext(context.Context, hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error)
+	NextAccountsPage(hProtocol.AccountsPage) (hProtocol.AccountsPage, error)
+	NextAccountsPageWithContext(context.Context, hProtocol.AccountsPage) (hProtocol.AccountsPage, error)
+	PrevAccountsPage(hProtocol.AccountsPage) (hProtocol.AccountsPage, error)
+	PrevAccountsPageWithContext(context.Context, hProtocol.AccountsPage) (hProtocol.AccountsPage, error)
 }
 
 // DefaultTestNetClient is a default client to connect to test network.
@@ -220,6 +288,19 @@ type AccountRequest struct {
 	DataKey   string
 }
 
+// AccountsRequest struct contains data for getting the accounts matching a filter from a horizon server.
+// Exactly one of "Signer" and "Asset" must be set. "Signer" returns the accounts having the given
+// account as a signer, "Asset" the accounts having a trustline to the given asset, written as
+// "code:issuer".
+// The query parameters (Order, Cursor and Limit) are optional. All or none can be set.
+type AccountsRequest struct {
+	Signer string
+	Asset  string
+	Order  Order
+	Cursor string
+	Limit  uint
+}
+
 // EffectRequest struct contains data for getting effects from a horizon server.
 // "ForAccount", "ForLedger", "ForOperation" and "ForTransaction": Not more than one of these
 // can be set at a time. If none are set, the default is to return all effects.
@@ -320,8 +401,10 @@ type OrderBookRequest struct {
 	Limit              uint
 }
 
-// PathsRequest struct contains data for getting available payment paths from a horizon server.
-// All parameters are required.
+// PathsRequest struct contains data for getting available strict receive payment paths from a
+// horizon server. All the destination parameters are required. Exactly one of "SourceAccount" and
+// "SourceAssets" must be set; "SourceAssets" is a comma-separated list of assets, each written as
+// "native" or "code:issuer".
 type PathsRequest struct {
 	DestinationAccount     string
 	DestinationAssetType   AssetType
@@ -329,6 +412,20 @@ type PathsRequest struct {
 	DestinationAssetIssuer string
 	DestinationAmount      string
 	SourceAccount          string
+	SourceAssets           string
+}
+
+// StrictSendPathsRequest struct contains data for getting available strict send payment paths from
+// a horizon server. All the source parameters are required. Exactly one of "DestinationAccount"
+// and "DestinationAssets" must be set; "DestinationAssets" is a comma-separated list of assets,
+// each written as "native" or "code:issuer".
+type StrictSendPathsRequest struct {
+	DestinationAccount string
+	DestinationAssets  string
+	SourceAssetType    AssetType
+	SourceAssetCode    string
+	SourceAssetIssuer  string
+	SourceAmount       string
 }
 
 // TradeRequest struct contains data for getting trade details from a horizon server.
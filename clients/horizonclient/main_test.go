@@ -1,6 +1,7 @@
 package horizonclient
 
 import (
+	"context"
 	"net/http"
 	"testing"
 	"time"
@@ -108,6 +109,51 @@ func TestAccountDetail(t *testing.T) {
 	}
 }
 
+func TestAccountDetailWithContext(t *testing.T) {
+	server := httptest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") == "" {
+			w.Write([]byte(accountResponse))
+			return
+		}
+		// hang until the client gives up
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := &Client{
+		HorizonURL: server.URL,
+		HTTP:       http.DefaultClient,
+	}
+
+	// happy path
+	account, err := client.AccountDetailWithContext(
+		context.Background(),
+		AccountRequest{AccountID: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU"},
+	)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU", account.ID)
+	}
+
+	// cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.AccountDetailWithContext(
+		ctx,
+		AccountRequest{AccountID: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU"},
+	)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "context canceled")
+	}
+
+	// deadline exceeded
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = client.sendRequestURL(ctx, server.URL+"/?slow=1", "get", &account)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "deadline exceeded")
+	}
+}
+
 func TestAccountData(t *testing.T) {
 	hmock := httptest.NewClient()
 	client := &Client{
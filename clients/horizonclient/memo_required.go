@@ -0,0 +1,155 @@
+package horizonclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/txnbuild"
+)
+
+const (
+	// memoRequiredDataKey is the data entry of the accounts requiring a memo, as defined by SEP-29:
+	// https://github.com/stellar/stellar-protocol/blob/master/ecosystem/sep-0029.md
+	memoRequiredDataKey = "config.memo_required"
+	// accountRequiresMemo is the value of the data entry of the accounts requiring a memo, "1" in
+	// base64.
+	accountRequiresMemo = "MQ=="
+	// memoRequiredCacheTTL is how long whether an account requires a memo is cached.
+	memoRequiredCacheTTL = 10 * time.Minute
+	// memoRequiredCacheSize is the maximum number of accounts in the cache.
+	memoRequiredCacheSize = 10000
+)
+
+// AccountRequiresMemoError is returned when a transaction without a memo pays an account which
+// requires one, as defined by SEP-29.
+type AccountRequiresMemoError struct {
+	// AccountID is the account requiring a memo.
+	AccountID string
+	// OperationIndex is the index of the first operation of the transaction paying the account.
+	OperationIndex int
+}
+
+func (e *AccountRequiresMemoError) Error() string {
+	return fmt.Sprintf("destination account %s of operation %d requires a memo in the transaction", e.AccountID, e.OperationIndex)
+}
+
+// SubmitTxOpts are the options of SubmitTransactionWithOptions.
+type SubmitTxOpts struct {
+	// SkipMemoRequiredCheck disables the check of the destination accounts requiring a memo.
+	SkipMemoRequiredCheck bool
+}
+
+type memoRequiredKey struct {
+	horizonURL string
+	accountID  string
+}
+
+type memoRequiredRecord struct {
+	required  bool
+	expiresAt time.Time
+}
+
+// memoRequiredCache records, for every horizon instance, whether the accounts already looked up
+// require a memo. Its size is bounded by memoRequiredCacheSize, see cacheMemoRequired.
+var memoRequiredCache = make(map[memoRequiredKey]memoRequiredRecord)
+var memoRequiredCacheMutex = &sync.Mutex{}
+
+// CheckMemoRequired checks that transaction has a memo if any of the destination accounts of its
+// payment, path payment and account merge operations requires one, returning an
+// *AccountRequiresMemoError otherwise. The config.memo_required data entry of every destination is
+// loaded, and cached for 10 minutes.
+// See https://github.com/stellar/stellar-protocol/blob/master/ecosystem/sep-0029.md
+func (c *Client) CheckMemoRequired(transaction txnbuild.Transaction) error {
+	return c.CheckMemoRequiredWithContext(context.Background(), transaction)
+}
+
+// CheckMemoRequiredWithContext is the same as CheckMemoRequired, but the requests are cancelled
+// when ctx is done.
+func (c *Client) CheckMemoRequiredWithContext(ctx context.Context, transaction txnbuild.Transaction) error {
+	if transaction.Memo != nil {
+		return nil
+	}
+
+	checked := map[string]bool{}
+	for i, op := range transaction.Operations {
+		var destination string
+		switch op := op.(type) {
+		case *txnbuild.Payment:
+			destination = op.Destination
+		case *txnbuild.PathPaymentStrictReceive:
+			destination = op.Destination
+		case *txnbuild.PathPaymentStrictSend:
+			destination = op.Destination
+		case *txnbuild.AccountMerge:
+			destination = op.Destination
+		default:
+			continue
+		}
+		if checked[destination] {
+			continue
+		}
+		checked[destination] = true
+
+		required, err := c.accountRequiresMemo(ctx, destination)
+		if err != nil {
+			return errors.Wrapf(err, "checking whether account %s requires a memo", destination)
+		}
+		if required {
+			return &AccountRequiresMemoError{AccountID: destination, OperationIndex: i}
+		}
+	}
+	return nil
+}
+
+// accountRequiresMemo returns whether accountID has the data entry of the accounts requiring a
+// memo, from the cache when it was looked up recently.
+func (c *Client) accountRequiresMemo(ctx context.Context, accountID string) (bool, error) {
+	key := memoRequiredKey{horizonURL: c.HorizonURL, accountID: accountID}
+	now := c.clock.Now()
+
+	memoRequiredCacheMutex.Lock()
+	record, ok := memoRequiredCache[key]
+	memoRequiredCacheMutex.Unlock()
+	if ok && now.Before(record.expiresAt) {
+		return record.required, nil
+	}
+
+	data, err := c.AccountDataWithContext(ctx, AccountRequest{AccountID: accountID, DataKey: memoRequiredDataKey})
+	switch {
+	case isNotFound(err):
+		// either the account or its data entry does not exist
+		record = memoRequiredRecord{required: false}
+	case err != nil:
+		return false, err
+	default:
+		record = memoRequiredRecord{required: data.Value == accountRequiresMemo}
+	}
+	record.expiresAt = now.Add(memoRequiredCacheTTL)
+	cacheMemoRequired(key, record, now)
+	return record.required, nil
+}
+
+// cacheMemoRequired adds record to the cache. When the cache is full the expired records are
+// evicted, then arbitrary ones if none expired.
+func cacheMemoRequired(key memoRequiredKey, record memoRequiredRecord, now time.Time) {
+	memoRequiredCacheMutex.Lock()
+	defer memoRequiredCacheMutex.Unlock()
+
+	if _, ok := memoRequiredCache[key]; !ok && len(memoRequiredCache) >= memoRequiredCacheSize {
+		for k, r := range memoRequiredCache {
+			if !now.Before(r.expiresAt) {
+				delete(memoRequiredCache, k)
+			}
+		}
+		for k := range memoRequiredCache {
+			if len(memoRequiredCache) < memoRequiredCacheSize {
+				break
+			}
+			delete(memoRequiredCache, k)
+		}
+	}
+	memoRequiredCache[key] = record
+}
@@ -0,0 +1,144 @@
+package horizonclient
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/support/http/httptest"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitTransactionMemoRequired(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		HorizonURL: "https://memo.localhost/",
+		HTTP:       hmock,
+	}
+	kp := keypair.MustRandom()
+	other := keypair.MustRandom().Address()
+	exchange := keypair.MustRandom().Address()
+
+	hmock.On(
+		"GET",
+		"https://memo.localhost/accounts/"+other+"/data/config.memo_required",
+	).ReturnString(404, notFoundResponse)
+	hmock.On(
+		"GET",
+		"https://memo.localhost/accounts/"+exchange+"/data/config.memo_required",
+	).ReturnString(200, `{"value": "MQ=="}`)
+	hmock.On("POST", "https://memo.localhost/transactions").ReturnString(200, txSuccess)
+
+	newTx := func(memo txnbuild.Memo) txnbuild.Transaction {
+		source := txnbuild.NewSimpleAccount(kp.Address(), 1)
+		tx := txnbuild.Transaction{
+			SourceAccount: &source,
+			Operations: []txnbuild.Operation{
+				&txnbuild.BumpSequence{BumpTo: 10},
+				&txnbuild.Payment{Destination: other, Amount: "10", Asset: txnbuild.NativeAsset{}},
+				&txnbuild.Payment{Destination: exchange, Amount: "10", Asset: txnbuild.NativeAsset{}},
+				&txnbuild.AccountMerge{Destination: exchange},
+			},
+			Memo:       memo,
+			Timebounds: txnbuild.NewInfiniteTimeout(),
+			Network:    network.TestNetworkPassphrase,
+		}
+		_, err := tx.BuildSignEncode(kp)
+		require.NoError(t, err)
+		return tx
+	}
+
+	// the transaction is not submitted without a memo
+	_, err := client.SubmitTransaction(newTx(nil))
+	if assert.Error(t, err) {
+		assert.Equal(t, &AccountRequiresMemoError{AccountID: exchange, OperationIndex: 2}, err)
+		assert.Equal(t, "destination account "+exchange+" of operation 2 requires a memo in the transaction", err.Error())
+	}
+
+	// the data entries are cached
+	hmock.On(
+		"GET",
+		"https://memo.localhost/accounts/"+exchange+"/data/config.memo_required",
+	).ReturnString(404, notFoundResponse)
+	assert.Error(t, client.CheckMemoRequired(newTx(nil)))
+
+	resp, err := client.SubmitTransaction(newTx(txnbuild.MemoText("1234")))
+	if assert.NoError(t, err) {
+		assert.Equal(t, int32(354811), resp.Ledger)
+	}
+
+	// the check can be skipped
+	hmock.On("POST", "https://memo.localhost/transactions").ReturnString(200, txSuccess)
+	resp, err = client.SubmitTransactionWithOptions(newTx(nil), SubmitTxOpts{SkipMemoRequiredCheck: true})
+	if assert.NoError(t, err) {
+		assert.Equal(t, int32(354811), resp.Ledger)
+	}
+}
+
+func TestCheckMemoRequiredError(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		HorizonURL: "https://memo-error.localhost/",
+		HTTP:       hmock,
+	}
+	destination := keypair.MustRandom().Address()
+	hmock.On(
+		"GET",
+		"https://memo-error.localhost/accounts/"+destination+"/data/config.memo_required",
+	).ReturnError("http.Client error")
+
+	source := txnbuild.NewSimpleAccount(keypair.MustRandom().Address(), 1)
+	err := client.CheckMemoRequired(txnbuild.Transaction{
+		SourceAccount: &source,
+		Operations:    []txnbuild.Operation{&txnbuild.Payment{Destination: destination, Amount: "10", Asset: txnbuild.NativeAsset{}}},
+	})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "checking whether account "+destination+" requires a memo")
+		_, ok := err.(*AccountRequiresMemoError)
+		assert.False(t, ok)
+	}
+}
+
+func TestMemoRequiredCacheEviction(t *testing.T) {
+	memoRequiredCacheMutex.Lock()
+	saved := memoRequiredCache
+	memoRequiredCache = make(map[memoRequiredKey]memoRequiredRecord)
+	memoRequiredCacheMutex.Unlock()
+	defer func() {
+		memoRequiredCacheMutex.Lock()
+		memoRequiredCache = saved
+		memoRequiredCacheMutex.Unlock()
+	}()
+
+	now := time.Now()
+	for i := 0; i < memoRequiredCacheSize; i++ {
+		expiresAt := now.Add(time.Minute)
+		if i%2 == 0 {
+			expiresAt = now.Add(-time.Minute)
+		}
+		key := memoRequiredKey{horizonURL: "https://memo.localhost/", accountID: strconv.Itoa(i)}
+		cacheMemoRequired(key, memoRequiredRecord{expiresAt: expiresAt}, now)
+	}
+	assert.Len(t, memoRequiredCache, memoRequiredCacheSize)
+
+	// the expired records are evicted once the cache is full
+	key := memoRequiredKey{horizonURL: "https://memo.localhost/", accountID: "new"}
+	cacheMemoRequired(key, memoRequiredRecord{expiresAt: now.Add(time.Minute)}, now)
+	assert.Len(t, memoRequiredCache, memoRequiredCacheSize/2+1)
+	for _, record := range memoRequiredCache {
+		assert.True(t, now.Before(record.expiresAt))
+	}
+
+	// arbitrary records are evicted when none expired
+	for i := 0; len(memoRequiredCache) < memoRequiredCacheSize; i++ {
+		key := memoRequiredKey{horizonURL: "https://memo.localhost/", accountID: "other" + strconv.Itoa(i)}
+		cacheMemoRequired(key, memoRequiredRecord{expiresAt: now.Add(time.Minute)}, now)
+	}
+	cacheMemoRequired(memoRequiredKey{accountID: "last"}, memoRequiredRecord{expiresAt: now.Add(time.Minute)}, now)
+	assert.Len(t, memoRequiredCache, memoRequiredCacheSize)
+	assert.Contains(t, memoRequiredCache, memoRequiredKey{accountID: "last"})
+}
@@ -21,126 +21,300 @@ func (m *MockClient) AccountDetail(request AccountRequest) (hProtocol.Account, e
 	return a.Get(0).(hProtocol.Account), a.Error(1)
 }
 
+// AccountDetailWithContext is a mocking method
+func (m *MockClient) AccountDetailWithContext(ctx context.Context, request AccountRequest) (hProtocol.Account, error) {
+	a := m.Called(ctx, request)
+	return a.Get(0).(hProtocol.Account), a.Error(1)
+}
+
 // AccountData is a mocking method
 func (m *MockClient) AccountData(request AccountRequest) (hProtocol.AccountData, error) {
 	a := m.Called(request)
 	return a.Get(0).(hProtocol.AccountData), a.Error(1)
 }
 
+// AccountDataWithContext is a mocking method
+func (m *MockClient) AccountDataWithContext(ctx context.Context, request AccountRequest) (hProtocol.AccountData, error) {
+	a := m.Called(ctx, request)
+	return a.Get(0).(hProtocol.AccountData), a.Error(1)
+}
+
+// Accounts is a mocking method
+func (m *MockClient) Accounts(request AccountsRequest) (hProtocol.AccountsPage, error) {
+	a := m.Called(request)
+	return a.Get(0).(hProtocol.AccountsPage), a.Error(1)
+}
+
+// AccountsWithContext is a mocking method
+func (m *MockClient) AccountsWithContext(ctx context.Context, request AccountsRequest) (hProtocol.AccountsPage, error) {
+	a := m.Called(ctx, request)
+	return a.Get(0).(hProtocol.AccountsPage), a.Error(1)
+}
+
 // Effects is a mocking method
 func (m *MockClient) Effects(request EffectRequest) (effects.EffectsPage, error) {
 	a := m.Called(request)
 	return a.Get(0).(effects.EffectsPage), a.Error(1)
 }
 
+// EffectsWithContext is a mocking method
+func (m *MockClient) EffectsWithContext(ctx context.Context, request EffectRequest) (effects.EffectsPage, error) {
+	a := m.Called(ctx, request)
+	return a.Get(0).(effects.EffectsPage), a.Error(1)
+}
+
 // Assets is a mocking method
 func (m *MockClient) Assets(request AssetRequest) (hProtocol.AssetsPage, error) {
 	a := m.Called(request)
 	return a.Get(0).(hProtocol.AssetsPage), a.Error(1)
 }
 
+// AssetsWithContext is a mocking method
+func (m *MockClient) AssetsWithContext(ctx context.Context, request AssetRequest) (hProtocol.AssetsPage, error) {
+	a := m.Called(ctx, request)
+	return a.Get(0).(hProtocol.AssetsPage), a.Error(1)
+}
+
 // Ledgers is a mocking method
 func (m *MockClient) Ledgers(request LedgerRequest) (hProtocol.LedgersPage, error) {
 	a := m.Called(request)
 	return a.Get(0).(hProtocol.LedgersPage), a.Error(1)
 }
 
+// LedgersWithContext is a mocking method
+func (m *MockClient) LedgersWithContext(ctx context.Context, request LedgerRequest) (hProtocol.LedgersPage, error) {
+	a := m.Called(ctx, request)
+	return a.Get(0).(hProtocol.LedgersPage), a.Error(1)
+}
+
 // LedgerDetail is a mocking method
 func (m *MockClient) LedgerDetail(sequence uint32) (hProtocol.Ledger, error) {
 	a := m.Called(sequence)
 	return a.Get(0).(hProtocol.Ledger), a.Error(1)
 }
 
+// LedgerDetailWithContext is a mocking method
+func (m *MockClient) LedgerDetailWithContext(ctx context.Context, sequence uint32) (hProtocol.Ledger, error) {
+	a := m.Called(ctx, sequence)
+	return a.Get(0).(hProtocol.Ledger), a.Error(1)
+}
+
 // Metrics is a mocking method
 func (m *MockClient) Metrics() (hProtocol.Metrics, error) {
 	a := m.Called()
 	return a.Get(0).(hProtocol.Metrics), a.Error(1)
 }
 
+// MetricsWithContext is a mocking method
+func (m *MockClient) MetricsWithContext(ctx context.Context) (hProtocol.Metrics, error) {
+	a := m.Called(ctx)
+	return a.Get(0).(hProtocol.Metrics), a.Error(1)
+}
+
 // FeeStats is a mocking method
 func (m *MockClient) FeeStats() (hProtocol.FeeStats, error) {
 	a := m.Called()
 	return a.Get(0).(hProtocol.FeeStats), a.Error(1)
 }
 
+// FeeStatsWithContext is a mocking method
+func (m *MockClient) FeeStatsWithContext(ctx context.Context) (hProtocol.FeeStats, error) {
+	a := m.Called(ctx)
+	return a.Get(0).(hProtocol.FeeStats), a.Error(1)
+}
+
 // Offers is a mocking method
 func (m *MockClient) Offers(request OfferRequest) (hProtocol.OffersPage, error) {
 	a := m.Called(request)
 	return a.Get(0).(hProtocol.OffersPage), a.Error(1)
 }
 
+// OffersWithContext is a mocking method
+func (m *MockClient) OffersWithContext(ctx context.Context, request OfferRequest) (hProtocol.OffersPage, error) {
+	a := m.Called(ctx, request)
+	return a.Get(0).(hProtocol.OffersPage), a.Error(1)
+}
+
 // Operations is a mocking method
 func (m *MockClient) Operations(request OperationRequest) (operations.OperationsPage, error) {
 	a := m.Called(request)
 	return a.Get(0).(operations.OperationsPage), a.Error(1)
 }
 
+// OperationsWithContext is a mocking method
+func (m *MockClient) OperationsWithContext(ctx context.Context, request OperationRequest) (operations.OperationsPage, error) {
+	a := m.Called(ctx, request)
+	return a.Get(0).(operations.OperationsPage), a.Error(1)
+}
+
 // OperationDetail is a mocking method
 func (m *MockClient) OperationDetail(id string) (operations.Operation, error) {
 	a := m.Called(id)
 	return a.Get(0).(operations.Operation), a.Error(1)
 }
 
+// OperationDetailWithContext is a mocking method
+func (m *MockClient) OperationDetailWithContext(ctx context.Context, id string) (operations.Operation, error) {
+	a := m.Called(ctx, id)
+	return a.Get(0).(operations.Operation), a.Error(1)
+}
+
 // SubmitTransactionXDR is a mocking method
 func (m *MockClient) SubmitTransactionXDR(transactionXdr string) (hProtocol.TransactionSuccess, error) {
 	a := m.Called(transactionXdr)
 	return a.Get(0).(hProtocol.TransactionSuccess), a.Error(1)
 }
 
+// SubmitTransactionXDRWithContext is a mocking method
+func (m *MockClient) SubmitTransactionXDRWithContext(ctx context.Context, transactionXdr string) (hProtocol.TransactionSuccess, error) {
+	a := m.Called(ctx, transactionXdr)
+	return a.Get(0).(hProtocol.TransactionSuccess), a.Error(1)
+}
+
 // SubmitTransaction is a mocking method
 func (m *MockClient) SubmitTransaction(transaction txnbuild.Transaction) (hProtocol.TransactionSuccess, error) {
 	a := m.Called(transaction)
 	return a.Get(0).(hProtocol.TransactionSuccess), a.Error(1)
 }
 
+// SubmitTransactionWithContext is a mocking method
+func (m *MockClient) SubmitTransactionWithContext(ctx context.Context, transaction txnbuild.Transaction) (hProtocol.TransactionSuccess, error) {
+	a := m.Called(ctx, transaction)
+	return a.Get(0).(hProtocol.TransactionSuccess), a.Error(1)
+}
+
+// SubmitTransactionWithOptions is a mocking method
+func (m *MockClient) SubmitTransactionWithOptions(transaction txnbuild.Transaction, opts SubmitTxOpts) (hProtocol.TransactionSuccess, error) {
+	a := m.Called(transaction, opts)
+	return a.Get(0).(hProtocol.TransactionSuccess), a.Error(1)
+}
+
+// SubmitTransactionWithOptionsWithContext is a mocking method
+func (m *MockClient) SubmitTransactionWithOptionsWithContext(ctx context.Context, transaction txnbuild.Transaction, opts SubmitTxOpts) (hProtocol.TransactionSuccess, error) {
+	a := m.Called(ctx, transaction, opts)
+	return a.Get(0).(hProtocol.TransactionSuccess), a.Error(1)
+}
+
+// CheckMemoRequired is a mocking method
+func (m *MockClient) CheckMemoRequired(transaction txnbuild.Transaction) error {
+	a := m.Called(transaction)
+	return a.Error(0)
+}
+
+// CheckMemoRequiredWithContext is a mocking method
+func (m *MockClient) CheckMemoRequiredWithContext(ctx context.Context, transaction txnbuild.Transaction) error {
+	a := m.Called(ctx, transaction)
+	return a.Error(0)
+}
+
 // Transactions is a mocking method
 func (m *MockClient) Transactions(request TransactionRequest) (hProtocol.TransactionsPage, error) {
 	a := m.Called(request)
 	return a.Get(0).(hProtocol.TransactionsPage), a.Error(1)
 }
 
+// TransactionsWithContext is a mocking method
+func (m *MockClient) TransactionsWithContext(ctx context.Context, request TransactionRequest) (hProtocol.TransactionsPage, error) {
+	a := m.Called(ctx, request)
+	return a.Get(0).(hProtocol.TransactionsPage), a.Error(1)
+}
+
 // TransactionDetail is a mocking method
 func (m *MockClient) TransactionDetail(txHash string) (hProtocol.Transaction, error) {
 	a := m.Called(txHash)
 	return a.Get(0).(hProtocol.Transaction), a.Error(1)
 }
 
+// TransactionDetailWithContext is a mocking method
+func (m *MockClient) TransactionDetailWithContext(ctx context.Context, txHash string) (hProtocol.Transaction, error) {
+	a := m.Called(ctx, txHash)
+	return a.Get(0).(hProtocol.Transaction), a.Error(1)
+}
+
 // OrderBook is a mocking method
 func (m *MockClient) OrderBook(request OrderBookRequest) (hProtocol.OrderBookSummary, error) {
 	a := m.Called(request)
 	return a.Get(0).(hProtocol.OrderBookSummary), a.Error(1)
 }
 
+// OrderBookWithContext is a mocking method
+func (m *MockClient) OrderBookWithContext(ctx context.Context, request OrderBookRequest) (hProtocol.OrderBookSummary, error) {
+	a := m.Called(ctx, request)
+	return a.Get(0).(hProtocol.OrderBookSummary), a.Error(1)
+}
+
 // Paths is a mocking method
 func (m *MockClient) Paths(request PathsRequest) (hProtocol.PathsPage, error) {
 	a := m.Called(request)
 	return a.Get(0).(hProtocol.PathsPage), a.Error(1)
 }
 
+// PathsWithContext is a mocking method
+func (m *MockClient) PathsWithContext(ctx context.Context, request PathsRequest) (hProtocol.PathsPage, error) {
+	a := m.Called(ctx, request)
+	return a.Get(0).(hProtocol.PathsPage), a.Error(1)
+}
+
+// StrictSendPaths is a mocking method
+func (m *MockClient) StrictSendPaths(request StrictSendPathsRequest) (hProtocol.PathsPage, error) {
+	a := m.Called(request)
+	return a.Get(0).(hProtocol.PathsPage), a.Error(1)
+}
+
+// StrictSendPathsWithContext is a mocking method
+func (m *MockClient) StrictSendPathsWithContext(ctx context.Context, request StrictSendPathsRequest) (hProtocol.PathsPage, error) {
+	a := m.Called(ctx, request)
+	return a.Get(0).(hProtocol.PathsPage), a.Error(1)
+}
+
 // Payments is a mocking method
 func (m *MockClient) Payments(request OperationRequest) (operations.OperationsPage, error) {
 	a := m.Called(request)
 	return a.Get(0).(operations.OperationsPage), a.Error(1)
 }
 
+// PaymentsWithContext is a mocking method
+func (m *MockClient) PaymentsWithContext(ctx context.Context, request OperationRequest) (operations.OperationsPage, error) {
+	a := m.Called(ctx, request)
+	return a.Get(0).(operations.OperationsPage), a.Error(1)
+}
+
 // TradeAggregations is a mocking method
 func (m *MockClient) TradeAggregations(request TradeAggregationRequest) (hProtocol.TradeAggregationsPage, error) {
 	a := m.Called(request)
 	return a.Get(0).(hProtocol.TradeAggregationsPage), a.Error(1)
 }
 
+// TradeAggregationsWithContext is a mocking method
+func (m *MockClient) TradeAggregationsWithContext(ctx context.Context, request TradeAggregationRequest) (hProtocol.TradeAggregationsPage, error) {
+	a := m.Called(ctx, request)
+	return a.Get(0).(hProtocol.TradeAggregationsPage), a.Error(1)
+}
+
 // Trades is a mocking method
 func (m *MockClient) Trades(request TradeRequest) (hProtocol.TradesPage, error) {
 	a := m.Called(request)
 	return a.Get(0).(hProtocol.TradesPage), a.Error(1)
 }
 
+// TradesWithContext is a mocking method
+func (m *MockClient) TradesWithContext(ctx context.Context, request TradeRequest) (hProtocol.TradesPage, error) {
+	a := m.Called(ctx, request)
+	return a.Get(0).(hProtocol.TradesPage), a.Error(1)
+}
+
 // Fund is a mocking method
 func (m *MockClient) Fund(addr string) (hProtocol.TransactionSuccess, error) {
 	a := m.Called(addr)
 	return a.Get(0).(hProtocol.TransactionSuccess), a.Error(1)
 }
 
+// FundWithContext is a mocking method
+func (m *MockClient) FundWithContext(ctx context.Context, addr string) (hProtocol.TransactionSuccess, error) {
+	a := m.Called(ctx, addr)
+	return a.Get(0).(hProtocol.TransactionSuccess), a.Error(1)
+}
+
 // StreamTransactions is a mocking method
 func (m *MockClient) StreamTransactions(ctx context.Context, request TransactionRequest, handler TransactionHandler) error {
 	return m.Called(ctx, request, handler).Error(0)
@@ -187,117 +361,259 @@ func (m *MockClient) Root() (hProtocol.Root, error) {
 	return a.Get(0).(hProtocol.Root), a.Error(1)
 }
 
+// RootWithContext is a mocking method
+func (m *MockClient) RootWithContext(ctx context.Context) (hProtocol.Root, error) {
+	a := m.Called(ctx)
+	return a.Get(0).(hProtocol.Root), a.Error(1)
+}
+
 // NextAssetsPage is a mocking method
 func (m *MockClient) NextAssetsPage(page hProtocol.AssetsPage) (hProtocol.AssetsPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(hProtocol.AssetsPage), a.Error(1)
 }
 
+// NextAssetsPageWithContext is a mocking method
+func (m *MockClient) NextAssetsPageWithContext(ctx context.Context, page hProtocol.AssetsPage) (hProtocol.AssetsPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(hProtocol.AssetsPage), a.Error(1)
+}
+
 // PrevAssetsPage is a mocking method
 func (m *MockClient) PrevAssetsPage(page hProtocol.AssetsPage) (hProtocol.AssetsPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(hProtocol.AssetsPage), a.Error(1)
 }
 
+// PrevAssetsPageWithContext is a mocking method
+func (m *MockClient) PrevAssetsPageWithContext(ctx context.Context, page hProtocol.AssetsPage) (hProtocol.AssetsPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(hProtocol.AssetsPage), a.Error(1)
+}
+
 // NextLedgersPage is a mocking method
 func (m *MockClient) NextLedgersPage(page hProtocol.LedgersPage) (hProtocol.LedgersPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(hProtocol.LedgersPage), a.Error(1)
 }
 
+// NextLedgersPageWithContext is a mocking method
+func (m *MockClient) NextLedgersPageWithContext(ctx context.Context, page hProtocol.LedgersPage) (hProtocol.LedgersPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(hProtocol.LedgersPage), a.Error(1)
+}
+
 // PrevLedgersPage is a mocking method
 func (m *MockClient) PrevLedgersPage(page hProtocol.LedgersPage) (hProtocol.LedgersPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(hProtocol.LedgersPage), a.Error(1)
 }
 
+// PrevLedgersPageWithContext is a mocking method
+func (m *MockClient) PrevLedgersPageWithContext(ctx context.Context, page hProtocol.LedgersPage) (hProtocol.LedgersPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(hProtocol.LedgersPage), a.Error(1)
+}
+
 // NextEffectsPage is a mocking method
 func (m *MockClient) NextEffectsPage(page effects.EffectsPage) (effects.EffectsPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(effects.EffectsPage), a.Error(1)
 }
 
+// NextEffectsPageWithContext is a mocking method
+func (m *MockClient) NextEffectsPageWithContext(ctx context.Context, page effects.EffectsPage) (effects.EffectsPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(effects.EffectsPage), a.Error(1)
+}
+
 // PrevEffectsPage is a mocking method
 func (m *MockClient) PrevEffectsPage(page effects.EffectsPage) (effects.EffectsPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(effects.EffectsPage), a.Error(1)
 }
 
+// PrevEffectsPageWithContext is a mocking method
+func (m *MockClient) PrevEffectsPageWithContext(ctx context.Context, page effects.EffectsPage) (effects.EffectsPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(effects.EffectsPage), a.Error(1)
+}
+
 // NextTransactionsPage is a mocking method
 func (m *MockClient) NextTransactionsPage(page hProtocol.TransactionsPage) (hProtocol.TransactionsPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(hProtocol.TransactionsPage), a.Error(1)
 }
 
+// NextTransactionsPageWithContext is a mocking method
+func (m *MockClient) NextTransactionsPageWithContext(ctx context.Context, page hProtocol.TransactionsPage) (hProtocol.TransactionsPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(hProtocol.TransactionsPage), a.Error(1)
+}
+
 // PrevTransactionsPage is a mocking method
 func (m *MockClient) PrevTransactionsPage(page hProtocol.TransactionsPage) (hProtocol.TransactionsPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(hProtocol.TransactionsPage), a.Error(1)
 }
 
+// PrevTransactionsPageWithContext is a mocking method
+func (m *MockClient) PrevTransactionsPageWithContext(ctx context.Context, page hProtocol.TransactionsPage) (hProtocol.TransactionsPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(hProtocol.TransactionsPage), a.Error(1)
+}
+
 // NextOperationsPage is a mocking method
 func (m *MockClient) NextOperationsPage(page operations.OperationsPage) (operations.OperationsPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(operations.OperationsPage), a.Error(1)
 }
 
+// NextOperationsPageWithContext is a mocking method
+func (m *MockClient) NextOperationsPageWithContext(ctx context.Context, page operations.OperationsPage) (operations.OperationsPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(operations.OperationsPage), a.Error(1)
+}
+
 // PrevOperationsPage is a mocking method
 func (m *MockClient) PrevOperationsPage(page operations.OperationsPage) (operations.OperationsPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(operations.OperationsPage), a.Error(1)
 }
 
+// PrevOperationsPageWithContext is a mocking method
+func (m *MockClient) PrevOperationsPageWithContext(ctx context.Context, page operations.OperationsPage) (operations.OperationsPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(operations.OperationsPage), a.Error(1)
+}
+
 // NextPaymentsPage is a mocking method
 func (m *MockClient) NextPaymentsPage(page operations.OperationsPage) (operations.OperationsPage, error) {
 	return m.NextOperationsPage(page)
 }
 
+// NextPaymentsPageWithContext is a mocking method
+func (m *MockClient) NextPaymentsPageWithContext(ctx context.Context, page operations.OperationsPage) (operations.OperationsPage, error) {
+	return m.NextOperationsPage(page)
+}
+
 // PrevPaymentsPage is a mocking method
 func (m *MockClient) PrevPaymentsPage(page operations.OperationsPage) (operations.OperationsPage, error) {
 	return m.PrevOperationsPage(page)
 }
 
+// PrevPaymentsPageWithContext is a mocking method
+func (m *MockClient) PrevPaymentsPageWithContext(ctx context.Context, page operations.OperationsPage) (operations.OperationsPage, error) {
+	return m.PrevOperationsPage(page)
+}
+
 // NextOffersPage is a mocking method
 func (m *MockClient) NextOffersPage(page hProtocol.OffersPage) (hProtocol.OffersPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(hProtocol.OffersPage), a.Error(1)
 }
 
+// NextOffersPageWithContext is a mocking method
+func (m *MockClient) NextOffersPageWithContext(ctx context.Context, page hProtocol.OffersPage) (hProtocol.OffersPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(hProtocol.OffersPage), a.Error(1)
+}
+
 // PrevOffersPage is a mocking method
 func (m *MockClient) PrevOffersPage(page hProtocol.OffersPage) (hProtocol.OffersPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(hProtocol.OffersPage), a.Error(1)
 }
 
+// PrevOffersPageWithContext is a mocking method
+func (m *MockClient) PrevOffersPageWithContext(ctx context.Context, page hProtocol.OffersPage) (hProtocol.OffersPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(hProtocol.OffersPage), a.Error(1)
+}
+
 // NextTradesPage is a mocking method
 func (m *MockClient) NextTradesPage(page hProtocol.TradesPage) (hProtocol.TradesPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(hProtocol.TradesPage), a.Error(1)
 }
 
+// NextTradesPageWithContext is a mocking method
+func (m *MockClient) NextTradesPageWithContext(ctx context.Context, page hProtocol.TradesPage) (hProtocol.TradesPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(hProtocol.TradesPage), a.Error(1)
+}
+
 // PrevTradesPage is a mocking method
 func (m *MockClient) PrevTradesPage(page hProtocol.TradesPage) (hProtocol.TradesPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(hProtocol.TradesPage), a.Error(1)
 }
 
+// PrevTradesPageWithContext is a mocking method
+func (m *MockClient) PrevTradesPageWithContext(ctx context.Context, page hProtocol.TradesPage) (hProtocol.TradesPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(hProtocol.TradesPage), a.Error(1)
+}
+
 // HomeDomainForAccount is a mocking method
 func (m *MockClient) HomeDomainForAccount(aid string) (string, error) {
 	a := m.Called(aid)
 	return a.Get(0).(string), a.Error(1)
 }
 
+// HomeDomainForAccountWithContext is a mocking method
+func (m *MockClient) HomeDomainForAccountWithContext(ctx context.Context, aid string) (string, error) {
+	a := m.Called(ctx, aid)
+	return a.Get(0).(string), a.Error(1)
+}
+
 // NextTradeAggregationsPage is a mocking method
 func (m *MockClient) NextTradeAggregationsPage(page hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(hProtocol.TradeAggregationsPage), a.Error(1)
 }
 
+// NextTradeAggregationsPageWithContext is a mocking method
+func (m *MockClient) NextTradeAggregationsPageWithContext(ctx context.Context, page hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(hProtocol.TradeAggregationsPage), a.Error(1)
+}
+
 // PrevTradeAggregationsPage is a mocking method
 func (m *MockClient) PrevTradeAggregationsPage(page hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error) {
 	a := m.Called(page)
 	return a.Get(0).(hProtocol.TradeAggregationsPage), a.Error(1)
 }
 
+// PrevTradeAggregationsPageWithContext is a mocking method
+func (m *MockClient) PrevTradeAggregationsPageWithContext(ctx context.Context, page hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(hProtocol.TradeAggregationsPage), a.Error(1)
+}
+
+// NextAccountsPage is a mocking method
+func (m *MockClient) NextAccountsPage(page hProtocol.AccountsPage) (hProtocol.AccountsPage, error) {
+	a := m.Called(page)
+	return a.Get(0).(hProtocol.AccountsPage), a.Error(1)
+}
+
+// NextAccountsPageWithContext is a mocking method
+func (m *MockClient) NextAccountsPageWithContext(ctx context.Context, page hProtocol.AccountsPage) (hProtocol.AccountsPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(hProtocol.AccountsPage), a.Error(1)
+}
+
+// PrevAccountsPage is a mocking method
+func (m *MockClient) PrevAccountsPage(page hProtocol.AccountsPage) (hProtocol.AccountsPage, error) {
+	a := m.Called(page)
+	return a.Get(0).(hProtocol.AccountsPage), a.Error(1)
+}
+
+// PrevAccountsPageWithContext is a mocking method
+func (m *MockClient) PrevAccountsPageWithContext(ctx context.Context, page hProtocol.AccountsPage) (hProtocol.AccountsPage, error) {
+	a := m.Called(ctx, page)
+	return a.Get(0).(hProtocol.AccountsPage), a.Error(1)
+}
+
 // ensure that the MockClient implements ClientInterface
 var _ ClientInterface = &MockClient{}
@@ -0,0 +1,170 @@
+package horizonclient
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	metrics "github.com/rcrowley/go-metrics"
+)
+
+// Observer is notified of the requests a Client sends to horizon, e.g. to collect metrics or to
+// log them. Its methods are called synchronously by the goroutine sending the request, so they
+// must not block, and concurrently when the Client is shared.
+type Observer interface {
+	// RequestStarted is called before every attempt of a request, including retries and
+	// reconnections of streams.
+	RequestStarted(info RequestInfo)
+	// RequestFinished is called once the response to an attempt has been received and decoded, or
+	// once the attempt failed. For streams it is called when the stream is connected, or failed to.
+	RequestFinished(info RequestInfo)
+	// StreamReconnected is called before a stream connects again to horizon after its connection
+	// was closed.
+	StreamReconnected(info RequestInfo)
+}
+
+// RequestInfo describes a request sent to horizon.
+type RequestInfo struct {
+	// Endpoint is the name of the horizon endpoint, made of the static segments of the URL path,
+	// e.g. "transactions" for both /transactions and /transactions/{hash}, "accounts/payments" or
+	// "paths/strict-send". The root endpoint is named "root".
+	Endpoint string
+	Method   string
+	URL      string
+	// Attempt is 0 for the first attempt of a request and is incremented with every retry.
+	Attempt int
+	// Stream is true for the connections of the Stream* methods.
+	Stream bool
+
+	// The fields below are only set for RequestFinished.
+
+	// StatusCode is the HTTP status code of the response, 0 when none was received.
+	StatusCode int
+	// Duration is the time elapsed since the attempt started.
+	Duration time.Duration
+	// Err is the error the attempt failed with, if any.
+	Err error
+
+	started time.Time
+}
+
+// Succeeded reports whether the request finished without error and with a 2xx or 3xx status code.
+func (info RequestInfo) Succeeded() bool {
+	return info.Err == nil && info.StatusCode > 0 && info.StatusCode < http.StatusBadRequest
+}
+
+// endpointSegments are the URL path segments which are part of the name of an endpoint even
+// though they follow a collection name.
+var endpointSegments = map[string]bool{
+	"strict-send":    true,
+	"strict-receive": true,
+}
+
+// endpointName returns the name of the endpoint requested with requestURL. Segments following a
+// collection name hold IDs, like in /accounts/{account_id}/payments, and are left out.
+func (c *Client) endpointName(requestURL string) string {
+	path := requestURL
+	if strings.HasPrefix(requestURL, c.fixHorizonURL()) {
+		path = strings.TrimPrefix(requestURL, c.fixHorizonURL())
+	} else if u, err := url.Parse(requestURL); err == nil {
+		path = u.Path
+	}
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+
+	var segments []string
+	for i, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if i%2 == 0 || endpointSegments[segment] {
+			segments = append(segments, segment)
+		}
+	}
+	name := strings.Join(segments, "/")
+	if name == "" {
+		return "root"
+	}
+	return name
+}
+
+// requestStarted notifies the client's observer, if any, that an attempt of a request starts.
+func (c *Client) requestStarted(requestURL, method string, attempt int, stream bool) RequestInfo {
+	if c.Observer == nil {
+		return RequestInfo{}
+	}
+	info := RequestInfo{
+		Endpoint: c.endpointName(requestURL),
+		Method:   strings.ToUpper(method),
+		URL:      requestURL,
+		Attempt:  attempt,
+		Stream:   stream,
+		started:  time.Now(),
+	}
+	c.Observer.RequestStarted(info)
+	return info
+}
+
+// requestFinished notifies the client's observer, if any, that the attempt described by info is
+// done.
+func (c *Client) requestFinished(info RequestInfo, resp *http.Response, err error) {
+	if c.Observer == nil {
+		return
+	}
+	if resp != nil {
+		info.StatusCode = resp.StatusCode
+	}
+	info.Duration = time.Since(info.started)
+	info.Err = err
+	c.Observer.RequestFinished(info)
+}
+
+// MetricsObserver is an Observer recording requests in a go-metrics registry, the metrics library
+// used by horizon. For every endpoint it registers:
+//
+//   - requests.{endpoint}.total: a timer of the duration of every attempt
+//   - requests.{endpoint}.succeeded and requests.{endpoint}.failed: meters of the attempts
+//     which succeeded or failed
+//   - requests.{endpoint}.retried: a meter of the retried attempts
+//   - streams.{endpoint}.reconnected: a meter of the stream reconnections
+//
+// where {endpoint} is the endpoint name with slashes replaced by dots, e.g. "accounts.payments".
+type MetricsObserver struct {
+	Registry metrics.Registry
+}
+
+// NewMetricsObserver returns a MetricsObserver registering its metrics in registry, or in
+// metrics.DefaultRegistry when it is nil.
+func NewMetricsObserver(registry metrics.Registry) *MetricsObserver {
+	if registry == nil {
+		registry = metrics.DefaultRegistry
+	}
+	return &MetricsObserver{Registry: registry}
+}
+
+// RequestStarted implements Observer.
+func (o *MetricsObserver) RequestStarted(info RequestInfo) {
+	if info.Attempt > 0 {
+		metrics.GetOrRegisterMeter(metricName("requests", info.Endpoint, "retried"), o.Registry).Mark(1)
+	}
+}
+
+// RequestFinished implements Observer.
+func (o *MetricsObserver) RequestFinished(info RequestInfo) {
+	metrics.GetOrRegisterTimer(metricName("requests", info.Endpoint, "total"), o.Registry).Update(info.Duration)
+	if info.Succeeded() {
+		metrics.GetOrRegisterMeter(metricName("requests", info.Endpoint, "succeeded"), o.Registry).Mark(1)
+	} else {
+		metrics.GetOrRegisterMeter(metricName("requests", info.Endpoint, "failed"), o.Registry).Mark(1)
+	}
+}
+
+// StreamReconnected implements Observer.
+func (o *MetricsObserver) StreamReconnected(info RequestInfo) {
+	metrics.GetOrRegisterMeter(metricName("streams", info.Endpoint, "reconnected"), o.Registry).Mark(1)
+}
+
+func metricName(kind, endpoint, name string) string {
+	return kind + "." + strings.Replace(endpoint, "/", ".", -1) + "." + name
+}
+
+var _ Observer = &MetricsObserver{}
@@ -0,0 +1,123 @@
+package horizonclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	metrics "github.com/rcrowley/go-metrics"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/support/http/httptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingObserver records the notifications it receives.
+type recordingObserver struct {
+	mutex      sync.Mutex
+	started    []RequestInfo
+	finished   []RequestInfo
+	reconnects []RequestInfo
+}
+
+func (o *recordingObserver) RequestStarted(info RequestInfo) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.started = append(o.started, info)
+}
+
+func (o *recordingObserver) RequestFinished(info RequestInfo) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.finished = append(o.finished, info)
+}
+
+func (o *recordingObserver) StreamReconnected(info RequestInfo) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.reconnects = append(o.reconnects, info)
+}
+
+func TestEndpointName(t *testing.T) {
+	client := &Client{HorizonURL: "https://localhost/horizon"}
+	for requestURL, endpoint := range map[string]string{
+		"https://localhost/horizon/":                                       "root",
+		"https://localhost/horizon/transactions?cursor=now":                "transactions",
+		"https://localhost/horizon/transactions/abc":                       "transactions",
+		"https://localhost/horizon/accounts/GABC/payments?limit=10":        "accounts/payments",
+		"https://localhost/horizon/paths/strict-send?source_amount=10":     "paths/strict-send",
+		"https://localhost/horizon/order_book?selling_asset_type=native":   "order_book",
+		"https://otherhost/ledgers/69859/transactions?cursor=1&order=desc": "ledgers/transactions",
+	} {
+		assert.Equal(t, endpoint, client.endpointName(requestURL), requestURL)
+	}
+}
+
+func TestObserverRetries(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(t, failingHandler(&hits, 1, http.StatusServiceUnavailable, nil, ledgerResponse))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	client := &Client{HorizonURL: server.URL, RetryPolicy: testRetryPolicy(), Observer: observer}
+	_, err := client.LedgerDetail(69859)
+	require.NoError(t, err)
+
+	require.Len(t, observer.started, 2)
+	require.Len(t, observer.finished, 2)
+	for i, info := range observer.finished {
+		assert.Equal(t, "ledgers", info.Endpoint)
+		assert.Equal(t, "GET", info.Method)
+		assert.Equal(t, i, info.Attempt)
+		assert.False(t, info.Stream)
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, observer.finished[0].StatusCode)
+	assert.Error(t, observer.finished[0].Err)
+	assert.False(t, observer.finished[0].Succeeded())
+	assert.Equal(t, http.StatusOK, observer.finished[1].StatusCode)
+	assert.True(t, observer.finished[1].Succeeded())
+	assert.Empty(t, observer.reconnects)
+}
+
+func TestObserverStreamReconnects(t *testing.T) {
+	hmock := httptest.NewClient()
+	observer := &recordingObserver{}
+	client := &Client{HorizonURL: "https://localhost/", HTTP: hmock, Observer: observer}
+
+	hmock.On("GET", "https://localhost/ledgers?cursor=now").ReturnString(200, ledgerStreamWithIDs)
+	hmock.On("GET", "https://localhost/ledgers?cursor=300046415298560").ReturnString(200, ledgerStreamWithIDs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ledgers := 0
+	err := client.StreamLedgers(ctx, LedgerRequest{}, func(ledger hProtocol.Ledger) {
+		ledgers++
+		if ledgers == 3 {
+			cancel()
+		}
+	})
+	require.NoError(t, err)
+
+	require.Len(t, observer.started, 2)
+	require.Len(t, observer.finished, 2)
+	assert.True(t, observer.finished[0].Stream)
+	assert.Equal(t, "ledgers", observer.finished[0].Endpoint)
+	require.Len(t, observer.reconnects, 1)
+	assert.Equal(t, "https://localhost/ledgers?cursor=300046415298560", observer.reconnects[0].URL)
+}
+
+func TestMetricsObserver(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(t, failingHandler(&hits, 1, http.StatusServiceUnavailable, nil, ledgerResponse))
+	defer server.Close()
+
+	registry := metrics.NewRegistry()
+	client := &Client{HorizonURL: server.URL, RetryPolicy: testRetryPolicy(), Observer: NewMetricsObserver(registry)}
+	_, err := client.LedgerDetail(69859)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2), registry.Get("requests.ledgers.total").(metrics.Timer).Count())
+	assert.Equal(t, int64(1), registry.Get("requests.ledgers.succeeded").(metrics.Meter).Count())
+	assert.Equal(t, int64(1), registry.Get("requests.ledgers.failed").(metrics.Meter).Count())
+	assert.Equal(t, int64(1), registry.Get("requests.ledgers.retried").(metrics.Meter).Count())
+}
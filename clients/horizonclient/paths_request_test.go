@@ -33,6 +33,100 @@ func TestPathsRequestBuildUrl(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "paths?destination_account=GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU&destination_amount=100&destination_asset_code=NGN&destination_asset_issuer=GDZST3XVCDTUJ76ZAV2HA72KYQODXXZ5PTMAPZGDHZ6CS7RO7MGG3DBM&destination_asset_type=credit_alphanum4&source_account=GDZST3XVCDTUJ76ZAV2HA72KYQODXXZ5PTMAPZGDHZ6CS7RO7MGG3DBM", endpoint)
 
+	pr = PathsRequest{
+		DestinationAmount:      "100",
+		DestinationAssetCode:   "NGN",
+		DestinationAssetIssuer: "GDZST3XVCDTUJ76ZAV2HA72KYQODXXZ5PTMAPZGDHZ6CS7RO7MGG3DBM",
+		DestinationAssetType:   AssetType4,
+		SourceAssets:           "native,EUR:GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN",
+	}
+
+	endpoint, err = pr.BuildURL()
+
+	// It should return valid paths endpoint with a list of source assets and no errors
+	require.NoError(t, err)
+	assert.Equal(t, "paths?destination_amount=100&destination_asset_code=NGN&destination_asset_issuer=GDZST3XVCDTUJ76ZAV2HA72KYQODXXZ5PTMAPZGDHZ6CS7RO7MGG3DBM&destination_asset_type=credit_alphanum4&source_assets=native%2CEUR%3AGDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN", endpoint)
+}
+
+func TestStrictSendPathsRequestBuildUrl(t *testing.T) {
+	pr := StrictSendPathsRequest{}
+	endpoint, err := pr.BuildURL()
+
+	// It should return no errors and strict send paths endpoint
+	// Horizon will return an error though because there are no parameters
+	require.NoError(t, err)
+	assert.Equal(t, "paths/strict-send", endpoint)
+
+	pr = StrictSendPathsRequest{
+		DestinationAccount: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU",
+		SourceAmount:       "100",
+		SourceAssetCode:    "NGN",
+		SourceAssetIssuer:  "GDZST3XVCDTUJ76ZAV2HA72KYQODXXZ5PTMAPZGDHZ6CS7RO7MGG3DBM",
+		SourceAssetType:    AssetType4,
+	}
+
+	endpoint, err = pr.BuildURL()
+
+	// It should return valid strict send paths endpoint and no errors
+	require.NoError(t, err)
+	assert.Equal(t, "paths/strict-send?destination_account=GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU&source_amount=100&source_asset_code=NGN&source_asset_issuer=GDZST3XVCDTUJ76ZAV2HA72KYQODXXZ5PTMAPZGDHZ6CS7RO7MGG3DBM&source_asset_type=credit_alphanum4", endpoint)
+
+	pr = StrictSendPathsRequest{
+		DestinationAssets: "native,EUR:GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN",
+		SourceAmount:      "100",
+		SourceAssetType:   AssetTypeNative,
+	}
+
+	endpoint, err = pr.BuildURL()
+
+	// It should return valid strict send paths endpoint and no errors
+	require.NoError(t, err)
+	assert.Equal(t, "paths/strict-send?destination_assets=native%2CEUR%3AGDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN&source_amount=100&source_asset_type=native", endpoint)
+}
+
+func TestStrictSendPathsRequest(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		HorizonURL: "https://localhost/",
+		HTTP:       hmock,
+	}
+
+	pr := StrictSendPathsRequest{
+		DestinationAccount: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU",
+		SourceAmount:       "30",
+		SourceAssetCode:    "USD",
+		SourceAssetIssuer:  "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN",
+		SourceAssetType:    AssetType4,
+	}
+
+	hmock.On(
+		"GET",
+		"https://localhost/paths/strict-send?destination_account=GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU&source_amount=30&source_asset_code=USD&source_asset_issuer=GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN&source_asset_type=credit_alphanum4",
+	).ReturnString(200, pathsResponse)
+
+	paths, err := client.StrictSendPaths(pr)
+	if assert.NoError(t, err) {
+		assert.IsType(t, paths, hProtocol.PathsPage{})
+		record := paths.Embedded.Records[0]
+		assert.Equal(t, record.DestinationAmount, "20.0000000")
+		assert.Equal(t, record.SourceAssetCode, "USD")
+		assert.Equal(t, record.SourceAmount, "30.0000000")
+	}
+
+	// failure response
+	pr = StrictSendPathsRequest{}
+	hmock.On(
+		"GET",
+		"https://localhost/paths/strict-send",
+	).ReturnString(400, badRequestResponse)
+
+	_, err = client.StrictSendPaths(pr)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "horizon error")
+		horizonError, ok := err.(*Error)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, horizonError.Problem.Title, "Bad Request")
+	}
 }
 
 func TestPathsRequest(t *testing.T) {
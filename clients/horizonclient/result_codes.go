@@ -0,0 +1,392 @@
+package horizonclient
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stellar/go/xdr"
+)
+
+// ResultCodeError is a transaction or operation result code used as an error value. Its value
+// is the string used by horizon for the code, e.g. "tx_bad_seq" or "op_underfunded".
+type ResultCodeError string
+
+func (e ResultCodeError) Error() string {
+	return string(e)
+}
+
+// Transaction result codes.
+var (
+	ErrTxFailed              = ResultCodeError("tx_failed")
+	ErrTxTooEarly            = ResultCodeError("tx_too_early")
+	ErrTxTooLate             = ResultCodeError("tx_too_late")
+	ErrTxMissingOperation    = ResultCodeError("tx_missing_operation")
+	ErrTxBadSeq              = ResultCodeError("tx_bad_seq")
+	ErrTxBadAuth             = ResultCodeError("tx_bad_auth")
+	ErrTxInsufficientBalance = ResultCodeError("tx_insufficient_balance")
+	ErrTxNoSourceAccount     = ResultCodeError("tx_no_source_account")
+	ErrTxInsufficientFee     = ResultCodeError("tx_insufficient_fee")
+	ErrTxBadAuthExtra        = ResultCodeError("tx_bad_auth_extra")
+	ErrTxInternalError       = ResultCodeError("tx_internal_error")
+)
+
+// Operation result codes. Codes shared by several operation types, such as op_underfunded, have a
+// single value.
+var (
+	ErrOpBadAuth             = ResultCodeError("op_bad_auth")
+	ErrOpNoSourceAccount     = ResultCodeError("op_no_source_account")
+	ErrOpNotSupported        = ResultCodeError("op_not_supported")
+	ErrOpTooManySubentries   = ResultCodeError("op_too_many_subentries")
+	ErrOpExceededWorkLimit   = ResultCodeError("op_exceeded_work_limit")
+	ErrOpMalformed           = ResultCodeError("op_malformed")
+	ErrOpUnderfunded         = ResultCodeError("op_underfunded")
+	ErrOpLowReserve          = ResultCodeError("op_low_reserve")
+	ErrOpLineFull            = ResultCodeError("op_line_full")
+	ErrOpNoIssuer            = ResultCodeError("op_no_issuer")
+	ErrOpAlreadyExists       = ResultCodeError("op_already_exists")
+	ErrOpSrcNoTrust          = ResultCodeError("op_src_no_trust")
+	ErrOpSrcNotAuthorized    = ResultCodeError("op_src_not_authorized")
+	ErrOpNoDestination       = ResultCodeError("op_no_destination")
+	ErrOpNoTrust             = ResultCodeError("op_no_trust")
+	ErrOpNotAuthorized       = ResultCodeError("op_not_authorized")
+	ErrOpTooFewOffers        = ResultCodeError("op_too_few_offers")
+	ErrOpCrossSelf           = ResultCodeError("op_cross_self")
+	ErrOpOverSourceMax       = ResultCodeError("op_over_source_max")
+	ErrOpUnderDestMin        = ResultCodeError("op_under_dest_min")
+	ErrOpSellNoTrust         = ResultCodeError("op_sell_no_trust")
+	ErrOpBuyNoTrust          = ResultCodeError("op_buy_no_trust")
+	ErrOpSellNotAuthorized   = ResultCodeError("sell_not_authorized")
+	ErrOpBuyNotAuthorized    = ResultCodeError("buy_not_authorized")
+	ErrOpSellNoIssuer        = ResultCodeError("op_sell_no_issuer")
+	ErrOpBuyNoIssuer         = ResultCodeError("buy_no_issuer")
+	ErrOpOfferNotFound       = ResultCodeError("op_offer_not_found")
+	ErrOpTooManySigners      = ResultCodeError("op_too_many_signers")
+	ErrOpBadFlags            = ResultCodeError("op_bad_flags")
+	ErrOpInvalidInflation    = ResultCodeError("op_invalid_inflation")
+	ErrOpCantChange          = ResultCodeError("op_cant_change")
+	ErrOpUnknownFlag         = ResultCodeError("op_unknown_flag")
+	ErrOpThresholdOutOfRange = ResultCodeError("op_threshold_out_of_range")
+	ErrOpBadSigner           = ResultCodeError("op_bad_signer")
+	ErrOpInvalidHomeDomain   = ResultCodeError("op_invalid_home_domain")
+	ErrOpInvalidLimit        = ResultCodeError("op_invalid_limit")
+	ErrOpSelfNotAllowed      = ResultCodeError("op_self_not_allowed")
+	ErrOpNoTrustline         = ResultCodeError("op_no_trustline")
+	ErrOpNotRequired         = ResultCodeError("op_not_required")
+	ErrOpCantRevoke          = ResultCodeError("op_cant_revoke")
+	ErrOpNoAccount           = ResultCodeError("op_no_account")
+	ErrOpImmutableSet        = ResultCodeError("op_immutable_set")
+	ErrOpHasSubEntries       = ResultCodeError("op_has_sub_entries")
+	ErrOpSeqNumTooFar        = ResultCodeError("op_seq_num_too_far")
+	ErrOpDestFull            = ResultCodeError("op_dest_full")
+	ErrOpNotTime             = ResultCodeError("op_not_time")
+	ErrOpNotSupportedYet     = ResultCodeError("op_not_supported_yet")
+	ErrOpDataNameNotFound    = ResultCodeError("op_data_name_not_found")
+	ErrOpDataInvalidName     = ResultCodeError("op_data_invalid_name")
+	ErrOpBadSeq              = ResultCodeError("op_bad_seq")
+)
+
+// TransactionResultError is a transaction rejected by stellar-core, decoded from the result XDR
+// of a horizon error. It matches its transaction code and the codes of its failed operations with
+// errors.Is, and can be converted to the first failed operation with errors.As:
+//
+//	if errors.Is(err, horizonclient.ErrOpUnderfunded) { ... }
+//
+//	var opErr *horizonclient.OperationResultError
+//	if errors.As(err, &opErr) { ... opErr.Index ... }
+type TransactionResultError struct {
+	// Code is the transaction result code, one of the ErrTx* values.
+	Code ResultCodeError
+	// Operations are the operations that failed, for tx_failed transactions.
+	Operations []*OperationResultError
+	// Result is the decoded transaction result.
+	Result xdr.TransactionResult
+}
+
+func (e *TransactionResultError) Error() string {
+	if len(e.Operations) == 0 {
+		return "transaction failed: " + string(e.Code)
+	}
+
+	ops := make([]string, len(e.Operations))
+	for i, op := range e.Operations {
+		ops[i] = op.Error()
+	}
+	return fmt.Sprintf("transaction failed: %s (%s)", e.Code, strings.Join(ops, ", "))
+}
+
+// Unwrap returns the transaction result code.
+func (e *TransactionResultError) Unwrap() error {
+	return e.Code
+}
+
+// Is reports whether target is the transaction result code or the code of one of the failed
+// operations.
+func (e *TransactionResultError) Is(target error) bool {
+	if target == error(e.Code) {
+		return true
+	}
+	for _, op := range e.Operations {
+		if target == error(op.Code) {
+			return true
+		}
+	}
+	return false
+}
+
+// As sets target to the first failed operation when it is a **OperationResultError.
+func (e *TransactionResultError) As(target interface{}) bool {
+	opErr, ok := target.(**OperationResultError)
+	if !ok || len(e.Operations) == 0 {
+		return false
+	}
+	*opErr = e.Operations[0]
+	return true
+}
+
+// OperationResultError is an operation of a transaction that failed.
+type OperationResultError struct {
+	// Index is the position of the operation in the transaction.
+	Index int
+	// Code is the operation result code, one of the ErrOp* values.
+	Code ResultCodeError
+}
+
+func (e *OperationResultError) Error() string {
+	return fmt.Sprintf("operation %d failed: %s", e.Index, e.Code)
+}
+
+// Unwrap returns the operation result code.
+func (e *OperationResultError) Unwrap() error {
+	return e.Code
+}
+
+// newTransactionResultError returns the error for the failed transaction result.
+func newTransactionResultError(result xdr.TransactionResult) *TransactionResultError {
+	resultErr := &TransactionResultError{
+		Code:   transactionResultCode(result.Result.Code),
+		Result: result,
+	}
+	if result.Result.Results == nil {
+		return resultErr
+	}
+
+	for i, opResult := range *result.Result.Results {
+		code, failed := operationResultCode(opResult)
+		if failed {
+			resultErr.Operations = append(resultErr.Operations, &OperationResultError{Index: i, Code: code})
+		}
+	}
+	return resultErr
+}
+
+var transactionResultCodes = map[xdr.TransactionResultCode]ResultCodeError{
+	xdr.TransactionResultCodeTxFailed:              ErrTxFailed,
+	xdr.TransactionResultCodeTxTooEarly:            ErrTxTooEarly,
+	xdr.TransactionResultCodeTxTooLate:             ErrTxTooLate,
+	xdr.TransactionResultCodeTxMissingOperation:    ErrTxMissingOperation,
+	xdr.TransactionResultCodeTxBadSeq:              ErrTxBadSeq,
+	xdr.TransactionResultCodeTxBadAuth:             ErrTxBadAuth,
+	xdr.TransactionResultCodeTxInsufficientBalance: ErrTxInsufficientBalance,
+	xdr.TransactionResultCodeTxNoAccount:           ErrTxNoSourceAccount,
+	xdr.TransactionResultCodeTxInsufficientFee:     ErrTxInsufficientFee,
+	xdr.TransactionResultCodeTxBadAuthExtra:        ErrTxBadAuthExtra,
+	xdr.TransactionResultCodeTxInternalError:       ErrTxInternalError,
+}
+
+func transactionResultCode(code xdr.TransactionResultCode) ResultCodeError {
+	if rc, ok := transactionResultCodes[code]; ok {
+		return rc
+	}
+	return unknownResultCode(code)
+}
+
+var operationResultCodes = map[xdr.OperationResultCode]ResultCodeError{
+	xdr.OperationResultCodeOpBadAuth:           ErrOpBadAuth,
+	xdr.OperationResultCodeOpNoAccount:         ErrOpNoSourceAccount,
+	xdr.OperationResultCodeOpNotSupported:      ErrOpNotSupported,
+	xdr.OperationResultCodeOpTooManySubentries: ErrOpTooManySubentries,
+	xdr.OperationResultCodeOpExceededWorkLimit: ErrOpExceededWorkLimit,
+}
+
+// operationResultCode returns the result code of an operation. failed is false if the operation
+// succeeded.
+func operationResultCode(opResult xdr.OperationResult) (code ResultCodeError, failed bool) {
+	if opResult.Code != xdr.OperationResultCodeOpInner {
+		if rc, ok := operationResultCodes[opResult.Code]; ok {
+			return rc, true
+		}
+		return unknownResultCode(opResult.Code), true
+	}
+
+	// inner result codes are 0 on success for every operation type
+	var inner interface{}
+	var value int32
+	tr := opResult.MustTr()
+	switch tr.Type {
+	case xdr.OperationTypeCreateAccount:
+		c := tr.MustCreateAccountResult().Code
+		inner, value = c, int32(c)
+	case xdr.OperationTypePayment:
+		c := tr.MustPaymentResult().Code
+		inner, value = c, int32(c)
+	case xdr.OperationTypePathPaymentStrictReceive:
+		c := tr.MustPathPaymentStrictReceiveResult().Code
+		inner, value = c, int32(c)
+	case xdr.OperationTypeManageBuyOffer:
+		c := tr.MustManageBuyOfferResult().Code
+		inner, value = c, int32(c)
+	case xdr.OperationTypeManageSellOffer:
+		c := tr.MustManageSellOfferResult().Code
+		inner, value = c, int32(c)
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		c := tr.MustCreatePassiveSellOfferResult().Code
+		inner, value = c, int32(c)
+	case xdr.OperationTypeSetOptions:
+		c := tr.MustSetOptionsResult().Code
+		inner, value = c, int32(c)
+	case xdr.OperationTypeChangeTrust:
+		c := tr.MustChangeTrustResult().Code
+		inner, value = c, int32(c)
+	case xdr.OperationTypeAllowTrust:
+		c := tr.MustAllowTrustResult().Code
+		inner, value = c, int32(c)
+	case xdr.OperationTypeAccountMerge:
+		c := tr.MustAccountMergeResult().Code
+		inner, value = c, int32(c)
+	case xdr.OperationTypeInflation:
+		c := tr.MustInflationResult().Code
+		inner, value = c, int32(c)
+	case xdr.OperationTypeManageData:
+		c := tr.MustManageDataResult().Code
+		inner, value = c, int32(c)
+	case xdr.OperationTypeBumpSequence:
+		c := tr.MustBumpSeqResult().Code
+		inner, value = c, int32(c)
+	case xdr.OperationTypePathPaymentStrictSend:
+		c := tr.MustPathPaymentStrictSendResult().Code
+		inner, value = c, int32(c)
+	default:
+		return unknownResultCode(tr.Type), true
+	}
+
+	if value == 0 {
+		return "", false
+	}
+	if rc, ok := innerResultCodes[inner]; ok {
+		return rc, true
+	}
+	return unknownResultCode(inner), true
+}
+
+// innerResultCodes maps the result codes of every operation type to their ResultCodeError.
+var innerResultCodes = map[interface{}]ResultCodeError{
+	xdr.CreateAccountResultCodeCreateAccountMalformed:    ErrOpMalformed,
+	xdr.CreateAccountResultCodeCreateAccountUnderfunded:  ErrOpUnderfunded,
+	xdr.CreateAccountResultCodeCreateAccountLowReserve:   ErrOpLowReserve,
+	xdr.CreateAccountResultCodeCreateAccountAlreadyExist: ErrOpAlreadyExists,
+
+	xdr.PaymentResultCodePaymentMalformed:        ErrOpMalformed,
+	xdr.PaymentResultCodePaymentUnderfunded:      ErrOpUnderfunded,
+	xdr.PaymentResultCodePaymentSrcNoTrust:       ErrOpSrcNoTrust,
+	xdr.PaymentResultCodePaymentSrcNotAuthorized: ErrOpSrcNotAuthorized,
+	xdr.PaymentResultCodePaymentNoDestination:    ErrOpNoDestination,
+	xdr.PaymentResultCodePaymentNoTrust:          ErrOpNoTrust,
+	xdr.PaymentResultCodePaymentNotAuthorized:    ErrOpNotAuthorized,
+	xdr.PaymentResultCodePaymentLineFull:         ErrOpLineFull,
+	xdr.PaymentResultCodePaymentNoIssuer:         ErrOpNoIssuer,
+
+	xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveMalformed:        ErrOpMalformed,
+	xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveUnderfunded:      ErrOpUnderfunded,
+	xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveSrcNoTrust:       ErrOpSrcNoTrust,
+	xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveSrcNotAuthorized: ErrOpSrcNotAuthorized,
+	xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveNoDestination:    ErrOpNoDestination,
+	xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveNoTrust:          ErrOpNoTrust,
+	xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveNotAuthorized:    ErrOpNotAuthorized,
+	xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveLineFull:         ErrOpLineFull,
+	xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveNoIssuer:         ErrOpNoIssuer,
+	xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveTooFewOffers:     ErrOpTooFewOffers,
+	xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveOfferCrossSelf:   ErrOpCrossSelf,
+	xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveOverSendmax:      ErrOpOverSourceMax,
+
+	xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendMalformed:        ErrOpMalformed,
+	xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendUnderfunded:      ErrOpUnderfunded,
+	xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendSrcNoTrust:       ErrOpSrcNoTrust,
+	xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendSrcNotAuthorized: ErrOpSrcNotAuthorized,
+	xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendNoDestination:    ErrOpNoDestination,
+	xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendNoTrust:          ErrOpNoTrust,
+	xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendNotAuthorized:    ErrOpNotAuthorized,
+	xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendLineFull:         ErrOpLineFull,
+	xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendNoIssuer:         ErrOpNoIssuer,
+	xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendTooFewOffers:     ErrOpTooFewOffers,
+	xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendOfferCrossSelf:   ErrOpCrossSelf,
+	xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendUnderDestmin:     ErrOpUnderDestMin,
+
+	xdr.ManageBuyOfferResultCodeManageBuyOfferMalformed:         ErrOpMalformed,
+	xdr.ManageBuyOfferResultCodeManageBuyOfferSellNoTrust:       ErrOpSellNoTrust,
+	xdr.ManageBuyOfferResultCodeManageBuyOfferBuyNoTrust:        ErrOpBuyNoTrust,
+	xdr.ManageBuyOfferResultCodeManageBuyOfferSellNotAuthorized: ErrOpSellNotAuthorized,
+	xdr.ManageBuyOfferResultCodeManageBuyOfferBuyNotAuthorized:  ErrOpBuyNotAuthorized,
+	xdr.ManageBuyOfferResultCodeManageBuyOfferLineFull:          ErrOpLineFull,
+	xdr.ManageBuyOfferResultCodeManageBuyOfferUnderfunded:       ErrOpUnderfunded,
+	xdr.ManageBuyOfferResultCodeManageBuyOfferCrossSelf:         ErrOpCrossSelf,
+	xdr.ManageBuyOfferResultCodeManageBuyOfferSellNoIssuer:      ErrOpSellNoIssuer,
+	xdr.ManageBuyOfferResultCodeManageBuyOfferBuyNoIssuer:       ErrOpBuyNoIssuer,
+	xdr.ManageBuyOfferResultCodeManageBuyOfferNotFound:          ErrOpOfferNotFound,
+	xdr.ManageBuyOfferResultCodeManageBuyOfferLowReserve:        ErrOpLowReserve,
+
+	xdr.ManageSellOfferResultCodeManageSellOfferMalformed:         ErrOpMalformed,
+	xdr.ManageSellOfferResultCodeManageSellOfferSellNoTrust:       ErrOpSellNoTrust,
+	xdr.ManageSellOfferResultCodeManageSellOfferBuyNoTrust:        ErrOpBuyNoTrust,
+	xdr.ManageSellOfferResultCodeManageSellOfferSellNotAuthorized: ErrOpSellNotAuthorized,
+	xdr.ManageSellOfferResultCodeManageSellOfferBuyNotAuthorized:  ErrOpBuyNotAuthorized,
+	xdr.ManageSellOfferResultCodeManageSellOfferLineFull:          ErrOpLineFull,
+	xdr.ManageSellOfferResultCodeManageSellOfferUnderfunded:       ErrOpUnderfunded,
+	xdr.ManageSellOfferResultCodeManageSellOfferCrossSelf:         ErrOpCrossSelf,
+	xdr.ManageSellOfferResultCodeManageSellOfferSellNoIssuer:      ErrOpSellNoIssuer,
+	xdr.ManageSellOfferResultCodeManageSellOfferBuyNoIssuer:       ErrOpBuyNoIssuer,
+	xdr.ManageSellOfferResultCodeManageSellOfferNotFound:          ErrOpOfferNotFound,
+	xdr.ManageSellOfferResultCodeManageSellOfferLowReserve:        ErrOpLowReserve,
+
+	xdr.SetOptionsResultCodeSetOptionsLowReserve:          ErrOpLowReserve,
+	xdr.SetOptionsResultCodeSetOptionsTooManySigners:      ErrOpTooManySigners,
+	xdr.SetOptionsResultCodeSetOptionsBadFlags:            ErrOpBadFlags,
+	xdr.SetOptionsResultCodeSetOptionsInvalidInflation:    ErrOpInvalidInflation,
+	xdr.SetOptionsResultCodeSetOptionsCantChange:          ErrOpCantChange,
+	xdr.SetOptionsResultCodeSetOptionsUnknownFlag:         ErrOpUnknownFlag,
+	xdr.SetOptionsResultCodeSetOptionsThresholdOutOfRange: ErrOpThresholdOutOfRange,
+	xdr.SetOptionsResultCodeSetOptionsBadSigner:           ErrOpBadSigner,
+	xdr.SetOptionsResultCodeSetOptionsInvalidHomeDomain:   ErrOpInvalidHomeDomain,
+
+	xdr.ChangeTrustResultCodeChangeTrustMalformed:      ErrOpMalformed,
+	xdr.ChangeTrustResultCodeChangeTrustNoIssuer:       ErrOpNoIssuer,
+	xdr.ChangeTrustResultCodeChangeTrustInvalidLimit:   ErrOpInvalidLimit,
+	xdr.ChangeTrustResultCodeChangeTrustLowReserve:     ErrOpLowReserve,
+	xdr.ChangeTrustResultCodeChangeTrustSelfNotAllowed: ErrOpSelfNotAllowed,
+
+	xdr.AllowTrustResultCodeAllowTrustMalformed:        ErrOpMalformed,
+	xdr.AllowTrustResultCodeAllowTrustNoTrustLine:      ErrOpNoTrustline,
+	xdr.AllowTrustResultCodeAllowTrustTrustNotRequired: ErrOpNotRequired,
+	xdr.AllowTrustResultCodeAllowTrustCantRevoke:       ErrOpCantRevoke,
+
+	xdr.AccountMergeResultCodeAccountMergeMalformed:     ErrOpMalformed,
+	xdr.AccountMergeResultCodeAccountMergeNoAccount:     ErrOpNoAccount,
+	xdr.AccountMergeResultCodeAccountMergeImmutableSet:  ErrOpImmutableSet,
+	xdr.AccountMergeResultCodeAccountMergeHasSubEntries: ErrOpHasSubEntries,
+	xdr.AccountMergeResultCodeAccountMergeSeqnumTooFar:  ErrOpSeqNumTooFar,
+	xdr.AccountMergeResultCodeAccountMergeDestFull:      ErrOpDestFull,
+
+	xdr.InflationResultCodeInflationNotTime: ErrOpNotTime,
+
+	xdr.ManageDataResultCodeManageDataNotSupportedYet: ErrOpNotSupportedYet,
+	xdr.ManageDataResultCodeManageDataNameNotFound:    ErrOpDataNameNotFound,
+	xdr.ManageDataResultCodeManageDataLowReserve:      ErrOpLowReserve,
+	xdr.ManageDataResultCodeManageDataInvalidName:     ErrOpDataInvalidName,
+
+	xdr.BumpSequenceResultCodeBumpSequenceBadSeq: ErrOpBadSeq,
+}
+
+// unknownResultCode returns a ResultCodeError for a code this package does not know about, for
+// instance one added by a newer protocol version.
+func unknownResultCode(code interface{}) ResultCodeError {
+	return ResultCodeError(fmt.Sprintf("unknown result code %v", code))
+}
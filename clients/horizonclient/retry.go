@@ -0,0 +1,170 @@
+package horizonclient
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy configures how a Client retries requests that failed because the horizon server was
+// rate limiting the client (429), timed out (504) or was temporarily unavailable (5xx), or because
+// the connection to it failed. Retries are delayed using exponential backoff with full jitter. When
+// horizon reports through the `X-RateLimit-Remaining` header that no requests are left, further
+// requests to that server wait until the time given in `X-RateLimit-Reset` has passed.
+//
+// A RetryPolicy may be shared between clients and goroutines. It must not be copied after first use.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of times a request is retried after the first attempt.
+	MaxRetries int
+	// MinBackoff is the delay before the first retry. It doubles with every further retry.
+	MinBackoff time.Duration
+	// MaxBackoff is the upper bound of the delay between two attempts.
+	MaxBackoff time.Duration
+	// RetrySubmissions enables retrying transaction submissions. Submitting a transaction is not
+	// idempotent: a submission that timed out may still make it into a ledger, so callers opting in
+	// must be prepared to handle the result of the retried submission accordingly (e.g. tx_bad_seq).
+	RetrySubmissions bool
+
+	mutex sync.Mutex
+	// resets holds, per horizon host, the time at which its rate limit resets when the last
+	// response from it reported that no requests were remaining.
+	resets map[string]time.Time
+}
+
+// DefaultRetryPolicy returns a RetryPolicy retrying up to 3 times, waiting between 500ms and 10s.
+// Transaction submissions are not retried.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: 3,
+		MinBackoff: 500 * time.Millisecond,
+		MaxBackoff: 10 * time.Second,
+	}
+}
+
+// backoff returns the delay before retry number `attempt` (starting at 0).
+func (rp *RetryPolicy) backoff(attempt int) time.Duration {
+	ceiling := rp.MinBackoff
+	for i := 0; i < attempt && (rp.MaxBackoff <= 0 || ceiling < rp.MaxBackoff); i++ {
+		ceiling *= 2
+	}
+	if rp.MaxBackoff > 0 && ceiling > rp.MaxBackoff {
+		ceiling = rp.MaxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// shouldRetry reports whether an attempt that got resp and err should be retried, and if so, how
+// long to wait before doing it. ctx is the context of the whole request, not of the attempt.
+func (rp *RetryPolicy) shouldRetry(ctx context.Context, attempt int, submission bool, resp *http.Response,
+	err error) (time.Duration, bool) {
+	if rp == nil || attempt >= rp.MaxRetries || ctx.Err() != nil {
+		return 0, false
+	}
+	if submission && !rp.RetrySubmissions {
+		return 0, false
+	}
+
+	if resp == nil {
+		// the request never got a response, e.g. the connection was refused or the per-request
+		// timeout elapsed
+		return rp.backoff(attempt), err != nil
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		delay := rp.backoff(attempt)
+		if wait, ok := rateLimitWait(resp.Header); ok && wait > delay {
+			delay = wait
+		}
+		return delay, true
+	case resp.StatusCode >= 500:
+		return rp.backoff(attempt), true
+	default:
+		return 0, false
+	}
+}
+
+// observe records the rate limit state reported in the headers of resp for the horizon server
+// that sent it.
+func (rp *RetryPolicy) observe(requestURL string, resp *http.Response, now time.Time) {
+	if rp == nil || resp == nil {
+		return
+	}
+	host := hostOf(requestURL)
+
+	rp.mutex.Lock()
+	defer rp.mutex.Unlock()
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" && resp.StatusCode != http.StatusTooManyRequests {
+		delete(rp.resets, host)
+		return
+	}
+	wait, ok := rateLimitWait(resp.Header)
+	if !ok {
+		return
+	}
+	if rp.resets == nil {
+		rp.resets = make(map[string]time.Time)
+	}
+	rp.resets[host] = now.Add(wait)
+}
+
+// waitForRateLimit blocks until the rate limit of the horizon server at requestURL resets, if
+// the server previously reported that no requests were remaining. It returns early with an
+// error if ctx is done.
+func (rp *RetryPolicy) waitForRateLimit(ctx context.Context, requestURL string, now time.Time) error {
+	if rp == nil {
+		return nil
+	}
+
+	rp.mutex.Lock()
+	reset, ok := rp.resets[hostOf(requestURL)]
+	rp.mutex.Unlock()
+	if !ok || !reset.After(now) {
+		return nil
+	}
+
+	return sleep(ctx, reset.Sub(now))
+}
+
+// rateLimitWait returns how long horizon asked the client to wait, based on the `Retry-After`
+// and `X-RateLimit-Reset` headers. Both contain a number of seconds.
+func rateLimitWait(header http.Header) (time.Duration, bool) {
+	for _, name := range []string{"Retry-After", "X-RateLimit-Reset"} {
+		seconds, err := strconv.ParseInt(header.Get(name), 10, 64)
+		if err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	return 0, false
+}
+
+// hostOf returns the host part of rawURL, or rawURL itself if it can't be parsed.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Host
+}
+
+// sleep pauses for d, returning ctx.Err() if ctx is done before d elapses.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
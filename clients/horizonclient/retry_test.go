@@ -0,0 +1,175 @@
+package horizonclient
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/support/http/httptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingHandler responds with status to the first `failures` requests it receives and with body
+// afterwards. It counts every request in hits.
+func failingHandler(hits *int32, failures int32, status int, header http.Header, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(hits, 1)
+		if n <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			w.Write([]byte(`{"type": "https://stellar.org/horizon-errors/server_error", "title": "Internal Server Error", "status": 500}`))
+			return
+		}
+		w.Write([]byte(body))
+	})
+}
+
+func testRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: 3,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 5 * time.Millisecond,
+	}
+}
+
+func TestRetryServerErrors(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(t, failingHandler(&hits, 2, http.StatusServiceUnavailable, nil, ledgerResponse))
+	defer server.Close()
+
+	client := &Client{HorizonURL: server.URL, RetryPolicy: testRetryPolicy()}
+	ledger, err := client.LedgerDetail(1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(69859), ledger.Sequence)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&hits))
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(t, failingHandler(&hits, 10, http.StatusGatewayTimeout, nil, ledgerResponse))
+	defer server.Close()
+
+	client := &Client{HorizonURL: server.URL, RetryPolicy: testRetryPolicy()}
+	_, err := client.LedgerDetail(1)
+	if assert.Error(t, err) {
+		herr, ok := err.(*Error)
+		if assert.True(t, ok) {
+			assert.Equal(t, http.StatusGatewayTimeout, herr.Response.StatusCode)
+		}
+	}
+	// first attempt + 3 retries
+	assert.Equal(t, int32(4), atomic.LoadInt32(&hits))
+}
+
+func TestRetryDisabled(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(t, failingHandler(&hits, 1, http.StatusServiceUnavailable, nil, ledgerResponse))
+	defer server.Close()
+
+	client := &Client{HorizonURL: server.URL}
+	_, err := client.LedgerDetail(1)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+}
+
+func TestRetryClientErrorsAreNotRetried(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(t, failingHandler(&hits, 1, http.StatusNotFound, nil, ledgerResponse))
+	defer server.Close()
+
+	client := &Client{HorizonURL: server.URL, RetryPolicy: testRetryPolicy()}
+	_, err := client.LedgerDetail(1)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+}
+
+func TestRetryRateLimited(t *testing.T) {
+	var hits int32
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset", "0")
+	server := httptest.NewServer(t, failingHandler(&hits, 1, http.StatusTooManyRequests, header, ledgerResponse))
+	defer server.Close()
+
+	client := &Client{HorizonURL: server.URL, RetryPolicy: testRetryPolicy()}
+	_, err := client.LedgerDetail(1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+}
+
+func TestRetrySubmissions(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(t, failingHandler(&hits, 1, http.StatusGatewayTimeout, nil, txSuccess))
+	defer server.Close()
+
+	// submissions are not retried by default
+	client := &Client{HorizonURL: server.URL, RetryPolicy: testRetryPolicy()}
+	_, err := client.SubmitTransactionXDR("AAAA")
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits))
+
+	// unless the caller opts in
+	atomic.StoreInt32(&hits, 0)
+	client.RetryPolicy.RetrySubmissions = true
+	_, err = client.SubmitTransactionXDR("AAAA")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits))
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(t, failingHandler(&hits, 10, http.StatusServiceUnavailable, nil, ledgerResponse))
+	defer server.Close()
+
+	client := &Client{
+		HorizonURL:  server.URL,
+		RetryPolicy: &RetryPolicy{MaxRetries: 10, MinBackoff: time.Hour, MaxBackoff: time.Hour},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.LedgerDetailWithContext(ctx, 1)
+	assert.Error(t, err)
+	assert.True(t, atomic.LoadInt32(&hits) < 10)
+}
+
+func TestRetryPolicyWaitsForRateLimitReset(t *testing.T) {
+	rp := testRetryPolicy()
+	now := time.Now()
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	resp.Header.Set("X-RateLimit-Remaining", "0")
+	resp.Header.Set("X-RateLimit-Reset", "3600")
+	rp.observe("https://horizon.example.com/ledgers", resp, now)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := rp.waitForRateLimit(ctx, "https://horizon.example.com/accounts", now)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// other hosts are not affected
+	err = rp.waitForRateLimit(ctx, "https://other.example.com/accounts", now)
+	assert.NoError(t, err)
+
+	// once requests are available again, there is no wait
+	resp.Header.Set("X-RateLimit-Remaining", "10")
+	rp.observe("https://horizon.example.com/ledgers", resp, now)
+	err = rp.waitForRateLimit(context.Background(), "https://horizon.example.com/accounts", now)
+	assert.NoError(t, err)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	rp := &RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt := 0; attempt < 10; attempt++ {
+		delay := rp.backoff(attempt)
+		assert.True(t, delay >= 0)
+		assert.True(t, delay <= time.Second)
+		if attempt == 0 {
+			assert.True(t, delay <= 100*time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package horizonclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/stellar/go/keypair"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/txnbuild"
+)
+
+// defaultSubmitAttempts is the number of submissions made by a TransactionSubmitter whose
+// MaxAttempts is not set.
+const defaultSubmitAttempts = 3
+
+// TransactionSubmitter builds, signs and submits transactions from a txnbuild.Transaction
+// template, recovering from the two situations where a plain submission leaves the caller unsure
+// what to do next:
+//
+//   - tx_bad_seq: the sequence number of the source account is reloaded with AccountDetail and the
+//     transaction is rebuilt and signed again.
+//   - timeouts (504 responses and network errors): the transaction may still be applied, so it is
+//     looked up by hash with TransactionDetail. If it is not found, the same envelope is submitted
+//     again. Because the envelope keeps its sequence number it can never be applied twice.
+type TransactionSubmitter struct {
+	Client ClientInterface
+	// Signers sign every transaction built by the submitter.
+	Signers []keypair.Signer
+	// MaxAttempts is the maximum number of submissions made for a transaction, 3 when 0.
+	MaxAttempts int
+	// SkipMemoRequiredCheck disables the check of the destination accounts requiring a memo.
+	SkipMemoRequiredCheck bool
+}
+
+// Submit builds template, signs it with the submitter's signers and submits it. The source account
+// of template must be set; it is used as-is for the first submission, so its sequence number is
+// incremented like with txnbuild.Transaction.Build.
+//
+// Like Client.SubmitTransaction, the transaction is not submitted, and an *AccountRequiresMemoError
+// is returned, when it has no memo and pays an account which requires one.
+func (s *TransactionSubmitter) Submit(template txnbuild.Transaction) (hProtocol.TransactionSuccess, error) {
+	return s.SubmitWithContext(context.Background(), template)
+}
+
+// SubmitWithContext is the same as Submit, but the requests are cancelled when ctx is done.
+func (s *TransactionSubmitter) SubmitWithContext(ctx context.Context, template txnbuild.Transaction) (txSuccess hProtocol.TransactionSuccess, err error) {
+	source := template.SourceAccount
+	if source == nil {
+		err = errors.New("transaction template has no source account")
+		return
+	}
+
+	if !s.SkipMemoRequiredCheck {
+		// the memo and the operations are the same in every transaction built from template
+		err = s.Client.CheckMemoRequiredWithContext(ctx, template)
+		if err != nil {
+			return
+		}
+	}
+
+	maxAttempts := s.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultSubmitAttempts
+	}
+
+	var txeBase64, txHash string
+	// timedOut is set when a submission of the current envelope timed out: the envelope may be
+	// applied at any time, so it must be ruled out before building a new one
+	timedOut := false
+	for attempt := 1; ; attempt++ {
+		if txeBase64 == "" {
+			txeBase64, txHash, err = s.build(template, source)
+			if err != nil {
+				return
+			}
+			timedOut = false
+		}
+
+		var submitErr error
+		txSuccess, submitErr = s.Client.SubmitTransactionXDRWithContext(ctx, txeBase64)
+		if submitErr == nil || ctx.Err() != nil {
+			return txSuccess, submitErr
+		}
+
+		switch {
+		case isSubmissionTimeout(submitErr):
+			timedOut = true
+			var found bool
+			txSuccess, found, err = s.lookup(ctx, txHash)
+			if err != nil || found {
+				return
+			}
+		case isBadSequence(submitErr):
+			if timedOut {
+				// the sequence number may have been consumed by a previous submission
+				var found bool
+				txSuccess, found, err = s.lookup(ctx, txHash)
+				if err != nil || found {
+					return
+				}
+			}
+
+			var account hProtocol.Account
+			account, err = s.Client.AccountDetailWithContext(ctx, AccountRequest{AccountID: source.GetAccountID()})
+			if err != nil {
+				err = errors.Wrap(err, "reloading source account")
+				return
+			}
+			source = &account
+			txeBase64 = ""
+		default:
+			return txSuccess, submitErr
+		}
+
+		if attempt >= maxAttempts {
+			return txSuccess, errors.Wrapf(submitErr, "transaction not submitted after %d attempts", attempt)
+		}
+	}
+}
+
+// build builds a transaction from template using source as its source account and signs it. It
+// returns the envelope and the hex-encoded hash of the transaction.
+func (s *TransactionSubmitter) build(template txnbuild.Transaction, source txnbuild.Account) (txeBase64, txHash string, err error) {
+	tx := template
+	tx.SourceAccount = source
+
+	txeBase64, err = tx.BuildSignEncode(s.Signers...)
+	if err != nil {
+		return
+	}
+
+	txHash, err = tx.HashHex()
+	err = errors.Wrap(err, "couldn't hash transaction")
+	return
+}
+
+// lookup looks up a transaction by hash. found is false when horizon does not know the
+// transaction.
+func (s *TransactionSubmitter) lookup(ctx context.Context, txHash string) (txSuccess hProtocol.TransactionSuccess, found bool, err error) {
+	tx, err := s.Client.TransactionDetailWithContext(ctx, txHash)
+	if isNotFound(err) {
+		return txSuccess, false, nil
+	}
+	if err != nil {
+		err = errors.Wrap(err, "looking up transaction")
+		return
+	}
+
+	if !tx.Successful {
+		err = errors.Errorf("transaction %s failed in ledger %d", tx.Hash, tx.Ledger)
+		return
+	}
+
+	txSuccess.Links.Transaction = tx.Links.Self
+	txSuccess.Hash = tx.Hash
+	txSuccess.Ledger = tx.Ledger
+	txSuccess.Env = tx.EnvelopeXdr
+	txSuccess.Result = tx.ResultXdr
+	txSuccess.Meta = tx.ResultMetaXdr
+	return txSuccess, true, nil
+}
+
+// isSubmissionTimeout reports whether err means a transaction was sent to horizon but it is
+// unknown whether it was applied.
+func isSubmissionTimeout(err error) bool {
+	switch cause := errors.Cause(err).(type) {
+	case *Error:
+		return cause.Response.StatusCode == http.StatusGatewayTimeout
+	case net.Error:
+		return true
+	}
+	return false
+}
+
+// isBadSequence reports whether err is a tx_bad_seq submission failure.
+func isBadSequence(err error) bool {
+	return isTransactionCode(err, "tx_bad_seq")
+}
+
+// isTransactionCode reports whether err is a submission failure with the given transaction result
+// code.
+func isTransactionCode(err error, code string) bool {
+	herr, ok := errors.Cause(err).(*Error)
+	if !ok {
+		return false
+	}
+	codes, err := herr.ResultCodes()
+	return err == nil && codes.TransactionCode == code
+}
+
+// isNotFound reports whether err is a 404 response from horizon.
+func isNotFound(err error) bool {
+	herr, ok := errors.Cause(err).(*Error)
+	return ok && herr.Response.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,200 @@
+package horizonclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionSubmitterBadSequence(t *testing.T) {
+	kp := keypair.MustParse("SBPQUZ6G4FZNWFHKUWC5BEYWF6R52E3SEP7R3GWYSM2XTKGF5LNTWW4R").(*keypair.Full)
+	client := &MockClient{}
+	submitter := &TransactionSubmitter{Client: client, Signers: []keypair.Signer{kp}}
+	client.On("CheckMemoRequiredWithContext", mock.Anything, mock.Anything).Return(nil)
+
+	var sequences []xdr.SequenceNumber
+	recordSequence := func(args mock.Arguments) {
+		var envelope xdr.TransactionEnvelope
+		require.NoError(t, xdr.SafeUnmarshalBase64(args.String(1), &envelope))
+		sequences = append(sequences, envelope.Tx.SeqNum)
+	}
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{}, submitterError(400, "tx_bad_seq")).Run(recordSequence).Once()
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: kp.Address()}).
+		Return(hProtocol.Account{AccountID: kp.Address(), Sequence: "20"}, nil).Once()
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{Hash: "abc"}, nil).Run(recordSequence).Once()
+
+	resp, err := submitter.Submit(submitterTemplate(kp.Address()))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", resp.Hash)
+	assert.Equal(t, []xdr.SequenceNumber{11, 21}, sequences)
+	client.AssertExpectations(t)
+}
+
+func TestTransactionSubmitterTimeout(t *testing.T) {
+	kp := keypair.MustParse("SBPQUZ6G4FZNWFHKUWC5BEYWF6R52E3SEP7R3GWYSM2XTKGF5LNTWW4R").(*keypair.Full)
+	client := &MockClient{}
+	submitter := &TransactionSubmitter{Client: client, Signers: []keypair.Signer{kp}}
+	client.On("CheckMemoRequiredWithContext", mock.Anything, mock.Anything).Return(nil)
+
+	var envelopes []string
+	recordEnvelope := func(args mock.Arguments) {
+		envelopes = append(envelopes, args.String(1))
+	}
+
+	// the first submission times out and the transaction is unknown: the same envelope is
+	// submitted again, times out again and is then found in a ledger
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{}, submitterError(504, "")).Run(recordEnvelope).Twice()
+	client.On("TransactionDetailWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.Transaction{}, submitterError(404, "")).Once()
+	client.On("TransactionDetailWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.Transaction{Hash: "abc", Ledger: 42, Successful: true}, nil).Once()
+
+	resp, err := submitter.Submit(submitterTemplate(kp.Address()))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", resp.Hash)
+	assert.Equal(t, int32(42), resp.Ledger)
+	require.Len(t, envelopes, 2)
+	assert.Equal(t, envelopes[0], envelopes[1])
+	client.AssertExpectations(t)
+}
+
+func TestTransactionSubmitterGivesUp(t *testing.T) {
+	kp := keypair.MustParse("SBPQUZ6G4FZNWFHKUWC5BEYWF6R52E3SEP7R3GWYSM2XTKGF5LNTWW4R").(*keypair.Full)
+	client := &MockClient{}
+	submitter := &TransactionSubmitter{Client: client, Signers: []keypair.Signer{kp}, MaxAttempts: 2}
+	client.On("CheckMemoRequiredWithContext", mock.Anything, mock.Anything).Return(nil)
+
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{}, submitterError(400, "tx_bad_seq")).Twice()
+	client.On("AccountDetailWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.Account{AccountID: kp.Address(), Sequence: "20"}, nil).Twice()
+
+	_, err := submitter.SubmitWithContext(context.Background(), submitterTemplate(kp.Address()))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "transaction not submitted after 2 attempts")
+		assert.True(t, isBadSequence(err))
+	}
+
+	// other failures are returned as they are
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{}, submitterError(400, "tx_insufficient_balance")).Once()
+
+	_, err = submitter.Submit(submitterTemplate(kp.Address()))
+	if assert.Error(t, err) {
+		herr, ok := errors.Cause(err).(*Error)
+		require.True(t, ok)
+		codes, err := herr.ResultCodes()
+		require.NoError(t, err)
+		assert.Equal(t, "tx_insufficient_balance", codes.TransactionCode)
+	}
+	client.AssertExpectations(t)
+}
+
+func TestTransactionSubmitterRemoteSigner(t *testing.T) {
+	kp := keypair.MustParse("SBPQUZ6G4FZNWFHKUWC5BEYWF6R52E3SEP7R3GWYSM2XTKGF5LNTWW4R").(*keypair.Full)
+	// the stand-in for the remote key store holds the seed of kp
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		var request struct{ Payload []byte }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		signature, err := kp.Sign(request.Payload)
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(w).Encode(map[string][]byte{"signature": signature}))
+	}))
+	defer server.Close()
+	remote, err := keypair.NewRemoteSigner(server.URL, kp.Address())
+	require.NoError(t, err)
+
+	client := &MockClient{}
+	submitter := &TransactionSubmitter{Client: client, Signers: []keypair.Signer{remote}}
+	client.On("CheckMemoRequiredWithContext", mock.Anything, mock.Anything).Return(nil)
+
+	var envelopes []xdr.TransactionEnvelope
+	recordEnvelope := func(args mock.Arguments) {
+		var envelope xdr.TransactionEnvelope
+		require.NoError(t, xdr.SafeUnmarshalBase64(args.String(1), &envelope))
+		envelopes = append(envelopes, envelope)
+	}
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{}, submitterError(400, "tx_bad_seq")).Run(recordEnvelope).Once()
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: kp.Address()}).
+		Return(hProtocol.Account{AccountID: kp.Address(), Sequence: "20"}, nil).Once()
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{Hash: "abc"}, nil).Run(recordEnvelope).Once()
+
+	resp, err := submitter.Submit(submitterTemplate(kp.Address()))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", resp.Hash)
+	client.AssertExpectations(t)
+
+	// the transaction rebuilt after tx_bad_seq is signed again by the remote signer
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	require.Len(t, envelopes, 2)
+	for _, envelope := range envelopes {
+		hash, err := network.HashTransaction(&envelope.Tx, network.TestNetworkPassphrase)
+		require.NoError(t, err)
+		require.Len(t, envelope.Signatures, 1)
+		assert.NoError(t, kp.Verify(hash[:], envelope.Signatures[0].Signature))
+	}
+}
+
+func TestTransactionSubmitterMemoRequired(t *testing.T) {
+	kp := keypair.MustParse("SBPQUZ6G4FZNWFHKUWC5BEYWF6R52E3SEP7R3GWYSM2XTKGF5LNTWW4R").(*keypair.Full)
+	client := &MockClient{}
+	submitter := &TransactionSubmitter{Client: client, Signers: []keypair.Signer{kp}}
+
+	// nothing is submitted to a destination requiring a memo
+	memoErr := &AccountRequiresMemoError{AccountID: kp.Address(), OperationIndex: 0}
+	client.On("CheckMemoRequiredWithContext", mock.Anything, mock.Anything).Return(memoErr).Once()
+	_, err := submitter.Submit(submitterTemplate(kp.Address()))
+	assert.Equal(t, memoErr, err)
+	client.AssertExpectations(t)
+
+	// unless the check is skipped
+	submitter.SkipMemoRequiredCheck = true
+	client.On("SubmitTransactionXDRWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.TransactionSuccess{Hash: "abc"}, nil).Once()
+	resp, err := submitter.Submit(submitterTemplate(kp.Address()))
+	require.NoError(t, err)
+	assert.Equal(t, "abc", resp.Hash)
+	client.AssertExpectations(t)
+}
+
+func submitterTemplate(source string) txnbuild.Transaction {
+	return txnbuild.Transaction{
+		SourceAccount: &txnbuild.SimpleAccount{AccountID: source, Sequence: 10},
+		Operations: []txnbuild.Operation{
+			&txnbuild.BumpSequence{BumpTo: 100},
+		},
+		Timebounds: txnbuild.NewInfiniteTimeout(),
+		Network:    network.TestNetworkPassphrase,
+	}
+}
+
+func submitterError(status int, transactionCode string) *Error {
+	herr := &Error{Response: &http.Response{StatusCode: status}}
+	herr.Problem.Status = status
+	if transactionCode != "" {
+		herr.Problem.Extras = map[string]interface{}{
+			"result_codes": map[string]interface{}{"transaction": transactionCode},
+		}
+	}
+	return herr
+}
@@ -36,10 +36,11 @@ type HTTP interface {
 
 // Response represents the results of successfully resolving a stellar.toml file
 type Response struct {
-	AuthServer       string `toml:"AUTH_SERVER"`
-	FederationServer string `toml:"FEDERATION_SERVER"`
-	EncryptionKey    string `toml:"ENCRYPTION_KEY"`
-	SigningKey       string `toml:"SIGNING_KEY"`
+	AuthServer           string `toml:"AUTH_SERVER"`
+	FederationServer     string `toml:"FEDERATION_SERVER"`
+	EncryptionKey        string `toml:"ENCRYPTION_KEY"`
+	SigningKey           string `toml:"SIGNING_KEY"`
+	URIRequestSigningKey string `toml:"URI_REQUEST_SIGNING_KEY"`
 }
 
 // GetStellarToml returns stellar.toml file for a given domain
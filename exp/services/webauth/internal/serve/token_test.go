@@ -314,13 +314,22 @@ func TestToken_jsonInputValidMultipleSigners(t *testing.T) {
 	h.ServeHTTP(w, r)
 	resp := w.Result()
 
-	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
 	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
 
-	respBodyBytes, err := ioutil.ReadAll(resp.Body)
+	res := struct {
+		Token string `json:"token"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&res)
 	require.NoError(t, err)
 
-	assert.JSONEq(t, `{"error":"The request could not be authenticated."}`, string(respBodyBytes))
+	token, err := jwt.Parse(res.Token, func(token *jwt.Token) (interface{}, error) {
+		return &jwtPrivateKey.PublicKey, nil
+	})
+	require.NoError(t, err)
+
+	claims := token.Claims.(jwt.MapClaims)
+	assert.Equal(t, account.Address(), claims["sub"])
 }
 
 func TestToken_jsonInputNotEnoughWeight(t *testing.T) {
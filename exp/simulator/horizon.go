@@ -0,0 +1,142 @@
+package simulator
+
+import (
+	"encoding/base64"
+	"strconv"
+
+	"github.com/stellar/go/amount"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// AddHorizonAccount adds an account loaded from horizon to the snapshot, along with its trust
+// lines and data entries.
+func (s *Snapshot) AddHorizonAccount(account hProtocol.Account) error {
+	id, err := xdr.AddressToAccountId(account.AccountID)
+	if err != nil {
+		return errors.Wrap(err, "invalid account id")
+	}
+	sequence, err := strconv.ParseInt(account.Sequence, 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "invalid sequence number")
+	}
+
+	entry := xdr.AccountEntry{
+		AccountId:     id,
+		SeqNum:        xdr.SequenceNumber(sequence),
+		NumSubEntries: xdr.Uint32(account.SubentryCount),
+		HomeDomain:    xdr.String32(account.HomeDomain),
+		Thresholds: xdr.Thresholds{
+			0,
+			account.Thresholds.LowThreshold,
+			account.Thresholds.MedThreshold,
+			account.Thresholds.HighThreshold,
+		},
+	}
+	if account.InflationDestination != "" {
+		inflationDest, err := xdr.AddressToAccountId(account.InflationDestination)
+		if err != nil {
+			return errors.Wrap(err, "invalid inflation destination")
+		}
+		entry.InflationDest = &inflationDest
+	}
+	if account.Flags.AuthRequired {
+		entry.Flags |= xdr.Uint32(xdr.AccountFlagsAuthRequiredFlag)
+	}
+	if account.Flags.AuthRevocable {
+		entry.Flags |= xdr.Uint32(xdr.AccountFlagsAuthRevocableFlag)
+	}
+	if account.Flags.AuthImmutable {
+		entry.Flags |= xdr.Uint32(xdr.AccountFlagsAuthImmutableFlag)
+	}
+	for _, signer := range account.Signers {
+		if signer.Key == account.AccountID {
+			entry.Thresholds[0] = byte(signer.Weight)
+			continue
+		}
+		var key xdr.SignerKey
+		if err = key.SetAddress(signer.Key); err != nil {
+			return errors.Wrapf(err, "invalid signer %s", signer.Key)
+		}
+		entry.Signers = append(entry.Signers, xdr.Signer{Key: key, Weight: xdr.Uint32(signer.Weight)})
+	}
+
+	lastModified := xdr.Uint32(account.LastModifiedLedger)
+	var entries []xdr.LedgerEntry
+	for _, balance := range account.Balances {
+		parsed, liabilities, err := parseBalance(balance)
+		if err != nil {
+			return errors.Wrapf(err, "invalid balance of %s", balance.Code)
+		}
+
+		if balance.Type == "native" {
+			entry.Balance = parsed
+			entry.Ext = xdr.AccountEntryExt{V: 1, V1: &xdr.AccountEntryV1{Liabilities: liabilities}}
+			continue
+		}
+
+		asset, err := xdr.BuildAsset(balance.Type, balance.Issuer, balance.Code)
+		if err != nil {
+			return errors.Wrapf(err, "invalid asset %s", balance.Code)
+		}
+		limit, err := amount.Parse(balance.Limit)
+		if err != nil {
+			return errors.Wrapf(err, "invalid limit of %s", balance.Code)
+		}
+		trustLine := xdr.TrustLineEntry{
+			AccountId: id,
+			Asset:     asset,
+			Balance:   parsed,
+			Limit:     limit,
+			Ext:       xdr.TrustLineEntryExt{V: 1, V1: &xdr.TrustLineEntryV1{Liabilities: liabilities}},
+		}
+		if balance.IsAuthorized == nil || *balance.IsAuthorized {
+			trustLine.Flags = xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag)
+		}
+		entries = append(entries, xdr.LedgerEntry{
+			LastModifiedLedgerSeq: xdr.Uint32(balance.LastModifiedLedger),
+			Data:                  xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeTrustline, TrustLine: &trustLine},
+		})
+	}
+
+	for name, value := range account.Data {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid value of data entry %s", name)
+		}
+		entries = append(entries, xdr.LedgerEntry{
+			LastModifiedLedgerSeq: lastModified,
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeData,
+				Data: &xdr.DataEntry{AccountId: id, DataName: xdr.String64(name), DataValue: decoded},
+			},
+		})
+	}
+
+	s.Add(xdr.LedgerEntry{
+		LastModifiedLedgerSeq: lastModified,
+		Data:                  xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeAccount, Account: &entry},
+	})
+	s.Add(entries...)
+	return nil
+}
+
+func parseBalance(balance hProtocol.Balance) (xdr.Int64, xdr.Liabilities, error) {
+	parsed, err := amount.Parse(balance.Balance)
+	if err != nil {
+		return 0, xdr.Liabilities{}, err
+	}
+	var liabilities xdr.Liabilities
+	if balance.BuyingLiabilities != "" {
+		if liabilities.Buying, err = amount.Parse(balance.BuyingLiabilities); err != nil {
+			return 0, xdr.Liabilities{}, err
+		}
+	}
+	if balance.SellingLiabilities != "" {
+		if liabilities.Selling, err = amount.Parse(balance.SellingLiabilities); err != nil {
+			return 0, xdr.Liabilities{}, err
+		}
+	}
+	return parsed, liabilities, nil
+}
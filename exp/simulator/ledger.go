@@ -0,0 +1,209 @@
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/stellar/go/xdr"
+)
+
+// ledgerTxn holds the changes made to the entries of a snapshot while applying a transaction, so
+// that they can be committed at once or dropped, like stellar-core's LedgerTxn.
+//
+// Entries are never modified in place: the entries returned by ledgerTxn are copies, which are
+// stored back once changed.
+type ledgerTxn struct {
+	snapshot *Snapshot
+	// entries maps the keys of the changed entries to their new state, nil for removed entries
+	entries map[string]*xdr.LedgerEntry
+	// keys lists the keys of the changed entries in the order they were first changed
+	keys []xdr.LedgerKey
+}
+
+func newLedgerTxn(snapshot *Snapshot) *ledgerTxn {
+	return &ledgerTxn{
+		snapshot: snapshot,
+		entries:  map[string]*xdr.LedgerEntry{},
+	}
+}
+
+// load returns a copy of the current state of the entry identified by key, nil when the entry
+// does not exist.
+func (l *ledgerTxn) load(key xdr.LedgerKey) *xdr.LedgerEntry {
+	id := keyString(key)
+	if entry, ok := l.entries[id]; ok {
+		if entry == nil {
+			return nil
+		}
+		cp := copyEntry(*entry)
+		return &cp
+	}
+	if entry, ok := l.snapshot.entries[id]; ok {
+		cp := copyEntry(entry)
+		return &cp
+	}
+	return nil
+}
+
+// store creates or updates an entry.
+func (l *ledgerTxn) store(data xdr.LedgerEntryData) {
+	entry := copyEntry(xdr.LedgerEntry{
+		LastModifiedLedgerSeq: xdr.Uint32(l.snapshot.LedgerSequence),
+		Data:                  data,
+	})
+	key := entry.LedgerKey()
+	l.touch(key)
+	l.entries[keyString(key)] = &entry
+}
+
+// remove removes the entry identified by key.
+func (l *ledgerTxn) remove(key xdr.LedgerKey) {
+	l.touch(key)
+	l.entries[keyString(key)] = nil
+}
+
+func (l *ledgerTxn) touch(key xdr.LedgerKey) {
+	if _, ok := l.entries[keyString(key)]; !ok {
+		l.keys = append(l.keys, key)
+	}
+}
+
+// commit applies the changes to the snapshot and returns them the way stellar-core reports them
+// in transaction meta: the state of every updated or removed entry followed by its new state.
+func (l *ledgerTxn) commit() xdr.LedgerEntryChanges {
+	var changes xdr.LedgerEntryChanges
+	for _, key := range l.keys {
+		id := keyString(key)
+		before, existed := l.snapshot.entries[id]
+		after := l.entries[id]
+
+		switch {
+		case !existed && after == nil:
+			continue
+		case !existed:
+			changes = append(changes, xdr.LedgerEntryChange{
+				Type:    xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+				Created: after,
+			})
+		case after == nil:
+			removed := key
+			changes = append(changes,
+				xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &before},
+				xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &removed},
+			)
+		default:
+			changes = append(changes,
+				xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &before},
+				xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: after},
+			)
+		}
+
+		if after == nil {
+			delete(l.snapshot.entries, id)
+		} else {
+			l.snapshot.entries[id] = *after
+		}
+	}
+
+	l.entries = map[string]*xdr.LedgerEntry{}
+	l.keys = nil
+	return changes
+}
+
+func (l *ledgerTxn) account(id xdr.AccountId) *xdr.AccountEntry {
+	entry := l.load(id.LedgerKey())
+	if entry == nil {
+		return nil
+	}
+	return entry.Data.Account
+}
+
+func (l *ledgerTxn) storeAccount(account *xdr.AccountEntry) {
+	l.store(xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeAccount, Account: account})
+}
+
+func (l *ledgerTxn) trustLine(id xdr.AccountId, asset xdr.Asset) *xdr.TrustLineEntry {
+	entry := l.load(trustLineKey(id, asset))
+	if entry == nil {
+		return nil
+	}
+	return entry.Data.TrustLine
+}
+
+func (l *ledgerTxn) storeTrustLine(trustLine *xdr.TrustLineEntry) {
+	l.store(xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeTrustline, TrustLine: trustLine})
+}
+
+func (l *ledgerTxn) data(id xdr.AccountId, name xdr.String64) *xdr.DataEntry {
+	entry := l.load(dataKey(id, name))
+	if entry == nil {
+		return nil
+	}
+	return entry.Data.Data
+}
+
+func (l *ledgerTxn) storeData(data *xdr.DataEntry) {
+	l.store(xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeData, Data: data})
+}
+
+func trustLineKey(id xdr.AccountId, asset xdr.Asset) xdr.LedgerKey {
+	return xdr.LedgerKey{
+		Type:      xdr.LedgerEntryTypeTrustline,
+		TrustLine: &xdr.LedgerKeyTrustLine{AccountId: id, Asset: asset},
+	}
+}
+
+func dataKey(id xdr.AccountId, name xdr.String64) xdr.LedgerKey {
+	return xdr.LedgerKey{
+		Type: xdr.LedgerEntryTypeData,
+		Data: &xdr.LedgerKeyData{AccountId: id, DataName: name},
+	}
+}
+
+// keyString returns a string identifying the entry with the given key, used to index entries.
+func keyString(key xdr.LedgerKey) string {
+	switch key.Type {
+	case xdr.LedgerEntryTypeAccount:
+		return "account/" + key.Account.AccountId.Address()
+	case xdr.LedgerEntryTypeTrustline:
+		return "trustline/" + key.TrustLine.AccountId.Address() + "/" + key.TrustLine.Asset.String()
+	case xdr.LedgerEntryTypeOffer:
+		return fmt.Sprintf("offer/%s/%d", key.Offer.SellerId.Address(), key.Offer.OfferId)
+	case xdr.LedgerEntryTypeData:
+		return "data/" + key.Data.AccountId.Address() + "/" + string(key.Data.DataName)
+	}
+	panic(fmt.Errorf("unknown ledger entry type: %v", key.Type))
+}
+
+// copyEntry returns a copy of entry which does not share its account, trust line, offer or data
+// entry with it.
+func copyEntry(entry xdr.LedgerEntry) xdr.LedgerEntry {
+	switch entry.Data.Type {
+	case xdr.LedgerEntryTypeAccount:
+		account := *entry.Data.Account
+		entry.Data.Account = &account
+	case xdr.LedgerEntryTypeTrustline:
+		trustLine := *entry.Data.TrustLine
+		entry.Data.TrustLine = &trustLine
+	case xdr.LedgerEntryTypeOffer:
+		offer := *entry.Data.Offer
+		entry.Data.Offer = &offer
+	case xdr.LedgerEntryTypeData:
+		data := *entry.Data.Data
+		entry.Data.Data = &data
+	}
+	return entry
+}
+
+func accountLiabilities(account *xdr.AccountEntry) xdr.Liabilities {
+	if account.Ext.V1 == nil {
+		return xdr.Liabilities{}
+	}
+	return account.Ext.V1.Liabilities
+}
+
+func trustLineLiabilities(trustLine *xdr.TrustLineEntry) xdr.Liabilities {
+	if trustLine.Ext.V1 == nil {
+		return xdr.Liabilities{}
+	}
+	return trustLine.Ext.V1.Liabilities
+}
@@ -0,0 +1,290 @@
+// Package simulator predicts the outcome of a transaction before it is submitted, by applying it
+// to a snapshot of the ledger entries it touches: the accounts, trust lines, offers and data
+// entries loaded from horizon or supplied by hand.
+//
+// The simulator applies create_account, payment, change_trust, manage_data and set_options
+// operations with the reserve, liability and limit rules of stellar-core, and reports the
+// predicted result codes along with the ledger changes, in the same shape as the metadata of
+// transactions applied by the network. Signatures and time bounds are not checked.
+package simulator
+
+import (
+	"math"
+
+	"github.com/stellar/go/meta"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+// DefaultBaseReserve is the base reserve of the network, in stroops.
+const DefaultBaseReserve = 5000000
+
+// Snapshot holds the state of the ledger entries a transaction is applied to. Offers only matter
+// through the liabilities they add to the accounts and trust lines of their sellers, which must be
+// set on those entries as they are in the ledger.
+type Snapshot struct {
+	// BaseReserve is the base reserve, in stroops, used to compute the minimum balance of
+	// accounts. It defaults to DefaultBaseReserve.
+	BaseReserve xdr.Int64
+	// LedgerSequence is the sequence of the ledger the transaction is applied in. It is used as
+	// the last modified ledger of changed entries and to compute the sequence number of created
+	// accounts.
+	LedgerSequence uint32
+
+	entries map[string]xdr.LedgerEntry
+}
+
+// NewSnapshot returns a snapshot holding entries.
+func NewSnapshot(entries ...xdr.LedgerEntry) *Snapshot {
+	snapshot := &Snapshot{entries: map[string]xdr.LedgerEntry{}}
+	snapshot.Add(entries...)
+	return snapshot
+}
+
+// Add adds entries to the snapshot, replacing the entries with the same keys.
+func (s *Snapshot) Add(entries ...xdr.LedgerEntry) {
+	for _, entry := range entries {
+		s.entries[keyString(entry.LedgerKey())] = copyEntry(entry)
+	}
+}
+
+// Entry returns the entry identified by key.
+func (s *Snapshot) Entry(key xdr.LedgerKey) (xdr.LedgerEntry, bool) {
+	entry, ok := s.entries[keyString(key)]
+	if !ok {
+		return xdr.LedgerEntry{}, false
+	}
+	return copyEntry(entry), true
+}
+
+// Account returns the account with the given address.
+func (s *Snapshot) Account(address string) (xdr.AccountEntry, bool) {
+	id, err := xdr.AddressToAccountId(address)
+	if err != nil {
+		return xdr.AccountEntry{}, false
+	}
+	entry, ok := s.Entry(id.LedgerKey())
+	if !ok {
+		return xdr.AccountEntry{}, false
+	}
+	return *entry.Data.Account, true
+}
+
+// Balance returns the balance of asset held by the account with the given address. It reports
+// false when the account does not exist, or has no trust line to asset.
+func (s *Snapshot) Balance(address string, asset xdr.Asset) (xdr.Int64, bool) {
+	id, err := xdr.AddressToAccountId(address)
+	if err != nil {
+		return 0, false
+	}
+	if asset.Type == xdr.AssetTypeAssetTypeNative {
+		account, ok := s.Account(address)
+		return account.Balance, ok
+	}
+	entry, ok := s.Entry(trustLineKey(id, asset))
+	if !ok {
+		return 0, false
+	}
+	return entry.Data.TrustLine.Balance, true
+}
+
+func (s *Snapshot) clone() *Snapshot {
+	cp := *s
+	cp.entries = make(map[string]xdr.LedgerEntry, len(s.entries))
+	for key, entry := range s.entries {
+		cp.entries[key] = entry
+	}
+	return &cp
+}
+
+func (s *Snapshot) baseReserve() xdr.Int64 {
+	if s.BaseReserve == 0 {
+		return DefaultBaseReserve
+	}
+	return s.BaseReserve
+}
+
+// minBalance returns the minimum balance of an account with numSubEntries subentries.
+func (s *Snapshot) minBalance(numSubEntries xdr.Uint32) xdr.Int64 {
+	return (2 + xdr.Int64(numSubEntries)) * s.baseReserve()
+}
+
+// availableBalance returns the lumens account can spend without going below its minimum balance.
+func (s *Snapshot) availableBalance(account *xdr.AccountEntry) xdr.Int64 {
+	return account.Balance - s.minBalance(account.NumSubEntries) - accountLiabilities(account).Selling
+}
+
+// Result is the predicted outcome of a transaction.
+type Result struct {
+	// TransactionResult holds the result code of the transaction, the fee charged and the
+	// result codes of its operations.
+	TransactionResult xdr.TransactionResult
+	// Bundle holds the changes made to the ledger entries: the fee charged, the sequence number
+	// consumed and, when the transaction succeeds, the changes made by each operation.
+	Bundle meta.Bundle
+	// Snapshot holds the state of the ledger entries after the transaction is applied.
+	Snapshot *Snapshot
+}
+
+// Successful reports whether the transaction is predicted to succeed.
+func (r Result) Successful() bool {
+	return r.TransactionResult.Result.Code == xdr.TransactionResultCodeTxSuccess
+}
+
+// OperationResults returns the predicted results of the operations of the transaction, nil when
+// the transaction is rejected before its operations are applied.
+func (r Result) OperationResults() []xdr.OperationResult {
+	if r.TransactionResult.Result.Results == nil {
+		return nil
+	}
+	return *r.TransactionResult.Result.Results
+}
+
+// Simulate applies a previously built transaction to snapshot. snapshot is left unchanged; the
+// resulting state is returned in the Snapshot of the result.
+func Simulate(tx *txnbuild.Transaction, snapshot *Snapshot) (Result, error) {
+	envelope := tx.TxEnvelope()
+	if envelope == nil {
+		return Result{}, errors.New("transaction has not been built")
+	}
+	return SimulateXDR(envelope.Tx, snapshot)
+}
+
+// SimulateXDR applies tx to snapshot. snapshot is left unchanged; the resulting state is returned
+// in the Snapshot of the result.
+func SimulateXDR(tx xdr.Transaction, snapshot *Snapshot) (Result, error) {
+	state := snapshot.clone()
+	result := Result{
+		Bundle:   meta.Bundle{TransactionMeta: xdr.TransactionMeta{V: 1, V1: &xdr.TransactionMetaV1{}}},
+		Snapshot: state,
+	}
+	reject := func(code xdr.TransactionResultCode) (Result, error) {
+		result.TransactionResult.Result.Code = code
+		return result, nil
+	}
+
+	// unsupported operations are reported before any check, whose result would be meaningless
+	for i, op := range tx.Operations {
+		if err := checkSupported(op); err != nil {
+			return Result{}, errors.Wrapf(err, "operation %d", i)
+		}
+	}
+
+	if len(tx.Operations) == 0 {
+		return reject(xdr.TransactionResultCodeTxMissingOperation)
+	}
+	if int64(tx.Fee) < int64(len(tx.Operations))*txnbuild.MinBaseFee {
+		return reject(xdr.TransactionResultCodeTxInsufficientFee)
+	}
+
+	ltx := newLedgerTxn(state)
+	source := ltx.account(tx.SourceAccount)
+	if source == nil {
+		return reject(xdr.TransactionResultCodeTxNoAccount)
+	}
+	if tx.SeqNum != source.SeqNum+1 {
+		return reject(xdr.TransactionResultCodeTxBadSeq)
+	}
+	if state.availableBalance(source) < xdr.Int64(tx.Fee) {
+		return reject(xdr.TransactionResultCodeTxInsufficientBalance)
+	}
+
+	results := make([]xdr.OperationResult, len(tx.Operations))
+	valid := true
+	for i, op := range tx.Operations {
+		opResult, err := checkOperation(operationSource(tx, op), op)
+		if err != nil {
+			return Result{}, errors.Wrapf(err, "operation %d", i)
+		}
+		if !valid {
+			// like stellar-core, the operations after the first invalid one are not checked
+			opResult = successResult(op.Body.Type)
+		}
+		valid = valid && operationSucceeded(opResult)
+		results[i] = opResult
+	}
+	result.TransactionResult.Result.Results = &results
+	if !valid {
+		return reject(xdr.TransactionResultCodeTxFailed)
+	}
+
+	// the fee is charged and the sequence number consumed even when an operation fails
+	source.Balance -= xdr.Int64(tx.Fee)
+	ltx.storeAccount(source)
+	result.Bundle.FeeMeta = ltx.commit()
+	result.TransactionResult.FeeCharged = xdr.Int64(tx.Fee)
+
+	source = ltx.account(tx.SourceAccount)
+	source.SeqNum = tx.SeqNum
+	ltx.storeAccount(source)
+	result.Bundle.TransactionMeta.V1.TxChanges = ltx.commit()
+
+	// the operations are applied to a copy of the state, which is dropped when one of them fails.
+	// Like stellar-core, the changes of a failed operation are discarded but the next operations
+	// are still applied on top of the changes of the successful ones, to report their results.
+	opState := state.clone()
+	opLtx := newLedgerTxn(opState)
+	success := true
+	operations := make([]xdr.OperationMeta, len(tx.Operations))
+	for i, op := range tx.Operations {
+		results[i] = applyOperation(opLtx, operationSource(tx, op), op)
+		if operationSucceeded(results[i]) {
+			operations[i].Changes = opLtx.commit()
+		} else {
+			success = false
+			opLtx = newLedgerTxn(opState)
+		}
+	}
+
+	if !success {
+		return reject(xdr.TransactionResultCodeTxFailed)
+	}
+	result.Bundle.TransactionMeta.V1.Operations = operations
+	result.Snapshot = opState
+	result.TransactionResult.Result.Code = xdr.TransactionResultCodeTxSuccess
+	return result, nil
+}
+
+// operationSource returns the source account of op, which defaults to the source account of tx.
+func operationSource(tx xdr.Transaction, op xdr.Operation) xdr.AccountId {
+	if op.SourceAccount != nil {
+		return *op.SourceAccount
+	}
+	return tx.SourceAccount
+}
+
+// operationSucceeded reports whether result is the success result of its operation.
+func operationSucceeded(result xdr.OperationResult) bool {
+	if result.Code != xdr.OperationResultCodeOpInner {
+		return false
+	}
+	tr := result.Tr
+	switch tr.Type {
+	case xdr.OperationTypeCreateAccount:
+		return tr.CreateAccountResult.Code == xdr.CreateAccountResultCodeCreateAccountSuccess
+	case xdr.OperationTypePayment:
+		return tr.PaymentResult.Code == xdr.PaymentResultCodePaymentSuccess
+	case xdr.OperationTypeChangeTrust:
+		return tr.ChangeTrustResult.Code == xdr.ChangeTrustResultCodeChangeTrustSuccess
+	case xdr.OperationTypeManageData:
+		return tr.ManageDataResult.Code == xdr.ManageDataResultCodeManageDataSuccess
+	case xdr.OperationTypeSetOptions:
+		return tr.SetOptionsResult.Code == xdr.SetOptionsResultCodeSetOptionsSuccess
+	}
+	return false
+}
+
+// addBalance adds delta to balance, reporting false when the result would be negative or higher
+// than limit.
+func addBalance(balance *xdr.Int64, delta, limit xdr.Int64) bool {
+	if delta > 0 && *balance > math.MaxInt64-delta {
+		return false
+	}
+	newBalance := *balance + delta
+	if newBalance < 0 || newBalance > limit {
+		return false
+	}
+	*balance = newBalance
+	return true
+}
@@ -0,0 +1,271 @@
+package simulator
+
+import (
+	"testing"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/base"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func accountEntry(address string, balance xdr.Int64, sequence xdr.SequenceNumber) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{
+				AccountId:  xdr.MustAddress(address),
+				Balance:    balance,
+				SeqNum:     sequence,
+				Thresholds: xdr.Thresholds{1, 0, 0, 0},
+			},
+		},
+	}
+}
+
+func buildTx(t *testing.T, source string, sequence int64, ops ...txnbuild.Operation) *txnbuild.Transaction {
+	tx := &txnbuild.Transaction{
+		SourceAccount: &txnbuild.SimpleAccount{AccountID: source, Sequence: sequence},
+		Operations:    ops,
+		Timebounds:    txnbuild.NewInfiniteTimeout(),
+		Network:       network.TestNetworkPassphrase,
+	}
+	require.NoError(t, tx.Build())
+	return tx
+}
+
+func TestSimulateSuccess(t *testing.T) {
+	source := keypair.MustRandom().Address()
+	issuer := keypair.MustRandom().Address()
+	destination := keypair.MustRandom().Address()
+	usd := txnbuild.CreditAsset{Code: "USD", Issuer: issuer}
+	xdrUSD := xdr.MustNewCreditAsset("USD", issuer)
+
+	snapshot := NewSnapshot(
+		accountEntry(source, 1000000000, 100),
+		accountEntry(issuer, 100000000, 200),
+	)
+	snapshot.LedgerSequence = 300
+
+	tx := buildTx(t, source, 100,
+		&txnbuild.CreateAccount{Destination: destination, Amount: "10"},
+		&txnbuild.ChangeTrust{Line: usd},
+		&txnbuild.ManageData{Name: "name", Value: []byte("value")},
+		&txnbuild.SetOptions{Signer: &txnbuild.Signer{Address: issuer, Weight: 1}},
+		&txnbuild.Payment{
+			Destination:   source,
+			Amount:        "50",
+			Asset:         usd,
+			SourceAccount: &txnbuild.SimpleAccount{AccountID: issuer},
+		},
+	)
+
+	result, err := Simulate(tx, snapshot)
+	require.NoError(t, err)
+	require.True(t, result.Successful())
+	assert.Equal(t, xdr.Int64(500), result.TransactionResult.FeeCharged)
+	for _, opResult := range result.OperationResults() {
+		assert.True(t, operationSucceeded(opResult))
+	}
+
+	state := result.Snapshot
+	balance, ok := state.Balance(source, xdr.MustNewNativeAsset())
+	require.True(t, ok)
+	assert.Equal(t, xdr.Int64(1000000000-500-100000000), balance)
+	balance, ok = state.Balance(source, xdrUSD)
+	require.True(t, ok)
+	assert.Equal(t, xdr.Int64(500000000), balance)
+	balance, ok = state.Balance(destination, xdr.MustNewNativeAsset())
+	require.True(t, ok)
+	assert.Equal(t, xdr.Int64(100000000), balance)
+
+	account, ok := state.Account(source)
+	require.True(t, ok)
+	assert.Equal(t, xdr.SequenceNumber(101), account.SeqNum)
+	assert.Equal(t, xdr.Uint32(3), account.NumSubEntries)
+	assert.Equal(t, map[string]int32{source: 1, issuer: 1}, account.SignerSummary())
+
+	created, ok := state.Account(destination)
+	require.True(t, ok)
+	assert.Equal(t, xdr.SequenceNumber(300<<32), created.SeqNum)
+
+	// the changes are reported the way stellar-core reports them in transaction meta
+	sourceID := xdr.MustAddress(source)
+	destinationID := xdr.MustAddress(destination)
+	assert.Len(t, result.Bundle.OperationsMetas(), 5)
+	entry, err := result.Bundle.StateAfter(destinationID.LedgerKey(), 0)
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, xdr.Uint32(300), entry.LastModifiedLedgerSeq)
+	entry, err = result.Bundle.StateBefore(sourceID.LedgerKey(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, xdr.Int64(1000000000-500), entry.Data.Account.Balance)
+
+	// the original snapshot is unchanged
+	account, ok = snapshot.Account(source)
+	require.True(t, ok)
+	assert.Equal(t, xdr.Int64(1000000000), account.Balance)
+	_, ok = snapshot.Account(destination)
+	assert.False(t, ok)
+}
+
+func TestSimulateFailedOperation(t *testing.T) {
+	source := keypair.MustRandom().Address()
+	issuer := keypair.MustRandom().Address()
+	usd := txnbuild.CreditAsset{Code: "USD", Issuer: issuer}
+
+	snapshot := NewSnapshot(
+		accountEntry(source, 15000000, 100),
+		accountEntry(issuer, 100000000, 200),
+	)
+
+	tx := buildTx(t, source, 100,
+		&txnbuild.Payment{Destination: issuer, Amount: "0.4", Asset: txnbuild.NativeAsset{}},
+		&txnbuild.ChangeTrust{Line: usd},
+		&txnbuild.Payment{Destination: issuer, Amount: "1", Asset: usd},
+	)
+
+	result, err := Simulate(tx, snapshot)
+	require.NoError(t, err)
+	assert.False(t, result.Successful())
+	assert.Equal(t, xdr.TransactionResultCodeTxFailed, result.TransactionResult.Result.Code)
+	assert.Equal(t, xdr.Int64(300), result.TransactionResult.FeeCharged)
+
+	opResults := result.OperationResults()
+	require.Len(t, opResults, 3)
+	assert.Equal(t, xdr.PaymentResultCodePaymentSuccess, opResults[0].Tr.PaymentResult.Code)
+	assert.Equal(t, xdr.ChangeTrustResultCodeChangeTrustLowReserve, opResults[1].Tr.ChangeTrustResult.Code)
+	assert.Equal(t, xdr.PaymentResultCodePaymentSrcNoTrust, opResults[2].Tr.PaymentResult.Code)
+
+	// only the fee and the sequence number are consumed
+	assert.Empty(t, result.Bundle.OperationsMetas())
+	account, ok := result.Snapshot.Account(source)
+	require.True(t, ok)
+	assert.Equal(t, xdr.Int64(15000000-300), account.Balance)
+	assert.Equal(t, xdr.SequenceNumber(101), account.SeqNum)
+	balance, ok := result.Snapshot.Balance(issuer, xdr.MustNewNativeAsset())
+	require.True(t, ok)
+	assert.Equal(t, xdr.Int64(100000000), balance)
+}
+
+func TestSimulateOperationsAfterFailure(t *testing.T) {
+	source := keypair.MustRandom().Address()
+	issuer := keypair.MustRandom().Address()
+	destination := keypair.MustRandom().Address()
+	usd := txnbuild.CreditAsset{Code: "USD", Issuer: issuer}
+
+	snapshot := NewSnapshot(
+		accountEntry(source, 1000000000, 100),
+		accountEntry(issuer, 100000000, 200),
+	)
+
+	// the last payment succeeds because the account created by the first operation is kept after
+	// the second operation failed
+	tx := buildTx(t, source, 100,
+		&txnbuild.CreateAccount{Destination: destination, Amount: "10"},
+		&txnbuild.Payment{Destination: issuer, Amount: "1", Asset: usd},
+		&txnbuild.Payment{Destination: destination, Amount: "1", Asset: txnbuild.NativeAsset{}},
+	)
+
+	result, err := Simulate(tx, snapshot)
+	require.NoError(t, err)
+	assert.False(t, result.Successful())
+
+	opResults := result.OperationResults()
+	require.Len(t, opResults, 3)
+	assert.Equal(t, xdr.CreateAccountResultCodeCreateAccountSuccess, opResults[0].Tr.CreateAccountResult.Code)
+	assert.Equal(t, xdr.PaymentResultCodePaymentSrcNoTrust, opResults[1].Tr.PaymentResult.Code)
+	assert.Equal(t, xdr.PaymentResultCodePaymentSuccess, opResults[2].Tr.PaymentResult.Code)
+
+	// none of the operations are applied
+	_, ok := result.Snapshot.Account(destination)
+	assert.False(t, ok)
+	account, ok := result.Snapshot.Account(source)
+	require.True(t, ok)
+	assert.Equal(t, xdr.Int64(1000000000-300), account.Balance)
+}
+
+func TestSimulateRejected(t *testing.T) {
+	source := keypair.MustRandom().Address()
+	snapshot := NewSnapshot(accountEntry(source, 100000000, 100))
+
+	_, err := Simulate(buildTx(t, source, 101, &txnbuild.BumpSequence{BumpTo: 1}), snapshot)
+	assert.EqualError(t, err, "operation 0: OperationTypeBumpSequence operations are not supported")
+
+	// unsupported operations are reported before the transaction is rejected
+	_, err = Simulate(buildTx(t, keypair.MustRandom().Address(), 101, &txnbuild.ManageData{Name: "name"}, &txnbuild.BumpSequence{BumpTo: 1}), snapshot)
+	assert.EqualError(t, err, "operation 1: OperationTypeBumpSequence operations are not supported")
+
+	result, err := Simulate(buildTx(t, source, 101, &txnbuild.ManageData{Name: "name"}), snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, xdr.TransactionResultCodeTxBadSeq, result.TransactionResult.Result.Code)
+	assert.Nil(t, result.OperationResults())
+
+	result, err = Simulate(buildTx(t, keypair.MustRandom().Address(), 100, &txnbuild.ManageData{Name: "name"}), snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, xdr.TransactionResultCodeTxNoAccount, result.TransactionResult.Result.Code)
+
+	// invalid operations are rejected before the fee is charged
+	tx := buildTx(t, source, 100,
+		&txnbuild.SetOptions{SetFlags: []txnbuild.AccountFlag{txnbuild.AuthRequired}, ClearFlags: []txnbuild.AccountFlag{txnbuild.AuthRequired}},
+		&txnbuild.ManageData{Name: "name"},
+	)
+	result, err = Simulate(tx, snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, xdr.TransactionResultCodeTxFailed, result.TransactionResult.Result.Code)
+	assert.Equal(t, xdr.Int64(0), result.TransactionResult.FeeCharged)
+	opResults := result.OperationResults()
+	require.Len(t, opResults, 2)
+	assert.Equal(t, xdr.SetOptionsResultCodeSetOptionsBadFlags, opResults[0].Tr.SetOptionsResult.Code)
+	assert.Equal(t, xdr.ManageDataResultCodeManageDataSuccess, opResults[1].Tr.ManageDataResult.Code)
+	account, ok := result.Snapshot.Account(source)
+	require.True(t, ok)
+	assert.Equal(t, xdr.SequenceNumber(100), account.SeqNum)
+}
+
+func TestAddHorizonAccount(t *testing.T) {
+	source := keypair.MustRandom().Address()
+	issuer := keypair.MustRandom().Address()
+	usd := txnbuild.CreditAsset{Code: "USD", Issuer: issuer}
+
+	snapshot := NewSnapshot(accountEntry(issuer, 100000000, 200))
+	err := snapshot.AddHorizonAccount(hProtocol.Account{
+		AccountID:     source,
+		Sequence:      "100",
+		SubentryCount: 2,
+		Balances: []hProtocol.Balance{
+			{Balance: "10.0000000", Asset: base.Asset{Type: "native"}},
+			{
+				Balance:           "95.0000000",
+				Limit:             "100.0000000",
+				BuyingLiabilities: "2.0000000",
+				Asset:             base.Asset{Type: "credit_alphanum4", Code: "USD", Issuer: issuer},
+			},
+		},
+		Signers: []hProtocol.Signer{{Key: source, Weight: 1}},
+		Data:    map[string]string{"name": "dmFsdWU="},
+	})
+	require.NoError(t, err)
+
+	entry, ok := snapshot.Entry(dataKey(xdr.MustAddress(source), "name"))
+	require.True(t, ok)
+	assert.Equal(t, xdr.DataValue("value"), entry.Data.Data.DataValue)
+
+	// the buying liabilities leave room for 3 USD
+	payment := &txnbuild.Payment{Destination: source, Amount: "5", Asset: usd}
+	result, err := Simulate(buildTx(t, issuer, 200, payment), snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, xdr.PaymentResultCodePaymentLineFull, result.OperationResults()[0].Tr.PaymentResult.Code)
+
+	payment.Amount = "3"
+	result, err = Simulate(buildTx(t, issuer, 200, payment), snapshot)
+	require.NoError(t, err)
+	require.True(t, result.Successful())
+	balance, ok := result.Snapshot.Balance(source, xdr.MustNewCreditAsset("USD", issuer))
+	require.True(t, ok)
+	assert.Equal(t, xdr.Int64(980000000), balance)
+}
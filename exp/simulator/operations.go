@@ -0,0 +1,406 @@
+package simulator
+
+import (
+	"math"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// maxSigners is the maximum number of signers of an account, besides its master key.
+const maxSigners = 20
+
+// checkSupported returns an error if the simulator cannot apply operations of the type of op.
+func checkSupported(op xdr.Operation) error {
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount,
+		xdr.OperationTypePayment,
+		xdr.OperationTypeChangeTrust,
+		xdr.OperationTypeManageData,
+		xdr.OperationTypeSetOptions:
+		return nil
+	}
+	return errors.Errorf("%s operations are not supported", op.Body.Type)
+}
+
+// checkOperation returns the result of the checks stellar-core runs on op before a transaction is
+// accepted, which do not depend on the state of the ledger.
+func checkOperation(source xdr.AccountId, op xdr.Operation) (xdr.OperationResult, error) {
+	body := op.Body
+	var code int32
+	switch body.Type {
+	case xdr.OperationTypeCreateAccount:
+		code = int32(checkCreateAccount(source, *body.CreateAccountOp))
+	case xdr.OperationTypePayment:
+		code = int32(checkPayment(*body.PaymentOp))
+	case xdr.OperationTypeChangeTrust:
+		code = int32(checkChangeTrust(*body.ChangeTrustOp))
+	case xdr.OperationTypeManageData:
+		code = int32(checkManageData(*body.ManageDataOp))
+	case xdr.OperationTypeSetOptions:
+		code = int32(checkSetOptions(source, *body.SetOptionsOp))
+	default:
+		return xdr.OperationResult{}, checkSupported(op)
+	}
+	return operationResult(body.Type, code), nil
+}
+
+// applyOperation applies op, whose source account is source, and returns its result.
+func applyOperation(ltx *ledgerTxn, source xdr.AccountId, op xdr.Operation) xdr.OperationResult {
+	if ltx.account(source) == nil {
+		return xdr.OperationResult{Code: xdr.OperationResultCodeOpNoAccount}
+	}
+
+	body := op.Body
+	var code int32
+	switch body.Type {
+	case xdr.OperationTypeCreateAccount:
+		code = int32(applyCreateAccount(ltx, source, *body.CreateAccountOp))
+	case xdr.OperationTypePayment:
+		code = int32(applyPayment(ltx, source, *body.PaymentOp))
+	case xdr.OperationTypeChangeTrust:
+		code = int32(applyChangeTrust(ltx, source, *body.ChangeTrustOp))
+	case xdr.OperationTypeManageData:
+		code = int32(applyManageData(ltx, source, *body.ManageDataOp))
+	case xdr.OperationTypeSetOptions:
+		code = int32(applySetOptions(ltx, source, *body.SetOptionsOp))
+	}
+	return operationResult(body.Type, code)
+}
+
+func checkCreateAccount(source xdr.AccountId, op xdr.CreateAccountOp) xdr.CreateAccountResultCode {
+	if op.StartingBalance <= 0 || op.Destination.Equals(source) {
+		return xdr.CreateAccountResultCodeCreateAccountMalformed
+	}
+	return xdr.CreateAccountResultCodeCreateAccountSuccess
+}
+
+func applyCreateAccount(ltx *ledgerTxn, source xdr.AccountId, op xdr.CreateAccountOp) xdr.CreateAccountResultCode {
+	if ltx.account(op.Destination) != nil {
+		return xdr.CreateAccountResultCodeCreateAccountAlreadyExist
+	}
+	if op.StartingBalance < ltx.snapshot.minBalance(0) {
+		return xdr.CreateAccountResultCodeCreateAccountLowReserve
+	}
+
+	account := ltx.account(source)
+	if ltx.snapshot.availableBalance(account) < op.StartingBalance {
+		return xdr.CreateAccountResultCodeCreateAccountUnderfunded
+	}
+	account.Balance -= op.StartingBalance
+	ltx.storeAccount(account)
+
+	ltx.storeAccount(&xdr.AccountEntry{
+		AccountId:  op.Destination,
+		Balance:    op.StartingBalance,
+		SeqNum:     xdr.SequenceNumber(int64(ltx.snapshot.LedgerSequence) << 32),
+		Thresholds: xdr.Thresholds{1, 0, 0, 0},
+	})
+	return xdr.CreateAccountResultCodeCreateAccountSuccess
+}
+
+func checkPayment(op xdr.PaymentOp) xdr.PaymentResultCode {
+	if op.Amount <= 0 {
+		return xdr.PaymentResultCodePaymentMalformed
+	}
+	return xdr.PaymentResultCodePaymentSuccess
+}
+
+func applyPayment(ltx *ledgerTxn, source xdr.AccountId, op xdr.PaymentOp) xdr.PaymentResultCode {
+	native := op.Asset.Type == xdr.AssetTypeAssetTypeNative
+	if native && op.Destination.Equals(source) {
+		return xdr.PaymentResultCodePaymentSuccess
+	}
+	destination := ltx.account(op.Destination)
+	if destination == nil {
+		return xdr.PaymentResultCodePaymentNoDestination
+	}
+
+	if native {
+		limit := xdr.Int64(math.MaxInt64) - accountLiabilities(destination).Buying
+		if !addBalance(&destination.Balance, op.Amount, limit) {
+			return xdr.PaymentResultCodePaymentLineFull
+		}
+		ltx.storeAccount(destination)
+
+		account := ltx.account(source)
+		if ltx.snapshot.availableBalance(account) < op.Amount {
+			return xdr.PaymentResultCodePaymentUnderfunded
+		}
+		account.Balance -= op.Amount
+		ltx.storeAccount(account)
+		return xdr.PaymentResultCodePaymentSuccess
+	}
+
+	// the issuer of the asset has no trust line: it creates the asset it sends and destroys the
+	// asset it receives
+	issuer := assetIssuer(op.Asset)
+	if !issuer.Equals(source) && !issuer.Equals(op.Destination) && ltx.account(issuer) == nil {
+		return xdr.PaymentResultCodePaymentNoIssuer
+	}
+
+	if !issuer.Equals(op.Destination) {
+		trustLine := ltx.trustLine(op.Destination, op.Asset)
+		if trustLine == nil {
+			return xdr.PaymentResultCodePaymentNoTrust
+		}
+		if !xdr.TrustLineFlags(trustLine.Flags).IsAuthorized() {
+			return xdr.PaymentResultCodePaymentNotAuthorized
+		}
+		limit := trustLine.Limit - trustLineLiabilities(trustLine).Buying
+		if !addBalance(&trustLine.Balance, op.Amount, limit) {
+			return xdr.PaymentResultCodePaymentLineFull
+		}
+		ltx.storeTrustLine(trustLine)
+	}
+
+	if !issuer.Equals(source) {
+		trustLine := ltx.trustLine(source, op.Asset)
+		if trustLine == nil {
+			return xdr.PaymentResultCodePaymentSrcNoTrust
+		}
+		if !xdr.TrustLineFlags(trustLine.Flags).IsAuthorized() {
+			return xdr.PaymentResultCodePaymentSrcNotAuthorized
+		}
+		if trustLine.Balance-trustLineLiabilities(trustLine).Selling < op.Amount {
+			return xdr.PaymentResultCodePaymentUnderfunded
+		}
+		trustLine.Balance -= op.Amount
+		ltx.storeTrustLine(trustLine)
+	}
+
+	return xdr.PaymentResultCodePaymentSuccess
+}
+
+func checkChangeTrust(op xdr.ChangeTrustOp) xdr.ChangeTrustResultCode {
+	if op.Line.Type == xdr.AssetTypeAssetTypeNative || op.Limit < 0 {
+		return xdr.ChangeTrustResultCodeChangeTrustMalformed
+	}
+	return xdr.ChangeTrustResultCodeChangeTrustSuccess
+}
+
+func applyChangeTrust(ltx *ledgerTxn, source xdr.AccountId, op xdr.ChangeTrustOp) xdr.ChangeTrustResultCode {
+	issuer := assetIssuer(op.Line)
+	if issuer.Equals(source) {
+		return xdr.ChangeTrustResultCodeChangeTrustMalformed
+	}
+
+	trustLine := ltx.trustLine(source, op.Line)
+	if trustLine != nil {
+		if op.Limit < trustLine.Balance+trustLineLiabilities(trustLine).Buying {
+			return xdr.ChangeTrustResultCodeChangeTrustInvalidLimit
+		}
+		if op.Limit > 0 {
+			trustLine.Limit = op.Limit
+			ltx.storeTrustLine(trustLine)
+			return xdr.ChangeTrustResultCodeChangeTrustSuccess
+		}
+
+		ltx.remove(trustLineKey(source, op.Line))
+		account := ltx.account(source)
+		account.NumSubEntries--
+		ltx.storeAccount(account)
+		return xdr.ChangeTrustResultCodeChangeTrustSuccess
+	}
+
+	if op.Limit == 0 {
+		return xdr.ChangeTrustResultCodeChangeTrustInvalidLimit
+	}
+	issuerAccount := ltx.account(issuer)
+	if issuerAccount == nil {
+		return xdr.ChangeTrustResultCodeChangeTrustNoIssuer
+	}
+	account := ltx.account(source)
+	if !addSubEntry(ltx.snapshot, account) {
+		return xdr.ChangeTrustResultCodeChangeTrustLowReserve
+	}
+	ltx.storeAccount(account)
+
+	var flags xdr.Uint32
+	if !xdr.AccountFlags(issuerAccount.Flags).IsAuthRequired() {
+		flags = xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag)
+	}
+	ltx.storeTrustLine(&xdr.TrustLineEntry{
+		AccountId: source,
+		Asset:     op.Line,
+		Limit:     op.Limit,
+		Flags:     flags,
+	})
+	return xdr.ChangeTrustResultCodeChangeTrustSuccess
+}
+
+func checkManageData(op xdr.ManageDataOp) xdr.ManageDataResultCode {
+	if len(op.DataName) == 0 || !validString(string(op.DataName)) {
+		return xdr.ManageDataResultCodeManageDataInvalidName
+	}
+	return xdr.ManageDataResultCodeManageDataSuccess
+}
+
+func applyManageData(ltx *ledgerTxn, source xdr.AccountId, op xdr.ManageDataOp) xdr.ManageDataResultCode {
+	account := ltx.account(source)
+	data := ltx.data(source, op.DataName)
+
+	if op.DataValue == nil {
+		if data == nil {
+			return xdr.ManageDataResultCodeManageDataNameNotFound
+		}
+		ltx.remove(dataKey(source, op.DataName))
+		account.NumSubEntries--
+		ltx.storeAccount(account)
+		return xdr.ManageDataResultCodeManageDataSuccess
+	}
+
+	if data == nil {
+		if !addSubEntry(ltx.snapshot, account) {
+			return xdr.ManageDataResultCodeManageDataLowReserve
+		}
+		ltx.storeAccount(account)
+		data = &xdr.DataEntry{AccountId: source, DataName: op.DataName}
+	}
+	data.DataValue = *op.DataValue
+	ltx.storeData(data)
+	return xdr.ManageDataResultCodeManageDataSuccess
+}
+
+func checkSetOptions(source xdr.AccountId, op xdr.SetOptionsOp) xdr.SetOptionsResultCode {
+	allFlags := xdr.Uint32(xdr.AccountFlagsAuthRequiredFlag | xdr.AccountFlagsAuthRevocableFlag |
+		xdr.AccountFlagsAuthImmutableFlag)
+	if (op.SetFlags != nil && *op.SetFlags&^allFlags != 0) ||
+		(op.ClearFlags != nil && *op.ClearFlags&^allFlags != 0) {
+		return xdr.SetOptionsResultCodeSetOptionsUnknownFlag
+	}
+	if op.SetFlags != nil && op.ClearFlags != nil && *op.SetFlags&*op.ClearFlags != 0 {
+		return xdr.SetOptionsResultCodeSetOptionsBadFlags
+	}
+	for _, threshold := range []*xdr.Uint32{op.MasterWeight, op.LowThreshold, op.MedThreshold, op.HighThreshold} {
+		if threshold != nil && *threshold > math.MaxUint8 {
+			return xdr.SetOptionsResultCodeSetOptionsThresholdOutOfRange
+		}
+	}
+	if op.Signer != nil && (op.Signer.Key.Address() == source.Address() || op.Signer.Weight > math.MaxUint8) {
+		return xdr.SetOptionsResultCodeSetOptionsBadSigner
+	}
+	if op.HomeDomain != nil && !validString(string(*op.HomeDomain)) {
+		return xdr.SetOptionsResultCodeSetOptionsInvalidHomeDomain
+	}
+	return xdr.SetOptionsResultCodeSetOptionsSuccess
+}
+
+func applySetOptions(ltx *ledgerTxn, source xdr.AccountId, op xdr.SetOptionsOp) xdr.SetOptionsResultCode {
+	account := ltx.account(source)
+
+	if op.InflationDest != nil {
+		if ltx.account(*op.InflationDest) == nil {
+			return xdr.SetOptionsResultCodeSetOptionsInvalidInflation
+		}
+		account.InflationDest = op.InflationDest
+	}
+
+	if op.ClearFlags != nil || op.SetFlags != nil {
+		if xdr.AccountFlags(account.Flags).IsAuthImmutable() {
+			return xdr.SetOptionsResultCodeSetOptionsCantChange
+		}
+		if op.ClearFlags != nil {
+			account.Flags &^= *op.ClearFlags
+		}
+		if op.SetFlags != nil {
+			account.Flags |= *op.SetFlags
+		}
+	}
+
+	if op.MasterWeight != nil {
+		account.Thresholds[0] = byte(*op.MasterWeight)
+	}
+	if op.LowThreshold != nil {
+		account.Thresholds[1] = byte(*op.LowThreshold)
+	}
+	if op.MedThreshold != nil {
+		account.Thresholds[2] = byte(*op.MedThreshold)
+	}
+	if op.HighThreshold != nil {
+		account.Thresholds[3] = byte(*op.HighThreshold)
+	}
+	if op.HomeDomain != nil {
+		account.HomeDomain = *op.HomeDomain
+	}
+
+	if op.Signer != nil {
+		var signers []xdr.Signer
+		found := false
+		for _, signer := range account.Signers {
+			if !signer.Key.Equals(op.Signer.Key) {
+				signers = append(signers, signer)
+				continue
+			}
+			found = true
+			if op.Signer.Weight > 0 {
+				signers = append(signers, *op.Signer)
+			}
+		}
+
+		switch {
+		case found && op.Signer.Weight == 0:
+			account.NumSubEntries--
+		case !found && op.Signer.Weight > 0:
+			if len(signers) >= maxSigners {
+				return xdr.SetOptionsResultCodeSetOptionsTooManySigners
+			}
+			if !addSubEntry(ltx.snapshot, account) {
+				return xdr.SetOptionsResultCodeSetOptionsLowReserve
+			}
+			signers = append(signers, *op.Signer)
+		}
+		account.Signers = signers
+	}
+
+	ltx.storeAccount(account)
+	return xdr.SetOptionsResultCodeSetOptionsSuccess
+}
+
+// addSubEntry adds a subentry to account, reporting false when its balance does not cover the
+// reserve of the new subentry.
+func addSubEntry(snapshot *Snapshot, account *xdr.AccountEntry) bool {
+	account.NumSubEntries++
+	return snapshot.availableBalance(account) >= 0
+}
+
+// operationResult returns the result of an operation of type opType with the given code.
+func operationResult(opType xdr.OperationType, code int32) xdr.OperationResult {
+	tr := xdr.OperationResultTr{Type: opType}
+	switch opType {
+	case xdr.OperationTypeCreateAccount:
+		tr.CreateAccountResult = &xdr.CreateAccountResult{Code: xdr.CreateAccountResultCode(code)}
+	case xdr.OperationTypePayment:
+		tr.PaymentResult = &xdr.PaymentResult{Code: xdr.PaymentResultCode(code)}
+	case xdr.OperationTypeChangeTrust:
+		tr.ChangeTrustResult = &xdr.ChangeTrustResult{Code: xdr.ChangeTrustResultCode(code)}
+	case xdr.OperationTypeManageData:
+		tr.ManageDataResult = &xdr.ManageDataResult{Code: xdr.ManageDataResultCode(code)}
+	case xdr.OperationTypeSetOptions:
+		tr.SetOptionsResult = &xdr.SetOptionsResult{Code: xdr.SetOptionsResultCode(code)}
+	}
+	return xdr.OperationResult{Code: xdr.OperationResultCodeOpInner, Tr: &tr}
+}
+
+// successResult returns the success result of an operation of type opType.
+func successResult(opType xdr.OperationType) xdr.OperationResult {
+	return operationResult(opType, 0)
+}
+
+func assetIssuer(asset xdr.Asset) xdr.AccountId {
+	if asset.Type == xdr.AssetTypeAssetTypeCreditAlphanum4 {
+		return asset.AlphaNum4.Issuer
+	}
+	return asset.AlphaNum12.Issuer
+}
+
+// validString reports whether s only holds printable ASCII characters, like stellar-core requires
+// for home domains and data entry names.
+func validString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
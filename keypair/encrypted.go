@@ -0,0 +1,172 @@
+package keypair
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/stellar/go/xdr"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// ErrInvalidPassphrase is returned when an encrypted key cannot be decrypted,
+// because the passphrase is wrong or the file was altered.
+var ErrInvalidPassphrase = errors.New("invalid passphrase")
+
+const encryptedKeyVersion = 1
+
+// scrypt parameters of the keys written by WriteEncryptedFile.
+var (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// encryptedKey is the content of encrypted key files. The seed is encrypted
+// with AES-256-GCM, with the address as additional data, under a key derived
+// from the passphrase with scrypt.
+type encryptedKey struct {
+	Version    int          `json:"version"`
+	Address    string       `json:"address"`
+	Scrypt     scryptParams `json:"scrypt"`
+	Nonce      []byte       `json:"nonce"`
+	Ciphertext []byte       `json:"ciphertext"`
+}
+
+type scryptParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+}
+
+// EncryptedFile is a Signer whose seed is stored in a file encrypted with a
+// passphrase. The file is read and decrypted for every signature, so that the
+// seed is only held in memory while signing. Files are written with
+// WriteEncryptedFile.
+type EncryptedFile struct {
+	path       string
+	passphrase []byte
+	address    string
+}
+
+// WriteEncryptedFile writes the seed of kp to a new file at path, encrypted
+// with passphrase. Existing files are not overwritten.
+func WriteEncryptedFile(path string, kp *Full, passphrase []byte) error {
+	key := encryptedKey{
+		Version: encryptedKeyVersion,
+		Address: kp.Address(),
+		Scrypt:  scryptParams{N: scryptN, R: scryptR, P: scryptP, Salt: make([]byte, 32)},
+	}
+	if _, err := io.ReadFull(rand.Reader, key.Scrypt.Salt); err != nil {
+		return err
+	}
+	aead, err := key.aead(passphrase)
+	if err != nil {
+		return err
+	}
+	key.Nonce = make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, key.Nonce); err != nil {
+		return err
+	}
+	key.Ciphertext = aead.Seal(nil, key.Nonce, kp.rawSeed(), []byte(key.Address))
+
+	data, err := json.MarshalIndent(key, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// OpenEncryptedFile returns a signer using the seed encrypted in the file at
+// path, checking that passphrase decrypts it.
+func OpenEncryptedFile(path string, passphrase []byte) (*EncryptedFile, error) {
+	f := &EncryptedFile{path: path, passphrase: append([]byte(nil), passphrase...)}
+	kp, err := f.decrypt()
+	if err != nil {
+		return nil, err
+	}
+	f.address = kp.Address()
+	return f, nil
+}
+
+// Address returns the address of the encrypted key.
+func (f *EncryptedFile) Address() string {
+	return f.address
+}
+
+// SignDecorated decrypts the key and signs input.
+func (f *EncryptedFile) SignDecorated(input []byte) (xdr.DecoratedSignature, error) {
+	kp, err := f.decrypt()
+	if err != nil {
+		return xdr.DecoratedSignature{}, err
+	}
+	if kp.Address() != f.address {
+		return xdr.DecoratedSignature{}, fmt.Errorf("key file %s was replaced by the key of %s", f.path, kp.Address())
+	}
+	return kp.SignDecorated(input)
+}
+
+func (f *EncryptedFile) decrypt() (*Full, error) {
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	var key encryptedKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("invalid key file %s: %v", f.path, err)
+	}
+	if key.Version != encryptedKeyVersion {
+		return nil, fmt.Errorf("unsupported key file version %d", key.Version)
+	}
+
+	aead, err := key.aead(f.passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(key.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid key file %s: invalid nonce", f.path)
+	}
+	rawSeed, err := aead.Open(nil, key.Nonce, key.Ciphertext, []byte(key.Address))
+	if err != nil || len(rawSeed) != 32 {
+		return nil, ErrInvalidPassphrase
+	}
+	var seed [32]byte
+	copy(seed[:], rawSeed)
+	kp, err := FromRawSeed(seed)
+	if err != nil {
+		return nil, err
+	}
+	if kp.Address() != key.Address {
+		return nil, ErrInvalidPassphrase
+	}
+	return kp, nil
+}
+
+// aead returns the cipher encrypting the seed, keyed with passphrase.
+func (key *encryptedKey) aead(passphrase []byte) (cipher.AEAD, error) {
+	derived, err := scrypt.Key(passphrase, key.Scrypt.Salt, key.Scrypt.N, key.Scrypt.R, key.Scrypt.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scrypt parameters: %v", err)
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,58 @@
+package keypair
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("keypair.EncryptedFile", func() {
+	var (
+		dir  string
+		path string
+		kp   *Full
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "keypair")
+		Expect(err).To(BeNil())
+		path = filepath.Join(dir, "key.json")
+		kp = &Full{seed}
+		Expect(WriteEncryptedFile(path, kp, []byte("passphrase"))).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("signs with the encrypted key", func() {
+		subject, err := OpenEncryptedFile(path, []byte("passphrase"))
+		Expect(err).To(BeNil())
+		Expect(subject.Address()).To(Equal(address))
+
+		sig, err := subject.SignDecorated(message)
+		Expect(err).To(BeNil())
+		expected, _ := kp.SignDecorated(message)
+		Expect(sig).To(Equal(expected))
+	})
+
+	It("does not store the seed in clear", func() {
+		data, err := ioutil.ReadFile(path)
+		Expect(err).To(BeNil())
+		Expect(string(data)).NotTo(ContainSubstring(seed))
+		Expect(string(data)).To(ContainSubstring(address))
+	})
+
+	It("rejects a wrong passphrase", func() {
+		_, err := OpenEncryptedFile(path, []byte("wrong"))
+		Expect(err).To(Equal(ErrInvalidPassphrase))
+	})
+
+	It("does not overwrite existing files", func() {
+		Expect(WriteEncryptedFile(path, MustRandom(), []byte("passphrase"))).NotTo(Succeed())
+	})
+})
@@ -41,6 +41,18 @@ type KP interface {
 	SignDecorated(input []byte) (xdr.DecoratedSignature, error)
 }
 
+// Signer signs transaction hashes on behalf of an account. It is implemented
+// by *Full, which holds its seed in memory, and by signers keeping the secret
+// key elsewhere, such as RemoteSigner and EncryptedFile, so that transactions
+// can be signed by external key stores (HSMs, KMSs...).
+type Signer interface {
+	// Address returns the address of the signing key.
+	Address() string
+	// SignDecorated signs input and returns the signature with the hint of
+	// the signing key.
+	SignDecorated(input []byte) (xdr.DecoratedSignature, error)
+}
+
 // Random creates a random full keypair
 func Random() (*Full, error) {
 	var rawSeed [32]byte
@@ -0,0 +1,126 @@
+package keypair
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/stellar/go/xdr"
+)
+
+// remoteSignerClient is the HTTP client used by the remote signers which have
+// none.
+var remoteSignerClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxRemoteSignerResponse is the maximum size of the responses of remote
+// signers.
+const maxRemoteSignerResponse = 64 * 1024
+
+// errRemoteSignerAddress is returned by the remote signers which were not
+// created with NewRemoteSigner, and so don't know their address.
+var errRemoteSignerAddress = errors.New("remote signer has no address: create it with NewRemoteSigner")
+
+// RemoteSigner is a Signer asking a remote HTTP service to sign, so that the
+// secret key never leaves the key store behind the service.
+//
+// Every signature is requested with a POST to URL with the JSON body
+//
+//	{"address": "G...", "payload": "<base64 of the input to sign>"}
+//
+// and the service must respond with a 200 status and the JSON body
+//
+//	{"signature": "<base64 of the ed25519 signature of the payload>"}
+//
+// The signature is verified against the address before it is returned.
+//
+// A RemoteSigner must be created with NewRemoteSigner, which sets its address.
+type RemoteSigner struct {
+	// URL is the URL signatures are requested from.
+	URL string
+	// Header is added to every request, e.g. to authenticate with the service.
+	Header http.Header
+	// HTTP is the client sending the requests. A client with a 30 seconds
+	// timeout is used when nil.
+	HTTP *http.Client
+
+	kp *FromAddress
+}
+
+type remoteSignRequest struct {
+	Address string `json:"address"`
+	Payload []byte `json:"payload"`
+}
+
+type remoteSignResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+// NewRemoteSigner returns a signer asking the service at url to sign with the
+// key of address.
+func NewRemoteSigner(url, address string) (*RemoteSigner, error) {
+	kp, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteSigner{URL: url, kp: kp}, nil
+}
+
+// Address returns the address of the signing key, empty when the signer was
+// not created with NewRemoteSigner.
+func (s *RemoteSigner) Address() string {
+	if s.kp == nil {
+		return ""
+	}
+	return s.kp.Address()
+}
+
+// SignDecorated asks the remote service to sign input.
+func (s *RemoteSigner) SignDecorated(input []byte) (xdr.DecoratedSignature, error) {
+	if s.kp == nil {
+		return xdr.DecoratedSignature{}, errRemoteSignerAddress
+	}
+
+	body, err := json.Marshal(remoteSignRequest{Address: s.Address(), Payload: input})
+	if err != nil {
+		return xdr.DecoratedSignature{}, err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return xdr.DecoratedSignature{}, fmt.Errorf("invalid remote signer request: %v", err)
+	}
+	for key, values := range s.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.HTTP
+	if client == nil {
+		client = remoteSignerClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return xdr.DecoratedSignature{}, fmt.Errorf("remote signer request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return xdr.DecoratedSignature{}, fmt.Errorf("remote signer responded with status %d", resp.StatusCode)
+	}
+
+	var response remoteSignResponse
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxRemoteSignerResponse)).Decode(&response)
+	if err != nil {
+		return xdr.DecoratedSignature{}, fmt.Errorf("invalid remote signer response: %v", err)
+	}
+	if err := s.kp.Verify(input, response.Signature); err != nil {
+		return xdr.DecoratedSignature{}, err
+	}
+
+	return xdr.DecoratedSignature{
+		Hint:      xdr.SignatureHint(s.kp.Hint()),
+		Signature: xdr.Signature(response.Signature),
+	}, nil
+}
@@ -0,0 +1,85 @@
+package keypair
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("keypair.RemoteSigner", func() {
+	var (
+		kp      *Full
+		server  *httptest.Server
+		subject *RemoteSigner
+	)
+
+	BeforeEach(func() {
+		kp = &Full{seed}
+		// the stand-in signs with kp, or with another key for the "wrong" address
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var request remoteSignRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			signer := kp
+			if request.Address != kp.Address() {
+				signer = MustRandom()
+			}
+			signature, _ := signer.Sign(request.Payload)
+			json.NewEncoder(w).Encode(remoteSignResponse{Signature: signature})
+		}))
+
+		var err error
+		subject, err = NewRemoteSigner(server.URL, address)
+		Expect(err).To(BeNil())
+		subject.Header = http.Header{"Authorization": []string{"Bearer token"}}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("signs through the remote service", func() {
+		Expect(subject.Address()).To(Equal(address))
+
+		sig, err := subject.SignDecorated(message)
+		Expect(err).To(BeNil())
+		expected, _ := kp.SignDecorated(message)
+		Expect(sig).To(Equal(expected))
+	})
+
+	It("rejects signatures from another key", func() {
+		subject.kp = MustRandom().FromAddress()
+
+		_, err := subject.SignDecorated(message)
+		Expect(err).To(Equal(ErrInvalidSignature))
+	})
+
+	It("fails when the service refuses to sign", func() {
+		subject.Header = nil
+
+		_, err := subject.SignDecorated(message)
+		Expect(err).To(MatchError("remote signer responded with status 401"))
+	})
+
+	It("fails when it was not created with NewRemoteSigner", func() {
+		subject = &RemoteSigner{URL: server.URL}
+		Expect(subject.Address()).To(Equal(""))
+
+		_, err := subject.SignDecorated(message)
+		Expect(err).To(MatchError("remote signer has no address: create it with NewRemoteSigner"))
+	})
+
+	It("rejects invalid addresses", func() {
+		_, err := NewRemoteSigner(server.URL, "GABC")
+		Expect(err).To(HaveOccurred())
+	})
+})
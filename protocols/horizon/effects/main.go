@@ -316,6 +316,11 @@ func (b Base) GetAccount() string {
 	return b.Account
 }
 
+// GetLedgerCloseTime returns the close time of the ledger the effect happened in
+func (b Base) GetLedgerCloseTime() time.Time {
+	return b.LedgerCloseTime
+}
+
 // EffectsPage contains page of effects returned by Horizon.
 type EffectsPage struct {
 	Links    hal.Links `json:"_links"`
@@ -485,6 +485,23 @@ type TransactionSuccess struct {
 	Meta   string `json:"result_meta_xdr"`
 }
 
+// TransactionStatus represents the status of a transaction submitted
+// asynchronously.
+type TransactionStatus struct {
+	Links struct {
+		Self        hal.Link `json:"self"`
+		Transaction hal.Link `json:"transaction"`
+	} `json:"_links"`
+	Hash string `json:"hash"`
+	// Status is one of "queued", "sent", "in_ledger", "failed" or "timeout".
+	Status      string                  `json:"status"`
+	Ledger      int32                   `json:"ledger,omitempty"`
+	Env         string                  `json:"envelope_xdr,omitempty"`
+	Result      string                  `json:"result_xdr,omitempty"`
+	Meta        string                  `json:"result_meta_xdr,omitempty"`
+	ResultCodes *TransactionResultCodes `json:"result_codes,omitempty"`
+}
+
 // PrintTransactionSuccess prints the fields of a Horizon response.
 func (resp TransactionSuccess) TransactionSuccessToString() (s string) {
 	s += fmt.Sprintln("***TransactionSuccess dump***")
@@ -554,6 +571,14 @@ type OffersPage struct {
 	} `json:"_embedded"`
 }
 
+// AccountsPage returns a list of account records
+type AccountsPage struct {
+	Links    hal.Links `json:"_links"`
+	Embedded struct {
+		Records []Account `json:"records"`
+	} `json:"_embedded"`
+}
+
 // AssetsPage contains page of assets returned by Horizon.
 type AssetsPage struct {
 	Links    hal.Links `json:"_links"`
@@ -249,6 +249,11 @@ func (base Base) IsTransactionSuccessful() bool {
 	return base.TransactionSuccessful
 }
 
+// GetLedgerCloseTime returns the close time of the ledger the operation was included in
+func (base Base) GetLedgerCloseTime() time.Time {
+	return base.LedgerCloseTime
+}
+
 // OperationsPage is the json resource representing a page of operations.
 // OperationsPage.Record can contain various operation types.
 type OperationsPage struct {
@@ -5,9 +5,11 @@ import (
 	"database/sql"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/stellar/go/services/horizon/internal/actions"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
 	"github.com/stellar/go/services/horizon/internal/db2"
 	"github.com/stellar/go/services/horizon/internal/db2/core"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
@@ -157,6 +159,45 @@ func (action *Action) baseURL() *url.URL {
 	return httpx.BaseURL(action.R.Context())
 }
 
+// sharedStreamAction returns an Action querying the DB with ctx, for loading
+// the events of a shared stream once the request of the action is done. Links
+// are still rendered from the request of the action.
+func (action *Action) sharedStreamAction(ctx context.Context) Action {
+	ctx = sharedStreamContext(ctx, action.R)
+	return Action{
+		Base: actions.Base{R: action.R.WithContext(ctx)},
+		App:  action.App,
+		Log:  action.Log,
+	}
+}
+
+// streamTopic returns the topic of the shared stream of this request, see
+// sharedStreamTopic.
+func (action *Action) streamTopic(filters url.Values) string {
+	return sharedStreamTopic(action.R, filters)
+}
+
+// sharedStreamContext returns ctx with the request of r, so that the links of
+// the events of a shared stream loaded with ctx, once r is done, are rendered
+// from r.
+func sharedStreamContext(ctx context.Context, r *http.Request) context.Context {
+	request := httpx.RequestFromContext(r.Context())
+	return context.WithValue(ctx, &horizonContext.RequestContextKey, request)
+}
+
+// sharedStreamTopic returns the topic of the shared stream of r, made of its
+// base url, its path and the given filters.
+func sharedStreamTopic(r *http.Request, filters url.Values) string {
+	topic := r.URL.Path
+	if base := httpx.BaseURL(r.Context()); base != nil {
+		topic = base.String() + topic
+	}
+	if len(filters) > 0 {
+		topic += "?" + filters.Encode()
+	}
+	return topic
+}
+
 // Fields of this struct are exported for json marshaling/unmarshaling in
 // support/render/hal package.
 type indexActionQueryParams struct {
@@ -231,3 +272,38 @@ func (w *web) streamTransactions(ctx context.Context, s *sse.Stream, qp *indexAc
 
 	return actions.StreamTransactions(ctx, s, &history.Q{horizonSession}, qp.AccountID, qp.LedgerID, qp.IncludeFailedTxs, qp.PagingParams)
 }
+
+// sharedTransactionStream returns the shared stream of the transaction records
+// of an account or a ledger. Only the streams in ascending order are shared.
+func (w *web) sharedTransactionStream(r *http.Request, qp *indexActionQueryParams) *sse.SharedStream {
+	if qp.PagingParams.Order != db2.OrderAscending {
+		return nil
+	}
+
+	filters := url.Values{}
+	if qp.AccountID != "" {
+		filters.Set("account_id", qp.AccountID)
+	}
+	if qp.LedgerID > 0 {
+		filters.Set("ledger_id", strconv.FormatInt(int64(qp.LedgerID), 10))
+	}
+	if qp.IncludeFailedTxs {
+		filters.Set("include_failed", "true")
+	}
+
+	return &sse.SharedStream{
+		Topic:  sharedStreamTopic(r, filters),
+		Cursor: qp.PagingParams.Cursor,
+		Limit:  int(qp.PagingParams.Limit),
+		Load: func(ctx context.Context, cursor string, limit uint64) ([]sse.Event, error) {
+			ctx = sharedStreamContext(ctx, r)
+			horizonSession, err := w.horizonSession(ctx)
+			if err != nil {
+				return nil, errors.Wrap(err, "getting horizon db session")
+			}
+
+			pq := db2.PageQuery{Cursor: cursor, Order: db2.OrderAscending, Limit: limit}
+			return actions.TransactionEvents(ctx, &history.Q{horizonSession}, qp.AccountID, qp.LedgerID, qp.IncludeFailedTxs, pq)
+		},
+	}
+}
@@ -69,24 +69,20 @@ func (base *Base) Execute(action interface{}) {
 
 		stream := sse.NewStream(ctx, base.W)
 
+		if ac, ok := action.(SharedEventStreamer); ok {
+			if served := base.serveSharedStream(ac, stream); served {
+				return
+			}
+		}
+
 		var oldHash [32]byte
 		for {
 			lastLedgerState := ledger.CurrentState()
 
 			// Rate limit the request if it's a call to stream since it queries the DB every second. See
 			// https://github.com/stellar/go/issues/715 for more details.
-			app := base.R.Context().Value(&horizonContext.AppContextKey)
-			rateLimiter := app.(RateLimiterProvider).GetRateLimiter()
-			if rateLimiter != nil {
-				limited, _, err := rateLimiter.RateLimiter.RateLimit(rateLimiter.VaryBy.Key(base.R), 1)
-				if err != nil {
-					stream.Err(errors.Wrap(err, "RateLimiter error"))
-					return
-				}
-				if limited {
-					stream.Err(sse.ErrRateLimited)
-					return
-				}
+			if limited := base.rateLimit(stream); limited {
+				return
 			}
 
 			switch ac := action.(type) {
@@ -173,6 +169,62 @@ NotAcceptable:
 	problem.Render(ctx, base.W, hProblem.NotAcceptable)
 }
 
+// serveSharedStream streams the events of action through the sse.Fanout of the
+// app, so that they are loaded once for all the streams of the same topic. It
+// returns false, without sending anything on stream, when the app has no
+// sse.Fanout or the stream requested can't be shared.
+func (base *Base) serveSharedStream(action SharedEventStreamer, stream *sse.Stream) bool {
+	app := base.R.Context().Value(&horizonContext.AppContextKey)
+	provider, ok := app.(StreamFanoutProvider)
+	if !ok {
+		return false
+	}
+	fanout := provider.GetStreamFanout()
+	if fanout == nil {
+		return false
+	}
+
+	shared, err := action.SharedStream()
+	if err != nil {
+		stream.Err(err)
+		return true
+	}
+	if shared == nil {
+		return false
+	}
+
+	// The events of the stream are only loaded by the stream itself until it
+	// catches up with its topic, so it is rate limited once like the streams
+	// querying the DB on their own.
+	if limited := base.rateLimit(stream); limited {
+		return true
+	}
+
+	fanout.Serve(base.R.Context(), stream, *shared)
+	return true
+}
+
+// rateLimit rate limits the stream requests, returning true after sending an
+// error on stream if the request was limited.
+func (base *Base) rateLimit(stream *sse.Stream) bool {
+	app := base.R.Context().Value(&horizonContext.AppContextKey)
+	rateLimiter := app.(RateLimiterProvider).GetRateLimiter()
+	if rateLimiter == nil {
+		return false
+	}
+
+	limited, _, err := rateLimiter.RateLimiter.RateLimit(rateLimiter.VaryBy.Key(base.R), 1)
+	if err != nil {
+		stream.Err(errors.Wrap(err, "RateLimiter error"))
+		return true
+	}
+	if limited {
+		stream.Err(sse.ErrRateLimited)
+		return true
+	}
+	return false
+}
+
 // Do executes the provided func iff there is no current error for the action.
 // Provides a nicer way to invoke a set of steps that each may set `action.Err`
 // during execution
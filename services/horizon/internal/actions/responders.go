@@ -20,6 +20,15 @@ type EventStreamer interface {
 	SSE(*sse.Stream) error
 }
 
+// SharedEventStreamer implementors are EventStreamers whose events can be
+// loaded once for all the streams of the same topic, see sse.Fanout.
+// SharedStream returns nil when the stream requested can't be shared, in which
+// case SSE is used instead.
+type SharedEventStreamer interface {
+	EventStreamer
+	SharedStream() (*sse.SharedStream, error)
+}
+
 // SingleObjectStreamer implementors can respond to a request whose response
 // type was negotiated to be MimeEventStream. A SingleObjectStreamer loads an
 // object whenever a ledger is closed.
@@ -0,0 +1,8 @@
+package actions
+
+import "github.com/stellar/go/services/horizon/internal/render/sse"
+
+// StreamFanoutProvider is an interface that provides access to the type's sse.Fanout.
+type StreamFanoutProvider interface {
+	GetStreamFanout() *sse.Fanout
+}
@@ -91,20 +91,35 @@ func loadTransactionRecords(hq *history.Q, accountID string, ledgerID int32, inc
 // StreamTransactions streams transaction records of an account/ledger
 // identified by accountID/ledgerID based on pq and includeFailedTx.
 func StreamTransactions(ctx context.Context, s *sse.Stream, hq *history.Q, accountID string, ledgerID int32, includeFailedTx bool, pq db2.PageQuery) error {
-	allRecords, err := loadTransactionRecords(hq, accountID, ledgerID, includeFailedTx, pq)
+	events, err := TransactionEvents(ctx, hq, accountID, ledgerID, includeFailedTx, pq)
 	if err != nil {
-		return errors.Wrap(err, "loading transaction records")
+		return err
 	}
 
 	s.SetLimit(int(pq.Limit))
-	records := allRecords[s.SentCount():]
+	for _, event := range events[s.SentCount():] {
+		s.Send(event)
+	}
+
+	return nil
+}
+
+// TransactionEvents returns the stream events of the transaction records of an
+// account/ledger identified by accountID/ledgerID based on pq and
+// includeFailedTx.
+func TransactionEvents(ctx context.Context, hq *history.Q, accountID string, ledgerID int32, includeFailedTx bool, pq db2.PageQuery) ([]sse.Event, error) {
+	records, err := loadTransactionRecords(hq, accountID, ledgerID, includeFailedTx, pq)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading transaction records")
+	}
+
+	events := make([]sse.Event, 0, len(records))
 	for _, record := range records {
 		var res horizon.Transaction
 		resourceadapter.PopulateTransaction(ctx, &res, record)
-		s.Send(sse.Event{ID: res.PagingToken(), Data: res})
+		events = append(events, sse.Event{ID: res.PagingToken(), Data: res})
 	}
-
-	return nil
+	return events, nil
 }
 
 // TransactionResource returns a single transaction resource identified by txHash.
@@ -1,8 +1,11 @@
 package horizon
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"regexp"
+	"strconv"
 
 	"github.com/stellar/go/services/horizon/internal/actions"
 	"github.com/stellar/go/services/horizon/internal/db2"
@@ -21,18 +24,21 @@ import (
 // Interface verifications
 var _ actions.JSONer = (*EffectIndexAction)(nil)
 var _ actions.EventStreamer = (*EffectIndexAction)(nil)
+var _ actions.SharedEventStreamer = (*EffectIndexAction)(nil)
 
 var effectsCursorRegexp = regexp.MustCompile(`now|\d+(-\d+)?`)
 
 // EffectIndexAction renders a page of effect resources, identified by
 // a normal page query and optionally filtered by an account, ledger,
-// transaction, or operation.
+// transaction, or operation, and by effect types, asset and amount.
 type EffectIndexAction struct {
 	Action
 	AccountFilter     string
 	LedgerFilter      int32
 	TransactionFilter string
 	OperationFilter   int64
+	TypeFilter        []history.EffectType
+	DetailsFilters    detailsFilters
 
 	PagingParams db2.PageQuery
 	Records      []history.Effect
@@ -67,25 +73,13 @@ func (action *EffectIndexAction) SSE(stream *sse.Stream) error {
 		action.loadLedgers,
 		func() {
 			stream.SetLimit(int(action.PagingParams.Limit))
-			records := action.Records[stream.SentCount():]
-
-			for _, record := range records {
-				ledger, found := action.Ledgers.Records[record.LedgerSequence()]
-				if !found {
-					action.Err = errors.New(fmt.Sprintf("could not find ledger data for sequence %d", record.LedgerSequence()))
-					return
-				}
-
-				res, err := resourceadapter.NewEffect(action.R.Context(), record, ledger)
-				if err != nil {
-					action.Err = err
-					return
-				}
-
-				stream.Send(sse.Event{
-					ID:   res.PagingToken(),
-					Data: res,
-				})
+			events, err := action.events(stream.SentCount())
+			if err != nil {
+				action.Err = err
+				return
+			}
+			for _, event := range events {
+				stream.Send(event)
 			}
 		},
 	)
@@ -93,6 +87,89 @@ func (action *EffectIndexAction) SSE(stream *sse.Stream) error {
 	return action.Err
 }
 
+// SharedStream is a method for actions.SharedEventStreamer. Only the streams
+// in ascending order are shared, the streams with the same filters sharing the
+// same topic.
+func (action *EffectIndexAction) SharedStream() (*sse.SharedStream, error) {
+	action.Setup(
+		action.EnsureHistoryFreshness,
+		action.loadParams,
+		action.ValidateCursorWithinHistory,
+	)
+	if action.Err != nil {
+		return nil, action.Err
+	}
+	if action.PagingParams.Order != db2.OrderAscending {
+		return nil, nil
+	}
+
+	filters := url.Values{}
+	if action.AccountFilter != "" {
+		filters.Set("account_id", action.AccountFilter)
+	}
+	if action.LedgerFilter > 0 {
+		filters.Set("ledger_id", strconv.FormatInt(int64(action.LedgerFilter), 10))
+	}
+	if action.TransactionFilter != "" {
+		filters.Set("tx_id", action.TransactionFilter)
+	}
+	if action.OperationFilter > 0 {
+		filters.Set("op_id", strconv.FormatInt(action.OperationFilter, 10))
+	}
+	if len(action.TypeFilter) > 0 {
+		filters.Set("type", action.GetString("type"))
+	}
+	action.DetailsFilters.addTo(filters)
+
+	return &sse.SharedStream{
+		Topic:  action.streamTopic(filters),
+		Cursor: action.PagingParams.Cursor,
+		Limit:  int(action.PagingParams.Limit),
+		Load: func(ctx context.Context, cursor string, limit uint64) ([]sse.Event, error) {
+			loader := &EffectIndexAction{
+				Action:            action.sharedStreamAction(ctx),
+				AccountFilter:     action.AccountFilter,
+				LedgerFilter:      action.LedgerFilter,
+				TransactionFilter: action.TransactionFilter,
+				OperationFilter:   action.OperationFilter,
+				TypeFilter:        action.TypeFilter,
+				DetailsFilters:    action.DetailsFilters,
+				PagingParams:      db2.PageQuery{Cursor: cursor, Order: db2.OrderAscending, Limit: limit},
+			}
+			loader.Do(loader.loadRecords, loader.loadLedgers)
+			if loader.Err != nil {
+				return nil, loader.Err
+			}
+			return loader.events(0)
+		},
+	}, nil
+}
+
+// events returns the stream events of the loaded records, skipping the first
+// offset records.
+func (action *EffectIndexAction) events(offset int) ([]sse.Event, error) {
+	records := action.Records[offset:]
+
+	events := make([]sse.Event, 0, len(records))
+	for _, record := range records {
+		ledger, found := action.Ledgers.Records[record.LedgerSequence()]
+		if !found {
+			return nil, errors.New(fmt.Sprintf("could not find ledger data for sequence %d", record.LedgerSequence()))
+		}
+
+		res, err := resourceadapter.NewEffect(action.R.Context(), record, ledger)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, sse.Event{
+			ID:   res.PagingToken(),
+			Data: res,
+		})
+	}
+	return events, nil
+}
+
 // loadLedgers populates the ledger cache for this action
 func (action *EffectIndexAction) loadLedgers() {
 	action.Ledgers = &history.LedgerCache{}
@@ -128,6 +205,17 @@ func (action *EffectIndexAction) loadParams() {
 		action.Err = problem.BadRequest
 		return
 	}
+
+	action.TypeFilter, err = parseEffectTypes(&action.Action.Base, "type")
+	if err != nil {
+		action.Err = err
+		return
+	}
+	action.DetailsFilters, err = parseDetailsFilters(&action.Action.Base)
+	if err != nil {
+		action.Err = err
+		return
+	}
 }
 
 // loadRecords populates action.Records
@@ -145,6 +233,16 @@ func (action *EffectIndexAction) loadRecords() {
 		effects.ForTransaction(action.TransactionFilter)
 	}
 
+	if len(action.TypeFilter) > 0 {
+		effects.ForTypes(action.TypeFilter...)
+	}
+
+	if action.DetailsFilters.Asset != nil {
+		effects.ForAsset(*action.DetailsFilters.Asset)
+	}
+
+	effects.ForAmount(action.DetailsFilters.MinAmount, action.DetailsFilters.MaxAmount)
+
 	action.Err = effects.Page(action.PagingParams).Select(&action.Records)
 }
 
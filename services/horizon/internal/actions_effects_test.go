@@ -81,6 +81,37 @@ func TestEffectActions_Index(t *testing.T) {
 		ht.Logger.Error(w.Body.String())
 	})
 
+	t.Run("filters", func(t *testing.T) {
+		ht := StartHTTPTest(t, "non_native_payment")
+		defer ht.Finish()
+
+		w := ht.Get("/effects?type=account_credited,trustline_created")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(4, w.Body)
+		}
+
+		w = ht.Get("/effects?asset_type=credit_alphanum4&asset_code=USD&asset_issuer=GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(6, w.Body)
+		}
+
+		w = ht.Get("/effects?asset_type=native&type=account_debited")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(3, w.Body)
+		}
+
+		w = ht.Get("/effects?min_amount=60")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(5, w.Body)
+		}
+
+		w = ht.Get("/effects?type=unknown")
+		ht.Assert.Equal(400, w.Code)
+
+		w = ht.Get("/effects?max_amount=abc")
+		ht.Assert.Equal(400, w.Code)
+	})
+
 	t.Run("Effect resource props", func(t *testing.T) {
 		ht := StartHTTPTest(t, "base")
 		defer ht.Finish()
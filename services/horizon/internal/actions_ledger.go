@@ -1,6 +1,8 @@
 package horizon
 
 import (
+	"context"
+
 	"github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/services/horizon/internal/actions"
 	"github.com/stellar/go/services/horizon/internal/db2"
@@ -20,6 +22,7 @@ import (
 // Interface verifications
 var _ actions.JSONer = (*LedgerIndexAction)(nil)
 var _ actions.EventStreamer = (*LedgerIndexAction)(nil)
+var _ actions.SharedEventStreamer = (*LedgerIndexAction)(nil)
 
 // LedgerIndexAction renders a page of ledger resources, identified by
 // a normal page query.
@@ -54,11 +57,8 @@ func (action *LedgerIndexAction) SSE(stream *sse.Stream) error {
 		action.loadRecords,
 		func() {
 			stream.SetLimit(int(action.PagingParams.Limit))
-			records := action.Records[stream.SentCount():]
-			for _, record := range records {
-				var res horizon.Ledger
-				resourceadapter.PopulateLedger(action.R.Context(), &res, record)
-				stream.Send(sse.Event{ID: res.PagingToken(), Data: res})
+			for _, event := range action.events()[stream.SentCount():] {
+				stream.Send(event)
 			}
 		},
 	)
@@ -66,6 +66,39 @@ func (action *LedgerIndexAction) SSE(stream *sse.Stream) error {
 	return action.Err
 }
 
+// SharedStream is a method for actions.SharedEventStreamer. Only the streams
+// in ascending order are shared.
+func (action *LedgerIndexAction) SharedStream() (*sse.SharedStream, error) {
+	action.Setup(
+		action.EnsureHistoryFreshness,
+		action.loadParams,
+		action.ValidateCursorWithinHistory,
+	)
+	if action.Err != nil {
+		return nil, action.Err
+	}
+	if action.PagingParams.Order != db2.OrderAscending {
+		return nil, nil
+	}
+
+	return &sse.SharedStream{
+		Topic:  action.streamTopic(nil),
+		Cursor: action.PagingParams.Cursor,
+		Limit:  int(action.PagingParams.Limit),
+		Load: func(ctx context.Context, cursor string, limit uint64) ([]sse.Event, error) {
+			loader := &LedgerIndexAction{
+				Action:       action.sharedStreamAction(ctx),
+				PagingParams: db2.PageQuery{Cursor: cursor, Order: db2.OrderAscending, Limit: limit},
+			}
+			loader.loadRecords()
+			if loader.Err != nil {
+				return nil, loader.Err
+			}
+			return loader.events(), nil
+		},
+	}, nil
+}
+
 func (action *LedgerIndexAction) loadParams() {
 	action.ValidateCursorAsDefault()
 	action.PagingParams = action.GetPageQuery()
@@ -75,6 +108,17 @@ func (action *LedgerIndexAction) loadRecords() {
 	action.Err = action.HistoryQ().Ledgers().Page(action.PagingParams).Select(&action.Records)
 }
 
+// events returns the stream events of the loaded records.
+func (action *LedgerIndexAction) events() []sse.Event {
+	events := make([]sse.Event, 0, len(action.Records))
+	for _, record := range action.Records {
+		var res horizon.Ledger
+		resourceadapter.PopulateLedger(action.R.Context(), &res, record)
+		events = append(events, sse.Event{ID: res.PagingToken(), Data: res})
+	}
+	return events
+}
+
 func (action *LedgerIndexAction) loadPage() {
 	for _, record := range action.Records {
 		var res horizon.Ledger
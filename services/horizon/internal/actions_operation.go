@@ -1,7 +1,10 @@
 package horizon
 
 import (
+	"context"
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/stellar/go/services/horizon/internal/actions"
@@ -26,6 +29,7 @@ import (
 // Interface verifications
 var _ actions.JSONer = (*OperationIndexAction)(nil)
 var _ actions.EventStreamer = (*OperationIndexAction)(nil)
+var _ actions.SharedEventStreamer = (*OperationIndexAction)(nil)
 
 const (
 	joinTransactions = "transactions"
@@ -33,12 +37,14 @@ const (
 
 // OperationIndexAction renders a page of operations resources, identified by
 // a normal page query and optionally filtered by an account, ledger, or
-// transaction.
+// transaction, and by operation types, asset and amount.
 type OperationIndexAction struct {
 	Action
 	LedgerFilter        int32
 	AccountFilter       string
 	TransactionFilter   string
+	TypeFilter          []xdr.OperationType
+	DetailsFilters      detailsFilters
 	PagingParams        db2.PageQuery
 	OperationRecords    []history.Operation
 	TransactionRecords  []history.Transaction
@@ -75,33 +81,13 @@ func (action *OperationIndexAction) SSE(stream *sse.Stream) error {
 		action.loadLedgers,
 		func() {
 			stream.SetLimit(int(action.PagingParams.Limit))
-			operationRecords := action.OperationRecords[stream.SentCount():]
-			var transactionRecords []history.Transaction
-			if action.IncludeTransactions {
-				transactionRecords = action.TransactionRecords[stream.SentCount():]
+			events, err := action.events(stream.SentCount())
+			if err != nil {
+				action.Err = err
+				return
 			}
-			for i, operationRecord := range operationRecords {
-				ledger, found := action.Ledgers.Records[operationRecord.LedgerSequence()]
-				if !found {
-					action.Err = errors.New(fmt.Sprintf("could not find ledger data for sequence %d", operationRecord.LedgerSequence()))
-					return
-				}
-
-				var transactionRecord *history.Transaction
-				if action.IncludeTransactions {
-					transactionRecord = &transactionRecords[i]
-				}
-
-				res, err := resourceadapter.NewOperation(action.R.Context(), operationRecord, transactionRecord, ledger)
-				if err != nil {
-					action.Err = err
-					return
-				}
-
-				stream.Send(sse.Event{
-					ID:   res.PagingToken(),
-					Data: res,
-				})
+			for _, event := range events {
+				stream.Send(event)
 			}
 		},
 	)
@@ -109,6 +95,103 @@ func (action *OperationIndexAction) SSE(stream *sse.Stream) error {
 	return action.Err
 }
 
+// SharedStream is a method for actions.SharedEventStreamer. Only the streams
+// in ascending order are shared, the streams with the same filters sharing the
+// same topic.
+func (action *OperationIndexAction) SharedStream() (*sse.SharedStream, error) {
+	action.Setup(
+		action.EnsureHistoryFreshness,
+		action.loadParams,
+		action.ValidateCursorWithinHistory,
+	)
+	if action.Err != nil {
+		return nil, action.Err
+	}
+	if action.PagingParams.Order != db2.OrderAscending {
+		return nil, nil
+	}
+
+	filters := url.Values{}
+	if action.AccountFilter != "" {
+		filters.Set("account_id", action.AccountFilter)
+	}
+	if action.LedgerFilter > 0 {
+		filters.Set("ledger_id", strconv.FormatInt(int64(action.LedgerFilter), 10))
+	}
+	if action.TransactionFilter != "" {
+		filters.Set("tx_id", action.TransactionFilter)
+	}
+	if action.IncludeFailed {
+		filters.Set("include_failed", "true")
+	}
+	if action.IncludeTransactions {
+		filters.Set("join", joinTransactions)
+	}
+	if len(action.TypeFilter) > 0 {
+		filters.Set("type", action.GetString("type"))
+	}
+	action.DetailsFilters.addTo(filters)
+
+	return &sse.SharedStream{
+		Topic:  action.streamTopic(filters),
+		Cursor: action.PagingParams.Cursor,
+		Limit:  int(action.PagingParams.Limit),
+		Load: func(ctx context.Context, cursor string, limit uint64) ([]sse.Event, error) {
+			loader := &OperationIndexAction{
+				Action:              action.sharedStreamAction(ctx),
+				LedgerFilter:        action.LedgerFilter,
+				AccountFilter:       action.AccountFilter,
+				TransactionFilter:   action.TransactionFilter,
+				TypeFilter:          action.TypeFilter,
+				DetailsFilters:      action.DetailsFilters,
+				PagingParams:        db2.PageQuery{Cursor: cursor, Order: db2.OrderAscending, Limit: limit},
+				IncludeFailed:       action.IncludeFailed,
+				IncludeTransactions: action.IncludeTransactions,
+				OnlyPayments:        action.OnlyPayments,
+			}
+			loader.Do(loader.loadRecords, loader.loadLedgers)
+			if loader.Err != nil {
+				return nil, loader.Err
+			}
+			return loader.events(0)
+		},
+	}, nil
+}
+
+// events returns the stream events of the loaded records, skipping the first
+// offset records.
+func (action *OperationIndexAction) events(offset int) ([]sse.Event, error) {
+	operationRecords := action.OperationRecords[offset:]
+	var transactionRecords []history.Transaction
+	if action.IncludeTransactions {
+		transactionRecords = action.TransactionRecords[offset:]
+	}
+
+	events := make([]sse.Event, 0, len(operationRecords))
+	for i, operationRecord := range operationRecords {
+		ledger, found := action.Ledgers.Records[operationRecord.LedgerSequence()]
+		if !found {
+			return nil, errors.New(fmt.Sprintf("could not find ledger data for sequence %d", operationRecord.LedgerSequence()))
+		}
+
+		var transactionRecord *history.Transaction
+		if action.IncludeTransactions {
+			transactionRecord = &transactionRecords[i]
+		}
+
+		res, err := resourceadapter.NewOperation(action.R.Context(), operationRecord, transactionRecord, ledger)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, sse.Event{
+			ID:   res.PagingToken(),
+			Data: res,
+		})
+	}
+	return events, nil
+}
+
 func parseJoinField(action *actions.Base) (map[string]bool, error) {
 	join := action.GetString("join")
 	validJoins := map[string]bool{}
@@ -140,6 +223,16 @@ func (action *OperationIndexAction) loadParams() {
 		return
 	}
 	action.IncludeTransactions = parsed[joinTransactions]
+	action.TypeFilter, err = parseOperationTypes(&action.Action.Base, "type")
+	if err != nil {
+		action.Err = err
+		return
+	}
+	action.DetailsFilters, err = parseDetailsFilters(&action.Action.Base)
+	if err != nil {
+		action.Err = err
+		return
+	}
 
 	filters, err := countNonEmpty(
 		action.AccountFilter,
@@ -232,6 +325,16 @@ func (action *OperationIndexAction) loadRecords() {
 		ops.OnlyPayments()
 	}
 
+	if len(action.TypeFilter) > 0 {
+		ops.ForTypes(action.TypeFilter...)
+	}
+
+	if action.DetailsFilters.Asset != nil {
+		ops.ForAsset(*action.DetailsFilters.Asset)
+	}
+
+	ops.ForAmount(action.DetailsFilters.MinAmount, action.DetailsFilters.MaxAmount)
+
 	action.OperationRecords, action.TransactionRecords, action.Err = ops.Page(action.PagingParams).Fetch()
 	if action.Err != nil {
 		return
@@ -92,6 +92,59 @@ func TestOperationActions_Index(t *testing.T) {
 	ht.Assert.Equal(404, w.Code)
 }
 
+func TestOperationActions_Index_Filters(t *testing.T) {
+	ht := StartHTTPTest(t, "non_native_payment")
+	defer ht.Finish()
+
+	usd := "asset_type=credit_alphanum4&asset_code=USD&asset_issuer=GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+
+	// filtered by type
+	w := ht.Get("/operations?type=create_account")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(3, w.Body)
+	}
+
+	w = ht.Get("/operations?type=payment,change_trust")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(4, w.Body)
+	}
+
+	// filtered by asset
+	w = ht.Get("/operations?" + usd)
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(4, w.Body)
+	}
+
+	w = ht.Get("/payments?" + usd)
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(2, w.Body)
+	}
+
+	// filtered by amount
+	w = ht.Get("/payments?" + usd + "&min_amount=60")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(1, w.Body)
+	}
+
+	w = ht.Get("/accounts/GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU/payments?max_amount=60")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Assert.PageOf(1, w.Body)
+	}
+
+	// invalid filters
+	w = ht.Get("/operations?type=unknown")
+	ht.Assert.Equal(400, w.Code)
+
+	w = ht.Get("/operations?asset_type=credit_alphanum4&asset_code=USD")
+	ht.Assert.Equal(400, w.Code)
+
+	w = ht.Get("/operations?min_amount=-1")
+	ht.Assert.Equal(400, w.Code)
+
+	w = ht.Get("/operations?min_amount=100&max_amount=50")
+	ht.Assert.Equal(400, w.Code)
+}
+
 func TestOperationActions_Show_Failed(t *testing.T) {
 	ht := StartHTTPTest(t, "failed_transactions")
 	defer ht.Finish()
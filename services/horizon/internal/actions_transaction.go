@@ -6,9 +6,11 @@ import (
 	"github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/services/horizon/internal/actions"
 	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/services/horizon/internal/render/sse"
 	"github.com/stellar/go/services/horizon/internal/resourceadapter"
 	"github.com/stellar/go/services/horizon/internal/txsub"
 	"github.com/stellar/go/support/render/hal"
+	"github.com/stellar/go/support/render/httpjson"
 	"github.com/stellar/go/support/render/problem"
 )
 
@@ -17,17 +19,31 @@ var _ actions.JSONer = (*TransactionCreateAction)(nil)
 
 // TransactionCreateAction submits a transaction to the stellar-core network
 // on behalf of the requesting client.
+//
+// With the `async=true` query parameter, the transaction is submitted in the
+// background and the action responds with 202 Accepted and the status of the
+// transaction as soon as it is queued, see TransactionStatusAction.
 type TransactionCreateAction struct {
 	Action
 	TX       string
+	Async    bool
 	Result   txsub.Result
 	Resource horizon.TransactionSuccess
+	Status   horizon.TransactionStatus
 }
 
 // JSON format action handler
 func (action *TransactionCreateAction) JSON() error {
+	action.Do(action.loadTX)
+	if action.Async {
+		action.Do(
+			action.submitAsync,
+			func() { httpjson.RenderStatus(action.W, http.StatusAccepted, action.Status, httpjson.HALJSON) },
+		)
+		return action.Err
+	}
+
 	action.Do(
-		action.loadTX,
 		action.loadResult,
 		action.loadResource,
 		func() { hal.Render(action.W, action.Resource) },
@@ -38,6 +54,22 @@ func (action *TransactionCreateAction) JSON() error {
 func (action *TransactionCreateAction) loadTX() {
 	action.ValidateBodyType()
 	action.TX = action.GetString("tx")
+	action.Async = action.GetBool("async")
+}
+
+func (action *TransactionCreateAction) submitAsync() {
+	hash, err := action.App.submitter.SubmitAsync(action.R.Context(), action.TX)
+	if err != nil {
+		action.Result = txsub.Result{Err: err, EnvelopeXDR: action.TX}
+		action.loadResource()
+		return
+	}
+
+	action.Err = resourceadapter.PopulateTransactionStatus(
+		action.R.Context(),
+		&action.Status,
+		txsub.Status{Hash: hash, State: txsub.StatusQueued},
+	)
 }
 
 func (action *TransactionCreateAction) loadResult() {
@@ -104,3 +136,65 @@ func (action *TransactionCreateAction) loadResource() {
 		action.Err = err
 	}
 }
+
+// Interface verifications
+var _ actions.JSONer = (*TransactionStatusAction)(nil)
+var _ actions.EventStreamer = (*TransactionStatusAction)(nil)
+
+// TransactionStatusAction renders the status of a transaction submitted
+// asynchronously. When streamed, a new event is sent whenever the status
+// changes, until the transaction is in a ledger or failed.
+type TransactionStatusAction struct {
+	Action
+	Hash       string
+	Status     txsub.Status
+	Resource   horizon.TransactionStatus
+	lastStatus string
+}
+
+// JSON is a method for actions.JSON
+func (action *TransactionStatusAction) JSON() error {
+	action.Do(
+		action.loadParams,
+		action.loadStatus,
+		action.loadResource,
+		func() { hal.Render(action.W, action.Resource) },
+	)
+	return action.Err
+}
+
+// SSE is a method for actions.SSE
+func (action *TransactionStatusAction) SSE(stream *sse.Stream) error {
+	action.Do(
+		action.loadParams,
+		action.loadStatus,
+		action.loadResource,
+		func() {
+			if action.Status.State != action.lastStatus {
+				action.lastStatus = action.Status.State
+				stream.Send(sse.Event{Data: action.Resource})
+			}
+			if action.Status.Done() {
+				stream.Done()
+			}
+		},
+	)
+	return action.Err
+}
+
+func (action *TransactionStatusAction) loadParams() {
+	action.Hash = action.GetString("tx_id")
+}
+
+func (action *TransactionStatusAction) loadStatus() {
+	status, err := action.App.submitter.Status(action.R.Context(), action.Hash)
+	if err == txsub.ErrNoResults {
+		action.Err = &problem.NotFound
+		return
+	}
+	action.Status, action.Err = status, err
+}
+
+func (action *TransactionStatusAction) loadResource() {
+	action.Err = resourceadapter.PopulateTransactionStatus(action.R.Context(), &action.Resource, action.Status)
+}
@@ -257,3 +257,55 @@ func TestTransactionActions_PostFailed(t *testing.T) {
 	ht.Assert.Contains(string(w.Body.Bytes()), "op_underfunded")
 	ht.Assert.Contains(string(w.Body.Bytes()), `"result_xdr": "AAAAAAAAAGT/////AAAAAQAAAAAAAAAB/////gAAAAA="`)
 }
+
+func TestTransactionActions_PostAsync(t *testing.T) {
+	ht := StartHTTPTest(t, "failed_transactions")
+	defer ht.Finish()
+
+	// 56e3216045d579bea40f2d35a09406de3a894ecb5be70dbda5ec9c0427a0d5a1
+	form := url.Values{"tx": []string{"AAAAAK6jei3jmoI8TGlD/egc37PXtHKKzWV8wViZBaCu5L5MAAAAZAAAAAIAAAABAAAAAAAAAAAAAAABAAAAAAAAAAEAAAAAbmgm1V2dg5V1mq1elMcG1txjSYKZ9wEgoSBaeW8UiFoAAAABVVNEAAAAAACuo3ot45qCPExpQ/3oHN+z17Ryis1lfMFYmQWgruS+TAAAAAA7msoAAAAAAAAAAAGu5L5MAAAAQEnKDbDYvKkJjYK0arvhFln+GK0+7Ay6g0a+1hjRRelEAe4wmjeqNcRg2m4Cn7t4AjJzAsDQI0iXahGboJPINAw="}}
+
+	w := ht.Post("/transactions?async=true", form)
+	ht.Assert.Equal(202, w.Code)
+	var status horizon.TransactionStatus
+	err := json.Unmarshal(w.Body.Bytes(), &status)
+	ht.Require.NoError(err)
+	ht.Assert.Equal("56e3216045d579bea40f2d35a09406de3a894ecb5be70dbda5ec9c0427a0d5a1", status.Hash)
+	ht.Assert.Equal(txsub.StatusQueued, status.Status)
+
+	// the transaction is already in a ledger
+	w = ht.Get("/transactions/56e3216045d579bea40f2d35a09406de3a894ecb5be70dbda5ec9c0427a0d5a1/status")
+	ht.Assert.Equal(200, w.Code)
+	status = horizon.TransactionStatus{}
+	err = json.Unmarshal(w.Body.Bytes(), &status)
+	ht.Require.NoError(err)
+	ht.Assert.Equal(txsub.StatusInLedger, status.Status)
+	ht.Assert.Equal("AAAAAAAAAGQAAAAAAAAAAQAAAAAAAAABAAAAAAAAAAA=", status.Result)
+	ht.Assert.NotZero(status.Ledger)
+
+	// malformed transaction
+	w = ht.Post("/transactions?async=true", url.Values{"tx": []string{"not xdr"}})
+	ht.Assert.Equal(400, w.Code)
+	ht.Assert.Contains(w.Body.String(), "transaction_malformed")
+}
+
+func TestTransactionActions_Status(t *testing.T) {
+	ht := StartHTTPTest(t, "failed_transactions")
+	defer ht.Finish()
+
+	// aa168f12124b7c196c0adaee7c73a64d37f99428cacb59a91ff389626845e7cf failed
+	// in a ledger
+	w := ht.Get("/transactions/aa168f12124b7c196c0adaee7c73a64d37f99428cacb59a91ff389626845e7cf/status")
+	ht.Assert.Equal(200, w.Code)
+	var status horizon.TransactionStatus
+	err := json.Unmarshal(w.Body.Bytes(), &status)
+	ht.Require.NoError(err)
+	ht.Assert.Equal(txsub.StatusFailed, status.Status)
+	ht.Assert.Equal("AAAAAAAAAGT/////AAAAAQAAAAAAAAAB/////gAAAAA=", status.Result)
+	ht.Require.NotNil(status.ResultCodes)
+	ht.Assert.Equal([]string{"op_underfunded"}, status.ResultCodes.OperationCodes)
+
+	// unknown transaction
+	w = ht.Get("/transactions/0000000000000000000000000000000000000000000000000000000000000000/status")
+	ht.Assert.Equal(404, w.Code)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/stellar/go/services/horizon/internal/operationfeestats"
 	"github.com/stellar/go/services/horizon/internal/paths"
 	"github.com/stellar/go/services/horizon/internal/reap"
+	"github.com/stellar/go/services/horizon/internal/render/sse"
 	"github.com/stellar/go/services/horizon/internal/txsub"
 	"github.com/stellar/go/support/app"
 	"github.com/stellar/go/support/db"
@@ -531,6 +532,12 @@ func (a *App) GetRateLimiter() *throttled.HTTPRateLimiter {
 	return a.web.rateLimiter
 }
 
+// GetStreamFanout returns the sse.Fanout sharing the queries of the streams of
+// the App.
+func (a *App) GetStreamFanout() *sse.Fanout {
+	return a.web.streamFanout()
+}
+
 // AppFromContext returns the set app, if one has been set, from the
 // provided context returns nil if no app has been set.
 func AppFromContext(ctx context.Context) *App {
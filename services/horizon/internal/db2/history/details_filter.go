@@ -0,0 +1,54 @@
+package history
+
+import (
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/xdr"
+)
+
+// detailsAssetFilter returns the condition matching the rows whose details
+// column refers to the asset a in their asset_type, asset_code and
+// asset_issuer fields. It is backed by the indexes on these fields.
+func detailsAssetFilter(column string, a xdr.Asset) (sq.Sqlizer, error) {
+	var typ, code, iss string
+	err := a.Extract(&typ, &code, &iss)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.Type == xdr.AssetTypeAssetTypeNative {
+		clause := fmt.Sprintf(`
+			(%s->>'asset_type' = ?
+		AND %s ?? 'asset_code' = false
+		AND %s ?? 'asset_issuer' = false)`, column, column, column)
+		return sq.Expr(clause, typ), nil
+	}
+
+	clause := fmt.Sprintf(`
+		(%s->>'asset_type' = ?
+	AND %s->>'asset_code' = ?
+	AND %s->>'asset_issuer' = ?)`, column, column, column)
+	return sq.Expr(clause, typ, code, iss), nil
+}
+
+// detailsAmountFilter returns the condition matching the rows whose details
+// column has an amount field between min and max inclusive. A zero bound
+// leaves the range open on its side.
+func detailsAmountFilter(column string, min, max xdr.Int64) sq.Sqlizer {
+	and := sq.And{}
+	if min > 0 {
+		and = append(and, sq.Expr(
+			fmt.Sprintf("(%s->>'amount')::numeric >= ?::numeric", column),
+			amount.String(min),
+		))
+	}
+	if max > 0 {
+		and = append(and, sq.Expr(
+			fmt.Sprintf("(%s->>'amount')::numeric <= ?::numeric", column),
+			amount.String(max),
+		))
+	}
+	return and
+}
@@ -137,6 +137,41 @@ func (q *EffectsQ) OfType(typ EffectType) *EffectsQ {
 	return q
 }
 
+// ForTypes filters the query to only effects of the given types.
+func (q *EffectsQ) ForTypes(types ...EffectType) *EffectsQ {
+	q.sql = q.sql.Where(sq.Eq{"heff.type": types})
+	return q
+}
+
+// ForAsset filters the query to only effects whose asset is the given asset:
+// account credits and debits, and trust line changes.
+func (q *EffectsQ) ForAsset(asset xdr.Asset) *EffectsQ {
+	if q.Err != nil {
+		return q
+	}
+
+	var filter sq.Sqlizer
+	filter, q.Err = detailsAssetFilter("heff.details", asset)
+	if q.Err != nil {
+		return q
+	}
+
+	q.sql = q.sql.Where(filter)
+	return q
+}
+
+// ForAmount filters the query to only effects whose amount is between min and
+// max inclusive: account credits and debits. A zero bound leaves the range
+// open on its side.
+func (q *EffectsQ) ForAmount(min, max xdr.Int64) *EffectsQ {
+	if min <= 0 && max <= 0 {
+		return q
+	}
+
+	q.sql = q.sql.Where(detailsAmountFilter("heff.details", min, max))
+	return q
+}
+
 // Page specifies the paging constraints for the query being built by `q`.
 func (q *EffectsQ) Page(page db2.PageQuery) *EffectsQ {
 	if q.Err != nil {
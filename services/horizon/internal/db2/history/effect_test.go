@@ -0,0 +1,51 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/xdr"
+)
+
+func TestEffectFilters(t *testing.T) {
+	tt := test.Start(t).Scenario("non_native_payment")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	usd := xdr.MustNewCreditAsset("USD", "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
+
+	var effects []Effect
+	err := q.Effects().ForTypes(EffectAccountCredited, EffectTrustlineCreated).Select(&effects)
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(effects, 4)
+	}
+
+	effects = nil
+	err = q.Effects().ForAsset(usd).Select(&effects)
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(effects, 6)
+	}
+
+	effects = nil
+	err = q.Effects().ForAsset(xdr.MustNewNativeAsset()).Select(&effects)
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(effects, 3)
+	}
+
+	effects = nil
+	err = q.Effects().ForAmount(60*10000000, 0).Select(&effects)
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(effects, 5)
+	}
+
+	effects = nil
+	err = q.Effects().
+		ForTypes(EffectAccountDebited).
+		ForAsset(usd).
+		ForAmount(0, 60*10000000).
+		Select(&effects)
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(effects, 1)
+		tt.Assert.Equal(int64(21474840577), effects[0].HistoryOperationID)
+	}
+}
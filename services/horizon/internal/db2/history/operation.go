@@ -188,6 +188,44 @@ func (q *OperationsQ) OnlyPayments() *OperationsQ {
 	return q
 }
 
+// ForTypes filters the query being built to only include operations of the
+// given types.
+func (q *OperationsQ) ForTypes(types ...xdr.OperationType) *OperationsQ {
+	q.sql = q.sql.Where(sq.Eq{"hop.type": types})
+	return q
+}
+
+// ForAsset filters the query being built to only include operations whose
+// asset is the given asset: payments, path payments (by their destination
+// asset), trust line changes and authorizations.
+func (q *OperationsQ) ForAsset(asset xdr.Asset) *OperationsQ {
+	if q.Err != nil {
+		return q
+	}
+
+	var filter sq.Sqlizer
+	filter, q.Err = detailsAssetFilter("hop.details", asset)
+	if q.Err != nil {
+		return q
+	}
+
+	q.sql = q.sql.Where(filter)
+	return q
+}
+
+// ForAmount filters the query being built to only include operations whose
+// amount is between min and max inclusive: payments, path payments (by their
+// destination amount) and offers. A zero bound leaves the range open on its
+// side.
+func (q *OperationsQ) ForAmount(min, max xdr.Int64) *OperationsQ {
+	if min <= 0 && max <= 0 {
+		return q
+	}
+
+	q.sql = q.sql.Where(detailsAmountFilter("hop.details", min, max))
+	return q
+}
+
 // IncludeFailed changes the query to include failed transactions.
 func (q *OperationsQ) IncludeFailed() *OperationsQ {
 	q.includeFailed = true
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stellar/go/services/horizon/internal/db2"
 	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/xdr"
 )
 
 func TestOperationQueries(t *testing.T) {
@@ -293,3 +294,63 @@ func TestOperationIncludeTransactions(t *testing.T) {
 	tt.Assert.Equal(*transaction, expectedTransactions[0])
 	assertOperationMatchesTransaction(tt, op, *transaction)
 }
+
+func TestOperationFilters(t *testing.T) {
+	tt := test.Start(t).Scenario("non_native_payment")
+	defer tt.Finish()
+	q := &Q{tt.HorizonSession()}
+
+	usd := xdr.MustNewCreditAsset("USD", "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
+
+	// by type
+	ops, _, err := q.Operations().ForTypes(xdr.OperationTypeCreateAccount).Fetch()
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(ops, 3)
+	}
+
+	ops, _, err = q.Operations().
+		ForTypes(xdr.OperationTypePayment, xdr.OperationTypeChangeTrust).
+		Fetch()
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(ops, 4)
+	}
+
+	// by asset
+	ops, _, err = q.Operations().ForAsset(usd).Fetch()
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(ops, 4)
+	}
+
+	ops, _, err = q.Operations().ForAsset(xdr.MustNewNativeAsset()).Fetch()
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(ops, 0)
+	}
+
+	// by amount
+	ops, _, err = q.Operations().ForAmount(60*10000000, 0).Fetch()
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(ops, 1)
+		tt.Assert.Equal(int64(17179873281), ops[0].ID)
+	}
+
+	ops, _, err = q.Operations().ForAmount(0, 60*10000000).Fetch()
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(ops, 1)
+		tt.Assert.Equal(int64(21474840577), ops[0].ID)
+	}
+
+	ops, _, err = q.Operations().ForAmount(50*10000000, 100*10000000).Fetch()
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(ops, 2)
+	}
+
+	// combined
+	ops, _, err = q.Operations().
+		ForTypes(xdr.OperationTypePayment).
+		ForAsset(usd).
+		ForAmount(60*10000000, 0).
+		Fetch()
+	if tt.Assert.NoError(err) {
+		tt.Assert.Len(ops, 1)
+	}
+}
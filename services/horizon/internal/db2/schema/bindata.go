@@ -18,6 +18,7 @@
 // migrations/24_accounts.sql (1.402kB)
 // migrations/25_expingest_rename_columns.sql (641B)
 // migrations/26_exp_history_ledgers.sql (209B)
+// migrations/27_history_details_filters.sql (979B)
 // migrations/2_index_participants_by_toid.sql (277B)
 // migrations/3_use_sequence_in_history_accounts.sql (447B)
 // migrations/4_add_protocol_version.sql (188B)
@@ -455,6 +456,26 @@ func migrations26_exp_history_ledgersSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations27_history_details_filtersSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\x03\xbd\x92\x41\x4b\xc3\x40\x10\x85\xef\xfb\x2b\x86\x5e\x9a\x60\xf2\x07\x5a\x28\x88\x59\x34\x50\x36\x92\x36\xe8\x6d\x89\xdd\xa9\x5d\xa8\xbb\x61\x77\xc4\xf6\xdf\x1b\x43\x91\x48\x42\xba\x7a\xf0\xfe\xcd\xbc\x79\x6f\x5e\x9a\xc2\xcd\x9b\x7e\x75\x35\x21\x54\x0d\x63\x77\x25\xbf\xdd\x72\xc8\x45\xc6\x9f\x41\x1b\x85\x27\x79\xd0\x9e\xac\x3b\x4b\xdb\x60\x8b\x69\x6b\xbc\xb4\x46\xd2\xb9\x41\x59\x1b\x25\xb5\x82\x42\xc0\x10\x82\x6a\x93\x8b\x7b\x78\x21\x87\x08\xd1\x17\x9e\x80\x56\xf1\x32\x5c\xa2\xf6\x1e\x29\x64\x79\x14\x29\xa4\x5a\x1f\x3d\xa4\xab\x15\xcc\xbb\xb9\xee\xc0\xf9\x62\x41\x78\xa2\x38\x4e\x60\x94\xd9\x59\x75\x95\xd1\xde\xbf\xa3\xeb\x51\xad\x09\x78\x7a\xe0\x25\x87\xab\xba\x90\x6f\x40\x14\x5b\x10\xd5\x7a\x3d\xed\x1c\xf7\x7b\xdc\xd1\xcf\x64\xbf\xed\xf6\x33\xb8\x80\x63\xe9\x0e\x62\x6a\x7f\x93\xc0\xcc\x3a\x85\x6e\x16\x2c\x3f\x48\x7d\x54\xf1\x7f\x23\x9f\x76\xf6\xc7\x6f\xb0\xb4\xd7\xfd\xcc\x7e\x18\xc6\xb2\xb2\x78\xfc\x5d\xf7\x97\xa1\x33\xdd\x39\x13\xf4\x64\x01\xc2\xe6\x2e\x12\x9f\x08\x57\x71\x08\xd3\x03\x00\x00")
+
+func migrations27_history_details_filtersSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations27_history_details_filtersSql,
+		"migrations/27_history_details_filters.sql",
+	)
+}
+
+func migrations27_history_details_filtersSql() (*asset, error) {
+	bytes, err := migrations27_history_details_filtersSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/27_history_details_filters.sql", size: 979, mode: os.FileMode(0644), modTime: time.Unix(1792274977, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x17, 0x5c, 0x14, 0xef, 0xa9, 0x71, 0xa3, 0x4f, 0x2a, 0xb3, 0x03, 0x58, 0x9a, 0xe6, 0xff, 0x5d, 0x3b, 0xc9, 0x40, 0x0a, 0x6a, 0x2b, 0x18, 0xbc, 0x45, 0x62, 0xc1, 0xa6, 0xa5, 0xa2, 0xc5, 0xbe}}
+	return a, nil
+}
+
 var _migrations2_index_participants_by_toidSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x8f\xb1\xca\xc2\x50\x0c\x46\xf7\x3c\x45\xc6\xff\x47\xfa\x04\x9d\xc4\x16\xe9\xd2\x4a\xb5\xe0\x76\x49\xdb\x8b\xcd\xe0\xcd\x25\x37\x20\x7d\x7b\x41\x07\x5b\xbb\xb8\x86\x8f\x73\x72\xb2\x0c\x77\x77\xbe\x29\x99\xc7\x2e\x02\x1c\xda\x72\x7f\x29\xb1\xaa\x8b\xf2\x8a\x93\x44\xd7\xcf\x6e\x12\x1e\xb1\xa9\x71\xe2\x64\xa2\xb3\x93\xe8\x95\x8c\x25\xb8\x48\x6a\x3c\x70\xa4\x60\x09\xbb\x73\x55\x1f\xb1\x37\xf5\x1e\xff\xb6\x5b\x1e\xff\xf3\x2f\xbc\xbd\xf1\xb6\xc6\x9b\x52\x48\x34\xfc\x28\x58\xae\x5f\x0a\x58\x26\x15\xf2\x08\x00\x45\xdb\x9c\xb6\x49\xf9\xea\xfe\xf9\x25\x87\x67\x00\x00\x00\xff\xff\x33\xec\x54\x7a\x15\x01\x00\x00")
 
 func migrations2_index_participants_by_toidSqlBytes() ([]byte, error) {
@@ -762,6 +783,8 @@ var _bindata = map[string]func() (*asset, error){
 
 	"migrations/26_exp_history_ledgers.sql": migrations26_exp_history_ledgersSql,
 
+	"migrations/27_history_details_filters.sql": migrations27_history_details_filtersSql,
+
 	"migrations/2_index_participants_by_toid.sql": migrations2_index_participants_by_toidSql,
 
 	"migrations/3_use_sequence_in_history_accounts.sql": migrations3_use_sequence_in_history_accountsSql,
@@ -841,6 +864,7 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"24_accounts.sql":                              &bintree{migrations24_accountsSql, map[string]*bintree{}},
 		"25_expingest_rename_columns.sql":              &bintree{migrations25_expingest_rename_columnsSql, map[string]*bintree{}},
 		"26_exp_history_ledgers.sql":                   &bintree{migrations26_exp_history_ledgersSql, map[string]*bintree{}},
+		"27_history_details_filters.sql":               &bintree{migrations27_history_details_filtersSql, map[string]*bintree{}},
 		"2_index_participants_by_toid.sql":             &bintree{migrations2_index_participants_by_toidSql, map[string]*bintree{}},
 		"3_use_sequence_in_history_accounts.sql":       &bintree{migrations3_use_sequence_in_history_accountsSql, map[string]*bintree{}},
 		"4_add_protocol_version.sql":                   &bintree{migrations4_add_protocol_versionSql, map[string]*bintree{}},
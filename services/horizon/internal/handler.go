@@ -28,6 +28,10 @@ import (
 // with stream mode turned on using server-sent events.
 type streamFunc func(context.Context, *sse.Stream, *indexActionQueryParams) error
 
+// sharedStreamFunc returns the shared stream of a request, see sse.Fanout, or
+// nil when the stream requested can't be shared.
+type sharedStreamFunc func(*http.Request, *indexActionQueryParams) *sse.SharedStream
+
 // streamableEndpointHandler handles endpoints that have the stream mode
 // available. It inspects the Accept header to determine which function to be
 // executed. If it's "application/hal+json" or "application/json", then jfn
@@ -81,17 +85,8 @@ func (we *web) streamHandler(jfn interface{}, sfn streamFunc, params interface{}
 
 			// Rate limit the request if it's a call to stream since it queries the DB every second. See
 			// https://github.com/stellar/go/issues/715 for more details.
-			rateLimiter := we.rateLimiter
-			if rateLimiter != nil {
-				limited, _, err := rateLimiter.RateLimiter.RateLimit(rateLimiter.VaryBy.Key(r), 1)
-				if err != nil {
-					stream.Err(errors.Wrap(err, "RateLimiter error"))
-					return
-				}
-				if limited {
-					stream.Err(sse.ErrRateLimited)
-					return
-				}
+			if limited := we.rateLimitStream(r, stream); limited {
+				return
 			}
 
 			if sfn != nil {
@@ -160,6 +155,38 @@ func (we *web) streamHandler(jfn interface{}, sfn streamFunc, params interface{}
 	})
 }
 
+// serveSharedStream streams the events of shared through the sse.Fanout of the
+// app, so that they are loaded once for all the streams of the same topic. Like
+// actions.Base, the request is rate limited once, when it connects.
+func (we *web) serveSharedStream(w http.ResponseWriter, r *http.Request, shared sse.SharedStream) {
+	stream := sse.NewStream(r.Context(), w)
+	if limited := we.rateLimitStream(r, stream); limited {
+		return
+	}
+
+	we.streamFanout().Serve(r.Context(), stream, shared)
+}
+
+// rateLimitStream rate limits the stream requests, returning true after
+// sending an error on stream if the request was limited.
+func (we *web) rateLimitStream(r *http.Request, stream *sse.Stream) bool {
+	rateLimiter := we.rateLimiter
+	if rateLimiter == nil {
+		return false
+	}
+
+	limited, _, err := rateLimiter.RateLimiter.RateLimit(rateLimiter.VaryBy.Key(r), 1)
+	if err != nil {
+		stream.Err(errors.Wrap(err, "RateLimiter error"))
+		return true
+	}
+	if limited {
+		stream.Err(sse.ErrRateLimited)
+		return true
+	}
+	return false
+}
+
 // streamShowActionHandler gets the showAction query params from the request
 // and pass it on to streamableEndpointHandler.
 func (we *web) streamShowActionHandler(jfn interface{}, requireAccountID bool) http.HandlerFunc {
@@ -178,7 +205,9 @@ func (we *web) streamShowActionHandler(jfn interface{}, requireAccountID bool) h
 // streamIndexActionHandler gets the required params for indexable endpoints from
 // the URL, validates the cursor is within history, and finally passes the
 // indexAction query params to the more general purpose streamableEndpointHandler.
-func (we *web) streamIndexActionHandler(jfn interface{}, sfn streamFunc) http.HandlerFunc {
+// Streams are served through the sse.Fanout of the app when ssfn is not nil and
+// returns a shared stream.
+func (we *web) streamIndexActionHandler(jfn interface{}, sfn streamFunc, ssfn sharedStreamFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -194,6 +223,13 @@ func (we *web) streamIndexActionHandler(jfn interface{}, sfn streamFunc) http.Ha
 			return
 		}
 
+		if ssfn != nil && render.Negotiate(r) == render.MimeEventStream {
+			if shared := ssfn(r, params); shared != nil {
+				we.serveSharedStream(w, r, *shared)
+				return
+			}
+		}
+
 		we.streamableEndpointHandler(jfn, false, sfn, params).ServeHTTP(w, r)
 	})
 }
@@ -2,8 +2,18 @@ package horizon
 
 import (
 	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
 
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/protocols/horizon/effects"
+	"github.com/stellar/go/protocols/horizon/operations"
+	"github.com/stellar/go/services/horizon/internal/actions"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
 )
 
 func countNonEmpty(params ...interface{}) (int, error) {
@@ -39,3 +49,116 @@ func isValidTransactionHash(hash string) bool {
 
 	return len(decoded) == 32
 }
+
+// detailsFilters are the filters of the operations and effects by asset and
+// amount, parsed by parseDetailsFilters.
+type detailsFilters struct {
+	Asset     *xdr.Asset
+	MinAmount xdr.Int64
+	MaxAmount xdr.Int64
+}
+
+// parseDetailsFilters parses the asset (asset_type, asset_code and
+// asset_issuer) and amount (min_amount and max_amount) filters of a request.
+func parseDetailsFilters(action *actions.Base) (detailsFilters, error) {
+	var filters detailsFilters
+	if action.GetString("asset_type") != "" {
+		asset, err := actions.GetAsset(action.R, "")
+		if err != nil {
+			return filters, err
+		}
+		filters.Asset = &asset
+	}
+
+	for _, bound := range []struct {
+		name string
+		dest *xdr.Int64
+	}{
+		{"min_amount", &filters.MinAmount},
+		{"max_amount", &filters.MaxAmount},
+	} {
+		if action.GetString(bound.name) == "" {
+			continue
+		}
+		parsed, err := actions.GetPositiveAmount(action.R, bound.name)
+		if err != nil {
+			return filters, err
+		}
+		*bound.dest = parsed
+	}
+
+	if filters.MinAmount > 0 && filters.MaxAmount > 0 && filters.MinAmount > filters.MaxAmount {
+		return filters, problem.MakeInvalidFieldProblem(
+			"max_amount",
+			errors.New("max_amount must not be lower than min_amount"),
+		)
+	}
+	return filters, action.Err
+}
+
+// addTo adds the filters to the query parameters values.
+func (f detailsFilters) addTo(values url.Values) {
+	if f.Asset != nil {
+		values.Set("asset", f.Asset.String())
+	}
+	if f.MinAmount > 0 {
+		values.Set("min_amount", amount.String(f.MinAmount))
+	}
+	if f.MaxAmount > 0 {
+		values.Set("max_amount", amount.String(f.MaxAmount))
+	}
+}
+
+// parseOperationTypes returns the operation types of the comma separated list
+// of operation type names in the request parameter name.
+func parseOperationTypes(action *actions.Base, name string) ([]xdr.OperationType, error) {
+	value := action.GetString(name)
+	if value == "" {
+		return nil, action.Err
+	}
+
+	byName := map[string]xdr.OperationType{}
+	for typ, typeName := range operations.TypeNames {
+		byName[typeName] = typ
+	}
+
+	var types []xdr.OperationType
+	for _, part := range strings.Split(value, ",") {
+		typ, ok := byName[part]
+		if !ok {
+			return nil, problem.MakeInvalidFieldProblem(
+				name,
+				fmt.Errorf("unknown operation type '%s'", part),
+			)
+		}
+		types = append(types, typ)
+	}
+	return types, nil
+}
+
+// parseEffectTypes returns the effect types of the comma separated list of
+// effect type names in the request parameter name.
+func parseEffectTypes(action *actions.Base, name string) ([]history.EffectType, error) {
+	value := action.GetString(name)
+	if value == "" {
+		return nil, action.Err
+	}
+
+	byName := map[string]history.EffectType{}
+	for typ, typeName := range effects.EffectTypeNames {
+		byName[typeName] = history.EffectType(typ)
+	}
+
+	var types []history.EffectType
+	for _, part := range strings.Split(value, ",") {
+		typ, ok := byName[part]
+		if !ok {
+			return nil, problem.MakeInvalidFieldProblem(
+				name,
+				fmt.Errorf("unknown effect type '%s'", part),
+			)
+		}
+		types = append(types, typ)
+	}
+	return types, nil
+}
@@ -20,6 +20,9 @@ type currentStateFunc func() State
 type HistoryDBSource struct {
 	updateFrequency time.Duration
 	currentState    currentStateFunc
+	// legacy is true when the source follows the ledgers ingested by the
+	// legacy ingestion system rather than the experimental one.
+	legacy bool
 }
 
 // NewHistoryDBSource constructs a new instance of HistoryDBSource
@@ -30,9 +33,24 @@ func NewHistoryDBSource(updateFrequency time.Duration) HistoryDBSource {
 	}
 }
 
+// NewLegacyHistoryDBSource constructs a new instance of HistoryDBSource
+// following the ledgers ingested by the legacy ingestion system.
+func NewLegacyHistoryDBSource(updateFrequency time.Duration) HistoryDBSource {
+	source := NewHistoryDBSource(updateFrequency)
+	source.legacy = true
+	return source
+}
+
 // CurrentLedger returns the current ledger.
 func (source HistoryDBSource) CurrentLedger() uint32 {
-	return source.currentState().ExpHistoryLatest
+	return source.latest(source.currentState())
+}
+
+func (source HistoryDBSource) latest(state State) uint32 {
+	if source.legacy {
+		return uint32(state.HistoryLatest)
+	}
+	return state.ExpHistoryLatest
 }
 
 // NextLedger returns a channel which yields every time there is a new ledger with a sequence number larger than currentSequence.
@@ -47,9 +65,9 @@ func (source HistoryDBSource) NextLedger(currentSequence uint32) chan uint32 {
 				time.Sleep(source.updateFrequency)
 			}
 
-			currentLedgerState := source.currentState()
-			if currentLedgerState.ExpHistoryLatest > currentSequence {
-				newLedgers <- currentLedgerState.ExpHistoryLatest
+			latest := source.latest(source.currentState())
+			if latest > currentSequence {
+				newLedgers <- latest
 				return
 			}
 		}
@@ -41,3 +41,28 @@ func Test_HistoryDBLedgerSourceNextLedger(t *testing.T) {
 		t.Errorf("NextLedger = %d, want 3", nextLedger)
 	}
 }
+
+func Test_LegacyHistoryDBLedgerSource(t *testing.T) {
+	state := State{
+		HistoryLatest:    5,
+		ExpHistoryLatest: 3,
+	}
+
+	ledgerSource := HistoryDBSource{
+		updateFrequency: 0,
+		currentState: func() State {
+			return state
+		},
+		legacy: true,
+	}
+
+	currentLedger := ledgerSource.CurrentLedger()
+	if currentLedger != 5 {
+		t.Errorf("CurrentLedger = %d, want 5", currentLedger)
+	}
+
+	nextLedger := <-ledgerSource.NextLedger(4)
+	if nextLedger != 5 {
+		t.Errorf("NextLedger = %d, want 5", nextLedger)
+	}
+}
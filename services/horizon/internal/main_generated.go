@@ -111,3 +111,9 @@ func (action TransactionCreateAction) Handle(w http.ResponseWriter, r *http.Requ
 	ap.Prepare(w, r)
 	ap.Execute(&action)
 }
+
+func (action TransactionStatusAction) Handle(w http.ResponseWriter, r *http.Request) {
+	ap := &action.Action
+	ap.Prepare(w, r)
+	ap.Execute(&action)
+}
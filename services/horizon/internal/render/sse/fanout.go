@@ -0,0 +1,399 @@
+package sse
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/support/render/problem"
+)
+
+const (
+	// fanoutBatchSize is the maximum number of events loaded by a query.
+	fanoutBatchSize = 200
+	// fanoutBufferSize is the number of recent events kept by every topic, so that streams which
+	// subscribe with a cursor slightly behind their topic don't need to catch up again.
+	fanoutBufferSize = 1000
+	// subscriberQueueSize is the number of batches of events queued for a subscriber before it is
+	// considered to be lagging.
+	subscriberQueueSize = 16
+)
+
+// LoadEventsFunc loads the events of a stream following cursor, ordered by paging token, up to
+// limit events.
+type LoadEventsFunc func(ctx context.Context, cursor string, limit uint64) ([]Event, error)
+
+// SharedStream is a stream whose events only depend on its topic and cursor, so that they can be
+// loaded once for all the streams of the same topic.
+type SharedStream struct {
+	// Topic identifies the collection streamed and its filters, e.g. "/payments" or
+	// "/accounts/GABC.../payments?include_failed=true". The streams of a topic must load the same
+	// events.
+	Topic string
+	// Cursor is the paging token the stream starts after. Paging tokens are made of one or two
+	// integers separated by a dash.
+	Cursor string
+	// Limit is the maximum number of events sent, if not 0.
+	Limit int
+	// Load loads the events of the stream. It is also called for the other streams of the topic,
+	// possibly after the request of the stream is done, so it must only depend on the context it
+	// is given.
+	Load LoadEventsFunc
+}
+
+// Fanout shares the queries of the streams of the same topic between them.
+//
+// A stream first catches up on its own, loading the events following its cursor until it gets
+// to the latest events. It then subscribes to its topic: whenever a ledger closes, the events of
+// every topic are loaded once, from the last event loaded for the topic, and pushed to all its
+// subscribers. Subscribers which don't keep up are unsubscribed and catch up on their own again.
+// The number of queries made on every ledger thus grows with the number of topics streamed
+// rather than with the number of streams.
+type Fanout struct {
+	ctx context.Context
+
+	mutex  sync.Mutex
+	ledger uint32
+	topics map[string]*fanoutTopic
+}
+
+type fanoutTopic struct {
+	name   string
+	load   LoadEventsFunc
+	wakeup chan struct{}
+
+	mutex sync.Mutex
+	// cursor is the paging token of the last event loaded.
+	cursor string
+	// buffer holds the last events loaded, following bufferStart.
+	buffer      []Event
+	bufferStart string
+	subscribers map[*subscriber]bool
+}
+
+type subscriber struct {
+	topic *fanoutTopic
+	// cursor is the paging token of the last event queued, guarded by the mutex of the topic.
+	cursor string
+	events chan []Event
+}
+
+// NewFanout returns a Fanout loading the events of its topics on every ledger yielded by
+// ledgerSource, until ctx is done.
+func NewFanout(ctx context.Context, ledgerSource ledger.Source) *Fanout {
+	f := &Fanout{
+		ctx:    ctx,
+		ledger: ledgerSource.CurrentLedger(),
+		topics: map[string]*fanoutTopic{},
+	}
+	go f.run(ledgerSource, f.ledger)
+	return f
+}
+
+func (f *Fanout) run(ledgerSource ledger.Source, sequence uint32) {
+	for {
+		select {
+		case sequence = <-ledgerSource.NextLedger(sequence):
+		case <-f.ctx.Done():
+			return
+		}
+
+		f.mutex.Lock()
+		f.ledger = sequence
+		for _, t := range f.topics {
+			t.wake()
+		}
+		f.mutex.Unlock()
+	}
+}
+
+func (f *Fanout) currentLedger() uint32 {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.ledger
+}
+
+// Serve sends the events of s on stream, until the limit of s is reached or either ctx or the
+// Fanout is done.
+func (f *Fanout) Serve(ctx context.Context, stream *Stream, s SharedStream) {
+	if _, ok := parsePagingToken(s.Cursor); !ok {
+		stream.Err(problem.MakeInvalidFieldProblem("cursor", errors.Errorf("invalid cursor %s", s.Cursor)))
+		return
+	}
+	stream.SetLimit(s.Limit)
+
+	cursor := s.Cursor
+	for {
+		// catch up on our own, recording the ledger the latest events were loaded at
+		var sequence uint32
+		for {
+			sequence = f.currentLedger()
+			events, err := s.Load(ctx, cursor, fanoutBatchSize)
+			if err != nil {
+				stream.Err(err)
+				return
+			}
+			cursor = sendEvents(stream, events, cursor)
+			if stream.IsDone() {
+				return
+			}
+			if len(events) < fanoutBatchSize {
+				break
+			}
+		}
+
+		// Manually send the preamble in case there are no data events in SSE to trigger a
+		// stream.Send call.
+		stream.Init()
+
+		sub := f.subscribe(s, cursor, sequence)
+		if sub == nil {
+			continue
+		}
+		if done := f.receive(ctx, stream, sub, &cursor); done {
+			return
+		}
+	}
+}
+
+// subscribe subscribes to the topic of s with cursor, the paging token of the last event sent,
+// and sequence, the ledger at which the events following cursor were loaded. It returns nil when
+// the topic is ahead of cursor and doesn't buffer the events following it anymore, in which case
+// the stream must catch up again.
+func (f *Fanout) subscribe(s SharedStream, cursor string, sequence uint32) *subscriber {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	t, ok := f.topics[s.Topic]
+	if !ok {
+		t = &fanoutTopic{
+			name:        s.Topic,
+			load:        s.Load,
+			wakeup:      make(chan struct{}, 1),
+			cursor:      cursor,
+			bufferStart: cursor,
+			subscribers: map[*subscriber]bool{},
+		}
+		f.topics[s.Topic] = t
+		go f.runTopic(t)
+		// a ledger closed while the stream was catching up
+		if f.ledger > sequence {
+			t.wake()
+		}
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	sub := &subscriber{topic: t, cursor: cursor, events: make(chan []Event, subscriberQueueSize)}
+	if comparePagingTokens(cursor, t.cursor) < 0 {
+		if comparePagingTokens(cursor, t.bufferStart) < 0 {
+			return nil
+		}
+		if pending := eventsAfter(t.buffer, cursor); len(pending) > 0 {
+			sub.events <- pending
+			sub.cursor = pending[len(pending)-1].ID
+		}
+	}
+	t.subscribers[sub] = true
+	return sub
+}
+
+// receive sends the events published to sub on stream. It returns true when the stream is done,
+// and false when sub was unsubscribed by its topic.
+func (f *Fanout) receive(ctx context.Context, stream *Stream, sub *subscriber, cursor *string) bool {
+	defer sub.topic.unsubscribe(sub)
+
+	for {
+		select {
+		case events, ok := <-sub.events:
+			if !ok {
+				return false
+			}
+			*cursor = sendEvents(stream, eventsAfter(events, *cursor), *cursor)
+			if stream.IsDone() {
+				return true
+			}
+		case <-ctx.Done():
+			stream.Done()
+			return true
+		case <-f.ctx.Done():
+			stream.Done()
+			return true
+		}
+	}
+}
+
+func (f *Fanout) runTopic(t *fanoutTopic) {
+	for {
+		select {
+		case <-t.wakeup:
+		case <-f.ctx.Done():
+			t.close()
+			return
+		}
+
+		if f.removeTopic(t, false) {
+			return
+		}
+		if err := t.update(f.ctx); err != nil {
+			log.WithField("topic", t.name).WithStack(err).Error(errors.Wrap(err, "loading stream events"))
+			f.removeTopic(t, true)
+			t.close()
+			return
+		}
+	}
+}
+
+// removeTopic removes t from the topics of the Fanout, unless it still has subscribers and force
+// is false. It returns whether t was removed.
+func (f *Fanout) removeTopic(t *fanoutTopic, force bool) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !force && len(t.subscribers) > 0 {
+		return false
+	}
+	delete(f.topics, t.name)
+	return true
+}
+
+func (t *fanoutTopic) wake() {
+	select {
+	case t.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// update loads the events following the cursor of the topic and publishes them.
+func (t *fanoutTopic) update(ctx context.Context) error {
+	for {
+		events, err := t.load(ctx, t.cursor, fanoutBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(events) > 0 {
+			if err := t.publish(events); err != nil {
+				return err
+			}
+		}
+		if len(events) < fanoutBatchSize {
+			return nil
+		}
+	}
+}
+
+// publish buffers events and queues them for the subscribers, unsubscribing the subscribers
+// whose queue is full.
+func (t *fanoutTopic) publish(events []Event) error {
+	last := events[len(events)-1].ID
+	if _, ok := parsePagingToken(last); !ok {
+		return errors.Errorf("invalid paging token %s", last)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.cursor = last
+	t.buffer = append(t.buffer, events...)
+	if excess := len(t.buffer) - fanoutBufferSize; excess > 0 {
+		t.bufferStart = t.buffer[excess-1].ID
+		t.buffer = append([]Event(nil), t.buffer[excess:]...)
+	}
+
+	for sub := range t.subscribers {
+		pending := eventsAfter(events, sub.cursor)
+		if len(pending) == 0 {
+			continue
+		}
+		select {
+		case sub.events <- pending:
+			sub.cursor = last
+		default:
+			delete(t.subscribers, sub)
+			close(sub.events)
+		}
+	}
+	return nil
+}
+
+func (t *fanoutTopic) unsubscribe(sub *subscriber) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	delete(t.subscribers, sub)
+}
+
+// close unsubscribes all the subscribers of the topic.
+func (t *fanoutTopic) close() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for sub := range t.subscribers {
+		delete(t.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// sendEvents sends events on stream until its limit is reached, and returns the paging token of
+// the last event sent, or cursor if none was sent.
+func sendEvents(stream *Stream, events []Event, cursor string) string {
+	for _, event := range events {
+		if stream.IsDone() {
+			break
+		}
+		stream.Send(event)
+		cursor = event.ID
+	}
+	return cursor
+}
+
+// eventsAfter returns the events, ordered by paging token, following cursor.
+func eventsAfter(events []Event, cursor string) []Event {
+	for i, event := range events {
+		if comparePagingTokens(event.ID, cursor) > 0 {
+			return events[i:]
+		}
+	}
+	return nil
+}
+
+// parsePagingToken parses a paging token made of one or two integers separated by a dash. As in
+// db2.PageQuery.CursorInt64Pair, a missing second integer is the largest integer, and an empty
+// token is before every other token.
+func parsePagingToken(token string) ([2]int64, bool) {
+	if token == "" {
+		return [2]int64{}, true
+	}
+
+	parts := strings.SplitN(token, "-", 2)
+	parsed := [2]int64{0, math.MaxInt64}
+	for i, part := range parts {
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || n < 0 {
+			return [2]int64{}, false
+		}
+		parsed[i] = n
+	}
+	return parsed, true
+}
+
+// comparePagingTokens returns -1, 0 or 1 when a is respectively before, equal to or after b.
+// Invalid tokens are before every other token.
+func comparePagingTokens(a, b string) int {
+	pa, _ := parsePagingToken(a)
+	pb, _ := parsePagingToken(b)
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1
+		case pa[i] > pb[i]:
+			return 1
+		}
+	}
+	return 0
+}
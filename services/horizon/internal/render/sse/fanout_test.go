@@ -0,0 +1,265 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// broadcastLedgerSource is a ledger.Source which, unlike ledger.TestingSource,
+// supports several consumers.
+type broadcastLedgerSource struct {
+	mutex   sync.Mutex
+	current uint32
+	closed  chan struct{}
+}
+
+func newBroadcastLedgerSource(current uint32) *broadcastLedgerSource {
+	return &broadcastLedgerSource{current: current, closed: make(chan struct{})}
+}
+
+func (s *broadcastLedgerSource) CurrentLedger() uint32 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.current
+}
+
+func (s *broadcastLedgerSource) NextLedger(currentSequence uint32) chan uint32 {
+	next := make(chan uint32, 1)
+	go func() {
+		for {
+			s.mutex.Lock()
+			current, closed := s.current, s.closed
+			s.mutex.Unlock()
+			if current > currentSequence {
+				next <- current
+				return
+			}
+			<-closed
+		}
+	}()
+	return next
+}
+
+func (s *broadcastLedgerSource) closeLedger(sequence uint32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.current = sequence
+	close(s.closed)
+	s.closed = make(chan struct{})
+}
+
+// eventsDB stores the events of every topic and counts the queries made.
+type eventsDB struct {
+	mutex   sync.Mutex
+	events  map[string][]Event
+	queries map[string]int
+}
+
+func newEventsDB() *eventsDB {
+	return &eventsDB{events: map[string][]Event{}, queries: map[string]int{}}
+}
+
+func (db *eventsDB) add(topic string, count int) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	for i := 0; i < count; i++ {
+		id := strconv.Itoa(len(db.events[topic]) + 1)
+		db.events[topic] = append(db.events[topic], Event{ID: id, Data: topic + " " + id})
+	}
+}
+
+func (db *eventsDB) loader(topic string) LoadEventsFunc {
+	return func(ctx context.Context, cursor string, limit uint64) ([]Event, error) {
+		db.mutex.Lock()
+		defer db.mutex.Unlock()
+		db.queries[topic]++
+		events := eventsAfter(db.events[topic], cursor)
+		if uint64(len(events)) > limit {
+			events = events[:limit]
+		}
+		return append([]Event(nil), events...), nil
+	}
+}
+
+func (db *eventsDB) resetQueries() {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	db.queries = map[string]int{}
+}
+
+func subscriberCount(f *Fanout) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	count := 0
+	for _, t := range f.topics {
+		t.mutex.Lock()
+		count += len(t.subscribers)
+		t.mutex.Unlock()
+	}
+	return count
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFanoutQueriesGrowWithTopics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := newBroadcastLedgerSource(1)
+	fanout := NewFanout(ctx, source)
+	db := newEventsDB()
+
+	topics := []string{"/ledgers", "/payments", "/accounts/GABC/payments"}
+	const streamsPerTopic = 50
+	const eventsPerLedger = 5
+
+	var wg sync.WaitGroup
+	recorders := map[string][]*httptest.ResponseRecorder{}
+	for _, topic := range topics {
+		for i := 0; i < streamsPerTopic; i++ {
+			w := httptest.NewRecorder()
+			recorders[topic] = append(recorders[topic], w)
+			s := SharedStream{Topic: topic, Limit: eventsPerLedger, Load: db.loader(topic)}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				fanout.Serve(ctx, NewStream(ctx, w), s)
+			}()
+		}
+	}
+
+	waitFor(t, func() bool { return subscriberCount(fanout) == len(topics)*streamsPerTopic })
+	db.resetQueries()
+
+	for _, topic := range topics {
+		db.add(topic, eventsPerLedger)
+	}
+	source.closeLedger(2)
+	wg.Wait()
+
+	// a single query per topic loaded the events of the ledger for all the streams
+	for _, topic := range topics {
+		assert.Equal(t, 1, db.queries[topic], topic)
+		for _, w := range recorders[topic] {
+			body := w.Body.String()
+			assert.Equal(t, eventsPerLedger, strings.Count(body, "id: "))
+			assert.Contains(t, body, fmt.Sprintf("%q", fmt.Sprintf("%s %d", topic, eventsPerLedger)))
+		}
+	}
+}
+
+func TestFanoutCatchUp(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := newBroadcastLedgerSource(1)
+	fanout := NewFanout(ctx, source)
+	db := newEventsDB()
+	db.add("/payments", fanoutBatchSize+10)
+
+	// the stream loads the events on its own until it catches up
+	w := httptest.NewRecorder()
+	fanout.Serve(ctx, NewStream(ctx, w), SharedStream{
+		Topic:  "/payments",
+		Cursor: "5",
+		Limit:  fanoutBatchSize + 5,
+		Load:   db.loader("/payments"),
+	})
+	assert.Equal(t, 2, db.queries["/payments"])
+	assert.Equal(t, fanoutBatchSize+5, strings.Count(w.Body.String(), "id: "))
+	assert.NotContains(t, w.Body.String(), `"/payments 5"`)
+	assert.Contains(t, w.Body.String(), `"/payments 6"`)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`"/payments %d"`, fanoutBatchSize+10))
+}
+
+func TestFanoutSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fanout := NewFanout(ctx, newBroadcastLedgerSource(1))
+	db := newEventsDB()
+	s := SharedStream{Topic: "/ledgers", Load: db.loader("/ledgers")}
+
+	lagging := fanout.subscribe(s, "", 1)
+	require.NotNil(t, lagging)
+	topic := lagging.topic
+
+	db.add("/ledgers", fanoutBufferSize+5)
+	require.NoError(t, topic.publish(db.events["/ledgers"]))
+	assert.Equal(t, "5", topic.bufferStart)
+	assert.Len(t, topic.buffer, fanoutBufferSize)
+
+	// the events following the cursor are no longer buffered
+	assert.Nil(t, fanout.subscribe(s, "3", 1))
+
+	// the buffered events following the cursor are queued
+	sub := fanout.subscribe(s, "10", 1)
+	require.NotNil(t, sub)
+	pending := <-sub.events
+	assert.Equal(t, "11", pending[0].ID)
+	assert.Equal(t, strconv.Itoa(fanoutBufferSize+5), pending[len(pending)-1].ID)
+
+	// subscribers which don't keep up are unsubscribed
+	for i := 0; i < subscriberQueueSize; i++ {
+		db.add("/ledgers", 1)
+		events := db.events["/ledgers"]
+		require.NoError(t, topic.publish(events[len(events)-1:]))
+		<-sub.events
+	}
+	batches := 0
+	for range lagging.events {
+		batches++
+	}
+	assert.Equal(t, subscriberQueueSize, batches)
+	topic.mutex.Lock()
+	assert.False(t, topic.subscribers[lagging])
+	assert.True(t, topic.subscribers[sub])
+	topic.mutex.Unlock()
+}
+
+func TestFanoutInvalidCursor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fanout := NewFanout(ctx, newBroadcastLedgerSource(1))
+
+	w := httptest.NewRecorder()
+	fanout.Serve(ctx, NewStream(ctx, w), SharedStream{
+		Topic:  "/ledgers",
+		Cursor: "abc",
+		Load:   newEventsDB().loader("/ledgers"),
+	})
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "cursor")
+}
+
+func TestComparePagingTokens(t *testing.T) {
+	for _, tc := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"", "1", -1},
+		{"2", "10", -1},
+		{"10", "10", 0},
+		{"10-2", "10-1", 1},
+		{"10", "10-1", 1},
+		{"9-5", "10", -1},
+	} {
+		assert.Equal(t, tc.expected, comparePagingTokens(tc.a, tc.b), "%s %s", tc.a, tc.b)
+		assert.Equal(t, -tc.expected, comparePagingTokens(tc.b, tc.a), "%s %s", tc.b, tc.a)
+	}
+}
@@ -0,0 +1,33 @@
+package resourceadapter
+
+import (
+	"context"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/httpx"
+	"github.com/stellar/go/services/horizon/internal/txsub"
+	"github.com/stellar/go/support/render/hal"
+)
+
+// PopulateTransactionStatus fills out the details
+func PopulateTransactionStatus(ctx context.Context, dest *protocol.TransactionStatus, status txsub.Status) error {
+	dest.Hash = status.Hash
+	dest.Status = status.State
+	dest.Ledger = status.Result.LedgerSequence
+	dest.Env = status.Result.EnvelopeXDR
+	dest.Result = status.Result.ResultXDR
+	dest.Meta = status.Result.ResultMetaXDR
+
+	if fail, ok := status.Result.Err.(*txsub.FailedTransactionError); ok {
+		dest.Result = fail.ResultXDR
+		dest.ResultCodes = &protocol.TransactionResultCodes{}
+		if err := PopulateTransactionResultCodes(ctx, dest.ResultCodes, fail); err != nil {
+			return err
+		}
+	}
+
+	lb := hal.LinkBuilder{httpx.BaseURL(ctx)}
+	dest.Links.Self = lb.Link("/transactions", status.Hash, "status")
+	dest.Links.Transaction = lb.Link("/transactions", status.Hash)
+	return nil
+}
@@ -0,0 +1,427 @@
+package horizon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/stellar/go/services/horizon/internal/render"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+)
+
+const (
+	// streamSocketPath is the path of the WebSocket endpoint multiplexing
+	// streams.
+	streamSocketPath = "/ws"
+	// maxStreamSubscriptions is the maximum number of streams a connection can
+	// subscribe to at the same time.
+	maxStreamSubscriptions = 20
+	// maxStreamSocketMessageBytes is the maximum size of the messages sent by
+	// the clients, which are a few hundred bytes of JSON.
+	maxStreamSocketMessageBytes = 4096
+)
+
+// Types of the messages sent by the clients.
+const (
+	streamSocketSubscribe   = "subscribe"
+	streamSocketUnsubscribe = "unsubscribe"
+)
+
+// Types of the messages sent to the clients.
+const (
+	streamSocketSubscribed = "subscribed"
+	streamSocketEvent      = "event"
+	streamSocketError      = "error"
+	streamSocketClosed     = "closed"
+)
+
+// streamSocketRequest is a message sent by a client, subscribing to or
+// unsubscribing from a stream.
+type streamSocketRequest struct {
+	Type string `json:"type"`
+	// ID identifies the subscription in the messages of the connection. It is
+	// chosen by the client.
+	ID string `json:"id"`
+	// Path is the path of the streamed resource, e.g. "/accounts/G.../payments",
+	// with its query parameters.
+	Path string `json:"path,omitempty"`
+	// Cursor and Limit, when set, override the cursor and limit parameters of
+	// Path.
+	Cursor string `json:"cursor,omitempty"`
+	Limit  uint64 `json:"limit,omitempty"`
+}
+
+// streamSocketMessage is a message sent to a client.
+type streamSocketMessage struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+	// Cursor is the paging token of an event, or of the last event of a closed
+	// subscription.
+	Cursor string `json:"cursor,omitempty"`
+	// Data is the resource of an event.
+	Data json.RawMessage `json:"data,omitempty"`
+	// Status and Problem are the status and problem of a subscription rejected
+	// by its endpoint.
+	Status  int             `json:"status,omitempty"`
+	Problem json.RawMessage `json:"problem,omitempty"`
+	// Error describes the other errors.
+	Error string `json:"error,omitempty"`
+}
+
+// streamSocketHandler serves the WebSocket endpoint multiplexing streams. A
+// single connection can subscribe to several streams, each subscription being
+// served by the SSE endpoint of the streamed resource: the rate limits of the
+// endpoints apply to the subscriptions as to the SSE requests made by the
+// client, and a subscription is closed when its SSE stream is, e.g. once its
+// limit is reached.
+//
+// A subscription is requested with
+//
+//	{"type": "subscribe", "id": "payments", "path": "/accounts/G.../payments", "cursor": "now"}
+//
+// and cancelled with
+//
+//	{"type": "unsubscribe", "id": "payments"}
+//
+// The events of the subscription are sent as
+//
+//	{"type": "event", "id": "payments", "cursor": "<paging token>", "data": {...}}
+//
+// and every subscription ends with a "closed" message including the cursor of
+// its last event, which can be used to subscribe again. As the SSE requests,
+// the connection is closed once the request times out. It is also closed when
+// the client sends a message larger than 4KB.
+type streamSocketHandler struct {
+	// router serves the SSE requests of the subscriptions.
+	router http.Handler
+}
+
+func (h streamSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server := websocket.Server{
+		// Horizon accepts cross-origin requests, so the origin is not checked.
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			ws.MaxPayloadBytes = maxStreamSocketMessageBytes
+			socket := &streamSocket{
+				router:        h.router,
+				ws:            ws,
+				r:             r,
+				subscriptions: map[string]*streamSubscription{},
+			}
+			socket.serve(r.Context())
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+// streamSocket is a WebSocket connection and its subscriptions.
+type streamSocket struct {
+	router http.Handler
+	ws     *websocket.Conn
+	// r is the request which opened the connection.
+	r *http.Request
+
+	sendMutex sync.Mutex
+
+	mutex         sync.Mutex
+	subscriptions map[string]*streamSubscription
+	wg            sync.WaitGroup
+}
+
+// streamSubscription is a stream a connection subscribed to.
+type streamSubscription struct {
+	cancel context.CancelFunc
+}
+
+// serve reads the requests of the client until the connection is closed or
+// ctx is done.
+func (s *streamSocket) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		s.unsubscribeAll()
+		s.wg.Wait()
+	}()
+
+	go func() {
+		<-ctx.Done()
+		s.ws.Close()
+	}()
+
+	for {
+		var message []byte
+		if err := websocket.Message.Receive(s.ws, &message); err != nil {
+			if err == websocket.ErrFrameTooLarge {
+				s.send(streamSocketMessage{
+					Type:  streamSocketError,
+					Error: "message larger than " + strconv.Itoa(maxStreamSocketMessageBytes) + " bytes",
+				})
+			}
+			return
+		}
+
+		var request streamSocketRequest
+		if err := json.Unmarshal(message, &request); err != nil {
+			s.send(streamSocketMessage{Type: streamSocketError, Error: "invalid message: " + err.Error()})
+			continue
+		}
+
+		switch request.Type {
+		case streamSocketSubscribe:
+			if err := s.subscribe(request); err != nil {
+				s.send(streamSocketMessage{Type: streamSocketError, ID: request.ID, Error: err.Error()})
+			}
+		case streamSocketUnsubscribe:
+			s.unsubscribe(request.ID)
+		default:
+			s.send(streamSocketMessage{
+				Type:  streamSocketError,
+				ID:    request.ID,
+				Error: "unknown message type " + strconv.Quote(request.Type),
+			})
+		}
+	}
+}
+
+// send sends message to the client. Errors are ignored, the connection being
+// closed by serve when it can't be read anymore.
+func (s *streamSocket) send(message streamSocketMessage) {
+	s.sendMutex.Lock()
+	defer s.sendMutex.Unlock()
+	if err := websocket.JSON.Send(s.ws, message); err != nil {
+		log.Ctx(s.r.Context()).WithField("subscription", message.ID).Debug("failed to send stream socket message")
+	}
+}
+
+func (s *streamSocket) subscribe(request streamSocketRequest) error {
+	if request.ID == "" {
+		return errors.New("missing subscription id")
+	}
+
+	u, err := url.Parse(request.Path)
+	if err != nil {
+		return errors.Wrap(err, "invalid path")
+	}
+	if u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+		return errors.New("the path must be absolute and not include a host")
+	}
+	if strings.TrimSuffix(u.Path, "/") == streamSocketPath {
+		return errors.New("invalid path")
+	}
+
+	query := u.Query()
+	if request.Cursor != "" {
+		query.Set("cursor", request.Cursor)
+	}
+	if request.Limit != 0 {
+		query.Set("limit", strconv.FormatUint(request.Limit, 10))
+	}
+	u.RawQuery = query.Encode()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.subscriptions[request.ID]; ok {
+		return errors.Errorf("subscription %s already exists", request.ID)
+	}
+	if len(s.subscriptions) >= maxStreamSubscriptions {
+		return errors.Errorf("too many subscriptions, the maximum is %d", maxStreamSubscriptions)
+	}
+
+	// The SSE request is routed from scratch, so its context must not derive
+	// from the one of the connection, which holds the routing state of chi.
+	ctx, cancel := context.WithCancel(context.Background())
+	subscription := &streamSubscription{cancel: cancel}
+	s.subscriptions[request.ID] = subscription
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.stream(ctx, request.ID, u)
+		s.remove(request.ID, subscription)
+	}()
+	return nil
+}
+
+func (s *streamSocket) unsubscribe(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if subscription, ok := s.subscriptions[id]; ok {
+		subscription.cancel()
+		delete(s.subscriptions, id)
+	}
+}
+
+func (s *streamSocket) unsubscribeAll() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, subscription := range s.subscriptions {
+		subscription.cancel()
+	}
+}
+
+// remove removes subscription, identified by id, after its stream is done.
+// The client may already have subscribed again with the same id.
+func (s *streamSocket) remove(id string, subscription *streamSubscription) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	subscription.cancel()
+	if s.subscriptions[id] == subscription {
+		delete(s.subscriptions, id)
+	}
+}
+
+// stream serves the SSE stream of u, forwarding its events to the client,
+// until it is done or ctx is cancelled.
+func (s *streamSocket) stream(ctx context.Context, id string, u *url.URL) {
+	r, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		s.send(streamSocketMessage{Type: streamSocketError, ID: id, Error: err.Error()})
+		s.send(streamSocketMessage{Type: streamSocketClosed, ID: id})
+		return
+	}
+	r = r.WithContext(ctx)
+
+	// The request keeps the headers and address of the client, so that it is
+	// rate limited and rendered as if the client made it.
+	for key, values := range s.r.Header {
+		switch {
+		case strings.HasPrefix(key, "Sec-Websocket-"), key == "Upgrade", key == "Connection":
+			continue
+		}
+		r.Header[key] = values
+	}
+	r.Header.Set("Accept", render.MimeEventStream)
+	r.Host = s.r.Host
+	r.RemoteAddr = s.r.RemoteAddr
+	r.TLS = s.r.TLS
+
+	w := &streamSocketWriter{
+		socket: s,
+		id:     id,
+		header: http.Header{},
+		closed: make(chan bool, 1),
+	}
+	go func() {
+		<-ctx.Done()
+		w.closed <- true
+	}()
+
+	s.router.ServeHTTP(w, r)
+	w.finish()
+}
+
+// streamSocketWriter is the http.ResponseWriter of the SSE request of a
+// subscription, translating the SSE events written into messages.
+type streamSocketWriter struct {
+	socket *streamSocket
+	id     string
+	header http.Header
+	closed chan bool
+
+	status int
+	buffer bytes.Buffer
+	// cursor is the paging token of the last event sent.
+	cursor string
+}
+
+func (w *streamSocketWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *streamSocketWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *streamSocketWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.buffer.Write(p)
+}
+
+// Flush sends the events written so far.
+func (w *streamSocketWriter) Flush() {
+	if !w.streaming() {
+		return
+	}
+
+	for {
+		i := bytes.Index(w.buffer.Bytes(), []byte("\n\n"))
+		if i < 0 {
+			return
+		}
+		w.sendEvent(string(w.buffer.Next(i + 2)))
+	}
+}
+
+// CloseNotify notifies when the subscription is cancelled, as the requests
+// are by httpx.RequestContext.
+func (w *streamSocketWriter) CloseNotify() <-chan bool {
+	return w.closed
+}
+
+func (w *streamSocketWriter) streaming() bool {
+	return w.status == http.StatusOK &&
+		strings.HasPrefix(w.header.Get("Content-Type"), render.MimeEventStream)
+}
+
+// sendEvent sends the SSE event formatted by sse.WriteEvent.
+func (w *streamSocketWriter) sendEvent(event string) {
+	var id, name, data string
+	for _, line := range strings.Split(strings.TrimSpace(event), "\n") {
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+
+	switch name {
+	case "open":
+		w.socket.send(streamSocketMessage{Type: streamSocketSubscribed, ID: w.id})
+	case "close":
+		// the subscription is closed by finish
+	case "error":
+		w.socket.send(streamSocketMessage{Type: streamSocketError, ID: w.id, Error: data})
+	default:
+		if id != "" {
+			w.cursor = id
+		}
+		w.socket.send(streamSocketMessage{
+			Type:   streamSocketEvent,
+			ID:     w.id,
+			Cursor: id,
+			Data:   json.RawMessage(data),
+		})
+	}
+}
+
+// finish sends the last events of the stream, or the problem of the request if
+// it was rejected, and closes the subscription.
+func (w *streamSocketWriter) finish() {
+	w.Flush()
+
+	if w.status != 0 && !w.streaming() {
+		message := streamSocketMessage{Type: streamSocketError, ID: w.id, Status: w.status}
+		if body := bytes.TrimSpace(w.buffer.Bytes()); w.status == http.StatusOK {
+			message.Error = "the resource can't be streamed"
+		} else if json.Valid(body) {
+			message.Problem = json.RawMessage(body)
+		} else {
+			message.Error = string(body)
+		}
+		w.socket.send(message)
+	}
+
+	w.socket.send(streamSocketMessage{Type: streamSocketClosed, ID: w.id, Cursor: w.cursor})
+}
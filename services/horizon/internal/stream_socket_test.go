@@ -0,0 +1,159 @@
+package horizon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/stellar/go/services/horizon/internal/render"
+	"github.com/stellar/go/services/horizon/internal/render/sse"
+	"github.com/stellar/go/support/render/problem"
+)
+
+func newStreamSocketTest(t *testing.T) (*websocket.Conn, func()) {
+	router := chi.NewRouter()
+	router.Get("/count", func(w http.ResponseWriter, r *http.Request) {
+		if render.Negotiate(r) != render.MimeEventStream {
+			problem.Render(r.Context(), w, problem.BadRequest)
+			return
+		}
+		cursor, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		stream := sse.NewStream(r.Context(), w)
+		stream.SetLimit(limit)
+		for i := cursor + 1; !stream.IsDone(); i++ {
+			stream.Send(sse.Event{ID: strconv.Itoa(i), Data: map[string]int{"count": i}})
+		}
+	})
+	router.Get("/forever", func(w http.ResponseWriter, r *http.Request) {
+		stream := sse.NewStream(r.Context(), w)
+		stream.Init()
+		<-r.Context().Done()
+		stream.Done()
+	})
+	router.Get("/missing", func(w http.ResponseWriter, r *http.Request) {
+		problem.Render(r.Context(), w, problem.NotFound)
+	})
+
+	server := httptest.NewServer(streamSocketHandler{router: router})
+	ws, err := websocket.Dial(strings.Replace(server.URL, "http", "ws", 1), "", server.URL)
+	require.NoError(t, err)
+
+	return ws, func() {
+		ws.Close()
+		server.Close()
+	}
+}
+
+func sendStreamSocketRequest(t *testing.T, ws *websocket.Conn, request streamSocketRequest) {
+	require.NoError(t, websocket.JSON.Send(ws, request))
+}
+
+func receiveStreamSocketMessage(t *testing.T, ws *websocket.Conn) streamSocketMessage {
+	var message streamSocketMessage
+	require.NoError(t, websocket.JSON.Receive(ws, &message))
+	return message
+}
+
+func TestStreamSocketSubscription(t *testing.T) {
+	ws, done := newStreamSocketTest(t)
+	defer done()
+
+	sendStreamSocketRequest(t, ws, streamSocketRequest{
+		Type:   streamSocketSubscribe,
+		ID:     "count",
+		Path:   "/count?limit=5",
+		Cursor: "3",
+		Limit:  2,
+	})
+
+	message := receiveStreamSocketMessage(t, ws)
+	assert.Equal(t, streamSocketMessage{Type: streamSocketSubscribed, ID: "count"}, message)
+
+	message = receiveStreamSocketMessage(t, ws)
+	assert.Equal(t, streamSocketEvent, message.Type)
+	assert.Equal(t, "count", message.ID)
+	assert.Equal(t, "4", message.Cursor)
+	assert.JSONEq(t, `{"count": 4}`, string(message.Data))
+
+	message = receiveStreamSocketMessage(t, ws)
+	assert.Equal(t, "5", message.Cursor)
+	assert.JSONEq(t, `{"count": 5}`, string(message.Data))
+
+	// the subscription is closed once its limit is reached
+	message = receiveStreamSocketMessage(t, ws)
+	assert.Equal(t, streamSocketMessage{Type: streamSocketClosed, ID: "count", Cursor: "5"}, message)
+}
+
+func TestStreamSocketUnsubscribe(t *testing.T) {
+	ws, done := newStreamSocketTest(t)
+	defer done()
+
+	sendStreamSocketRequest(t, ws, streamSocketRequest{Type: streamSocketSubscribe, ID: "forever", Path: "/forever"})
+	message := receiveStreamSocketMessage(t, ws)
+	assert.Equal(t, streamSocketSubscribed, message.Type)
+
+	sendStreamSocketRequest(t, ws, streamSocketRequest{Type: streamSocketSubscribe, ID: "forever", Path: "/forever"})
+	message = receiveStreamSocketMessage(t, ws)
+	assert.Equal(t, streamSocketError, message.Type)
+	assert.Equal(t, "subscription forever already exists", message.Error)
+
+	sendStreamSocketRequest(t, ws, streamSocketRequest{Type: streamSocketUnsubscribe, ID: "forever"})
+	message = receiveStreamSocketMessage(t, ws)
+	assert.Equal(t, streamSocketMessage{Type: streamSocketClosed, ID: "forever"}, message)
+}
+
+func TestStreamSocketErrors(t *testing.T) {
+	ws, done := newStreamSocketTest(t)
+	defer done()
+
+	sendStreamSocketRequest(t, ws, streamSocketRequest{Type: streamSocketSubscribe, ID: "missing", Path: "/missing"})
+	message := receiveStreamSocketMessage(t, ws)
+	assert.Equal(t, streamSocketError, message.Type)
+	assert.Equal(t, "missing", message.ID)
+	assert.Equal(t, http.StatusNotFound, message.Status)
+	var p problem.P
+	require.NoError(t, json.Unmarshal(message.Problem, &p))
+	assert.Equal(t, http.StatusNotFound, p.Status)
+	message = receiveStreamSocketMessage(t, ws)
+	assert.Equal(t, streamSocketMessage{Type: streamSocketClosed, ID: "missing"}, message)
+
+	for _, path := range []string{"https://example.com/count", "count", "/ws"} {
+		sendStreamSocketRequest(t, ws, streamSocketRequest{Type: streamSocketSubscribe, ID: "invalid", Path: path})
+		message = receiveStreamSocketMessage(t, ws)
+		assert.Equal(t, streamSocketError, message.Type, path)
+		assert.Equal(t, "invalid", message.ID, path)
+	}
+
+	sendStreamSocketRequest(t, ws, streamSocketRequest{Type: "publish", ID: "count"})
+	message = receiveStreamSocketMessage(t, ws)
+	assert.Equal(t, `unknown message type "publish"`, message.Error)
+
+	require.NoError(t, websocket.Message.Send(ws, "{"))
+	message = receiveStreamSocketMessage(t, ws)
+	assert.Equal(t, streamSocketError, message.Type)
+	assert.Contains(t, message.Error, "invalid message")
+}
+
+func TestStreamSocketMessageTooLarge(t *testing.T) {
+	ws, done := newStreamSocketTest(t)
+	defer done()
+
+	require.NoError(t, websocket.Message.Send(ws, strings.Repeat("a", maxStreamSocketMessageBytes+1)))
+	message := receiveStreamSocketMessage(t, ws)
+	assert.Equal(t, streamSocketError, message.Type)
+	assert.Equal(t, "message larger than 4096 bytes", message.Error)
+
+	// the connection is closed
+	var data []byte
+	assert.Error(t, websocket.Message.Receive(ws, &data))
+}
@@ -0,0 +1,208 @@
+package txsub
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/support/log"
+)
+
+// The states of a transaction submitted asynchronously.
+const (
+	// StatusQueued is the state of a transaction waiting for the sequence
+	// number of its source account to be submitted to stellar-core.
+	StatusQueued = "queued"
+	// StatusSent is the state of a transaction accepted by stellar-core and
+	// waiting to be included in a ledger.
+	StatusSent = "sent"
+	// StatusInLedger is the state of a transaction successfully applied in a
+	// ledger.
+	StatusInLedger = "in_ledger"
+	// StatusFailed is the state of a transaction rejected by stellar-core or
+	// which failed in a ledger.
+	StatusFailed = "failed"
+	// StatusTimeout is the state of a transaction which wasn't included in a
+	// ledger within the submission timeout. It may still be included in a
+	// later ledger, in which case its state becomes StatusInLedger or
+	// StatusFailed.
+	StatusTimeout = "timeout"
+)
+
+// statusRetention is how long the statuses of asynchronous submissions are
+// kept in memory after their last update. Later, the status of a transaction
+// is only found if it is in a ledger.
+const statusRetention = 10 * time.Minute
+
+// Status represents the state of a transaction submitted asynchronously.
+type Status struct {
+	Hash  string
+	State string
+
+	// Result is the result of the transaction once it is in a ledger or
+	// failed, in which case Result.Err is set.
+	Result Result
+}
+
+// Done returns true if the state of the transaction won't change anymore.
+func (s Status) Done() bool {
+	return s.State == StatusInLedger || s.State == StatusFailed
+}
+
+// statusFromResult returns the status of the transaction hash given its
+// result, or false if the result doesn't determine its status.
+func statusFromResult(hash string, r Result) (Status, bool) {
+	switch r.Err.(type) {
+	case nil:
+		return Status{Hash: hash, State: StatusInLedger, Result: r}, true
+	case *FailedTransactionError:
+		return Status{Hash: hash, State: StatusFailed, Result: r}, true
+	}
+
+	switch r.Err {
+	case ErrNoResults:
+		return Status{}, false
+	case ErrTimeout, ErrCanceled:
+		return Status{Hash: hash, State: StatusTimeout}, true
+	default:
+		return Status{Hash: hash, State: StatusFailed, Result: r}, true
+	}
+}
+
+type trackedStatus struct {
+	Status
+	UpdatedAt time.Time
+}
+
+// statusList tracks the statuses of the transactions submitted asynchronously
+// purely in memory.
+type statusList struct {
+	sync.Mutex
+	statuses map[string]*trackedStatus // hash => `*trackedStatus`
+}
+
+func newStatusList() *statusList {
+	return &statusList{statuses: map[string]*trackedStatus{}}
+}
+
+// Track starts tracking the status of the transaction hash as queued. It
+// returns false if the transaction is already queued or sent, in which case it
+// shouldn't be submitted again.
+func (l *statusList) Track(hash string) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	if s, ok := l.statuses[hash]; ok && (s.State == StatusQueued || s.State == StatusSent) {
+		return false
+	}
+	l.statuses[hash] = &trackedStatus{
+		Status:    Status{Hash: hash, State: StatusQueued},
+		UpdatedAt: time.Now(),
+	}
+	return true
+}
+
+// Update sets the status of a tracked transaction. It is a no-op for the
+// transactions not submitted asynchronously.
+func (l *statusList) Update(s Status) {
+	l.Lock()
+	defer l.Unlock()
+
+	if _, ok := l.statuses[s.Hash]; !ok {
+		return
+	}
+	l.statuses[s.Hash] = &trackedStatus{Status: s, UpdatedAt: time.Now()}
+}
+
+// Get returns the status of the transaction hash, if tracked.
+func (l *statusList) Get(hash string) (Status, bool) {
+	l.Lock()
+	defer l.Unlock()
+
+	s, ok := l.statuses[hash]
+	if !ok {
+		return Status{}, false
+	}
+	return s.Status, true
+}
+
+// Clean removes the statuses not updated for over maxAge.
+func (l *statusList) Clean(maxAge time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+
+	for hash, s := range l.statuses {
+		if time.Since(s.UpdatedAt) > maxAge {
+			delete(l.statuses, hash)
+		}
+	}
+}
+
+// SubmitAsync submits the provided base64 encoded transaction envelope to the
+// network in the background, returning the hash of the transaction once it is
+// queued. The progress of the submission is then reported by Status.
+func (sys *System) SubmitAsync(ctx context.Context, env string) (string, error) {
+	sys.Init()
+
+	info, err := extractEnvelopeInfo(ctx, env, sys.NetworkPassphrase)
+	if err != nil {
+		return "", err
+	}
+
+	if !sys.statuses.Track(info.Hash) {
+		sys.Log.Ctx(ctx).WithField("hash", info.Hash).Info("Transaction already submitted asynchronously")
+		return info.Hash, nil
+	}
+
+	// The submission outlives the request: it is canceled by the submission
+	// timeout only.
+	submissionCtx, cancel := context.WithTimeout(
+		log.Set(context.Background(), log.Ctx(ctx)),
+		sys.SubmissionTimeout,
+	)
+	go func() {
+		defer cancel()
+		r := <-sys.Submit(submissionCtx, env)
+		s, ok := statusFromResult(info.Hash, r)
+		if !ok {
+			s = Status{Hash: info.Hash, State: StatusFailed, Result: r}
+		}
+		sys.statuses.Update(s)
+	}()
+
+	return info.Hash, nil
+}
+
+// Status returns the status of the transaction hash submitted asynchronously.
+// Transactions found in a ledger are reported whether they were submitted
+// asynchronously or not. ErrNoResults is returned for unknown transactions.
+func (sys *System) Status(ctx context.Context, hash string) (Status, error) {
+	sys.Init()
+
+	tracked, ok := sys.statuses.Get(hash)
+	if ok && tracked.Done() {
+		return tracked, nil
+	}
+
+	r := sys.Results.ResultByHash(ctx, hash)
+	switch r.Err.(type) {
+	case nil, *FailedTransactionError:
+		s, _ := statusFromResult(hash, r)
+		return s, nil
+	}
+	if r.Err != ErrNoResults {
+		return Status{}, r.Err
+	}
+
+	if ok {
+		return tracked, nil
+	}
+
+	for _, pending := range sys.Pending.Pending(ctx) {
+		if pending == hash {
+			return Status{Hash: hash, State: StatusSent}, nil
+		}
+	}
+
+	return Status{}, ErrNoResults
+}
@@ -20,6 +20,9 @@ type System struct {
 	tickMutex      sync.Mutex
 	tickInProgress bool
 
+	// statuses tracks the transactions submitted asynchronously
+	statuses *statusList
+
 	Pending           OpenSubmissionList
 	Results           ResultProvider
 	Sequences         SequenceProvider
@@ -127,6 +130,7 @@ func (sys *System) Submit(ctx context.Context, env string) (result <-chan Result
 		if sr.Err == nil {
 			// add transactions to open list
 			sys.Pending.Add(ctx, info.Hash, response)
+			sys.statuses.Update(Status{Hash: info.Hash, State: StatusSent})
 			// update the submission queue, allowing the next submission to proceed
 			sys.SubmissionQueue.Update(map[string]uint64{info.SourceAddress: info.Sequence})
 			return
@@ -257,6 +261,8 @@ func (sys *System) Tick(ctx context.Context) {
 		return
 	}
 
+	sys.statuses.Clean(statusRetention)
+
 	sys.Metrics.OpenSubmissionsGauge.Update(int64(stillOpen))
 	sys.Metrics.BufferedSubmissionsGauge.Update(int64(sys.SubmissionQueue.Size()))
 }
@@ -265,6 +271,7 @@ func (sys *System) Tick(ctx context.Context) {
 func (sys *System) Init() {
 	sys.initializer.Do(func() {
 		sys.Log = log.DefaultLogger.WithField("service", "txsub.System")
+		sys.statuses = newStatusList()
 
 		sys.Metrics.FailedSubmissionsMeter = metrics.NewMeter()
 		sys.Metrics.SuccessfulSubmissionsMeter = metrics.NewMeter()
@@ -194,6 +194,69 @@ func (suite *SystemTestSuite) TestTick_RemovesStaleSubmissions() {
 	}
 }
 
+// SubmitAsync returns the hash of the transaction and tracks its status until
+// its result is found.
+func (suite *SystemTestSuite) TestSubmitAsync() {
+	hash, err := suite.system.SubmitAsync(suite.ctx, suite.successTx.EnvelopeXDR)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), suite.successTx.Hash, hash)
+
+	assert.Eventually(suite.T(), func() bool {
+		status, err := suite.system.Status(suite.ctx, hash)
+		return err == nil && status.State == StatusSent
+	}, time.Second, 10*time.Millisecond)
+	assert.True(suite.T(), suite.submitter.WasSubmittedTo)
+
+	// a transaction already sent isn't submitted again
+	suite.submitter.WasSubmittedTo = false
+	hash, err = suite.system.SubmitAsync(suite.ctx, suite.successTx.EnvelopeXDR)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), suite.successTx.Hash, hash)
+	assert.False(suite.T(), suite.submitter.WasSubmittedTo)
+
+	suite.results.Results = []Result{suite.successTx}
+	suite.system.Tick(suite.ctx)
+	assert.Eventually(suite.T(), func() bool {
+		status, _ := suite.system.statuses.Get(hash)
+		return status.State == StatusInLedger
+	}, time.Second, 10*time.Millisecond)
+
+	status, err := suite.system.Status(suite.ctx, hash)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), StatusInLedger, status.State)
+	assert.Equal(suite.T(), suite.successTx.LedgerSequence, status.Result.LedgerSequence)
+}
+
+// SubmitAsync tracks the transactions rejected by stellar-core as failed.
+func (suite *SystemTestSuite) TestSubmitAsync_Failed() {
+	suite.submitter.R = suite.badSeq
+	hash, err := suite.system.SubmitAsync(suite.ctx, suite.successTx.EnvelopeXDR)
+	suite.Require().NoError(err)
+
+	assert.Eventually(suite.T(), func() bool {
+		status, err := suite.system.Status(suite.ctx, hash)
+		return err == nil && status.State == StatusFailed
+	}, time.Second, 10*time.Millisecond)
+}
+
+// SubmitAsync returns the error of malformed transactions.
+func (suite *SystemTestSuite) TestSubmitAsync_Malformed() {
+	_, err := suite.system.SubmitAsync(suite.ctx, "not xdr")
+	assert.IsType(suite.T(), &MalformedTransactionError{}, err)
+}
+
+// Status returns ErrNoResults for unknown transactions.
+func (suite *SystemTestSuite) TestStatus_NotFound() {
+	_, err := suite.system.Status(suite.ctx, suite.successTx.Hash)
+	assert.Equal(suite.T(), ErrNoResults, err)
+
+	// transactions submitted synchronously are reported once sent
+	_ = suite.system.Submit(suite.ctx, suite.successTx.EnvelopeXDR)
+	status, err := suite.system.Status(suite.ctx, suite.successTx.Hash)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), StatusSent, status.State)
+}
+
 func TestSystemTestSuite(t *testing.T) {
 	suite.Run(t, new(SystemTestSuite))
 }
@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-chi/chi"
@@ -45,6 +46,9 @@ type web struct {
 	staleThreshold     uint
 	ingestFailedTx     bool
 
+	fanout     *sse.Fanout
+	fanoutOnce sync.Once
+
 	historyQ *history.Q
 	coreQ    *core.Q
 
@@ -89,6 +93,15 @@ func mustInitWeb(ctx context.Context, hq *history.Q, cq *core.Q, updateFreq time
 	}
 }
 
+// streamFanout returns the sse.Fanout sharing the queries of the streams,
+// starting it on first use.
+func (w *web) streamFanout() *sse.Fanout {
+	w.fanoutOnce.Do(func() {
+		w.fanout = sse.NewFanout(w.appCtx, ledger.NewLegacyHistoryDBSource(w.sseUpdateFrequency))
+	})
+	return w.fanout
+}
+
 // mustInstallMiddlewares installs the middleware stack used for horizon onto the
 // provided app.
 // Note that a request will go through the middlewares from top to bottom.
@@ -174,13 +187,14 @@ func (w *web) mustInstallActions(
 	r := w.router
 	r.Get("/", RootAction{}.Handle)
 	r.Get("/metrics", MetricsAction{}.Handle)
+	r.Method(http.MethodGet, streamSocketPath, streamSocketHandler{router: w.router})
 
 	// ledger actions
 	r.Route("/ledgers", func(r chi.Router) {
 		r.Get("/", LedgerIndexAction{}.Handle)
 		r.Route("/{ledger_id}", func(r chi.Router) {
 			r.Get("/", LedgerShowAction{}.Handle)
-			r.Get("/transactions", w.streamIndexActionHandler(w.getTransactionPage, w.streamTransactions))
+			r.Get("/transactions", w.streamIndexActionHandler(w.getTransactionPage, w.streamTransactions, w.sharedTransactionStream))
 			r.Get("/operations", OperationIndexAction{}.Handle)
 			r.Get("/payments", OperationIndexAction{OnlyPayments: true}.Handle)
 			r.Get("/effects", EffectIndexAction{}.Handle)
@@ -197,7 +211,7 @@ func (w *web) mustInstallActions(
 			)
 		r.Route("/{account_id}", func(r chi.Router) {
 			r.Get("/", w.streamShowActionHandler(w.getAccountInfo, true))
-			r.Get("/transactions", w.streamIndexActionHandler(w.getTransactionPage, w.streamTransactions))
+			r.Get("/transactions", w.streamIndexActionHandler(w.getTransactionPage, w.streamTransactions, w.sharedTransactionStream))
 			r.Get("/operations", OperationIndexAction{}.Handle)
 			r.Get("/payments", OperationIndexAction{OnlyPayments: true}.Handle)
 			r.Get("/effects", EffectIndexAction{}.Handle)
@@ -221,9 +235,10 @@ func (w *web) mustInstallActions(
 
 	// transaction history actions
 	r.Route("/transactions", func(r chi.Router) {
-		r.Get("/", w.streamIndexActionHandler(w.getTransactionPage, w.streamTransactions))
+		r.Get("/", w.streamIndexActionHandler(w.getTransactionPage, w.streamTransactions, w.sharedTransactionStream))
 		r.Route("/{tx_id}", func(r chi.Router) {
 			r.Get("/", showActionHandler(w.getTransactionResource))
+			r.Get("/status", TransactionStatusAction{}.Handle)
 			r.Get("/operations", OperationIndexAction{}.Handle)
 			r.Get("/payments", OperationIndexAction{OnlyPayments: true}.Handle)
 			r.Get("/effects", EffectIndexAction{}.Handle)
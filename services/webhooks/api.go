@@ -0,0 +1,158 @@
+package webhooks
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/support/render/httpjson"
+	"github.com/stellar/go/support/render/problem"
+)
+
+const (
+	// defaultDeliveriesLimit and maxDeliveriesLimit are the default and maximum
+	// number of deliveries listed.
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 200
+)
+
+func init() {
+	// register errors
+	problem.RegisterError(httpjson.ErrBadRequest, probInvalidRequest)
+	problem.RegisterError(sql.ErrNoRows, problem.NotFound)
+
+	// register service host as an empty string
+	problem.RegisterHost("")
+}
+
+func (s *Service) wrapMiddleware(handler http.Handler) http.Handler {
+	handler = authHandler(handler, s.APIToken)
+	handler = recoverHandler(handler)
+	return handler
+}
+
+// ServeMux returns the handler of the API managing the subscriptions and
+// deliveries of s.
+func ServeMux(s *Service) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/subscriptions", s.wrapMiddleware(s.subscriptionsHTTPMethodHandler()))
+	mux.Handle("/deliveries", s.wrapMiddleware(s.deliveriesHTTPMethodHandler()))
+	mux.Handle("/deliveries/redeliver", s.wrapMiddleware(s.redeliverHTTPMethodHandler()))
+	mux.Handle("/health", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	return mux
+}
+
+func (s *Service) subscriptionsHTTPMethodHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			jsonHandler(s.listSubscriptions).ServeHTTP(rw, req)
+
+		case http.MethodPost:
+			jsonHandler(s.createSubscription).ServeHTTP(rw, req)
+
+		case http.MethodDelete:
+			jsonHandler(s.deleteSubscription).ServeHTTP(rw, req)
+
+		default:
+			problem.Render(req.Context(), rw, probMethodNotAllowed)
+		}
+	})
+}
+
+func (s *Service) deliveriesHTTPMethodHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		if req.Method != http.MethodGet {
+			problem.Render(ctx, rw, probMethodNotAllowed)
+			return
+		}
+
+		query := req.URL.Query()
+		subscriptionID, err := strconv.ParseInt(query.Get("subscription_id"), 10, 64)
+		if err != nil {
+			problem.Render(ctx, rw, problem.MakeInvalidFieldProblem("subscription_id", errors.New("subscription_id must be an integer")))
+			return
+		}
+		limit := defaultDeliveriesLimit
+		if l := query.Get("limit"); l != "" {
+			limit, err = strconv.Atoi(l)
+			if err != nil || limit < 1 || limit > maxDeliveriesLimit {
+				problem.Render(ctx, rw, problem.MakeInvalidFieldProblem("limit", fmt.Errorf("limit must be between 1 and %d", maxDeliveriesLimit)))
+				return
+			}
+		}
+
+		deliveries, err := s.listDeliveries(ctx, subscriptionID, query.Get("state"), limit)
+		if err != nil {
+			problem.Render(ctx, rw, err)
+			return
+		}
+		httpjson.Render(rw, deliveries, httpjson.JSON)
+	})
+}
+
+func (s *Service) redeliverHTTPMethodHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			problem.Render(req.Context(), rw, probMethodNotAllowed)
+			return
+		}
+		jsonHandler(s.redeliver).ServeHTTP(rw, req)
+	})
+}
+
+func authHandler(next http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if token == "" {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		given := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			problem.Render(req.Context(), rw, probNotAuthorized)
+			return
+		}
+		next.ServeHTTP(rw, req)
+	})
+}
+
+func jsonHandler(f interface{}) http.Handler {
+	h, err := httpjson.ReqBodyHandler(f, httpjson.JSON)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+func recoverHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			err, ok := r.(error)
+			if !ok {
+				err = fmt.Errorf("panic: %v", r)
+			}
+			if errors.Cause(err) == http.ErrAbortHandler {
+				panic(err)
+			}
+
+			ctx := req.Context()
+			log.Ctx(ctx).WithStack(err).Error(err)
+			problem.Render(ctx, rw, err)
+		}()
+
+		next.ServeHTTP(rw, req)
+	})
+}
@@ -0,0 +1,24 @@
+package webhooks
+
+import (
+	"testing"
+
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/stellar/go/support/db/dbtest"
+)
+
+func openWebhooksDB(t *testing.T) *dbtest.DB {
+	db := dbtest.Postgres(t)
+	migrations := &migrate.FileMigrationSource{
+		Dir: "migrations",
+	}
+
+	conn := db.Open()
+	defer conn.Close()
+
+	_, err := migrate.Exec(conn.DB, "postgres", migrations, migrate.Up)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
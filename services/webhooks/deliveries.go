@@ -0,0 +1,371 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/support/render/problem"
+)
+
+// The states of the deliveries.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// The headers of the deliveries.
+const (
+	// DeliveryIDHeader is the id of the delivery, which is the same for all
+	// its attempts.
+	DeliveryIDHeader = "X-Webhook-Delivery"
+	// TimestampHeader is the unix time at which the delivery was signed.
+	TimestampHeader = "X-Webhook-Timestamp"
+	// SignatureHeader is the signature of the delivery, see Sign.
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+const (
+	// deliveryBatchSize is the number of due deliveries claimed at once.
+	deliveryBatchSize = 20
+	// deliveryLease is how long claimed deliveries are reserved for the
+	// attempt in progress. They are attempted again after a crash once it
+	// expires.
+	deliveryLease = 5 * time.Minute
+	// maxErrorLength is the maximum length of the errors stored.
+	maxErrorLength = 1024
+)
+
+// Delivery is a record delivered to a webhook.
+type Delivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	PagingToken    string          `json:"paging_token"`
+	State          string          `json:"state"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	History        []Attempt       `json:"history"`
+}
+
+// Attempt is an attempt of a delivery.
+type Attempt struct {
+	AttemptedAt time.Time `json:"attempted_at"`
+	// StatusCode is the status of the response of the webhook, if it
+	// responded.
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type redeliverRequest struct {
+	ID int64 `json:"id"`
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of the timestamp and body of a
+// delivery, keyed by the secret of its subscription: the SignatureHeader of
+// the deliveries. Webhooks should check the signature, and that the timestamp
+// is recent, before trusting a delivery.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	io.WriteString(mac, timestamp)
+	io.WriteString(mac, ".")
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks the signature of a delivery with the secret of its
+// subscription.
+func VerifySignature(secret, timestamp string, body []byte, signature string) bool {
+	expected := Sign(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// backoff returns the time to wait before the next attempt of a delivery
+// which failed attempts times.
+func (s *Service) backoff(attempts int) time.Duration {
+	backoff := s.MinBackoff
+	for i := 1; i < attempts && backoff < s.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > s.MaxBackoff {
+		backoff = s.MaxBackoff
+	}
+	return backoff
+}
+
+// deliver attempts the due deliveries every DeliveryInterval until ctx is
+// done.
+func (s *Service) deliver(ctx context.Context) {
+	ticker := time.NewTicker(s.DeliveryInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := s.deliverDue(ctx)
+			if err != nil {
+				log.Ctx(ctx).WithStack(err).Error(errors.Wrap(err, "delivering webhooks"))
+			}
+			if err != nil || n < deliveryBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+type dueDelivery struct {
+	id       int64
+	attempts int
+	payload  []byte
+	url      string
+	secret   string
+}
+
+// deliverDue claims a batch of due deliveries and attempts them, returning the
+// number of deliveries attempted.
+func (s *Service) deliverDue(ctx context.Context) (int, error) {
+	q := `
+		UPDATE deliveries d
+		SET next_attempt_at = NOW() + $1 * INTERVAL '1 second'
+		FROM subscriptions s
+		WHERE d.id IN (
+			SELECT id FROM deliveries
+			WHERE state = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		) AND s.id = d.subscription_id
+		RETURNING d.id, d.attempts, d.payload, s.url, s.secret
+	`
+	rows, err := s.db.QueryContext(ctx, q, deliveryLease.Seconds(), deliveryBatchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, "claiming deliveries")
+	}
+	var due []dueDelivery
+	for rows.Next() {
+		var d dueDelivery
+		if err := rows.Scan(&d.id, &d.attempts, &d.payload, &d.url, &d.secret); err != nil {
+			rows.Close()
+			return 0, errors.Wrap(err, "scanning delivery")
+		}
+		due = append(due, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, errors.Wrap(err, "claiming deliveries")
+	}
+
+	for _, d := range due {
+		statusCode, attemptErr := s.attempt(ctx, d)
+		if err := s.recordAttempt(ctx, d, statusCode, attemptErr); err != nil {
+			return 0, err
+		}
+	}
+	return len(due), nil
+}
+
+// attempt POSTs the payload of d to its webhook, returning the status of the
+// response, if any, and an error unless the status is 2xx.
+func (s *Service) attempt(ctx context.Context, d dueDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequest(http.MethodPost, d.url, bytes.NewReader(d.payload))
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryIDHeader, strconv.FormatInt(d.id, 10))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(d.secret, timestamp, d.payload))
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, errors.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// recordAttempt stores the attempt of d and the resulting state of d.
+func (s *Service) recordAttempt(ctx context.Context, d dueDelivery, statusCode int, attemptErr error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "beginning transaction")
+	}
+	defer tx.Rollback()
+
+	var (
+		status  sql.NullInt64
+		message sql.NullString
+	)
+	if statusCode != 0 {
+		status = sql.NullInt64{Int64: int64(statusCode), Valid: true}
+	}
+	if attemptErr != nil {
+		text := attemptErr.Error()
+		if len(text) > maxErrorLength {
+			text = text[:maxErrorLength]
+		}
+		message = sql.NullString{String: text, Valid: true}
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO delivery_attempts (delivery_id, status_code, error)
+		VALUES ($1, $2, $3)
+	`, d.id, status, message)
+	if err != nil {
+		return errors.Wrap(err, "storing attempt")
+	}
+
+	attempts := d.attempts + 1
+	switch {
+	case attemptErr == nil:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE deliveries
+			SET state = 'delivered', attempts = $2, delivered_at = NOW()
+			WHERE id = $1
+		`, d.id, attempts)
+	case attempts >= s.MaxAttempts:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE deliveries
+			SET state = 'dead', attempts = $2
+			WHERE id = $1
+		`, d.id, attempts)
+	default:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE deliveries
+			SET attempts = $2, next_attempt_at = NOW() + $3 * INTERVAL '1 second'
+			WHERE id = $1
+		`, d.id, attempts, s.backoff(attempts).Seconds())
+	}
+	if err != nil {
+		return errors.Wrap(err, "updating delivery")
+	}
+	return errors.Wrap(tx.Commit(), "committing transaction")
+}
+
+// listDeliveries returns the last deliveries of a subscription, with their
+// history, optionally only those in the given state.
+func (s *Service) listDeliveries(ctx context.Context, subscriptionID int64, state string, limit int) ([]Delivery, error) {
+	switch state {
+	case "", DeliveryPending, DeliveryDelivered, DeliveryDead:
+	default:
+		return nil, problem.MakeInvalidFieldProblem("state", errors.New("state must be one of pending, delivered or dead"))
+	}
+
+	q := `
+		SELECT id, subscription_id, paging_token, state, attempts, next_attempt_at, created_at, delivered_at, payload
+		FROM deliveries
+		WHERE subscription_id = $1 AND ($2 = '' OR state = $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+	rows, err := s.db.QueryContext(ctx, q, subscriptionID, state, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing deliveries")
+	}
+	defer rows.Close()
+
+	out := []Delivery{}
+	index := map[int64]int{}
+	var ids []int64
+	for rows.Next() {
+		var (
+			d             Delivery
+			nextAttemptAt pq.NullTime
+			deliveredAt   pq.NullTime
+			payload       []byte
+		)
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.PagingToken, &d.State, &d.Attempts, &nextAttemptAt, &d.CreatedAt, &deliveredAt, &payload)
+		if err != nil {
+			return nil, errors.Wrap(err, "scanning delivery")
+		}
+		if nextAttemptAt.Valid && d.State == DeliveryPending {
+			d.NextAttemptAt = &nextAttemptAt.Time
+		}
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		d.Payload = payload
+		d.History = []Attempt{}
+		index[d.ID] = len(out)
+		ids = append(ids, d.ID)
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "listing deliveries")
+	}
+	if len(ids) == 0 {
+		return out, nil
+	}
+
+	q = `
+		SELECT delivery_id, attempted_at, status_code, error
+		FROM delivery_attempts
+		WHERE delivery_id = ANY($1)
+		ORDER BY id
+	`
+	attemptRows, err := s.db.QueryContext(ctx, q, pq.Array(ids))
+	if err != nil {
+		return nil, errors.Wrap(err, "listing attempts")
+	}
+	defer attemptRows.Close()
+	for attemptRows.Next() {
+		var (
+			deliveryID int64
+			a          Attempt
+			status     sql.NullInt64
+			message    sql.NullString
+		)
+		if err := attemptRows.Scan(&deliveryID, &a.AttemptedAt, &status, &message); err != nil {
+			return nil, errors.Wrap(err, "scanning attempt")
+		}
+		a.StatusCode = int(status.Int64)
+		a.Error = message.String
+		d := &out[index[deliveryID]]
+		d.History = append(d.History, a)
+	}
+	return out, errors.Wrap(attemptRows.Err(), "listing attempts")
+}
+
+// redeliver schedules a delivery, e.g. a dead one, to be attempted again as a
+// new delivery. Its history is kept.
+func (s *Service) redeliver(ctx context.Context, in redeliverRequest) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE deliveries
+		SET state = 'pending', attempts = 0, next_attempt_at = NOW(), delivered_at = NULL
+		WHERE id = $1
+	`, in.ID)
+	if err != nil {
+		return errors.Wrap(err, "scheduling delivery")
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return errors.Wrap(err, "scheduling delivery")
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
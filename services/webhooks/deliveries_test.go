@@ -0,0 +1,118 @@
+package webhooks
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/keypair"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"paging_token":"1"}`)
+	signature := Sign("secret", "1571270400", body)
+	assert.Len(t, signature, 64)
+	assert.True(t, VerifySignature("secret", "1571270400", body, signature))
+	assert.False(t, VerifySignature("other", "1571270400", body, signature))
+	assert.False(t, VerifySignature("secret", "1571270401", body, signature))
+	assert.False(t, VerifySignature("secret", "1571270400", []byte(`{}`), signature))
+}
+
+func TestBackoff(t *testing.T) {
+	s := &Service{MinBackoff: 10 * time.Second, MaxBackoff: time.Minute}
+	assert.Equal(t, 10*time.Second, s.backoff(1))
+	assert.Equal(t, 20*time.Second, s.backoff(2))
+	assert.Equal(t, 40*time.Second, s.backoff(3))
+	assert.Equal(t, time.Minute, s.backoff(4))
+	assert.Equal(t, time.Minute, s.backoff(100))
+}
+
+func TestDeliverRetriesAndDeadLetters(t *testing.T) {
+	db := openWebhooksDB(t)
+	defer db.Close()
+	conn := db.Open()
+	defer conn.Close()
+
+	var (
+		status int32 = http.StatusInternalServerError
+		calls  int32
+		secret string
+	)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NotEmpty(t, r.Header.Get(DeliveryIDHeader))
+		assert.True(t, VerifySignature(secret, r.Header.Get(TimestampHeader), body, r.Header.Get(SignatureHeader)))
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+	}))
+	defer webhook.Close()
+
+	account := keypair.MustRandom().Address()
+	horizon := &horizonclient.MockClient{}
+	horizon.On("PaymentsWithContext", mock.Anything, mock.Anything).
+		Return(paymentsPage("1"), nil).Once()
+
+	s := NewService(conn.DB, horizon)
+	s.MaxAttempts = 2
+	s.MinBackoff = 0
+	s.MaxBackoff = 0
+	ctx := context.Background()
+	sub, err := s.createSubscription(ctx, createSubscriptionRequest{
+		AccountID: account,
+		Resource:  ResourcePayments,
+		URL:       webhook.URL,
+		Cursor:    "0",
+	})
+	require.NoError(t, err)
+	secret = sub.Secret
+	require.NoError(t, s.poll(ctx))
+
+	// the first attempt fails and is retried after the backoff
+	n, err := s.deliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	deliveries, err := s.listDeliveries(ctx, sub.ID, DeliveryPending, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	require.Len(t, deliveries[0].History, 1)
+	assert.Equal(t, http.StatusInternalServerError, deliveries[0].History[0].StatusCode)
+
+	// the second one dead-letters the delivery
+	n, err = s.deliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	deliveries, err = s.listDeliveries(ctx, sub.ID, DeliveryDead, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Len(t, deliveries[0].History, 2)
+
+	n, err = s.deliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// a redelivered delivery is attempted again, keeping its history
+	atomic.StoreInt32(&status, http.StatusOK)
+	require.NoError(t, s.redeliver(ctx, redeliverRequest{ID: deliveries[0].ID}))
+	n, err = s.deliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	deliveries, err = s.listDeliveries(ctx, sub.ID, DeliveryDelivered, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.NotNil(t, deliveries[0].DeliveredAt)
+	assert.Len(t, deliveries[0].History, 3)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	err = s.redeliver(ctx, redeliverRequest{ID: deliveries[0].ID + 1})
+	assert.Error(t, err)
+}
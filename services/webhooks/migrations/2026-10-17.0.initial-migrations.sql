@@ -0,0 +1,42 @@
+-- +migrate Up
+
+CREATE TABLE public.subscriptions (
+    id bigserial PRIMARY KEY,
+    account_id text NOT NULL,
+    resource text NOT NULL,
+    url text NOT NULL,
+    secret text NOT NULL,
+    cursor text NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE public.deliveries (
+    id bigserial PRIMARY KEY,
+    subscription_id bigint NOT NULL REFERENCES public.subscriptions (id) ON DELETE CASCADE,
+    paging_token text NOT NULL,
+    payload jsonb NOT NULL,
+    state text NOT NULL DEFAULT 'pending',
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    delivered_at timestamp with time zone,
+    UNIQUE (subscription_id, paging_token)
+);
+
+CREATE INDEX deliveries_pending_idx ON public.deliveries (next_attempt_at) WHERE state = 'pending';
+
+CREATE TABLE public.delivery_attempts (
+    id bigserial PRIMARY KEY,
+    delivery_id bigint NOT NULL REFERENCES public.deliveries (id) ON DELETE CASCADE,
+    attempted_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    status_code integer,
+    error text
+);
+
+CREATE INDEX delivery_attempts_delivery_id_idx ON public.delivery_attempts (delivery_id);
+
+-- +migrate Down
+
+DROP TABLE public.delivery_attempts;
+DROP TABLE public.deliveries;
+DROP TABLE public.subscriptions;
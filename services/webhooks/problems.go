@@ -0,0 +1,30 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/stellar/go/support/render/problem"
+)
+
+var (
+	probInvalidRequest = problem.P{
+		Type:   "invalid_request_body",
+		Title:  "Invalid Request Body",
+		Status: 400,
+		Detail: "Your request body is invalid.",
+	}
+
+	probMethodNotAllowed = problem.P{
+		Type:   "method_not_allowed",
+		Title:  "Method Not Allowed",
+		Status: http.StatusMethodNotAllowed,
+		Detail: "This endpoint does not support the request method you used.",
+	}
+
+	probNotAuthorized = problem.P{
+		Type:   "not_authorized",
+		Title:  "Not Authorized",
+		Status: 401,
+		Detail: "Your request is not authorized.",
+	}
+)
@@ -0,0 +1,82 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+)
+
+// Config is the configuration of the webhooks server.
+type Config struct {
+	DBURL          string
+	MaxIdleDBConns int
+	MaxOpenDBConns int
+
+	HorizonURL string
+	// APIToken is the bearer token required by the API. The API is not
+	// authenticated when it is empty.
+	APIToken string
+
+	ListenerPort int
+}
+
+// Service delivers the activity of Stellar accounts to the webhooks
+// subscribed to it.
+//
+// The payments, effects or transactions of the subscribed accounts are polled
+// from Horizon and stored as deliveries, together with the paging token of the
+// last record polled, so that polling resumes where it stopped after a
+// restart. Every delivery is POSTed to the URL of its subscription until it
+// responds with a 2xx status, with an exponential backoff between attempts: a
+// webhook may thus receive a delivery more than once. Deliveries still failing
+// after MaxAttempts attempts are dead-lettered, until they are redelivered
+// through the API.
+type Service struct {
+	db      *sql.DB
+	horizon horizonclient.ClientInterface
+	http    *http.Client
+
+	// APIToken is the bearer token required by the API. The API is not
+	// authenticated when it is empty.
+	APIToken string
+	// PollInterval is the time between two polls of Horizon.
+	PollInterval time.Duration
+	// DeliveryInterval is the time between two checks for due deliveries.
+	DeliveryInterval time.Duration
+	// MaxAttempts is the number of failed attempts after which a delivery is
+	// dead-lettered.
+	MaxAttempts int
+	// MinBackoff and MaxBackoff bound the time between two attempts of a
+	// delivery, which doubles after every failed attempt.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// NewService returns a Service storing its subscriptions in db and polling
+// horizon.
+func NewService(db *sql.DB, horizon horizonclient.ClientInterface) *Service {
+	return &Service{
+		db:               db,
+		horizon:          horizon,
+		http:             &http.Client{Timeout: 10 * time.Second},
+		PollInterval:     5 * time.Second,
+		DeliveryInterval: time.Second,
+		MaxAttempts:      10,
+		MinBackoff:       10 * time.Second,
+		MaxBackoff:       time.Hour,
+	}
+}
+
+// Run polls Horizon and delivers the webhooks until ctx is done.
+func (s *Service) Run(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.watch(ctx)
+	}()
+	s.deliver(ctx)
+	<-done
+}
@@ -0,0 +1,133 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"net/url"
+	"time"
+
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/problem"
+)
+
+// The resources of an account a webhook can subscribe to.
+const (
+	ResourcePayments     = "payments"
+	ResourceEffects      = "effects"
+	ResourceTransactions = "transactions"
+)
+
+// Subscription is a webhook subscribed to a resource of an account.
+type Subscription struct {
+	ID        int64  `json:"id"`
+	AccountID string `json:"account_id"`
+	Resource  string `json:"resource"`
+	URL       string `json:"url"`
+	// Secret signs the deliveries of the subscription. It is only returned
+	// when the subscription is created.
+	Secret string `json:"secret,omitempty"`
+	// Cursor is the paging token of the last record polled.
+	Cursor    string    `json:"cursor"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type createSubscriptionRequest struct {
+	AccountID string `json:"account_id"`
+	Resource  string `json:"resource"`
+	URL       string `json:"url"`
+	// Cursor is the paging token after which records are delivered. The
+	// records following the latest one are delivered when it is empty.
+	Cursor string `json:"cursor"`
+}
+
+type deleteSubscriptionRequest struct {
+	ID int64 `json:"id"`
+}
+
+func (s *Service) createSubscription(ctx context.Context, in createSubscriptionRequest) (*Subscription, error) {
+	if !strkey.IsValidEd25519PublicKey(in.AccountID) {
+		return nil, problem.MakeInvalidFieldProblem("account_id", errors.New("invalid account id"))
+	}
+	switch in.Resource {
+	case ResourcePayments, ResourceEffects, ResourceTransactions:
+	default:
+		return nil, problem.MakeInvalidFieldProblem("resource", errors.New("resource must be one of payments, effects or transactions"))
+	}
+	if u, err := url.Parse(in.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, problem.MakeInvalidFieldProblem("url", errors.New("url must be an absolute http or https URL"))
+	}
+
+	out := Subscription{
+		AccountID: in.AccountID,
+		Resource:  in.Resource,
+		URL:       in.URL,
+		Cursor:    in.Cursor,
+	}
+	if out.Cursor == "" {
+		records, err := s.loadRecords(ctx, out, true, 1)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading the latest record")
+		}
+		if len(records) > 0 {
+			out.Cursor = records[0].pagingToken
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.Wrap(err, "generating secret")
+	}
+	out.Secret = hex.EncodeToString(secret)
+
+	q := `
+		INSERT INTO subscriptions (account_id, resource, url, secret, cursor)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err := s.db.QueryRowContext(ctx, q, out.AccountID, out.Resource, out.URL, out.Secret, out.Cursor).
+		Scan(&out.ID, &out.CreatedAt)
+	if err != nil {
+		return nil, errors.Wrap(err, "storing subscription")
+	}
+	return &out, nil
+}
+
+func (s *Service) listSubscriptions(ctx context.Context) ([]Subscription, error) {
+	q := `
+		SELECT id, account_id, resource, url, cursor, created_at
+		FROM subscriptions
+		ORDER BY id
+	`
+	rows, err := s.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing subscriptions")
+	}
+	defer rows.Close()
+
+	out := []Subscription{}
+	for rows.Next() {
+		var sub Subscription
+		err := rows.Scan(&sub.ID, &sub.AccountID, &sub.Resource, &sub.URL, &sub.Cursor, &sub.CreatedAt)
+		if err != nil {
+			return nil, errors.Wrap(err, "scanning subscription")
+		}
+		out = append(out, sub)
+	}
+	return out, errors.Wrap(rows.Err(), "listing subscriptions")
+}
+
+func (s *Service) deleteSubscription(ctx context.Context, in deleteSubscriptionRequest) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM subscriptions WHERE id = $1`, in.ID)
+	if err != nil {
+		return errors.Wrap(err, "deleting subscription")
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return errors.Wrap(err, "deleting subscription")
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
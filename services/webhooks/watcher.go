@@ -0,0 +1,174 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+)
+
+// pollLimit is the number of records loaded by every request to Horizon.
+const pollLimit = 200
+
+// record is a record of the activity of an account.
+type record struct {
+	pagingToken string
+	data        interface{}
+}
+
+// payload is the body of the deliveries.
+type payload struct {
+	SubscriptionID int64       `json:"subscription_id"`
+	AccountID      string      `json:"account_id"`
+	Resource       string      `json:"resource"`
+	PagingToken    string      `json:"paging_token"`
+	Data           interface{} `json:"data"`
+}
+
+// loadRecords loads the records of the resource of sub following its cursor,
+// or the latest ones if latest is true.
+func (s *Service) loadRecords(ctx context.Context, sub Subscription, latest bool, limit uint) ([]record, error) {
+	order, cursor := horizonclient.OrderAsc, sub.Cursor
+	if latest {
+		order, cursor = horizonclient.OrderDesc, ""
+	}
+
+	var records []record
+	switch sub.Resource {
+	case ResourcePayments:
+		page, err := s.horizon.PaymentsWithContext(ctx, horizonclient.OperationRequest{
+			ForAccount: sub.AccountID,
+			Order:      order,
+			Cursor:     cursor,
+			Limit:      limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range page.Embedded.Records {
+			records = append(records, record{pagingToken: r.PagingToken(), data: r})
+		}
+	case ResourceEffects:
+		page, err := s.horizon.EffectsWithContext(ctx, horizonclient.EffectRequest{
+			ForAccount: sub.AccountID,
+			Order:      order,
+			Cursor:     cursor,
+			Limit:      limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range page.Embedded.Records {
+			records = append(records, record{pagingToken: r.PagingToken(), data: r})
+		}
+	case ResourceTransactions:
+		page, err := s.horizon.TransactionsWithContext(ctx, horizonclient.TransactionRequest{
+			ForAccount: sub.AccountID,
+			Order:      order,
+			Cursor:     cursor,
+			Limit:      limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range page.Embedded.Records {
+			records = append(records, record{pagingToken: r.PagingToken(), data: r})
+		}
+	default:
+		return nil, errors.Errorf("unknown resource %s", sub.Resource)
+	}
+	return records, nil
+}
+
+// watch polls Horizon every PollInterval until ctx is done.
+func (s *Service) watch(ctx context.Context) {
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.poll(ctx); err != nil {
+			log.Ctx(ctx).WithStack(err).Error(err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// poll stores the new records of every subscription as deliveries.
+func (s *Service) poll(ctx context.Context) error {
+	subs, err := s.listSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		if err := s.pollSubscription(ctx, sub); err != nil {
+			log.Ctx(ctx).WithField("subscription", sub.ID).WithStack(err).
+				Error(errors.Wrap(err, "polling subscription"))
+		}
+	}
+	return nil
+}
+
+func (s *Service) pollSubscription(ctx context.Context, sub Subscription) error {
+	for {
+		records, err := s.loadRecords(ctx, sub, false, pollLimit)
+		if err != nil {
+			return errors.Wrap(err, "loading records")
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		if err := s.enqueue(ctx, sub, records); err != nil {
+			return err
+		}
+		sub.Cursor = records[len(records)-1].pagingToken
+		if len(records) < pollLimit {
+			return nil
+		}
+	}
+}
+
+// enqueue stores records as deliveries of sub and moves the cursor of sub to
+// the last of them, in a single transaction.
+func (s *Service) enqueue(ctx context.Context, sub Subscription, records []record) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "beginning transaction")
+	}
+	defer tx.Rollback()
+
+	q := `
+		INSERT INTO deliveries (subscription_id, paging_token, payload)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (subscription_id, paging_token) DO NOTHING
+	`
+	for _, r := range records {
+		body, err := json.Marshal(payload{
+			SubscriptionID: sub.ID,
+			AccountID:      sub.AccountID,
+			Resource:       sub.Resource,
+			PagingToken:    r.pagingToken,
+			Data:           r.data,
+		})
+		if err != nil {
+			return errors.Wrap(err, "encoding payload")
+		}
+		if _, err := tx.ExecContext(ctx, q, sub.ID, r.pagingToken, body); err != nil {
+			return errors.Wrap(err, "storing delivery")
+		}
+	}
+
+	cursor := records[len(records)-1].pagingToken
+	_, err = tx.ExecContext(ctx, `UPDATE subscriptions SET cursor = $2 WHERE id = $1`, sub.ID, cursor)
+	if err != nil {
+		return errors.Wrap(err, "updating cursor")
+	}
+	return errors.Wrap(tx.Commit(), "committing transaction")
+}
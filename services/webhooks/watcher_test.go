@@ -0,0 +1,127 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/protocols/horizon/operations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func paymentsPage(pagingTokens ...string) operations.OperationsPage {
+	page := operations.OperationsPage{}
+	for _, pt := range pagingTokens {
+		page.Embedded.Records = append(page.Embedded.Records, operations.Payment{
+			Base: operations.Base{PT: pt},
+		})
+	}
+	return page
+}
+
+func TestCreateSubscriptionStartsAtLatestRecord(t *testing.T) {
+	db := openWebhooksDB(t)
+	defer db.Close()
+	conn := db.Open()
+	defer conn.Close()
+
+	account := keypair.MustRandom().Address()
+	horizon := &horizonclient.MockClient{}
+	horizon.On("PaymentsWithContext", mock.Anything, horizonclient.OperationRequest{
+		ForAccount: account,
+		Order:      horizonclient.OrderDesc,
+		Limit:      1,
+	}).Return(paymentsPage("42"), nil).Once()
+
+	s := NewService(conn.DB, horizon)
+	ctx := context.Background()
+	sub, err := s.createSubscription(ctx, createSubscriptionRequest{
+		AccountID: account,
+		Resource:  ResourcePayments,
+		URL:       "https://example.com/hook",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "42", sub.Cursor)
+	assert.Len(t, sub.Secret, 64)
+	horizon.AssertExpectations(t)
+
+	subs, err := s.listSubscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, sub.ID, subs[0].ID)
+	assert.Empty(t, subs[0].Secret)
+}
+
+func TestCreateSubscriptionInvalid(t *testing.T) {
+	s := NewService(nil, &horizonclient.MockClient{})
+	ctx := context.Background()
+	account := keypair.MustRandom().Address()
+
+	for _, in := range []createSubscriptionRequest{
+		{AccountID: "GABC", Resource: ResourcePayments, URL: "https://example.com"},
+		{AccountID: account, Resource: "offers", URL: "https://example.com"},
+		{AccountID: account, Resource: ResourcePayments, URL: "example.com"},
+		{AccountID: account, Resource: ResourcePayments, URL: "ftp://example.com"},
+	} {
+		_, err := s.createSubscription(ctx, in)
+		assert.Error(t, err, "%+v", in)
+	}
+}
+
+func TestPollResumesFromCursor(t *testing.T) {
+	db := openWebhooksDB(t)
+	defer db.Close()
+	conn := db.Open()
+	defer conn.Close()
+
+	account := keypair.MustRandom().Address()
+	horizon := &horizonclient.MockClient{}
+	s := NewService(conn.DB, horizon)
+	ctx := context.Background()
+	sub, err := s.createSubscription(ctx, createSubscriptionRequest{
+		AccountID: account,
+		Resource:  ResourcePayments,
+		URL:       "https://example.com/hook",
+		Cursor:    "10",
+	})
+	require.NoError(t, err)
+
+	request := horizonclient.OperationRequest{
+		ForAccount: account,
+		Order:      horizonclient.OrderAsc,
+		Cursor:     "10",
+		Limit:      pollLimit,
+	}
+	horizon.On("PaymentsWithContext", mock.Anything, request).
+		Return(paymentsPage("11", "12"), nil).Once()
+	require.NoError(t, s.poll(ctx))
+
+	// a restarted service polls from the stored cursor, and records delivered
+	// again are not duplicated
+	request.Cursor = "12"
+	horizon.On("PaymentsWithContext", mock.Anything, request).
+		Return(paymentsPage("12", "13"), nil).Once()
+	require.NoError(t, NewService(conn.DB, horizon).poll(ctx))
+	horizon.AssertExpectations(t)
+
+	deliveries, err := s.listDeliveries(ctx, sub.ID, DeliveryPending, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	assert.Equal(t, "13", deliveries[0].PagingToken)
+	assert.Equal(t, "11", deliveries[2].PagingToken)
+
+	var body payload
+	require.NoError(t, json.Unmarshal(deliveries[2].Payload, &body))
+	assert.Equal(t, sub.ID, body.SubscriptionID)
+	assert.Equal(t, account, body.AccountID)
+	assert.Equal(t, ResourcePayments, body.Resource)
+	assert.Equal(t, "11", body.PagingToken)
+
+	subs, err := s.listSubscriptions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "13", subs[0].Cursor)
+}
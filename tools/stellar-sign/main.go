@@ -1,7 +1,8 @@
 // stellar-sign is a small interactive utility to help you contribute a
 // signature to a transaction envelope.
 //
-// It prompts you for a key
+// It prompts you for a key. Envelopes can be given and printed in base64 or in
+// Txrep, the human-readable representation defined by SEP-11.
 package main
 
 import (
@@ -15,12 +16,14 @@ import (
 
 	"github.com/howeyc/gopass"
 	"github.com/stellar/go/build"
+	"github.com/stellar/go/txnbuild/txrep"
 	"github.com/stellar/go/xdr"
 )
 
 var in *bufio.Reader
 
-var infile = flag.String("infile", "", "transaction envelope")
+var infile = flag.String("infile", "", "transaction envelope, in base64 or txrep")
+var txrepOutput = flag.Bool("txrep", false, "print the signed envelope in txrep instead of base64")
 
 func main() {
 	flag.Parse()
@@ -33,7 +36,7 @@ func main() {
 
 	if *infile == "" {
 		// read envelope
-		env, err = readLine("Enter envelope (base64): ", false)
+		env, err = readEnvelope()
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -56,7 +59,11 @@ func main() {
 
 	// parse the envelope
 	var txe xdr.TransactionEnvelope
-	err = xdr.SafeUnmarshalBase64(env, &txe)
+	if isTxrep(env) {
+		txe, err = txrep.ToEnvelope(env)
+	} else {
+		err = xdr.SafeUnmarshalBase64(strings.TrimSpace(env), &txe)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -68,7 +75,12 @@ func main() {
 	fmt.Printf("  sigs: %d\n", len(txe.Signatures))
 	fmt.Println("")
 
-	// TODO: add operation details
+	details, err := txrep.FromEnvelope(txe)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Transaction Details:")
+	fmt.Println(details)
 
 	// read seed
 	seed, err := readLine("Enter seed: ", true)
@@ -88,7 +100,13 @@ func main() {
 		log.Fatal(err)
 	}
 
-	newEnv, err := xdr.MarshalBase64(b.E)
+	var newEnv string
+	if *txrepOutput {
+		newEnv, err = txrep.FromEnvelope(*b.E)
+		newEnv = strings.TrimSuffix(newEnv, "\n")
+	} else {
+		newEnv, err = xdr.MarshalBase64(b.E)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -100,6 +118,35 @@ func main() {
 
 }
 
+// isTxrep reports whether env is in txrep rather than base64, which never
+// contains colons.
+func isTxrep(env string) bool {
+	return strings.Contains(env, ":")
+}
+
+// readEnvelope reads an envelope from the terminal: a single line of base64,
+// or txrep lines up to an empty line.
+func readEnvelope() (string, error) {
+	line, err := readLine("Enter envelope (base64, or txrep ended by an empty line): ", false)
+	if err != nil || !isTxrep(line) {
+		return line, err
+	}
+
+	lines := []string{line}
+	for {
+		line, err = in.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		lines = append(lines, line)
+		if err != nil {
+			break
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 func readLine(prompt string, private bool) (string, error) {
 	fmt.Println(prompt)
 	var line string
@@ -578,3 +578,14 @@ func ExampleBuildChallengeTx() {
 
 	check(err)
 }
+
+func ExampleSuggestBaseFee() {
+	client := horizonclient.DefaultTestNetClient
+
+	// pay more than 90% of the transactions of the last ledgers, but no more than 0.0001 XLM per operation
+	baseFee, err := SuggestBaseFee(&client, PercentileFee{Percentile: 90, Max: 1000})
+	check(err)
+	fmt.Println(baseFee)
+
+	// Output: 1000
+}
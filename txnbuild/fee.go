@@ -0,0 +1,185 @@
+package txnbuild
+
+import (
+	"math"
+
+	"github.com/stellar/go/keypair"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// MinBaseFee is the minimum base fee, in stroops, accepted by the network.
+const MinBaseFee = 100
+
+// FeeStatsProvider returns the fee statistics of the network. horizonclient.Client implements it
+// with the /fee_stats endpoint of horizon.
+type FeeStatsProvider interface {
+	FeeStats() (hProtocol.FeeStats, error)
+}
+
+// FeeStrategy picks the base fee of a transaction, in stroops per operation, from the fee
+// statistics of the last ledgers.
+type FeeStrategy interface {
+	BaseFee(stats hProtocol.FeeStats) (uint32, error)
+}
+
+// SuggestBaseFee returns the base fee picked by strategy from the fee statistics returned by
+// provider, e.g.
+//
+//	tx.BaseFee, err = txnbuild.SuggestBaseFee(client, txnbuild.PercentileFee{Percentile: 90})
+func SuggestBaseFee(provider FeeStatsProvider, strategy FeeStrategy) (uint32, error) {
+	stats, err := provider.FeeStats()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get fee stats")
+	}
+	return strategy.BaseFee(stats)
+}
+
+// FixedFee is a FeeStrategy always picking the same base fee, regardless of the network's activity.
+type FixedFee uint32
+
+// BaseFee implements FeeStrategy.
+func (f FixedFee) BaseFee(stats hProtocol.FeeStats) (uint32, error) {
+	if f < MinBaseFee {
+		return 0, errors.Errorf("base fee can not be lower than %d", MinBaseFee)
+	}
+	return uint32(f), nil
+}
+
+// PercentileFee is a FeeStrategy picking the fee charged to a given percentile of the transactions
+// of the last ledgers, so that the transaction pays more than that percentile. Percentile must be
+// 10, 20, ..., 90, 95 or 99. When Max is set, the picked fee is capped to it.
+type PercentileFee struct {
+	Percentile int
+	Max        uint32
+}
+
+// BaseFee implements FeeStrategy.
+func (f PercentileFee) BaseFee(stats hProtocol.FeeStats) (uint32, error) {
+	fee, err := feePercentile(stats, f.Percentile)
+	if err != nil {
+		return 0, err
+	}
+	return boundFee(fee, f.Max), nil
+}
+
+// SurgeFee is a FeeStrategy for surge pricing, picking the 99th percentile of the fees charged in
+// the last ledgers multiplied by Multiplier, capped to Max when it is set. Multiplier defaults to 1.
+//
+// The percentile is the one reported by horizon's /fee_stats, computed over the last 5 ledgers:
+// horizon doesn't report the fees charged in the last ledger alone, only its base fee. A surge
+// which started in the last ledger is thus only partly reflected, and Multiplier should leave
+// room for it.
+type SurgeFee struct {
+	Multiplier float64
+	Max        uint32
+}
+
+// BaseFee implements FeeStrategy.
+func (f SurgeFee) BaseFee(stats hProtocol.FeeStats) (uint32, error) {
+	if f.Multiplier < 0 {
+		return 0, errors.New("multiplier can not be negative")
+	}
+	multiplier := f.Multiplier
+	if multiplier == 0 {
+		multiplier = 1
+	}
+
+	p99, err := feePercentile(stats, 99)
+	if err != nil {
+		return 0, err
+	}
+	fee := math.Ceil(float64(p99) * multiplier)
+	if fee > math.MaxUint32 {
+		fee = math.MaxUint32
+	}
+	return boundFee(int64(fee), f.Max), nil
+}
+
+// BumpFee sets the base fee of a previously built transaction to baseFee, which must be higher
+// than the current base fee, and signs it again with signers. The sequence number of the
+// transaction is kept, so at most one of the two transactions can be applied.
+//
+// There is no replace-by-fee in stellar-core: the transaction with the higher fee does not replace
+// the previous one once that was accepted. Whichever transaction stellar-core accepts first is
+// applied and the other fails with tx_bad_seq, so the bumped transaction only helps when the
+// previous one was not accepted, e.g. when it was rejected with tx_insufficient_fee or expired.
+//
+// The hash of the transaction changes, so all its signatures are removed: every other signer must
+// sign it again, and hash(x) preimages must be added again with SignHashX.
+func (tx *Transaction) BumpFee(baseFee uint32, signers ...keypair.Signer) error {
+	if tx.xdrEnvelope == nil {
+		return errors.New("transaction has not been built")
+	}
+	if baseFee <= tx.BaseFee {
+		return errors.Errorf("base fee %d is not higher than the current base fee %d", baseFee, tx.BaseFee)
+	}
+
+	tx.BaseFee = baseFee
+	err := tx.setTransactionFee()
+	if err != nil {
+		return err
+	}
+	tx.xdrEnvelope = &xdr.TransactionEnvelope{Tx: tx.xdrTransaction}
+
+	return tx.Sign(signers...)
+}
+
+// feePercentile returns the given percentile of the fees charged in the last ledgers. It falls
+// back to the accepted fees reported by older horizon servers.
+func feePercentile(stats hProtocol.FeeStats, percentile int) (int64, error) {
+	charged := stats.FeeCharged
+	if charged.Max == 0 {
+		charged = hProtocol.FeeDistribution{
+			P10: int64(stats.P10AcceptedFee),
+			P20: int64(stats.P20AcceptedFee),
+			P30: int64(stats.P30AcceptedFee),
+			P40: int64(stats.P40AcceptedFee),
+			P50: int64(stats.P50AcceptedFee),
+			P60: int64(stats.P60AcceptedFee),
+			P70: int64(stats.P70AcceptedFee),
+			P80: int64(stats.P80AcceptedFee),
+			P90: int64(stats.P90AcceptedFee),
+			P95: int64(stats.P95AcceptedFee),
+			P99: int64(stats.P99AcceptedFee),
+		}
+	}
+
+	switch percentile {
+	case 10:
+		return charged.P10, nil
+	case 20:
+		return charged.P20, nil
+	case 30:
+		return charged.P30, nil
+	case 40:
+		return charged.P40, nil
+	case 50:
+		return charged.P50, nil
+	case 60:
+		return charged.P60, nil
+	case 70:
+		return charged.P70, nil
+	case 80:
+		return charged.P80, nil
+	case 90:
+		return charged.P90, nil
+	case 95:
+		return charged.P95, nil
+	case 99:
+		return charged.P99, nil
+	}
+	return 0, errors.Errorf("unsupported fee percentile %d", percentile)
+}
+
+// boundFee returns fee capped to max when it is set, and no lower than MinBaseFee.
+func boundFee(fee int64, max uint32) uint32 {
+	if max > 0 && fee > int64(max) {
+		fee = int64(max)
+	}
+	if fee < MinBaseFee {
+		fee = MinBaseFee
+	}
+	return uint32(fee)
+}
@@ -0,0 +1,99 @@
+package txnbuild
+
+import (
+	"testing"
+
+	"github.com/stellar/go/network"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	horizonclient "github.com/stellar/go/txnbuild/examplehorizonclient"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeStrategies(t *testing.T) {
+	client := horizonclient.DefaultTestNetClient
+	stats, err := client.FeeStats()
+	require.NoError(t, err)
+
+	fee, err := FixedFee(150).BaseFee(stats)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(150), fee)
+
+	_, err = FixedFee(50).BaseFee(stats)
+	assert.EqualError(t, err, "base fee can not be lower than 100")
+
+	fee, err = PercentileFee{Percentile: 90}.BaseFee(stats)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2000), fee)
+
+	fee, err = PercentileFee{Percentile: 95, Max: 1000}.BaseFee(stats)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1000), fee)
+
+	_, err = PercentileFee{Percentile: 42}.BaseFee(stats)
+	assert.EqualError(t, err, "unsupported fee percentile 42")
+
+	fee, err = SurgeFee{Multiplier: 1.5}.BaseFee(stats)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(7500), fee)
+
+	fee, err = SurgeFee{Multiplier: 2, Max: 8000}.BaseFee(stats)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(8000), fee)
+
+	// the fees charged are preferred over the deprecated accepted fees
+	stats.FeeCharged = hProtocol.FeeDistribution{Max: 400, P50: 20, P99: 300}
+	fee, err = PercentileFee{Percentile: 50}.BaseFee(stats)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(MinBaseFee), fee)
+
+	fee, err = SurgeFee{}.BaseFee(stats)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(300), fee)
+
+	fee, err = SuggestBaseFee(&client, PercentileFee{Percentile: 10})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(250), fee)
+}
+
+func TestBumpFee(t *testing.T) {
+	kp0 := newKeypair0()
+	kp1 := newKeypair1()
+	sourceAccount := NewSimpleAccount(kp0.Address(), int64(9605939170639897))
+
+	tx := Transaction{
+		SourceAccount: &sourceAccount,
+		Operations: []Operation{
+			&BumpSequence{BumpTo: 1},
+			&BumpSequence{BumpTo: 2},
+		},
+		BaseFee:    MinBaseFee,
+		Timebounds: NewInfiniteTimeout(),
+		Network:    network.TestNetworkPassphrase,
+	}
+	require.NoError(t, tx.Build())
+	require.NoError(t, tx.Sign(kp0, kp1))
+	oldHash, err := tx.HashHex()
+	require.NoError(t, err)
+
+	err = tx.BumpFee(MinBaseFee)
+	assert.EqualError(t, err, "base fee 100 is not higher than the current base fee 100")
+
+	require.NoError(t, tx.BumpFee(500, kp0))
+	newHash, err := tx.HashHex()
+	require.NoError(t, err)
+	assert.NotEqual(t, oldHash, newHash)
+
+	envelope := tx.TxEnvelope()
+	assert.Equal(t, xdr.Uint32(1000), envelope.Tx.Fee)
+	assert.Equal(t, xdr.SequenceNumber(9605939170639898), envelope.Tx.SeqNum)
+	require.Len(t, envelope.Signatures, 1)
+
+	statuses, err := tx.CheckSignatures(AccountSigners{
+		AccountID: kp0.Address(),
+		Signers:   []Signer{{Address: kp0.Address(), Weight: 1}},
+	})
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Authorized())
+}
@@ -0,0 +1,249 @@
+package txnbuild
+
+import (
+	"bytes"
+	"crypto/sha256"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// ThresholdLevel is the category of threshold an operation needs to be authorized. See
+// https://www.stellar.org/developers/guides/concepts/multi-sig.html#thresholds
+type ThresholdLevel int
+
+const (
+	// ThresholdLevelLow is needed by AllowTrust, BumpSequence and Inflation operations, and by
+	// every transaction for its source account.
+	ThresholdLevelLow ThresholdLevel = iota + 1
+	// ThresholdLevelMedium is needed by all other operations.
+	ThresholdLevelMedium
+	// ThresholdLevelHigh is needed by AccountMerge operations and by SetOptions operations
+	// changing the signers, master weight or thresholds of an account.
+	ThresholdLevelHigh
+)
+
+// AccountSigners describes the signers of an account and its thresholds. The master key of the
+// account must be listed in Signers, with its weight, when it can sign.
+//
+// Signer addresses can be ed25519 public keys (G...), pre-authorized transaction hashes (T...) and
+// hash(x) keys (X...).
+type AccountSigners struct {
+	AccountID       string
+	Signers         []Signer
+	LowThreshold    Threshold
+	MediumThreshold Threshold
+	HighThreshold   Threshold
+}
+
+// SignatureStatus reports the signatures gathered by a transaction for one of its source accounts.
+type SignatureStatus struct {
+	AccountID string
+	// Required is the highest threshold level needed by the transaction and the operations the
+	// account is the source of.
+	Required ThresholdLevel
+	// Weight is the sum of the weights of the signers in Signed.
+	Weight             int
+	LowThresholdMet    bool
+	MediumThresholdMet bool
+	HighThresholdMet   bool
+	// Signed lists the signers of the account which signed the transaction.
+	Signed []Signer
+	// Missing lists the signers of the account which have not signed the transaction yet.
+	Missing []Signer
+}
+
+// Authorized reports whether the signatures meet the Required threshold of the account.
+func (s SignatureStatus) Authorized() bool {
+	switch s.Required {
+	case ThresholdLevelLow:
+		return s.LowThresholdMet
+	case ThresholdLevelMedium:
+		return s.MediumThresholdMet
+	case ThresholdLevelHigh:
+		return s.HighThresholdMet
+	}
+	return false
+}
+
+// MergeSignatures adds the signatures of the given base64 XDR transaction envelopes to a previously
+// built transaction, skipping the signatures it already has. The envelopes must hold the same
+// transaction, so that each copy of a transaction signed separately by several parties can be
+// combined into one.
+func (tx *Transaction) MergeSignatures(txeB64s ...string) error {
+	if tx.xdrEnvelope == nil {
+		return errors.New("transaction has not been built")
+	}
+	txBytes, err := xdr.MarshalBase64(tx.xdrEnvelope.Tx)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal transaction")
+	}
+
+	for _, txeB64 := range txeB64s {
+		var envelope xdr.TransactionEnvelope
+		err = xdr.SafeUnmarshalBase64(txeB64, &envelope)
+		if err != nil {
+			return errors.Wrap(err, "unable to unmarshal transaction envelope")
+		}
+		otherBytes, err := xdr.MarshalBase64(envelope.Tx)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal transaction")
+		}
+		if otherBytes != txBytes {
+			return errors.New("envelope holds a different transaction")
+		}
+
+		for _, sig := range envelope.Signatures {
+			if !hasSignature(tx.xdrEnvelope.Signatures, sig) {
+				tx.xdrEnvelope.Signatures = append(tx.xdrEnvelope.Signatures, sig)
+			}
+		}
+	}
+
+	return nil
+}
+
+// CheckSignatures checks the signatures of a previously built transaction against the signers of
+// its source accounts, i.e. the source of the transaction and the sources of its operations. It
+// returns the status of every source account, in the order they appear in the transaction.
+//
+// The network of the transaction must be set, and accounts must describe every source account.
+func (tx *Transaction) CheckSignatures(accounts ...AccountSigners) ([]SignatureStatus, error) {
+	if tx.xdrEnvelope == nil {
+		return nil, errors.New("transaction has not been built")
+	}
+	if tx.SourceAccount == nil {
+		return nil, errors.New("transaction has no source account")
+	}
+	txHash, err := tx.Hash()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to hash transaction")
+	}
+
+	// the threshold level needed by each source account, in order of appearance
+	var sources []string
+	required := map[string]ThresholdLevel{}
+	addSource := func(accountID string, level ThresholdLevel) {
+		current, ok := required[accountID]
+		if !ok {
+			sources = append(sources, accountID)
+		}
+		if level > current {
+			required[accountID] = level
+		}
+	}
+	addSource(tx.SourceAccount.GetAccountID(), ThresholdLevelLow)
+	for _, op := range tx.Operations {
+		sourceAccountID := tx.SourceAccount.GetAccountID()
+		if opSource := op.GetSourceAccount(); opSource != nil {
+			sourceAccountID = opSource.GetAccountID()
+		}
+		addSource(sourceAccountID, operationThreshold(op))
+	}
+
+	statuses := make([]SignatureStatus, 0, len(sources))
+	for _, accountID := range sources {
+		var account *AccountSigners
+		for i := range accounts {
+			if accounts[i].AccountID == accountID {
+				account = &accounts[i]
+				break
+			}
+		}
+		if account == nil {
+			return nil, errors.Errorf("no signers provided for source account %s", accountID)
+		}
+
+		status := SignatureStatus{AccountID: accountID, Required: required[accountID]}
+		for _, signer := range account.Signers {
+			if signer.Weight == 0 {
+				continue
+			}
+			signed, err := hasSigned(signer, txHash, tx.xdrEnvelope.Signatures)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid signer %s of account %s", signer.Address, accountID)
+			}
+			if signed {
+				status.Signed = append(status.Signed, signer)
+				status.Weight += int(signer.Weight)
+			} else {
+				status.Missing = append(status.Missing, signer)
+			}
+		}
+		status.LowThresholdMet = thresholdMet(status.Weight, account.LowThreshold)
+		status.MediumThresholdMet = thresholdMet(status.Weight, account.MediumThreshold)
+		status.HighThresholdMet = thresholdMet(status.Weight, account.HighThreshold)
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// operationThreshold returns the threshold level needed by op.
+func operationThreshold(op Operation) ThresholdLevel {
+	switch op := op.(type) {
+	case *AllowTrust, *BumpSequence, *Inflation:
+		return ThresholdLevelLow
+	case *AccountMerge:
+		return ThresholdLevelHigh
+	case *SetOptions:
+		if op.MasterWeight != nil || op.LowThreshold != nil || op.MediumThreshold != nil ||
+			op.HighThreshold != nil || op.Signer != nil {
+			return ThresholdLevelHigh
+		}
+	}
+	return ThresholdLevelMedium
+}
+
+// thresholdMet reports whether weight meets threshold. Like stellar-core, a threshold of 0 still
+// requires a signature.
+func thresholdMet(weight int, threshold Threshold) bool {
+	return weight > 0 && weight >= int(threshold)
+}
+
+// hasSigned reports whether signer authorized the transaction with hash txHash through one of
+// signatures.
+func hasSigned(signer Signer, txHash [32]byte, signatures []xdr.DecoratedSignature) (bool, error) {
+	version, key, err := strkey.DecodeAny(signer.Address)
+	if err != nil {
+		return false, err
+	}
+
+	switch version {
+	case strkey.VersionByteAccountID:
+		kp, err := keypair.ParseAddress(signer.Address)
+		if err != nil {
+			return false, err
+		}
+		hint := kp.Hint()
+		for _, sig := range signatures {
+			if sig.Hint == xdr.SignatureHint(hint) && kp.Verify(txHash[:], sig.Signature) == nil {
+				return true, nil
+			}
+		}
+		return false, nil
+	case strkey.VersionByteHashTx:
+		// pre-authorized transactions need no signature
+		return bytes.Equal(key, txHash[:]), nil
+	case strkey.VersionByteHashX:
+		for _, sig := range signatures {
+			preimageHash := sha256.Sum256(sig.Signature)
+			if bytes.Equal(key, preimageHash[:]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, errors.New("unsupported signer type")
+}
+
+func hasSignature(signatures []xdr.DecoratedSignature, sig xdr.DecoratedSignature) bool {
+	for _, s := range signatures {
+		if s.Hint == sig.Hint && bytes.Equal(s.Signature, sig.Signature) {
+			return true
+		}
+	}
+	return false
+}
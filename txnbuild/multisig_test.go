@@ -0,0 +1,149 @@
+package txnbuild
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeAndCheckSignatures(t *testing.T) {
+	kp0 := newKeypair0()
+	kp1 := newKeypair1()
+	kp2 := newKeypair2()
+	txSourceAccount := NewSimpleAccount(kp0.Address(), int64(9605939170639897))
+	opSourceAccount := NewSimpleAccount(kp1.Address(), int64(9606132444168199))
+
+	tx := Transaction{
+		SourceAccount: &txSourceAccount,
+		Operations: []Operation{
+			&Payment{Destination: kp2.Address(), Amount: "10", Asset: NativeAsset{}},
+			&Payment{Destination: kp2.Address(), Amount: "10", Asset: NativeAsset{}, SourceAccount: &opSourceAccount},
+		},
+		Timebounds: NewInfiniteTimeout(),
+		Network:    network.TestNetworkPassphrase,
+	}
+	require.NoError(t, tx.Build())
+	unsigned, err := tx.Base64()
+	require.NoError(t, err)
+
+	// each party signs its own copy of the transaction
+	signedBy := func(signer string) string {
+		txCopy, err := TransactionFromXDR(unsigned)
+		require.NoError(t, err)
+		txCopy.Network = network.TestNetworkPassphrase
+		require.NoError(t, txCopy.SignWithKeyString(signer))
+		txeB64, err := txCopy.Base64()
+		require.NoError(t, err)
+		return txeB64
+	}
+	signed0 := signedBy("SBPQUZ6G4FZNWFHKUWC5BEYWF6R52E3SEP7R3GWYSM2XTKGF5LNTWW4R")
+	signed1 := signedBy("SBMSVD4KKELKGZXHBUQTIROWUAPQASDX7KEJITARP4VMZ6KLUHOGPTYW")
+
+	require.NoError(t, tx.MergeSignatures(signed0, signed1, signed0))
+	assert.Len(t, tx.TxEnvelope().Signatures, 2)
+
+	accounts := []AccountSigners{
+		{
+			AccountID: kp0.Address(),
+			Signers: []Signer{
+				{Address: kp0.Address(), Weight: 1},
+				{Address: kp2.Address(), Weight: 1},
+			},
+			LowThreshold:    1,
+			MediumThreshold: 2,
+			HighThreshold:   3,
+		},
+		{
+			AccountID: kp1.Address(),
+			Signers:   []Signer{{Address: kp1.Address(), Weight: 1}},
+		},
+	}
+
+	statuses, err := tx.CheckSignatures(accounts...)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	assert.Equal(t, kp0.Address(), statuses[0].AccountID)
+	assert.Equal(t, ThresholdLevelMedium, statuses[0].Required)
+	assert.Equal(t, 1, statuses[0].Weight)
+	assert.True(t, statuses[0].LowThresholdMet)
+	assert.False(t, statuses[0].MediumThresholdMet)
+	assert.False(t, statuses[0].Authorized())
+	assert.Equal(t, []Signer{{Address: kp0.Address(), Weight: 1}}, statuses[0].Signed)
+	assert.Equal(t, []Signer{{Address: kp2.Address(), Weight: 1}}, statuses[0].Missing)
+
+	assert.Equal(t, kp1.Address(), statuses[1].AccountID)
+	assert.True(t, statuses[1].Authorized())
+	assert.Empty(t, statuses[1].Missing)
+
+	require.NoError(t, tx.Sign(kp2))
+	statuses, err = tx.CheckSignatures(accounts...)
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Authorized())
+	assert.False(t, statuses[0].HighThresholdMet)
+
+	// every source account must be described
+	_, err = tx.CheckSignatures(accounts[0])
+	assert.EqualError(t, err, "no signers provided for source account "+kp1.Address())
+}
+
+func TestMergeSignaturesDifferentTransaction(t *testing.T) {
+	kp0 := newKeypair0()
+	sourceAccount := NewSimpleAccount(kp0.Address(), int64(9605939170639897))
+
+	tx := Transaction{
+		SourceAccount: &sourceAccount,
+		Operations:    []Operation{&BumpSequence{BumpTo: 1}},
+		Timebounds:    NewInfiniteTimeout(),
+		Network:       network.TestNetworkPassphrase,
+	}
+	// the second build uses the next sequence number
+	first := buildSignEncode(t, tx, kp0)
+	require.NoError(t, tx.Build())
+
+	err := tx.MergeSignatures(first)
+	assert.EqualError(t, err, "envelope holds a different transaction")
+}
+
+func TestCheckSignaturesHashXAndPreAuth(t *testing.T) {
+	kp0 := newKeypair0()
+	sourceAccount := NewSimpleAccount(kp0.Address(), int64(9605939170639897))
+
+	tx := Transaction{
+		SourceAccount: &sourceAccount,
+		Operations:    []Operation{&AccountMerge{Destination: newKeypair1().Address()}},
+		Timebounds:    NewInfiniteTimeout(),
+		Network:       network.TestNetworkPassphrase,
+	}
+	require.NoError(t, tx.Build())
+	txHash, err := tx.Hash()
+	require.NoError(t, err)
+
+	preimage := []byte("the preimage")
+	preimageHash := sha256.Sum256(preimage)
+	hashXSigner := Signer{Address: strkey.MustEncode(strkey.VersionByteHashX, preimageHash[:]), Weight: 1}
+	preAuthSigner := Signer{Address: strkey.MustEncode(strkey.VersionByteHashTx, txHash[:]), Weight: 1}
+	account := AccountSigners{
+		AccountID:     kp0.Address(),
+		Signers:       []Signer{{Address: kp0.Address(), Weight: 0}, hashXSigner, preAuthSigner},
+		HighThreshold: 2,
+	}
+
+	statuses, err := tx.CheckSignatures(account)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, ThresholdLevelHigh, statuses[0].Required)
+	assert.Equal(t, []Signer{preAuthSigner}, statuses[0].Signed)
+	assert.Equal(t, []Signer{hashXSigner}, statuses[0].Missing)
+	assert.False(t, statuses[0].Authorized())
+
+	require.NoError(t, tx.SignHashX(preimage))
+	statuses, err = tx.CheckSignatures(account)
+	require.NoError(t, err)
+	assert.Equal(t, 2, statuses[0].Weight)
+	assert.True(t, statuses[0].Authorized())
+}
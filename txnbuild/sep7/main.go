@@ -0,0 +1,302 @@
+// Package sep7 builds, parses and signs the URIs defined by SEP-7, which let applications ask a
+// wallet to sign a transaction or to make a payment:
+// https://github.com/stellar/stellar-protocol/blob/master/ecosystem/sep-0007.md
+//
+// A web+stellar:tx URI holds a transaction envelope for the wallet to sign, and a web+stellar:pay
+// URI holds the details of a payment, leaving the wallet to build the transaction. Both can be
+// signed by the domain requesting them, so that wallets can show that domain to their users once
+// the signature is verified against the URI_REQUEST_SIGNING_KEY of its stellar.toml file.
+package sep7
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/txnbuild"
+)
+
+const (
+	// Scheme is the scheme of SEP-7 URIs.
+	Scheme = "web+stellar"
+	// OperationTx is the operation of URIs asking for a transaction to be signed.
+	OperationTx = "tx"
+	// OperationPay is the operation of URIs asking for a payment.
+	OperationPay = "pay"
+	// MsgMaxLength is the maximum number of characters of the message of a URI.
+	MsgMaxLength = 300
+
+	// callbackPrefix prefixes the callback URLs, as other kinds of callbacks may be added later
+	callbackPrefix = "url:"
+)
+
+// URI is a SEP-7 URI, either a *TxURI or a *PayURI.
+type URI interface {
+	// String returns the encoded URI.
+	String() string
+	// GetParams returns the parameters shared by all the operations.
+	GetParams() *Params
+}
+
+// Params holds the parameters shared by all the operations.
+type Params struct {
+	// Callback is the URL the signed transaction is posted to, instead of being submitted to the
+	// network.
+	Callback string
+	// Msg is a message shown to the user, of up to MsgMaxLength characters.
+	Msg string
+	// NetworkPassphrase is the passphrase of the network of the transaction, which is the public
+	// network when empty.
+	NetworkPassphrase string
+	// OriginDomain is the domain requesting the URI, which must sign it.
+	OriginDomain string
+	// Signature is the signature of the URI by the URI_REQUEST_SIGNING_KEY of OriginDomain. It is
+	// set by Sign.
+	Signature string
+}
+
+// GetParams returns p.
+func (p *Params) GetParams() *Params {
+	return p
+}
+
+// Network returns the passphrase of the network of the URI.
+func (p *Params) Network() string {
+	if p.NetworkPassphrase == "" {
+		return network.PublicNetworkPassphrase
+	}
+	return p.NetworkPassphrase
+}
+
+// TxURI is a web+stellar:tx URI, asking for a transaction to be signed.
+type TxURI struct {
+	// XDR is the base64-encoded envelope of the transaction.
+	XDR string
+	// Pubkey is the account which should sign the transaction, when set.
+	Pubkey string
+	Params
+}
+
+// PayURI is a web+stellar:pay URI, asking for a payment.
+type PayURI struct {
+	// Destination is the account or federation address receiving the payment.
+	Destination string
+	// Amount is the amount to pay. When empty, the user chooses the amount.
+	Amount string
+	// Asset is the asset to pay, which is lumens when nil.
+	Asset txnbuild.Asset
+	// Memo is the memo of the transaction, when not nil.
+	Memo txnbuild.Memo
+	Params
+}
+
+// NewTxURI returns a URI asking for tx to be signed. The transaction must have been built.
+func NewTxURI(tx *txnbuild.Transaction) (*TxURI, error) {
+	txeB64, err := tx.Base64()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode transaction")
+	}
+	u := &TxURI{XDR: txeB64}
+	if tx.Network != network.PublicNetworkPassphrase {
+		u.NetworkPassphrase = tx.Network
+	}
+	return u, nil
+}
+
+// Transaction returns the transaction of the URI.
+func (u *TxURI) Transaction() (txnbuild.Transaction, error) {
+	tx, err := txnbuild.TransactionFromXDR(u.XDR)
+	if err != nil {
+		return txnbuild.Transaction{}, err
+	}
+	tx.Network = u.Network()
+	return tx, nil
+}
+
+// String returns the encoded URI.
+func (u *TxURI) String() string {
+	q := &query{}
+	q.add("xdr", u.XDR)
+	q.add("pubkey", u.Pubkey)
+	q.params(&u.Params)
+	return Scheme + ":" + OperationTx + "?" + q.String()
+}
+
+// String returns the encoded URI.
+func (u *PayURI) String() string {
+	q := &query{}
+	q.add("destination", u.Destination)
+	q.add("amount", u.Amount)
+	if u.Asset != nil && !u.Asset.IsNative() {
+		q.add("asset_code", u.Asset.GetCode())
+		q.add("asset_issuer", u.Asset.GetIssuer())
+	}
+	switch memo := u.Memo.(type) {
+	case txnbuild.MemoText:
+		q.add("memo", string(memo))
+		q.add("memo_type", "MEMO_TEXT")
+	case txnbuild.MemoID:
+		q.add("memo", strconv.FormatUint(uint64(memo), 10))
+		q.add("memo_type", "MEMO_ID")
+	case txnbuild.MemoHash:
+		q.add("memo", base64.StdEncoding.EncodeToString(memo[:]))
+		q.add("memo_type", "MEMO_HASH")
+	case txnbuild.MemoReturn:
+		q.add("memo", base64.StdEncoding.EncodeToString(memo[:]))
+		q.add("memo_type", "MEMO_RETURN")
+	}
+	q.params(&u.Params)
+	return Scheme + ":" + OperationPay + "?" + q.String()
+}
+
+// Parse decodes a SEP-7 URI, returning a *TxURI or a *PayURI. Unknown parameters are ignored, as
+// required by SEP-7.
+func Parse(uri string) (URI, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid URI")
+	}
+	if parsed.Scheme != Scheme {
+		return nil, errors.Errorf("invalid scheme %s, expected %s", parsed.Scheme, Scheme)
+	}
+	values, err := url.ParseQuery(parsed.RawQuery)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid URI query")
+	}
+
+	params, err := parseParams(values)
+	if err != nil {
+		return nil, err
+	}
+	var u URI
+	switch parsed.Opaque {
+	case OperationTx:
+		u, err = parseTx(values, params)
+	case OperationPay:
+		u, err = parsePay(values, params)
+	default:
+		err = errors.Errorf("unknown operation %s", parsed.Opaque)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func parseParams(values url.Values) (Params, error) {
+	params := Params{
+		Msg:               values.Get("msg"),
+		NetworkPassphrase: values.Get("network_passphrase"),
+		OriginDomain:      values.Get("origin_domain"),
+		Signature:         values.Get("signature"),
+	}
+	if callback := values.Get("callback"); callback != "" {
+		if !strings.HasPrefix(callback, callbackPrefix) {
+			return Params{}, errors.Errorf("callback %s is not prefixed with %s", callback, callbackPrefix)
+		}
+		params.Callback = strings.TrimPrefix(callback, callbackPrefix)
+	}
+	if len([]rune(params.Msg)) > MsgMaxLength {
+		return Params{}, errors.Errorf("msg is longer than %d characters", MsgMaxLength)
+	}
+	return params, nil
+}
+
+func parseTx(values url.Values, params Params) (*TxURI, error) {
+	u := &TxURI{XDR: values.Get("xdr"), Pubkey: values.Get("pubkey"), Params: params}
+	if u.XDR == "" {
+		return nil, errors.New("missing xdr parameter")
+	}
+	if u.Pubkey != "" && !strkey.IsValidEd25519PublicKey(u.Pubkey) {
+		return nil, errors.Errorf("invalid pubkey %s", u.Pubkey)
+	}
+	return u, nil
+}
+
+func parsePay(values url.Values, params Params) (*PayURI, error) {
+	u := &PayURI{Destination: values.Get("destination"), Amount: values.Get("amount"), Params: params}
+	if u.Destination == "" {
+		return nil, errors.New("missing destination parameter")
+	}
+
+	code, issuer := values.Get("asset_code"), values.Get("asset_issuer")
+	switch {
+	case code == "" && issuer == "":
+		// lumens are left as a nil Asset, so that parsing what String returned gives back the same URI
+	case code == "" || issuer == "":
+		return nil, errors.New("asset_code and asset_issuer must be given together")
+	default:
+		u.Asset = txnbuild.CreditAsset{Code: code, Issuer: issuer}
+	}
+
+	memo, err := parseMemo(values.Get("memo"), values.Get("memo_type"))
+	if err != nil {
+		return nil, err
+	}
+	u.Memo = memo
+	return u, nil
+}
+
+func parseMemo(memo, memoType string) (txnbuild.Memo, error) {
+	if memo == "" {
+		return nil, nil
+	}
+	switch memoType {
+	case "", "MEMO_TEXT":
+		return txnbuild.MemoText(memo), nil
+	case "MEMO_ID":
+		id, err := strconv.ParseUint(memo, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid MEMO_ID memo")
+		}
+		return txnbuild.MemoID(id), nil
+	case "MEMO_HASH", "MEMO_RETURN":
+		raw, err := base64.StdEncoding.DecodeString(memo)
+		if err != nil || len(raw) != 32 {
+			return nil, errors.Errorf("invalid %s memo, expected 32 bytes in base64", memoType)
+		}
+		var hash [32]byte
+		copy(hash[:], raw)
+		if memoType == "MEMO_HASH" {
+			return txnbuild.MemoHash(hash), nil
+		}
+		return txnbuild.MemoReturn(hash), nil
+	default:
+		return nil, errors.Errorf("unknown memo_type %s", memoType)
+	}
+}
+
+// query builds the query of a URI, keeping the order of its parameters so that the signature,
+// added last, covers all of them.
+type query struct {
+	parts []string
+}
+
+func (q *query) add(key, value string) {
+	if value != "" {
+		q.parts = append(q.parts, key+"="+escape(value))
+	}
+}
+
+func (q *query) params(p *Params) {
+	if p.Callback != "" {
+		q.add("callback", callbackPrefix+p.Callback)
+	}
+	q.add("msg", p.Msg)
+	q.add("network_passphrase", p.NetworkPassphrase)
+	q.add("origin_domain", p.OriginDomain)
+	q.add("signature", p.Signature)
+}
+
+func (q *query) String() string {
+	return strings.Join(q.parts, "&")
+}
+
+// escape percent-encodes a query value, encoding spaces as %20 as in the examples of SEP-7.
+func escape(value string) string {
+	return strings.Replace(url.QueryEscape(value), "+", "%20", -1)
+}
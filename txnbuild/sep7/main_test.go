@@ -0,0 +1,137 @@
+package sep7
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stellar/go/clients/stellartoml"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	destination = "GCALNQQBXAPZ2WIRSDDBMSTAKCUH5SG6U76YBFLQLIXJTF7FE5AX7AOO"
+	signingSeed = "SBPQUZ6G4FZNWFHKUWC5BEYWF6R52E3SEP7R3GWYSM2XTKGF5LNTWW4R"
+)
+
+func TestTxURI(t *testing.T) {
+	source := txnbuild.NewSimpleAccount(destination, 100)
+	tx := txnbuild.Transaction{
+		SourceAccount: &source,
+		Operations:    []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 200}},
+		Timebounds:    txnbuild.NewInfiniteTimeout(),
+		Network:       network.TestNetworkPassphrase,
+	}
+	require.NoError(t, tx.Build())
+
+	u, err := NewTxURI(&tx)
+	require.NoError(t, err)
+	u.Callback = "https://example.com/sign?id=1"
+	u.Msg = "order number 24"
+	uri := u.String()
+	assert.True(t, strings.HasPrefix(uri, "web+stellar:tx?xdr="))
+	assert.Contains(t, uri, "&callback=url%3Ahttps%3A%2F%2Fexample.com%2Fsign%3Fid%3D1&msg=order%20number%2024&network_passphrase=Test%20SDF%20Network%20%3B%20September%202015")
+
+	parsed, err := Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, u, parsed)
+
+	decoded, err := parsed.(*TxURI).Transaction()
+	require.NoError(t, err)
+	assert.Equal(t, network.TestNetworkPassphrase, decoded.Network)
+	assert.Equal(t, int64(101), decoded.SourceAccount.(*txnbuild.SimpleAccount).Sequence)
+}
+
+func TestPayURI(t *testing.T) {
+	for _, u := range []*PayURI{
+		{Destination: destination},
+		{
+			Destination: destination,
+			Amount:      "120.1234567",
+			Asset:       txnbuild.CreditAsset{Code: "USD", Issuer: destination},
+			Memo:        txnbuild.MemoText("skdjfasf"),
+			Params:      Params{Msg: "pay me with lumens", OriginDomain: "example.com"},
+		},
+		{Destination: destination, Memo: txnbuild.MemoID(12345)},
+		{Destination: destination, Memo: txnbuild.MemoHash{1, 2, 3}},
+		{Destination: destination, Memo: txnbuild.MemoReturn{4, 5, 6}},
+	} {
+		parsed, err := Parse(u.String())
+		require.NoError(t, err)
+		assert.Equal(t, u, parsed)
+	}
+
+	// lumens are parsed as a nil Asset
+	parsed, err := Parse((&PayURI{Destination: destination, Asset: txnbuild.NativeAsset{}}).String())
+	require.NoError(t, err)
+	assert.Nil(t, parsed.(*PayURI).Asset)
+
+	u := &PayURI{
+		Destination: destination,
+		Amount:      "120.1234567",
+		Memo:        txnbuild.MemoText("skdjfasf"),
+		Params:      Params{Msg: "pay me with lumens"},
+	}
+	assert.Equal(t, "web+stellar:pay?destination="+destination+"&amount=120.1234567&memo=skdjfasf&memo_type=MEMO_TEXT&msg=pay%20me%20with%20lumens", u.String())
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, testCase := range []struct {
+		uri string
+		err string
+	}{
+		{"https://example.com", "invalid scheme https, expected web+stellar"},
+		{"web+stellar:sign?xdr=AAAA", "unknown operation sign"},
+		{"web+stellar:tx?callback=url%3Ahttps%3A%2F%2Fexample.com", "missing xdr parameter"},
+		{"web+stellar:tx?xdr=AAAA&callback=https%3A%2F%2Fexample.com", "callback https://example.com is not prefixed with url:"},
+		{"web+stellar:tx?xdr=AAAA&pubkey=GABC", "invalid pubkey GABC"},
+		{"web+stellar:pay?amount=10", "missing destination parameter"},
+		{"web+stellar:pay?destination=" + destination + "&asset_code=USD", "asset_code and asset_issuer must be given together"},
+		{"web+stellar:pay?destination=" + destination + "&memo=abc&memo_type=MEMO_ID", `invalid MEMO_ID memo: strconv.ParseUint: parsing "abc": invalid syntax`},
+		{"web+stellar:pay?destination=" + destination + "&memo=abc&memo_type=MEMO_HASH", "invalid MEMO_HASH memo, expected 32 bytes in base64"},
+		{"web+stellar:pay?destination=" + destination + "&memo=abc&memo_type=MEMO_OTHER", "unknown memo_type MEMO_OTHER"},
+		{"web+stellar:pay?destination=" + destination + "&msg=" + strings.Repeat("a", 301), "msg is longer than 300 characters"},
+	} {
+		_, err := Parse(testCase.uri)
+		assert.EqualError(t, err, testCase.err, testCase.uri)
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	kp := keypair.MustParse(signingSeed).(*keypair.Full)
+	u := &PayURI{
+		Destination: destination,
+		Amount:      "10",
+		Params:      Params{Msg: "order number 24", OriginDomain: "example.com"},
+	}
+	require.NoError(t, Sign(u, kp))
+	uri := u.String()
+	assert.Contains(t, uri, "&origin_domain=example.com&signature=")
+
+	client := &stellartoml.MockClient{}
+	client.On("GetStellarToml", "example.com").
+		Return(&stellartoml.Response{URIRequestSigningKey: kp.Address()}, nil)
+
+	verified, err := Verify(uri, client)
+	require.NoError(t, err)
+	assert.Equal(t, u, verified)
+
+	// any change to the URI invalidates the signature
+	_, err = Verify(strings.Replace(uri, "amount=10", "amount=100", 1), client)
+	assert.EqualError(t, err, "invalid signature")
+
+	// the signature must come from the signing key of the origin domain
+	other := keypair.MustParse("SBMSVD4KKELKGZXHBUQTIROWUAPQASDX7KEJITARP4VMZ6KLUHOGPTYW").(*keypair.Full)
+	require.NoError(t, Sign(u, other))
+	_, err = Verify(u.String(), client)
+	assert.EqualError(t, err, "invalid signature")
+
+	u.Signature = ""
+	_, err = Verify(u.String(), client)
+	assert.EqualError(t, err, "URI is not signed")
+
+	assert.EqualError(t, Sign(&TxURI{XDR: "AAAA"}, kp), "origin_domain is required to sign a URI")
+}
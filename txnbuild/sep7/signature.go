@@ -0,0 +1,106 @@
+package sep7
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"github.com/stellar/go/clients/stellartoml"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/support/errors"
+)
+
+// signaturePrefix is prepended to URIs before they are signed: 35 zero bytes followed by 4, then
+// the name of SEP-7.
+var signaturePrefix = append(append(make([]byte, 35), 4), "stellar.sep.7 - URI Scheme"...)
+
+// Sign signs u with kp, which must be the URI_REQUEST_SIGNING_KEY of the origin domain of u, and
+// sets its signature. The URI must not be changed once signed.
+func Sign(u URI, kp *keypair.Full) error {
+	params := u.GetParams()
+	if params.OriginDomain == "" {
+		return errors.New("origin_domain is required to sign a URI")
+	}
+	params.Signature = ""
+
+	signature, err := kp.Sign(signaturePayload(u.String()))
+	if err != nil {
+		return errors.Wrap(err, "failed to sign URI")
+	}
+	params.Signature = base64.StdEncoding.EncodeToString(signature)
+	return nil
+}
+
+// Verify parses a signed URI and verifies its signature against the URI_REQUEST_SIGNING_KEY of
+// its origin domain, fetched with client.
+func Verify(uri string, client stellartoml.ClientInterface) (URI, error) {
+	u, err := Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	domain := u.GetParams().OriginDomain
+	if domain == "" {
+		return nil, errors.New("URI has no origin_domain")
+	}
+
+	toml, err := client.GetStellarToml(domain)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get stellar.toml of %s", domain)
+	}
+	if toml.URIRequestSigningKey == "" {
+		return nil, errors.Errorf("stellar.toml of %s has no URI_REQUEST_SIGNING_KEY", domain)
+	}
+	if err := VerifySignature(uri, toml.URIRequestSigningKey); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// VerifySignature verifies the signature of a URI against signingKey, the address of the
+// URI_REQUEST_SIGNING_KEY of its origin domain.
+func VerifySignature(uri, signingKey string) error {
+	kp, err := keypair.ParseAddress(signingKey)
+	if err != nil {
+		return errors.Wrap(err, "invalid signing key")
+	}
+
+	// the signature covers the URI without its signature parameter
+	i := strings.Index(uri, "?")
+	if i < 0 {
+		return errors.New("URI is not signed")
+	}
+	var (
+		parts     []string
+		signature string
+	)
+	for _, part := range strings.Split(uri[i+1:], "&") {
+		if strings.HasPrefix(part, "signature=") {
+			signature = strings.TrimPrefix(part, "signature=")
+			continue
+		}
+		parts = append(parts, part)
+	}
+	if signature == "" {
+		return errors.New("URI is not signed")
+	}
+
+	signature, err = url.QueryUnescape(signature)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature encoding")
+	}
+	raw, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature encoding")
+	}
+	unsigned := uri[:i+1] + strings.Join(parts, "&")
+	if err := kp.Verify(signaturePayload(unsigned), raw); err != nil {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func signaturePayload(uri string) []byte {
+	payload := make([]byte, 0, len(signaturePrefix)+len(uri))
+	payload = append(payload, signaturePrefix...)
+	return append(payload, uri...)
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/stellar/go/keypair"
 	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
 )
@@ -169,8 +170,9 @@ func (tx *Transaction) Build() error {
 }
 
 // Sign for Transaction signs a previously built transaction. A signed transaction may be
-// submitted to the network.
-func (tx *Transaction) Sign(kps ...*keypair.Full) error {
+// submitted to the network. Any keypair.Signer can sign, such as a *keypair.Full or a signer
+// backed by an external key store.
+func (tx *Transaction) Sign(signers ...keypair.Signer) error {
 	// TODO: Only sign if Transaction has been previously built
 	// TODO: Validate network set before sign
 
@@ -181,8 +183,8 @@ func (tx *Transaction) Sign(kps ...*keypair.Full) error {
 	}
 
 	// Sign the hash
-	for _, kp := range kps {
-		sig, err := kp.SignDecorated(hash[:])
+	for _, signer := range signers {
+		sig, err := signer.SignDecorated(hash[:])
 		if err != nil {
 			return errors.Wrap(err, "failed to sign transaction")
 		}
@@ -195,13 +197,13 @@ func (tx *Transaction) Sign(kps ...*keypair.Full) error {
 
 // BuildSignEncode performs all the steps to produce a final transaction suitable
 // for submitting to the network.
-func (tx *Transaction) BuildSignEncode(keypairs ...*keypair.Full) (string, error) {
+func (tx *Transaction) BuildSignEncode(signers ...keypair.Signer) (string, error) {
 	err := tx.Build()
 	if err != nil {
 		return "", errors.Wrap(err, "couldn't build transaction")
 	}
 
-	err = tx.Sign(keypairs...)
+	err = tx.Sign(signers...)
 	if err != nil {
 		return "", errors.Wrap(err, "couldn't sign transaction")
 	}
@@ -397,7 +399,7 @@ func TransactionFromXDR(txeB64 string) (Transaction, error) {
 // as a string. This can be used when you don't have access to a Stellar keypair.
 // A signed transaction may be submitted to the network.
 func (tx *Transaction) SignWithKeyString(keys ...string) error {
-	signers := []*keypair.Full{}
+	signers := []keypair.Signer{}
 	for _, k := range keys {
 		kp, err := keypair.Parse(k)
 		if err != nil {
@@ -416,79 +418,208 @@ func (tx *Transaction) SignWithKeyString(keys ...string) error {
 // VerifyChallengeTx is a factory method that verifies a SEP 10 challenge transaction,
 // for use in web authentication. It can be used by a server to verify that the challenge
 // has been signed by the client.
+//
+// Only the signature of the master key of the client account is checked. Use
+// VerifyChallengeTxThreshold or VerifyChallengeTxSigners to authenticate accounts with other
+// signers.
 // More details on SEP 10: https://github.com/stellar/stellar-protocol/blob/master/ecosystem/sep-0010.md
 func VerifyChallengeTx(challengeTx, serverAccountID, network string) (bool, error) {
-	tx, err := TransactionFromXDR(challengeTx)
+	tx, clientAccountID, err := ReadChallengeTx(challengeTx, serverAccountID, network)
 	if err != nil {
 		return false, err
 	}
+
+	// verify signature from operation source
+	err = verifyTxSignature(tx, clientAccountID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ReadChallengeTx reads a SEP 10 challenge transaction and returns the decoded transaction and
+// the account ID of the client. It verifies the structure and the timebounds of the challenge,
+// and that it is signed by the server, but not the signatures of the client: they are checked
+// against the signers of the client account with VerifyChallengeTxThreshold or
+// VerifyChallengeTxSigners.
+// More details on SEP 10: https://github.com/stellar/stellar-protocol/blob/master/ecosystem/sep-0010.md
+func ReadChallengeTx(challengeTx, serverAccountID, network string) (Transaction, string, error) {
+	tx, err := TransactionFromXDR(challengeTx)
+	if err != nil {
+		return Transaction{}, "", err
+	}
 	tx.Network = network
 
 	// verify transaction source
 	if tx.SourceAccount == nil {
-		return false, errors.New("transaction requires a source account")
+		return Transaction{}, "", errors.New("transaction requires a source account")
 	}
 	if tx.SourceAccount.GetAccountID() != serverAccountID {
-		return false, errors.New("transaction source account is not equal to server's account")
+		return Transaction{}, "", errors.New("transaction source account is not equal to server's account")
 	}
 
 	//verify sequence number
 	txSourceAccount, ok := tx.SourceAccount.(*SimpleAccount)
 	if !ok {
-		return false, errors.New("source account is not of type SimpleAccount unable to verify sequence number")
+		return Transaction{}, "", errors.New("source account is not of type SimpleAccount unable to verify sequence number")
 	}
 	if txSourceAccount.Sequence != 0 {
-		return false, errors.New("transaction sequence number must be 0")
+		return Transaction{}, "", errors.New("transaction sequence number must be 0")
 	}
 
 	// verify timebounds
 	if tx.Timebounds.MaxTime == TimeoutInfinite {
-		return false, errors.New("transaction requires non-infinite timebounds")
+		return Transaction{}, "", errors.New("transaction requires non-infinite timebounds")
 	}
 	currentTime := time.Now().UTC().Unix()
 	if currentTime < tx.Timebounds.MinTime || currentTime > tx.Timebounds.MaxTime {
-		return false, errors.Errorf("transaction is not within range of the specified timebounds (currentTime=%d, MinTime=%d, MaxTime=%d)",
+		return Transaction{}, "", errors.Errorf("transaction is not within range of the specified timebounds (currentTime=%d, MinTime=%d, MaxTime=%d)",
 			currentTime, tx.Timebounds.MinTime, tx.Timebounds.MaxTime)
 	}
 
 	// verify operation
 	if len(tx.Operations) != 1 {
-		return false, errors.New("transaction requires a single manage_data operation")
+		return Transaction{}, "", errors.New("transaction requires a single manage_data operation")
 	}
 	op, ok := tx.Operations[0].(*ManageData)
 	if !ok {
-		return false, errors.New("operation type should be manage_data")
+		return Transaction{}, "", errors.New("operation type should be manage_data")
 	}
 	if op.SourceAccount == nil {
-		return false, errors.New("operation should have a source account")
+		return Transaction{}, "", errors.New("operation should have a source account")
 	}
 
 	// verify manage data value
 	nonceB64 := string(op.Value)
 	if len(nonceB64) != 64 {
-		return false, errors.New("random nonce encoded as base64 should be 64 bytes long")
+		return Transaction{}, "", errors.New("random nonce encoded as base64 should be 64 bytes long")
 	}
 	nonceBytes, err := base64.StdEncoding.DecodeString(nonceB64)
 	if err != nil {
-		return false, errors.Wrap(err, "failed to decode random nonce provided in manage_data operation")
+		return Transaction{}, "", errors.Wrap(err, "failed to decode random nonce provided in manage_data operation")
 	}
 	if len(nonceBytes) != 48 {
-		return false, errors.New("random nonce before encoding as base64 should be 48 bytes long")
+		return Transaction{}, "", errors.New("random nonce before encoding as base64 should be 48 bytes long")
 	}
 
-	// verify signature from operation source
-	err = verifyTxSignature(tx, op.SourceAccount.GetAccountID())
+	// verify signature from server signing key
+	err = verifyTxSignature(tx, serverAccountID)
 	if err != nil {
-		return false, err
+		return Transaction{}, "", err
 	}
 
-	// verify signature from server signing key
-	err = verifyTxSignature(tx, serverAccountID)
+	return tx, op.SourceAccount.GetAccountID(), nil
+}
+
+// VerifyChallengeTxThreshold verifies a SEP 10 challenge transaction signed by signers of the
+// client account, whose weights must add up to at least threshold. Signers are usually the
+// signers of the client account, including its master key with its weight, and threshold one of
+// its thresholds. It returns the signers which signed the challenge, in the order of the
+// signatures.
+//
+// The challenge must be signed once by the server and once by each of the signers returned: it
+// is rejected when it has any other signature, including duplicate signatures and signatures of
+// signers that are not in signers or have a weight of 0.
+// More details on SEP 10: https://github.com/stellar/stellar-protocol/blob/master/ecosystem/sep-0010.md
+func VerifyChallengeTxThreshold(challengeTx, serverAccountID, network string, threshold Threshold, signers []Signer) ([]Signer, error) {
+	weights := map[string]int{}
+	addresses := make([]string, 0, len(signers))
+	for _, signer := range signers {
+		if signer.Weight == 0 {
+			continue
+		}
+		if _, ok := weights[signer.Address]; !ok {
+			addresses = append(addresses, signer.Address)
+		}
+		weights[signer.Address] += int(signer.Weight)
+	}
+
+	signed, err := VerifyChallengeTxSigners(challengeTx, serverAccountID, network, addresses...)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	return true, nil
+	signersFound := make([]Signer, 0, len(signed))
+	weight := 0
+	for _, address := range signed {
+		signersFound = append(signersFound, Signer{Address: address, Weight: Threshold(weights[address])})
+		weight += weights[address]
+	}
+	if !thresholdMet(weight, threshold) {
+		return signersFound, errors.Errorf("signers with weight %d do not meet threshold %d", weight, threshold)
+	}
+
+	return signersFound, nil
+}
+
+// VerifyChallengeTxSigners verifies a SEP 10 challenge transaction signed by at least one of
+// signers, which are the addresses of the ed25519 keys allowed to authenticate the client account.
+// It returns the signers which signed the challenge, in the order of the signatures.
+//
+// The challenge must be signed once by the server and once by each of the signers returned: it
+// is rejected when it has any other signature, including duplicate signatures. Signers which are
+// not ed25519 public keys, and the server account, are ignored.
+// More details on SEP 10: https://github.com/stellar/stellar-protocol/blob/master/ecosystem/sep-0010.md
+func VerifyChallengeTxSigners(challengeTx, serverAccountID, network string, signers ...string) ([]string, error) {
+	tx, _, err := ReadChallengeTx(challengeTx, serverAccountID, network)
+	if err != nil {
+		return nil, err
+	}
+
+	// the keys allowed to sign the challenge, the server's first
+	serverKP, err := keypair.ParseAddress(serverAccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid server account")
+	}
+	keys := []*keypair.FromAddress{serverKP}
+	for _, signer := range signers {
+		if signer == serverAccountID || !strkey.IsValidEd25519PublicKey(signer) {
+			continue
+		}
+		kp, err := keypair.ParseAddress(signer)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid signer %s", signer)
+		}
+		keys = append(keys, kp)
+	}
+	if len(keys) == 1 {
+		return nil, errors.New("no ed25519 signer provided")
+	}
+
+	txHash, err := tx.Hash()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to hash transaction")
+	}
+
+	// match every signature with exactly one key, which must not have signed already
+	used := make([]bool, len(keys))
+	var signersFound []string
+	for _, sig := range tx.xdrEnvelope.Signatures {
+		found := false
+		for i, kp := range keys {
+			if sig.Hint != xdr.SignatureHint(kp.Hint()) || kp.Verify(txHash[:], sig.Signature) != nil {
+				continue
+			}
+			if used[i] {
+				return nil, errors.Errorf("transaction has a duplicate signature from %s", kp.Address())
+			}
+			used[i] = true
+			found = true
+			if i > 0 {
+				signersFound = append(signersFound, kp.Address())
+			}
+			break
+		}
+		if !found {
+			return nil, errors.New("transaction has unrecognized signatures")
+		}
+	}
+	if len(signersFound) == 0 {
+		return nil, errors.New("transaction not signed by any of the signers")
+	}
+
+	return signersFound, nil
 }
 
 // verifyTxSignature checks if a transaction has been signed by the provided Stellar account.
@@ -3,9 +3,14 @@ package txnbuild
 import (
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/stellar/go/keypair"
 	"github.com/stellar/go/network"
 	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/xdr"
@@ -1163,6 +1168,41 @@ func TestSignWithSecretKey(t *testing.T) {
 	assert.Equal(t, expected, actual, "base64 xdr should match")
 }
 
+func TestSignWithRemoteSigner(t *testing.T) {
+	kp0 := newKeypair0()
+	kp1 := newKeypair1()
+	// the stand-in for the remote key store holds the seed of kp1
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		var request struct{ Payload []byte }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		signature, err := kp1.Sign(request.Payload)
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(w).Encode(map[string][]byte{"signature": signature}))
+	}))
+	defer server.Close()
+	remote, err := keypair.NewRemoteSigner(server.URL, kp1.Address())
+	require.NoError(t, err)
+
+	newTx := func() *Transaction {
+		txSource := NewSimpleAccount(kp0.Address(), int64(9605939170639897))
+		return &Transaction{
+			SourceAccount: &txSource,
+			Operations:    []Operation{&BumpSequence{BumpTo: 9605939170639898}},
+			Timebounds:    NewInfiniteTimeout(),
+			Network:       network.TestNetworkPassphrase,
+		}
+	}
+	expected, err := newTx().BuildSignEncode(kp0, kp1)
+	require.NoError(t, err)
+
+	actual, err := newTx().BuildSignEncode(kp0, remote)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual, "base64 xdr should match")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
 func TestVerifyTxSignatureUnsignedTx(t *testing.T) {
 	kp0 := newKeypair0()
 	kp1 := newKeypair1()
@@ -1448,3 +1488,116 @@ func TestVerifyChallengeTxRandomNonce(t *testing.T) {
 	}
 	assert.Equal(t, false, isValid, "challenge should be invalid")
 }
+
+// signChallenge returns the challenge signed by kps.
+func signChallenge(t *testing.T, challenge string, kps ...keypair.Signer) string {
+	tx, err := TransactionFromXDR(challenge)
+	require.NoError(t, err)
+	tx.Network = network.TestNetworkPassphrase
+	require.NoError(t, tx.Sign(kps...))
+	signed, err := tx.Base64()
+	require.NoError(t, err)
+	return signed
+}
+
+func TestReadChallengeTx(t *testing.T) {
+	serverKP := newKeypair0()
+	clientKP := newKeypair1()
+	challenge, err := BuildChallengeTx(serverKP.Seed(), clientKP.Address(), "sdf", network.TestNetworkPassphrase, time.Minute)
+	require.NoError(t, err)
+
+	// the signatures of the client are not checked
+	tx, clientAccountID, err := ReadChallengeTx(challenge, serverKP.Address(), network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	assert.Equal(t, clientKP.Address(), clientAccountID)
+	assert.Equal(t, network.TestNetworkPassphrase, tx.Network)
+
+	// the challenge must be signed by the server
+	envelope, err := unmarshalBase64(challenge)
+	require.NoError(t, err)
+	envelope.Signatures = nil
+	challenge, err = xdr.MarshalBase64(envelope)
+	require.NoError(t, err)
+	_, _, err = ReadChallengeTx(challenge, serverKP.Address(), network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "transaction not signed by "+serverKP.Address())
+}
+
+func TestVerifyChallengeTxSigners(t *testing.T) {
+	serverKP := newKeypair0()
+	clientKP := newKeypair1()
+	signerKP := newKeypair2()
+	otherKP := keypair.MustRandom()
+	challenge, err := BuildChallengeTx(serverKP.Seed(), clientKP.Address(), "sdf", network.TestNetworkPassphrase, time.Minute)
+	require.NoError(t, err)
+	signers := []string{clientKP.Address(), signerKP.Address(), "TBU2ERG3NI4YDJQNXLQC4RFGKZL2ZKGV45CKFJNOLJDBRJW7IJXWSOBE"}
+
+	signersFound, err := VerifyChallengeTxSigners(signChallenge(t, challenge, signerKP, clientKP), serverKP.Address(), network.TestNetworkPassphrase, signers...)
+	require.NoError(t, err)
+	assert.Equal(t, []string{signerKP.Address(), clientKP.Address()}, signersFound)
+
+	for _, testCase := range []struct {
+		name      string
+		challenge string
+		signers   []string
+		err       string
+	}{
+		{
+			name:      "not signed by the client",
+			challenge: challenge,
+			signers:   signers,
+			err:       "transaction not signed by any of the signers",
+		},
+		{
+			name:      "unrecognized signer",
+			challenge: signChallenge(t, challenge, clientKP, otherKP),
+			signers:   signers,
+			err:       "transaction has unrecognized signatures",
+		},
+		{
+			name:      "duplicate signature",
+			challenge: signChallenge(t, challenge, clientKP, clientKP),
+			signers:   signers,
+			err:       "transaction has a duplicate signature from " + clientKP.Address(),
+		},
+		{
+			name:      "duplicate server signature",
+			challenge: signChallenge(t, challenge, clientKP, serverKP),
+			signers:   signers,
+			err:       "transaction has a duplicate signature from " + serverKP.Address(),
+		},
+		{
+			name:      "no ed25519 signer",
+			challenge: signChallenge(t, challenge, clientKP),
+			signers:   []string{serverKP.Address()},
+			err:       "no ed25519 signer provided",
+		},
+	} {
+		_, err := VerifyChallengeTxSigners(testCase.challenge, serverKP.Address(), network.TestNetworkPassphrase, testCase.signers...)
+		assert.EqualError(t, err, testCase.err, testCase.name)
+	}
+}
+
+func TestVerifyChallengeTxThreshold(t *testing.T) {
+	serverKP := newKeypair0()
+	clientKP := newKeypair1()
+	signerKP := newKeypair2()
+	challenge, err := BuildChallengeTx(serverKP.Seed(), clientKP.Address(), "sdf", network.TestNetworkPassphrase, time.Minute)
+	require.NoError(t, err)
+	signers := []Signer{
+		{Address: clientKP.Address(), Weight: 1},
+		{Address: signerKP.Address(), Weight: 2},
+	}
+
+	signersFound, err := VerifyChallengeTxThreshold(signChallenge(t, challenge, clientKP, signerKP), serverKP.Address(), network.TestNetworkPassphrase, 3, signers)
+	require.NoError(t, err)
+	assert.Equal(t, signers, signersFound)
+
+	signersFound, err = VerifyChallengeTxThreshold(signChallenge(t, challenge, signerKP), serverKP.Address(), network.TestNetworkPassphrase, 3, signers)
+	assert.EqualError(t, err, "signers with weight 2 do not meet threshold 3")
+	assert.Equal(t, signers[1:], signersFound)
+
+	// signers with a weight of 0 cannot sign
+	signers[0].Weight = 0
+	_, err = VerifyChallengeTxThreshold(signChallenge(t, challenge, clientKP, signerKP), serverKP.Address(), network.TestNetworkPassphrase, 2, signers)
+	assert.EqualError(t, err, "transaction has unrecognized signatures")
+}
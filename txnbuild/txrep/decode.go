@@ -0,0 +1,431 @@
+package txrep
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// decoder reads the fields of an envelope from Txrep lines. The first error met is kept in err,
+// and the methods reading fields return zero values once it is set, so that errors only need to
+// be checked once a whole envelope is read.
+type decoder struct {
+	values map[string]string
+	used   map[string]bool
+	err    error
+}
+
+func newDecoder(txrep string) (*decoder, error) {
+	d := &decoder{values: map[string]string{}, used: map[string]bool{}}
+	for i, line := range strings.Split(txrep, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		sep := strings.Index(line, ":")
+		if sep < 0 {
+			return nil, errors.Errorf("line %d: missing ':' separator", i+1)
+		}
+		key := strings.TrimSpace(line[:sep])
+		if _, ok := d.values[key]; ok {
+			return nil, errors.Errorf("line %d: duplicate field %s", i+1, key)
+		}
+		d.values[key] = strings.TrimSpace(line[sep+1:])
+	}
+	return d, nil
+}
+
+func (d *decoder) envelope() (xdr.TransactionEnvelope, error) {
+	var envelope xdr.TransactionEnvelope
+	tx := &envelope.Tx
+	tx.SourceAccount = d.account("tx.sourceAccount")
+	tx.Fee = xdr.Uint32(d.unsigned("tx.fee", 32))
+	tx.SeqNum = xdr.SequenceNumber(d.integer("tx.seqNum", 64))
+	if d.present("tx.timeBounds") {
+		tx.TimeBounds = &xdr.TimeBounds{
+			MinTime: xdr.TimePoint(d.unsigned("tx.timeBounds.minTime", 64)),
+			MaxTime: xdr.TimePoint(d.unsigned("tx.timeBounds.maxTime", 64)),
+		}
+	}
+	tx.Memo = d.memo("tx.memo")
+
+	tx.Operations = make([]xdr.Operation, d.length("tx.operations.len"))
+	for i := range tx.Operations {
+		tx.Operations[i] = d.operation(fmt.Sprintf("tx.operations[%d]", i))
+	}
+	tx.Ext.V = int32(d.integer("tx.ext.v", 32))
+
+	envelope.Signatures = make([]xdr.DecoratedSignature, d.length("signatures.len"))
+	for i := range envelope.Signatures {
+		prefix := fmt.Sprintf("signatures[%d]", i)
+		copy(envelope.Signatures[i].Hint[:], d.bytes(prefix+".hint", len(envelope.Signatures[i].Hint)))
+		envelope.Signatures[i].Signature = d.bytes(prefix+".signature", -1)
+	}
+
+	if d.err == nil {
+		d.checkUnused()
+	}
+	return envelope, d.err
+}
+
+func (d *decoder) memo(prefix string) xdr.Memo {
+	name := d.token(prefix + ".type")
+	var value interface{}
+	for memoType, memoName := range memoTypes {
+		if memoName != name {
+			continue
+		}
+		switch memoType {
+		case xdr.MemoTypeMemoText:
+			value = d.quoted(prefix + ".text")
+		case xdr.MemoTypeMemoId:
+			value = xdr.Uint64(d.unsigned(prefix+".id", 64))
+		case xdr.MemoTypeMemoHash:
+			value = d.hash(prefix + ".hash")
+		case xdr.MemoTypeMemoReturn:
+			value = d.hash(prefix + ".retHash")
+		}
+		memo, err := xdr.NewMemo(memoType, value)
+		d.fail(prefix, err)
+		return memo
+	}
+	d.fail(prefix+".type", errors.Errorf("unknown memo type %s", name))
+	return xdr.Memo{}
+}
+
+func (d *decoder) operation(prefix string) xdr.Operation {
+	var op xdr.Operation
+	if d.present(prefix + ".sourceAccount") {
+		source := d.account(prefix + ".sourceAccount")
+		op.SourceAccount = &source
+	}
+
+	name := d.token(prefix + ".body.type")
+	for opType, names := range operationTypes {
+		if names.name != name {
+			continue
+		}
+		value := d.operationBody(prefix+".body."+names.body, opType)
+		body, err := xdr.NewOperationBody(opType, value)
+		d.fail(prefix+".body", err)
+		op.Body = body
+		return op
+	}
+	d.fail(prefix+".body.type", errors.Errorf("unknown operation type %s", name))
+	return op
+}
+
+// operationBody returns the body of an operation of type opType, as expected by
+// xdr.NewOperationBody.
+func (d *decoder) operationBody(prefix string, opType xdr.OperationType) interface{} {
+	switch opType {
+	case xdr.OperationTypeCreateAccount:
+		return xdr.CreateAccountOp{
+			Destination:     d.account(prefix + ".destination"),
+			StartingBalance: d.amount(prefix + ".startingBalance"),
+		}
+	case xdr.OperationTypePayment:
+		return xdr.PaymentOp{
+			Destination: d.account(prefix + ".destination"),
+			Asset:       d.asset(prefix + ".asset"),
+			Amount:      d.amount(prefix + ".amount"),
+		}
+	case xdr.OperationTypePathPaymentStrictReceive:
+		return xdr.PathPaymentStrictReceiveOp{
+			SendAsset:   d.asset(prefix + ".sendAsset"),
+			SendMax:     d.amount(prefix + ".sendMax"),
+			Destination: d.account(prefix + ".destination"),
+			DestAsset:   d.asset(prefix + ".destAsset"),
+			DestAmount:  d.amount(prefix + ".destAmount"),
+			Path:        d.path(prefix + ".path"),
+		}
+	case xdr.OperationTypeManageSellOffer:
+		return xdr.ManageSellOfferOp{
+			Selling: d.asset(prefix + ".selling"),
+			Buying:  d.asset(prefix + ".buying"),
+			Amount:  d.amount(prefix + ".amount"),
+			Price:   d.price(prefix + ".price"),
+			OfferId: xdr.Int64(d.integer(prefix+".offerID", 64)),
+		}
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		return xdr.CreatePassiveSellOfferOp{
+			Selling: d.asset(prefix + ".selling"),
+			Buying:  d.asset(prefix + ".buying"),
+			Amount:  d.amount(prefix + ".amount"),
+			Price:   d.price(prefix + ".price"),
+		}
+	case xdr.OperationTypeSetOptions:
+		return d.setOptions(prefix)
+	case xdr.OperationTypeChangeTrust:
+		return xdr.ChangeTrustOp{
+			Line:  d.asset(prefix + ".line"),
+			Limit: d.amount(prefix + ".limit"),
+		}
+	case xdr.OperationTypeAllowTrust:
+		return xdr.AllowTrustOp{
+			Trustor:   d.account(prefix + ".trustor"),
+			Asset:     d.allowTrustAsset(prefix + ".asset"),
+			Authorize: d.boolean(prefix + ".authorize"),
+		}
+	case xdr.OperationTypeAccountMerge:
+		return d.account(prefix)
+	case xdr.OperationTypeManageData:
+		op := xdr.ManageDataOp{DataName: xdr.String64(d.quoted(prefix + ".dataName"))}
+		if d.present(prefix + ".dataValue") {
+			value := xdr.DataValue(d.bytes(prefix+".dataValue", -1))
+			op.DataValue = &value
+		}
+		return op
+	case xdr.OperationTypeBumpSequence:
+		return xdr.BumpSequenceOp{BumpTo: xdr.SequenceNumber(d.integer(prefix+".bumpTo", 64))}
+	case xdr.OperationTypeManageBuyOffer:
+		return xdr.ManageBuyOfferOp{
+			Selling:   d.asset(prefix + ".selling"),
+			Buying:    d.asset(prefix + ".buying"),
+			BuyAmount: d.amount(prefix + ".buyAmount"),
+			Price:     d.price(prefix + ".price"),
+			OfferId:   xdr.Int64(d.integer(prefix+".offerID", 64)),
+		}
+	case xdr.OperationTypePathPaymentStrictSend:
+		return xdr.PathPaymentStrictSendOp{
+			SendAsset:   d.asset(prefix + ".sendAsset"),
+			SendAmount:  d.amount(prefix + ".sendAmount"),
+			Destination: d.account(prefix + ".destination"),
+			DestAsset:   d.asset(prefix + ".destAsset"),
+			DestMin:     d.amount(prefix + ".destMin"),
+			Path:        d.path(prefix + ".path"),
+		}
+	}
+	// inflation operations have no body
+	return nil
+}
+
+func (d *decoder) setOptions(prefix string) xdr.SetOptionsOp {
+	var op xdr.SetOptionsOp
+	if d.present(prefix + ".inflationDest") {
+		inflationDest := d.account(prefix + ".inflationDest")
+		op.InflationDest = &inflationDest
+	}
+	for _, field := range []struct {
+		name  string
+		value **xdr.Uint32
+	}{
+		{"clearFlags", &op.ClearFlags},
+		{"setFlags", &op.SetFlags},
+		{"masterWeight", &op.MasterWeight},
+		{"lowThreshold", &op.LowThreshold},
+		{"medThreshold", &op.MedThreshold},
+		{"highThreshold", &op.HighThreshold},
+	} {
+		if d.present(prefix + "." + field.name) {
+			value := xdr.Uint32(d.unsigned(prefix+"."+field.name, 32))
+			*field.value = &value
+		}
+	}
+	if d.present(prefix + ".homeDomain") {
+		homeDomain := xdr.String32(d.quoted(prefix + ".homeDomain"))
+		op.HomeDomain = &homeDomain
+	}
+	if d.present(prefix + ".signer") {
+		signer := xdr.Signer{Weight: xdr.Uint32(d.unsigned(prefix+".signer.weight", 32))}
+		key := prefix + ".signer.key"
+		d.fail(key, signer.Key.SetAddress(d.token(key)))
+		op.Signer = &signer
+	}
+	return op
+}
+
+// fail records err, if it is the first error met, as an error of the field key.
+func (d *decoder) fail(key string, err error) {
+	if err != nil && d.err == nil {
+		d.err = errors.Wrapf(err, "invalid %s", key)
+	}
+}
+
+// token returns the value of the field key, without the comment following it.
+func (d *decoder) token(key string) string {
+	if d.err != nil {
+		return ""
+	}
+	value, ok := d.values[key]
+	if !ok {
+		d.err = errors.Errorf("missing field %s", key)
+		return ""
+	}
+	d.used[key] = true
+	if fields := strings.Fields(value); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// quoted returns the double-quoted string value of the field key.
+func (d *decoder) quoted(key string) string {
+	d.token(key)
+	if d.err != nil {
+		return ""
+	}
+	value := d.values[key]
+	// find the closing quote, skipping escaped characters
+	end := -1
+	for i := 1; i < len(value); i++ {
+		if value[i] == '\\' {
+			i++
+		} else if value[i] == '"' {
+			end = i
+			break
+		}
+	}
+	if value == "" || value[0] != '"' || end < 0 {
+		d.fail(key, errors.New("string is not double-quoted"))
+		return ""
+	}
+	s, err := strconv.Unquote(value[:end+1])
+	d.fail(key, err)
+	return s
+}
+
+func (d *decoder) present(key string) bool {
+	return d.boolean(key + "._present")
+}
+
+func (d *decoder) boolean(key string) bool {
+	value, err := strconv.ParseBool(d.token(key))
+	d.fail(key, err)
+	return value
+}
+
+func (d *decoder) integer(key string, bitSize int) int64 {
+	value, err := strconv.ParseInt(d.token(key), 10, bitSize)
+	d.fail(key, err)
+	return value
+}
+
+func (d *decoder) unsigned(key string, bitSize int) uint64 {
+	value, err := strconv.ParseUint(d.token(key), 10, bitSize)
+	d.fail(key, err)
+	return value
+}
+
+func (d *decoder) amount(key string) xdr.Int64 {
+	return xdr.Int64(d.integer(key, 64))
+}
+
+// length returns the value of a .len field, which is bounded to keep bogus documents from
+// allocating large slices.
+func (d *decoder) length(key string) int {
+	value := d.unsigned(key, 32)
+	if value > 100 {
+		d.fail(key, errors.New("length is too large"))
+		return 0
+	}
+	return int(value)
+}
+
+// bytes returns the hex-encoded value of the field key, which must hold size bytes unless size is
+// negative.
+func (d *decoder) bytes(key string, size int) []byte {
+	value, err := hex.DecodeString(d.token(key))
+	if err == nil && size >= 0 && len(value) != size {
+		err = errors.Errorf("expected %d bytes, got %d", size, len(value))
+	}
+	d.fail(key, err)
+	return value
+}
+
+func (d *decoder) hash(key string) xdr.Hash {
+	var hash xdr.Hash
+	copy(hash[:], d.bytes(key, len(hash)))
+	return hash
+}
+
+func (d *decoder) account(key string) xdr.AccountId {
+	var account xdr.AccountId
+	if token := d.token(key); d.err == nil {
+		d.fail(key, account.SetAddress(token))
+	}
+	return account
+}
+
+func (d *decoder) asset(key string) xdr.Asset {
+	token := d.token(key)
+	if d.err != nil {
+		return xdr.Asset{}
+	}
+	if token == "XLM" || token == "native" {
+		return xdr.MustNewNativeAsset()
+	}
+
+	parts := strings.SplitN(token, ":", 2)
+	if len(parts) != 2 {
+		d.fail(key, errors.Errorf("asset %s is not XLM or CODE:ISSUER", token))
+		return xdr.Asset{}
+	}
+	assetType := "credit_alphanum4"
+	if len(parts[0]) > 4 {
+		assetType = "credit_alphanum12"
+	}
+	asset, err := xdr.BuildAsset(assetType, parts[1], parts[0])
+	d.fail(key, err)
+	return asset
+}
+
+func (d *decoder) allowTrustAsset(key string) xdr.AllowTrustOpAsset {
+	code := d.token(key)
+	if d.err != nil {
+		return xdr.AllowTrustOpAsset{}
+	}
+
+	var (
+		asset xdr.AllowTrustOpAsset
+		err   error
+	)
+	switch {
+	case len(code) >= 1 && len(code) <= 4:
+		var code4 xdr.AssetCode4
+		copy(code4[:], code)
+		asset, err = xdr.NewAllowTrustOpAsset(xdr.AssetTypeAssetTypeCreditAlphanum4, code4)
+	case len(code) >= 5 && len(code) <= 12:
+		var code12 xdr.AssetCode12
+		copy(code12[:], code)
+		asset, err = xdr.NewAllowTrustOpAsset(xdr.AssetTypeAssetTypeCreditAlphanum12, code12)
+	default:
+		err = errors.Errorf("asset code %s must hold 1 to 12 characters", code)
+	}
+	d.fail(key, err)
+	return asset
+}
+
+func (d *decoder) price(key string) xdr.Price {
+	return xdr.Price{
+		N: xdr.Int32(d.integer(key+".n", 32)),
+		D: xdr.Int32(d.integer(key+".d", 32)),
+	}
+}
+
+func (d *decoder) path(key string) []xdr.Asset {
+	path := make([]xdr.Asset, d.length(key+".len"))
+	for i := range path {
+		path[i] = d.asset(fmt.Sprintf("%s[%d]", key, i))
+	}
+	return path
+}
+
+// checkUnused fails when the document holds fields which are not part of the envelope.
+func (d *decoder) checkUnused() {
+	var unused []string
+	for key := range d.values {
+		if !d.used[key] {
+			unused = append(unused, key)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		d.err = errors.Errorf("unexpected fields: %s", strings.Join(unused, ", "))
+	}
+}
@@ -0,0 +1,274 @@
+package txrep
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// encoder writes the fields of an envelope as Txrep lines.
+type encoder struct {
+	lines []string
+}
+
+func (e *encoder) String() string {
+	return strings.Join(e.lines, "\n") + "\n"
+}
+
+// add writes the line of a field, followed by comment when it is not empty.
+func (e *encoder) add(key, value, comment string) {
+	line := key + ": " + value
+	if comment != "" {
+		line += " (" + comment + ")"
+	}
+	e.lines = append(e.lines, line)
+}
+
+func (e *encoder) envelope(envelope xdr.TransactionEnvelope) error {
+	tx := envelope.Tx
+	e.account("tx.sourceAccount", tx.SourceAccount)
+	e.unsigned("tx.fee", uint64(tx.Fee))
+	e.integer("tx.seqNum", int64(tx.SeqNum))
+
+	e.present("tx.timeBounds", tx.TimeBounds != nil)
+	if tx.TimeBounds != nil {
+		e.timePoint("tx.timeBounds.minTime", tx.TimeBounds.MinTime)
+		e.timePoint("tx.timeBounds.maxTime", tx.TimeBounds.MaxTime)
+	}
+
+	if err := e.memo("tx.memo", tx.Memo); err != nil {
+		return err
+	}
+
+	e.unsigned("tx.operations.len", uint64(len(tx.Operations)))
+	for i, op := range tx.Operations {
+		if err := e.operation(fmt.Sprintf("tx.operations[%d]", i), op); err != nil {
+			return err
+		}
+	}
+	e.integer("tx.ext.v", int64(tx.Ext.V))
+
+	e.unsigned("signatures.len", uint64(len(envelope.Signatures)))
+	for i, sig := range envelope.Signatures {
+		prefix := fmt.Sprintf("signatures[%d]", i)
+		e.add(prefix+".hint", hex.EncodeToString(sig.Hint[:]), "")
+		e.add(prefix+".signature", hex.EncodeToString(sig.Signature), "")
+	}
+	return nil
+}
+
+func (e *encoder) memo(prefix string, memo xdr.Memo) error {
+	name, ok := memoTypes[memo.Type]
+	if !ok {
+		return errors.Errorf("unknown memo type %d", memo.Type)
+	}
+	e.add(prefix+".type", name, "")
+
+	switch memo.Type {
+	case xdr.MemoTypeMemoText:
+		e.quoted(prefix+".text", memo.MustText())
+	case xdr.MemoTypeMemoId:
+		e.unsigned(prefix+".id", uint64(memo.MustId()))
+	case xdr.MemoTypeMemoHash:
+		hash := memo.MustHash()
+		e.add(prefix+".hash", hex.EncodeToString(hash[:]), "")
+	case xdr.MemoTypeMemoReturn:
+		hash := memo.MustRetHash()
+		e.add(prefix+".retHash", hex.EncodeToString(hash[:]), "")
+	}
+	return nil
+}
+
+func (e *encoder) operation(prefix string, op xdr.Operation) error {
+	e.present(prefix+".sourceAccount", op.SourceAccount != nil)
+	if op.SourceAccount != nil {
+		e.account(prefix+".sourceAccount", *op.SourceAccount)
+	}
+
+	opType, ok := operationTypes[op.Body.Type]
+	if !ok {
+		return errors.Errorf("unsupported operation type %d", op.Body.Type)
+	}
+	e.add(prefix+".body.type", opType.name, "")
+	prefix += ".body." + opType.body
+
+	body := op.Body
+	switch body.Type {
+	case xdr.OperationTypeCreateAccount:
+		op := body.MustCreateAccountOp()
+		e.account(prefix+".destination", op.Destination)
+		e.amount(prefix+".startingBalance", op.StartingBalance)
+	case xdr.OperationTypePayment:
+		op := body.MustPaymentOp()
+		e.account(prefix+".destination", op.Destination)
+		e.asset(prefix+".asset", op.Asset)
+		e.amount(prefix+".amount", op.Amount)
+	case xdr.OperationTypePathPaymentStrictReceive:
+		op := body.MustPathPaymentStrictReceiveOp()
+		e.asset(prefix+".sendAsset", op.SendAsset)
+		e.amount(prefix+".sendMax", op.SendMax)
+		e.account(prefix+".destination", op.Destination)
+		e.asset(prefix+".destAsset", op.DestAsset)
+		e.amount(prefix+".destAmount", op.DestAmount)
+		e.path(prefix+".path", op.Path)
+	case xdr.OperationTypeManageSellOffer:
+		op := body.MustManageSellOfferOp()
+		e.asset(prefix+".selling", op.Selling)
+		e.asset(prefix+".buying", op.Buying)
+		e.amount(prefix+".amount", op.Amount)
+		e.price(prefix+".price", op.Price)
+		e.integer(prefix+".offerID", int64(op.OfferId))
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		op := body.MustCreatePassiveSellOfferOp()
+		e.asset(prefix+".selling", op.Selling)
+		e.asset(prefix+".buying", op.Buying)
+		e.amount(prefix+".amount", op.Amount)
+		e.price(prefix+".price", op.Price)
+	case xdr.OperationTypeSetOptions:
+		e.setOptions(prefix, body.MustSetOptionsOp())
+	case xdr.OperationTypeChangeTrust:
+		op := body.MustChangeTrustOp()
+		e.asset(prefix+".line", op.Line)
+		e.amount(prefix+".limit", op.Limit)
+	case xdr.OperationTypeAllowTrust:
+		op := body.MustAllowTrustOp()
+		e.account(prefix+".trustor", op.Trustor)
+		e.add(prefix+".asset", allowTrustAssetCode(op.Asset), "")
+		e.add(prefix+".authorize", strconv.FormatBool(op.Authorize), "")
+	case xdr.OperationTypeAccountMerge:
+		e.account(prefix, body.MustDestination())
+	case xdr.OperationTypeInflation:
+		// inflation operations have no body
+	case xdr.OperationTypeManageData:
+		op := body.MustManageDataOp()
+		e.quoted(prefix+".dataName", string(op.DataName))
+		e.present(prefix+".dataValue", op.DataValue != nil)
+		if op.DataValue != nil {
+			e.add(prefix+".dataValue", hex.EncodeToString(*op.DataValue), "")
+		}
+	case xdr.OperationTypeBumpSequence:
+		e.integer(prefix+".bumpTo", int64(body.MustBumpSequenceOp().BumpTo))
+	case xdr.OperationTypeManageBuyOffer:
+		op := body.MustManageBuyOfferOp()
+		e.asset(prefix+".selling", op.Selling)
+		e.asset(prefix+".buying", op.Buying)
+		e.amount(prefix+".buyAmount", op.BuyAmount)
+		e.price(prefix+".price", op.Price)
+		e.integer(prefix+".offerID", int64(op.OfferId))
+	case xdr.OperationTypePathPaymentStrictSend:
+		op := body.MustPathPaymentStrictSendOp()
+		e.asset(prefix+".sendAsset", op.SendAsset)
+		e.amount(prefix+".sendAmount", op.SendAmount)
+		e.account(prefix+".destination", op.Destination)
+		e.asset(prefix+".destAsset", op.DestAsset)
+		e.amount(prefix+".destMin", op.DestMin)
+		e.path(prefix+".path", op.Path)
+	}
+	return nil
+}
+
+func (e *encoder) setOptions(prefix string, op xdr.SetOptionsOp) {
+	e.present(prefix+".inflationDest", op.InflationDest != nil)
+	if op.InflationDest != nil {
+		e.account(prefix+".inflationDest", *op.InflationDest)
+	}
+	for _, field := range []struct {
+		name  string
+		value *xdr.Uint32
+	}{
+		{"clearFlags", op.ClearFlags},
+		{"setFlags", op.SetFlags},
+		{"masterWeight", op.MasterWeight},
+		{"lowThreshold", op.LowThreshold},
+		{"medThreshold", op.MedThreshold},
+		{"highThreshold", op.HighThreshold},
+	} {
+		e.present(prefix+"."+field.name, field.value != nil)
+		if field.value != nil {
+			e.unsigned(prefix+"."+field.name, uint64(*field.value))
+		}
+	}
+	e.present(prefix+".homeDomain", op.HomeDomain != nil)
+	if op.HomeDomain != nil {
+		e.quoted(prefix+".homeDomain", string(*op.HomeDomain))
+	}
+	e.present(prefix+".signer", op.Signer != nil)
+	if op.Signer != nil {
+		e.add(prefix+".signer.key", op.Signer.Key.Address(), "")
+		e.unsigned(prefix+".signer.weight", uint64(op.Signer.Weight))
+	}
+}
+
+func (e *encoder) present(key string, present bool) {
+	e.add(key+"._present", strconv.FormatBool(present), "")
+}
+
+func (e *encoder) account(key string, account xdr.AccountId) {
+	e.add(key, account.Address(), "")
+}
+
+func (e *encoder) integer(key string, value int64) {
+	e.add(key, strconv.FormatInt(value, 10), "")
+}
+
+func (e *encoder) unsigned(key string, value uint64) {
+	e.add(key, strconv.FormatUint(value, 10), "")
+}
+
+func (e *encoder) quoted(key, value string) {
+	e.add(key, strconv.Quote(value), "")
+}
+
+// amount writes an amount in stroops, with its value in lumens or asset units as a comment.
+func (e *encoder) amount(key string, value xdr.Int64) {
+	units := strings.TrimRight(strings.TrimRight(amount.String(value), "0"), ".")
+	e.add(key, strconv.FormatInt(int64(value), 10), units+"e7")
+}
+
+// timePoint writes a time point, with the date it stands for as a comment.
+func (e *encoder) timePoint(key string, value xdr.TimePoint) {
+	comment := ""
+	if value != 0 {
+		comment = time.Unix(int64(value), 0).UTC().Format(time.UnixDate)
+	}
+	e.add(key, strconv.FormatUint(uint64(value), 10), comment)
+}
+
+func (e *encoder) asset(key string, asset xdr.Asset) {
+	var assetType, code, issuer string
+	asset.MustExtract(&assetType, &code, &issuer)
+	if asset.Type == xdr.AssetTypeAssetTypeNative {
+		e.add(key, "XLM", "")
+		return
+	}
+	e.add(key, code+":"+issuer, "")
+}
+
+func (e *encoder) price(key string, price xdr.Price) {
+	e.integer(key+".n", int64(price.N))
+	e.integer(key+".d", int64(price.D))
+}
+
+func (e *encoder) path(key string, path []xdr.Asset) {
+	e.unsigned(key+".len", uint64(len(path)))
+	for i, asset := range path {
+		e.asset(fmt.Sprintf("%s[%d]", key, i), asset)
+	}
+}
+
+func allowTrustAssetCode(asset xdr.AllowTrustOpAsset) string {
+	var code []byte
+	switch asset.Type {
+	case xdr.AssetTypeAssetTypeCreditAlphanum4:
+		code = asset.AssetCode4[:]
+	case xdr.AssetTypeAssetTypeCreditAlphanum12:
+		code = asset.AssetCode12[:]
+	}
+	return strings.TrimRight(string(code), "\x00")
+}
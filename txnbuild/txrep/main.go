@@ -0,0 +1,96 @@
+// Package txrep converts transactions to and from Txrep, the human-readable representation of
+// transaction envelopes defined by SEP-11:
+// https://github.com/stellar/stellar-protocol/blob/master/ecosystem/sep-0011.md
+//
+// Each line of a Txrep document holds one field of the envelope, identified by its XDR path:
+//
+//	tx.sourceAccount: GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7
+//	tx.fee: 100
+//	tx.operations[0].body.type: PAYMENT
+//	tx.operations[0].body.paymentOp.amount: 400004000 (40.0004e7)
+//
+// Text in parentheses after a value is a comment, ignored when decoding. Accounts are written as
+// strkeys, assets as XLM or CODE:ISSUER, strings double-quoted with Go escapes and opaque values
+// in hex. Decoding is strict: every field of the envelope must be present and unknown fields are
+// rejected, so that converting an envelope to Txrep and back is lossless.
+package txrep
+
+import (
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+// FromEnvelope returns the Txrep representation of envelope.
+func FromEnvelope(envelope xdr.TransactionEnvelope) (string, error) {
+	e := &encoder{}
+	if err := e.envelope(envelope); err != nil {
+		return "", err
+	}
+	return e.String(), nil
+}
+
+// ToEnvelope decodes a Txrep document into a transaction envelope.
+func ToEnvelope(txrep string) (xdr.TransactionEnvelope, error) {
+	d, err := newDecoder(txrep)
+	if err != nil {
+		return xdr.TransactionEnvelope{}, err
+	}
+	return d.envelope()
+}
+
+// FromTransaction returns the Txrep representation of a previously built transaction, including
+// its signatures.
+func FromTransaction(tx *txnbuild.Transaction) (string, error) {
+	envelope := tx.TxEnvelope()
+	if envelope == nil {
+		return "", errors.New("transaction has not been built")
+	}
+	return FromEnvelope(*envelope)
+}
+
+// ToTransaction decodes a Txrep document into a transaction for the network with the given
+// passphrase.
+func ToTransaction(txrep, network string) (txnbuild.Transaction, error) {
+	envelope, err := ToEnvelope(txrep)
+	if err != nil {
+		return txnbuild.Transaction{}, err
+	}
+	txeB64, err := xdr.MarshalBase64(envelope)
+	if err != nil {
+		return txnbuild.Transaction{}, errors.Wrap(err, "failed to encode envelope")
+	}
+	tx, err := txnbuild.TransactionFromXDR(txeB64)
+	if err != nil {
+		return txnbuild.Transaction{}, err
+	}
+	tx.Network = network
+	return tx, nil
+}
+
+// operationTypes maps operation types to their SEP-11 name and to the name of their body field.
+var operationTypes = map[xdr.OperationType]struct{ name, body string }{
+	xdr.OperationTypeCreateAccount:            {"CREATE_ACCOUNT", "createAccountOp"},
+	xdr.OperationTypePayment:                  {"PAYMENT", "paymentOp"},
+	xdr.OperationTypePathPaymentStrictReceive: {"PATH_PAYMENT_STRICT_RECEIVE", "pathPaymentStrictReceiveOp"},
+	xdr.OperationTypeManageSellOffer:          {"MANAGE_SELL_OFFER", "manageSellOfferOp"},
+	xdr.OperationTypeCreatePassiveSellOffer:   {"CREATE_PASSIVE_SELL_OFFER", "createPassiveSellOfferOp"},
+	xdr.OperationTypeSetOptions:               {"SET_OPTIONS", "setOptionsOp"},
+	xdr.OperationTypeChangeTrust:              {"CHANGE_TRUST", "changeTrustOp"},
+	xdr.OperationTypeAllowTrust:               {"ALLOW_TRUST", "allowTrustOp"},
+	xdr.OperationTypeAccountMerge:             {"ACCOUNT_MERGE", "destination"},
+	xdr.OperationTypeInflation:                {"INFLATION", ""},
+	xdr.OperationTypeManageData:               {"MANAGE_DATA", "manageDataOp"},
+	xdr.OperationTypeBumpSequence:             {"BUMP_SEQUENCE", "bumpSequenceOp"},
+	xdr.OperationTypeManageBuyOffer:           {"MANAGE_BUY_OFFER", "manageBuyOfferOp"},
+	xdr.OperationTypePathPaymentStrictSend:    {"PATH_PAYMENT_STRICT_SEND", "pathPaymentStrictSendOp"},
+}
+
+// memoTypes maps memo types to their SEP-11 name.
+var memoTypes = map[xdr.MemoType]string{
+	xdr.MemoTypeMemoNone:   "MEMO_NONE",
+	xdr.MemoTypeMemoText:   "MEMO_TEXT",
+	xdr.MemoTypeMemoId:     "MEMO_ID",
+	xdr.MemoTypeMemoHash:   "MEMO_HASH",
+	xdr.MemoTypeMemoReturn: "MEMO_RETURN",
+}
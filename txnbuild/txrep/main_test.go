@@ -0,0 +1,208 @@
+package txrep
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	seed0 = "SBPQUZ6G4FZNWFHKUWC5BEYWF6R52E3SEP7R3GWYSM2XTKGF5LNTWW4R"
+	seed1 = "SBMSVD4KKELKGZXHBUQTIROWUAPQASDX7KEJITARP4VMZ6KLUHOGPTYW"
+)
+
+func TestTransactionRoundTrip(t *testing.T) {
+	kp0 := keypair.MustParse(seed0).(*keypair.Full)
+	kp1 := keypair.MustParse(seed1).(*keypair.Full)
+	source := txnbuild.NewSimpleAccount(kp0.Address(), 9605939170639897)
+	opSource := txnbuild.NewSimpleAccount(kp1.Address(), 0)
+	usd := txnbuild.CreditAsset{Code: "USD", Issuer: kp1.Address()}
+	long := txnbuild.CreditAsset{Code: "LONGASSET", Issuer: kp1.Address()}
+	homeDomain := "example.com"
+	inflationDest := kp1.Address()
+
+	tx := txnbuild.Transaction{
+		SourceAccount: &source,
+		Operations: []txnbuild.Operation{
+			&txnbuild.CreateAccount{Destination: kp1.Address(), Amount: "10", SourceAccount: &opSource},
+			&txnbuild.Payment{Destination: kp1.Address(), Amount: "40.0004", Asset: usd},
+			&txnbuild.PathPaymentStrictReceive{
+				SendAsset:   txnbuild.NativeAsset{},
+				SendMax:     "10",
+				Destination: kp1.Address(),
+				DestAsset:   usd,
+				DestAmount:  "1",
+				Path:        []txnbuild.Asset{long, txnbuild.NativeAsset{}},
+			},
+			&txnbuild.ManageSellOffer{Selling: usd, Buying: txnbuild.NativeAsset{}, Amount: "3", Price: "0.5", OfferID: 12},
+			&txnbuild.CreatePassiveSellOffer{Selling: long, Buying: usd, Amount: "1", Price: "2"},
+			&txnbuild.SetOptions{
+				InflationDestination: &inflationDest,
+				SetFlags:             []txnbuild.AccountFlag{txnbuild.AuthRequired},
+				MasterWeight:         txnbuild.NewThreshold(2),
+				HighThreshold:        txnbuild.NewThreshold(3),
+				HomeDomain:           &homeDomain,
+				Signer:               &txnbuild.Signer{Address: kp1.Address(), Weight: 1},
+			},
+			&txnbuild.SetOptions{},
+			&txnbuild.ChangeTrust{Line: long, Limit: "1000"},
+			&txnbuild.AllowTrust{Trustor: kp1.Address(), Type: usd, Authorize: true},
+			&txnbuild.AllowTrust{Trustor: kp1.Address(), Type: long},
+			&txnbuild.AccountMerge{Destination: kp1.Address()},
+			&txnbuild.Inflation{},
+			&txnbuild.ManageData{Name: "name with \"quotes\"", Value: []byte{0, 1, 2}},
+			&txnbuild.ManageData{Name: "deleted"},
+			&txnbuild.BumpSequence{BumpTo: 9605939170639999},
+			&txnbuild.ManageBuyOffer{Selling: txnbuild.NativeAsset{}, Buying: long, Amount: "5", Price: "1.25"},
+			&txnbuild.PathPaymentStrictSend{
+				SendAsset:   usd,
+				SendAmount:  "1",
+				Destination: kp0.Address(),
+				DestAsset:   txnbuild.NativeAsset{},
+				DestMin:     "0.0000001",
+			},
+		},
+		Memo:       txnbuild.MemoText("hello (world)"),
+		Timebounds: txnbuild.NewTimebounds(1577836800, 1577840400),
+		Network:    network.TestNetworkPassphrase,
+	}
+	require.NoError(t, tx.Build())
+	require.NoError(t, tx.Sign(kp0, kp1))
+	expected, err := tx.Base64()
+	require.NoError(t, err)
+
+	txrep, err := FromTransaction(&tx)
+	require.NoError(t, err)
+	assert.Contains(t, txrep, "tx.operations[1].body.paymentOp.amount: 400004000 (40.0004e7)\n")
+	assert.Contains(t, txrep, "tx.memo.text: \"hello (world)\"\n")
+	assert.Contains(t, txrep, "tx.timeBounds.minTime: 1577836800 (Wed Jan  1 00:00:00 UTC 2020)\n")
+	assert.Contains(t, txrep, "tx.operations[9].body.allowTrustOp.asset: LONGASSET\n")
+	assert.Contains(t, txrep, "tx.operations[10].body.destination: "+kp1.Address()+"\n")
+
+	decoded, err := ToTransaction(txrep, network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	assert.Equal(t, network.TestNetworkPassphrase, decoded.Network)
+	if assert.IsType(t, &txnbuild.ManageData{}, decoded.Operations[13]) {
+		deleted := decoded.Operations[13].(*txnbuild.ManageData)
+		assert.Equal(t, "deleted", deleted.Name)
+		assert.Nil(t, deleted.Value)
+	}
+	actual, err := decoded.Base64()
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	_, err = FromTransaction(&txnbuild.Transaction{})
+	assert.EqualError(t, err, "transaction has not been built")
+}
+
+func TestMemoRoundTrip(t *testing.T) {
+	kp0 := keypair.MustParse(seed0)
+	id := xdr.Uint64(1234567890123)
+	hash := xdr.Hash{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	text := "\t\"quotes\" ünicode"
+
+	for _, memo := range []xdr.Memo{
+		{Type: xdr.MemoTypeMemoNone},
+		{Type: xdr.MemoTypeMemoText, Text: &text},
+		{Type: xdr.MemoTypeMemoId, Id: &id},
+		{Type: xdr.MemoTypeMemoHash, Hash: &hash},
+		{Type: xdr.MemoTypeMemoReturn, RetHash: &hash},
+	} {
+		envelope := xdr.TransactionEnvelope{
+			Tx: xdr.Transaction{
+				SourceAccount: xdr.MustAddress(kp0.Address()),
+				Fee:           100,
+				SeqNum:        1,
+				Memo:          memo,
+				Operations: []xdr.Operation{{
+					Body: xdr.OperationBody{Type: xdr.OperationTypeInflation},
+				}},
+			},
+		}
+		txrep, err := FromEnvelope(envelope)
+		require.NoError(t, err)
+		assert.Contains(t, txrep, "tx.timeBounds._present: false\n")
+
+		decoded, err := ToEnvelope(txrep)
+		require.NoError(t, err)
+		expected, err := xdr.MarshalBase64(envelope)
+		require.NoError(t, err)
+		actual, err := xdr.MarshalBase64(decoded)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual, memoTypes[memo.Type])
+	}
+}
+
+func TestToEnvelopeErrors(t *testing.T) {
+	kp0 := keypair.MustParse(seed0)
+	envelope := xdr.TransactionEnvelope{
+		Tx: xdr.Transaction{
+			SourceAccount: xdr.MustAddress(kp0.Address()),
+			Fee:           100,
+			SeqNum:        1,
+			Operations: []xdr.Operation{{
+				Body: xdr.OperationBody{Type: xdr.OperationTypeBumpSequence, BumpSequenceOp: &xdr.BumpSequenceOp{BumpTo: 2}},
+			}},
+		},
+	}
+	txrep, err := FromEnvelope(envelope)
+	require.NoError(t, err)
+
+	replace := func(old, new string) string {
+		require.Contains(t, txrep, old)
+		return strings.Replace(txrep, old, new, 1)
+	}
+	for _, testCase := range []struct {
+		txrep string
+		err   string
+	}{
+		{
+			txrep: replace("tx.fee: 100\n", ""),
+			err:   "missing field tx.fee",
+		},
+		{
+			txrep: replace("tx.fee: 100\n", "tx.fee: -100\n"),
+			err:   `invalid tx.fee: strconv.ParseUint: parsing "-100": invalid syntax`,
+		},
+		{
+			txrep: replace("tx.fee: 100\n", "tx.fee: 100\ntx.fee: 200\n"),
+			err:   "line 3: duplicate field tx.fee",
+		},
+		{
+			txrep: replace("tx.fee: 100\n", "tx.fee 100\n"),
+			err:   "line 2: missing ':' separator",
+		},
+		{
+			txrep: replace("tx.fee: 100\n", "tx.fee: 100\ntx.unknown: 1\n"),
+			err:   "unexpected fields: tx.unknown",
+		},
+		{
+			txrep: replace("tx.memo.type: MEMO_NONE\n", "tx.memo.type: MEMO_TEXT\ntx.memo.text: unquoted\n"),
+			err:   "invalid tx.memo.text: string is not double-quoted",
+		},
+		{
+			txrep: replace("body.type: BUMP_SEQUENCE", "body.type: BUMP"),
+			err:   "invalid tx.operations[0].body.type: unknown operation type BUMP",
+		},
+		{
+			txrep: replace("sourceAccount: G", "sourceAccount: X"),
+			err:   "invalid tx.sourceAccount",
+		},
+	} {
+		_, err := ToEnvelope(testCase.txrep)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), testCase.err)
+		}
+	}
+
+	// comments and surrounding spaces are ignored
+	decoded, err := ToEnvelope(replace("tx.fee: 100\n", "  tx.fee: 100 (0.00001e7)  \n"))
+	require.NoError(t, err)
+	assert.Equal(t, xdr.Uint32(100), decoded.Tx.Fee)
+}